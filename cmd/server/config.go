@@ -3,8 +3,12 @@ package main
 import (
 	"flag"
 	"log"
+	"time"
 
 	"github.com/caarlos0/env/v6"
+	"github.com/zelas91/goph-keeper/internal/server/models"
+	"github.com/zelas91/goph-keeper/internal/server/storage"
+	"github.com/zelas91/goph-keeper/internal/utils/compress"
 )
 
 var (
@@ -13,6 +17,21 @@ var (
 	cfgLogger        *string
 	basePathSaveFile *string
 	secretKey        *string
+	s3Endpoint       *string
+	s3Region         *string
+	s3Bucket         *string
+	s3AccessKey      *string
+	s3SecretKey      *string
+	quotaBytes       *int64
+	quotaItems       *int
+	maxFileSize      *int64
+	fileCodec        *string
+	gcInterval       *time.Duration
+	emergencyEvery   *time.Duration
+	linkInterval     *time.Duration
+	fsck             *bool
+	fsckFix          *bool
+	reindex          *bool
 	buildCommit      = "N/A"
 	buildDate        = "N/A"
 )
@@ -23,14 +42,45 @@ func init() {
 	cfgLogger = flag.String("l", "cfg/config.json", "config file logger")
 	basePathSaveFile = flag.String("s", "save_file", "dir save file")
 	secretKey = flag.String("ek", "", "encrypt secret key")
+	s3Endpoint = flag.String("s3-endpoint", "", "S3-compatible storage endpoint, the files are kept in the dir without it")
+	s3Region = flag.String("s3-region", "us-east-1", "S3 region")
+	s3Bucket = flag.String("s3-bucket", "", "S3 bucket of the files")
+	s3AccessKey = flag.String("s3-access-key", "", "S3 access key")
+	s3SecretKey = flag.String("s3-secret-key", "", "S3 secret key")
+	quotaBytes = flag.Int64("quota-bytes", 0, "bytes of files a user keeps, 0 is no limit")
+	quotaItems = flag.Int("quota-items", 0, "items a user keeps, 0 is no limit")
+	maxFileSize = flag.Int64("max-file-size", 0, "largest file in bytes, 0 is no limit")
+	fileCodec = flag.String("codec", string(compress.Zstd), "compression of the stored files: zstd, gzip or none")
+	gcInterval = flag.Duration("gc-interval", 24*time.Hour, "interval of the orphan blobs removal, 0 is never")
+	emergencyEvery = flag.Duration("emergency-interval", time.Minute,
+		"interval of granting the emergency accesses whose waiting period passed, 0 is never")
+	linkInterval = flag.Duration("link-interval", time.Hour, "interval of the expired share links removal, 0 is never")
+	fsck = flag.Bool("fsck", false, "check that the file storage and the db agree, print the report and exit")
+	fsckFix = flag.Bool("fsck-fix", false, "with -fsck remove the orphans found on both sides")
+	reindex = flag.Bool("reindex", false, "save the search tokens of the items that have none and exit")
 }
 
 type Config struct {
-	Addr             *string `env:"RUN_ADDRESS"`
-	DBurl            *string `env:"DATABASE_URI"`
-	CfgLogger        *string `env:"CONFIG_LOGGER"`
-	BasePathSaveFile *string `env:"BASE_PATH_SAVE"`
-	SecretKey        *string `env:"ENCRYPT_SECRET_KEY"`
+	Addr             *string        `env:"RUN_ADDRESS"`
+	DBurl            *string        `env:"DATABASE_URI"`
+	CfgLogger        *string        `env:"CONFIG_LOGGER"`
+	BasePathSaveFile *string        `env:"BASE_PATH_SAVE"`
+	SecretKey        *string        `env:"ENCRYPT_SECRET_KEY"`
+	S3Endpoint       *string        `env:"S3_ENDPOINT"`
+	S3Region         *string        `env:"S3_REGION"`
+	S3Bucket         *string        `env:"S3_BUCKET"`
+	S3AccessKey      *string        `env:"S3_ACCESS_KEY"`
+	S3SecretKey      *string        `env:"S3_SECRET_KEY"`
+	QuotaBytes       *int64         `env:"QUOTA_BYTES"`
+	QuotaItems       *int           `env:"QUOTA_ITEMS"`
+	MaxFileSize      *int64         `env:"MAX_FILE_SIZE"`
+	FileCodec        *string        `env:"FILE_CODEC"`
+	GCInterval       *time.Duration `env:"GC_INTERVAL"`
+	EmergencyEvery   *time.Duration `env:"EMERGENCY_INTERVAL"`
+	LinkInterval     *time.Duration `env:"LINK_INTERVAL"`
+	Fsck             *bool
+	FsckFix          *bool
+	Reindex          *bool
 }
 
 func NewConfig() *Config {
@@ -56,7 +106,73 @@ func NewConfig() *Config {
 	if cfg.SecretKey == nil {
 		cfg.SecretKey = secretKey
 	}
+	if cfg.S3Endpoint == nil {
+		cfg.S3Endpoint = s3Endpoint
+	}
+	if cfg.S3Region == nil {
+		cfg.S3Region = s3Region
+	}
+	if cfg.S3Bucket == nil {
+		cfg.S3Bucket = s3Bucket
+	}
+	if cfg.S3AccessKey == nil {
+		cfg.S3AccessKey = s3AccessKey
+	}
+	if cfg.S3SecretKey == nil {
+		cfg.S3SecretKey = s3SecretKey
+	}
+	if cfg.QuotaBytes == nil {
+		cfg.QuotaBytes = quotaBytes
+	}
+	if cfg.QuotaItems == nil {
+		cfg.QuotaItems = quotaItems
+	}
+	if cfg.MaxFileSize == nil {
+		cfg.MaxFileSize = maxFileSize
+	}
+	if cfg.FileCodec == nil {
+		cfg.FileCodec = fileCodec
+	}
+	if cfg.GCInterval == nil {
+		cfg.GCInterval = gcInterval
+	}
+	if cfg.EmergencyEvery == nil {
+		cfg.EmergencyEvery = emergencyEvery
+	}
+	if cfg.LinkInterval == nil {
+		cfg.LinkInterval = linkInterval
+	}
+	cfg.Fsck = fsck
+	cfg.FsckFix = fsckFix
+	cfg.Reindex = reindex
 
 	flag.Parse()
 	return &cfg
 }
+
+// BlobStore is the S3-compatible storage when its endpoint is set,
+// otherwise the local dir.
+func (c *Config) BlobStore() storage.BlobStore {
+	if *c.S3Endpoint == "" {
+		return storage.NewLocal(*c.BasePathSaveFile)
+	}
+	return storage.NewS3(storage.S3Config{
+		Endpoint:  *c.S3Endpoint,
+		Region:    *c.S3Region,
+		Bucket:    *c.S3Bucket,
+		AccessKey: *c.S3AccessKey,
+		SecretKey: *c.S3SecretKey,
+	}, nil)
+}
+
+func (c *Config) Limits() models.Limits {
+	return models.Limits{Bytes: *c.QuotaBytes, Items: *c.QuotaItems, FileSize: *c.MaxFileSize}
+}
+
+func (c *Config) Codec() compress.Codec {
+	codec, err := compress.ParseCodec(*c.FileCodec)
+	if err != nil {
+		log.Fatalf("file codec error=%v", err)
+	}
+	return codec
+}
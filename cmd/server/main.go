@@ -1,7 +1,9 @@
 package main
 
 import (
+	"encoding/json"
 	"errors"
+	"fmt"
 	"net/http"
 	"os/signal"
 	"syscall"
@@ -38,16 +40,75 @@ func main() {
 		services.WithAuthUseRepository(repo.Auth),
 		services.WithCardUseRepository(repo.CreditCard, crypto),
 		services.WithCredentialUseRepository(repo.Credential, crypto),
+		services.WithOTPUseRepository(repo.OTP, crypto),
+		services.WithSSHKeyUseRepository(repo.SSHKey, crypto),
 		services.WithTextUseRepository(repo.TextData, crypto),
-		services.WithBinaryFileUseRepository(repo.BinaryFile, log, *cfg.BasePathSaveFile),
+		services.WithBinaryFileUseRepository(repo.BinaryFile, crypto, log, cfg.BlobStore(), cfg.Codec()),
+		services.WithFolderUseRepository(repo.Folder),
+		services.WithTagUseRepository(repo.Tag),
+		services.WithSearchUseRepository(repo.Search, crypto),
+		services.WithSyncUseRepository(repo.Change),
+		services.WithUploadUseRepository(repo.Upload),
+		services.WithQuotaUseRepository(repo.Usage, cfg.Limits()),
+		services.WithFsckUseRepository(repo.Fsck),
+		services.WithShareUseRepository(repo.Share),
+		services.WithOrganizationUseRepository(repo.Org),
+		services.WithEmergencyUseRepository(repo.Emergency, log),
+		services.WithShareLinkUseRepository(repo.ShareLink, log),
+		services.WithRecoveryUseRepository(repo.Recovery),
 	)
 
+	if *cfg.Fsck {
+		err = runFsck(ctx, serv, *cfg.FsckFix)
+		if closeErr := db.Close(); closeErr != nil {
+			log.Error(closeErr)
+		}
+		if err != nil {
+			log.Fatalf("fsck err: %v", err)
+		}
+		return
+	}
+	if *cfg.Reindex {
+		indexed, err := serv.Search.Reindex(ctx)
+		if closeErr := db.Close(); closeErr != nil {
+			log.Error(closeErr)
+		}
+		if err != nil {
+			log.Fatalf("reindex err: %v", err)
+		}
+		log.Infof("reindex: %d items indexed", indexed)
+		return
+	}
+	if *cfg.GCInterval > 0 {
+		go serv.Fsck.CollectEvery(ctx, *cfg.GCInterval)
+	}
+	if *cfg.EmergencyEvery > 0 {
+		go serv.Emergency.GrantEvery(ctx, *cfg.EmergencyEvery)
+	}
+	if *cfg.LinkInterval > 0 {
+		go serv.ShareLink.RemoveExpiredEvery(ctx, *cfg.LinkInterval)
+	}
+
 	handlers := controllers.New(log,
 		controllers.WithAuthUseService(serv.Auth),
 		controllers.WithCardUseService(serv.CreditCard),
 		controllers.WithUserCredentialUseService(serv.Credential),
+		controllers.WithOTPUseService(serv.OTP),
+		controllers.WithSSHKeyUseService(serv.SSHKey),
 		controllers.WithTextUseService(serv.TextData),
 		controllers.WithBinaryFileUseService(serv.BinaryFile),
+		controllers.WithFolderUseService(serv.Folder),
+		controllers.WithTagUseService(serv.Tag),
+		controllers.WithSearchUseService(serv.Search),
+		controllers.WithSyncUseService(serv.Sync),
+		controllers.WithEventsUseService(serv.Events),
+		controllers.WithUploadUseService(serv.Upload),
+		controllers.WithUsageUseService(serv.Quota),
+		controllers.WithShareUseService(serv.Share),
+		controllers.WithOrganizationUseService(serv.Org),
+		controllers.WithEmergencyUseService(serv.Emergency),
+		controllers.WithShareLinkUseService(serv.ShareLink),
+		controllers.WithRecoveryUseService(serv.Recovery),
 	)
 
 	router := chi.NewRouter()
@@ -76,3 +137,16 @@ func main() {
 	log.Info("server stop")
 
 }
+
+func runFsck(ctx context.Context, serv *services.Service, fix bool) error {
+	report, err := serv.Fsck.Check(ctx, fix)
+	if err != nil {
+		return err
+	}
+	out, err := json.MarshalIndent(report, "", "    ")
+	if err != nil {
+		return err
+	}
+	fmt.Println(string(out))
+	return nil
+}
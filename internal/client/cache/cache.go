@@ -0,0 +1,354 @@
+package cache
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+
+	"github.com/zelas91/goph-keeper/internal/server/models"
+	"github.com/zelas91/goph-keeper/internal/server/types"
+	"golang.org/x/crypto/argon2"
+)
+
+const (
+	magic   = "GKC1"
+	saltLen = 16
+)
+
+var ErrWrongPassword = errors.New("cache: wrong password or damaged file")
+
+// Write is an item change made offline, it is sent to the server
+// when the server is reachable again.
+type Write struct {
+	Method   string          `json:"method"`
+	ItemType string          `json:"item_type"`
+	ItemID   int             `json:"item_id,omitempty"`
+	Body     json.RawMessage `json:"body,omitempty"`
+	// Base is the item the update was made on, it is the base of the merge
+	// when the item was changed on the server too.
+	Base json.RawMessage `json:"base,omitempty"`
+}
+
+// replica is the local copy of the vault. Items keep the JSON of the server
+// models by item type and id, items created offline get negative ids.
+type replica struct {
+	Cursor  string                             `json:"cursor"`
+	Items   map[string]map[int]json.RawMessage `json:"items"`
+	Pending []Write                            `json:"pending"`
+	LastID  int                                `json:"last_id"`
+}
+
+// Cache is the local replica of the user vault kept in a file encrypted
+// with a key derived from the master password.
+type Cache struct {
+	mu      sync.Mutex
+	path    string
+	salt    []byte
+	gcm     cipher.AEAD
+	replica replica
+}
+
+// Path returns the cache file of the user of the server under the user config dir.
+func Path(host, login string) (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("cache: config dir err: %w", err)
+	}
+	sum := sha256.Sum256([]byte(host + "/" + login))
+	return filepath.Join(dir, "goph-keeper", hex.EncodeToString(sum[:8])+".cache"), nil
+}
+
+// Open reads the cache file, a missing file gives an empty cache
+// that is written on the first change.
+func Open(path, password string) (*Cache, error) {
+	c := &Cache{path: path}
+	data, err := os.ReadFile(path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("cache: read err: %w", err)
+	}
+	if errors.Is(err, os.ErrNotExist) {
+		c.salt = make([]byte, saltLen)
+		if _, err = rand.Read(c.salt); err != nil {
+			return nil, fmt.Errorf("cache: salt err: %w", err)
+		}
+		if c.gcm, err = newGCM(password, c.salt); err != nil {
+			return nil, err
+		}
+		c.replica.Items = make(map[string]map[int]json.RawMessage)
+		return c, nil
+	}
+
+	if len(data) < len(magic)+saltLen || string(data[:len(magic)]) != magic {
+		return nil, ErrWrongPassword
+	}
+	c.salt = data[len(magic) : len(magic)+saltLen]
+	if c.gcm, err = newGCM(password, c.salt); err != nil {
+		return nil, err
+	}
+	sealed := data[len(magic)+saltLen:]
+	if len(sealed) < c.gcm.NonceSize() {
+		return nil, ErrWrongPassword
+	}
+	nonce, sealed := sealed[:c.gcm.NonceSize()], sealed[c.gcm.NonceSize():]
+	plain, err := c.gcm.Open(nil, nonce, sealed, []byte(magic))
+	if err != nil {
+		return nil, ErrWrongPassword
+	}
+	if err = json.Unmarshal(plain, &c.replica); err != nil {
+		return nil, fmt.Errorf("cache: decode err: %w", err)
+	}
+	if c.replica.Items == nil {
+		c.replica.Items = make(map[string]map[int]json.RawMessage)
+	}
+	return c, nil
+}
+
+func newGCM(password string, salt []byte) (cipher.AEAD, error) {
+	key := argon2.IDKey([]byte(password), salt, 1, 64*1024, 4, 32)
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("cache: cipher err: %w", err)
+	}
+	return cipher.NewGCM(block)
+}
+
+// save writes the cache to a temporary file and renames it over the old one,
+// a crash never leaves a half written cache.
+func (c *Cache) save() error {
+	plain, err := json.Marshal(c.replica)
+	if err != nil {
+		return fmt.Errorf("cache: encode err: %w", err)
+	}
+	nonce := make([]byte, c.gcm.NonceSize())
+	if _, err = rand.Read(nonce); err != nil {
+		return fmt.Errorf("cache: nonce err: %w", err)
+	}
+	data := append([]byte(magic), c.salt...)
+	data = append(data, nonce...)
+	data = c.gcm.Seal(data, nonce, plain, []byte(magic))
+
+	if err = os.MkdirAll(filepath.Dir(c.path), 0o700); err != nil {
+		return fmt.Errorf("cache: create dir err: %w", err)
+	}
+	tmp := c.path + ".tmp"
+	if err = os.WriteFile(tmp, data, 0o600); err != nil {
+		return fmt.Errorf("cache: write err: %w", err)
+	}
+	if err = os.Rename(tmp, c.path); err != nil {
+		return fmt.Errorf("cache: rename err: %w", err)
+	}
+	return nil
+}
+
+func (c *Cache) Cursor() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.replica.Cursor
+}
+
+// Reset forgets the items and the sync cursor, the writes waiting
+// for the server are kept.
+func (c *Cache) Reset() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.replica.Cursor = ""
+	c.replica.Items = make(map[string]map[int]json.RawMessage)
+	return c.save()
+}
+
+// Apply merges the changes of a sync into the replica.
+func (c *Cache) Apply(changes models.SyncChanges) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, v := range changes.Cards {
+		if err := c.put(types.ItemCard, v.ID, v); err != nil {
+			return err
+		}
+	}
+	for _, v := range changes.Credentials {
+		if err := c.put(types.ItemCredential, v.ID, v); err != nil {
+			return err
+		}
+	}
+	for _, v := range changes.Texts {
+		if err := c.put(types.ItemText, v.ID, v); err != nil {
+			return err
+		}
+	}
+	for _, v := range changes.Files {
+		if err := c.put(types.ItemFile, v.ID, v); err != nil {
+			return err
+		}
+	}
+	for _, v := range changes.OTPs {
+		if err := c.put(types.ItemOTP, v.ID, v); err != nil {
+			return err
+		}
+	}
+	for _, v := range changes.SSHKeys {
+		if err := c.put(types.ItemSSHKey, v.ID, v); err != nil {
+			return err
+		}
+	}
+	for _, v := range changes.Deleted {
+		delete(c.replica.Items[v.ItemType], v.ItemID)
+	}
+	c.replica.Cursor = changes.Cursor
+	return c.save()
+}
+
+func (c *Cache) put(itemType string, id int, item any) error {
+	body, err := json.Marshal(item)
+	if err != nil {
+		return fmt.Errorf("cache: encode item err: %w", err)
+	}
+	if c.replica.Items[itemType] == nil {
+		c.replica.Items[itemType] = make(map[int]json.RawMessage)
+	}
+	c.replica.Items[itemType][id] = body
+	return nil
+}
+
+// List returns the items of the type ordered by id, the items created offline go first.
+func (c *Cache) List(itemType string) []json.RawMessage {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	ids := make([]int, 0, len(c.replica.Items[itemType]))
+	for id := range c.replica.Items[itemType] {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+	items := make([]json.RawMessage, len(ids))
+	for i, id := range ids {
+		items[i] = c.replica.Items[itemType][id]
+	}
+	return items
+}
+
+func (c *Cache) Item(itemType string, id int) (json.RawMessage, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	item, ok := c.replica.Items[itemType][id]
+	return item, ok
+}
+
+// Create keeps an item created offline under a new negative id
+// and queues it for the server.
+func (c *Cache) Create(itemType string, body json.RawMessage) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.replica.LastID--
+	id := c.replica.LastID
+	item, err := withID(body, id)
+	if err != nil {
+		return err
+	}
+	if err = c.put(itemType, id, item); err != nil {
+		return err
+	}
+	c.replica.Pending = append(c.replica.Pending,
+		Write{Method: "POST", ItemType: itemType, ItemID: id, Body: body})
+	return c.save()
+}
+
+// Update changes the item offline, the change of an item created offline
+// goes into its queued create. Repeated updates of an item are sent as one
+// over the item read from the server.
+func (c *Cache) Update(itemType string, id int, body json.RawMessage) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	base := c.replica.Items[itemType][id]
+	item, err := withID(body, id)
+	if err != nil {
+		return err
+	}
+	if err = c.put(itemType, id, item); err != nil {
+		return err
+	}
+	if i := c.pendingCreate(itemType, id); i >= 0 {
+		c.replica.Pending[i].Body = body
+	} else if i = c.pending("PUT", itemType, id); i >= 0 {
+		c.replica.Pending[i].Body = body
+	} else {
+		c.replica.Pending = append(c.replica.Pending,
+			Write{Method: "PUT", ItemType: itemType, ItemID: id, Body: body, Base: base})
+	}
+	return c.save()
+}
+
+// Delete removes the item offline, an item created offline is never sent.
+func (c *Cache) Delete(itemType string, id int) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.replica.Items[itemType], id)
+	if i := c.pendingCreate(itemType, id); i >= 0 {
+		pending := c.replica.Pending[:0]
+		for _, w := range c.replica.Pending {
+			if w.ItemType != itemType || w.ItemID != id {
+				pending = append(pending, w)
+			}
+		}
+		c.replica.Pending = pending
+	} else {
+		c.replica.Pending = append(c.replica.Pending,
+			Write{Method: "DELETE", ItemType: itemType, ItemID: id})
+	}
+	return c.save()
+}
+
+func (c *Cache) pendingCreate(itemType string, id int) int {
+	return c.pending("POST", itemType, id)
+}
+
+func (c *Cache) pending(method, itemType string, id int) int {
+	for i, w := range c.replica.Pending {
+		if w.Method == method && w.ItemType == itemType && w.ItemID == id {
+			return i
+		}
+	}
+	return -1
+}
+
+// Next returns the oldest write waiting for the server.
+func (c *Cache) Next() (Write, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(c.replica.Pending) == 0 {
+		return Write{}, false
+	}
+	return c.replica.Pending[0], true
+}
+
+// Done drops the oldest write once the server took it, the item created
+// offline leaves the replica, the sync brings it back with its server id.
+func (c *Cache) Done() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(c.replica.Pending) == 0 {
+		return nil
+	}
+	w := c.replica.Pending[0]
+	if w.Method == "POST" {
+		delete(c.replica.Items[w.ItemType], w.ItemID)
+	}
+	c.replica.Pending = c.replica.Pending[1:]
+	return c.save()
+}
+
+func withID(body json.RawMessage, id int) (json.RawMessage, error) {
+	var item map[string]any
+	if err := json.Unmarshal(body, &item); err != nil {
+		return nil, fmt.Errorf("cache: decode item err: %w", err)
+	}
+	item["id"] = id
+	return json.Marshal(item)
+}
@@ -0,0 +1,103 @@
+package cache
+
+import (
+	"encoding/json"
+	"net/http"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/zelas91/goph-keeper/internal/server/models"
+)
+
+func TestCacheReopen(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "user.cache")
+	c, err := Open(path, "secret")
+	assert.NoError(t, err)
+	assert.NoError(t, c.Apply(models.SyncChanges{
+		Texts:  []models.TextData{{ID: 3, Text: "text"}},
+		Cursor: "7",
+	}))
+
+	c, err = Open(path, "secret")
+	assert.NoError(t, err)
+	assert.Equal(t, "7", c.Cursor())
+	item, ok := c.Item("text", 3)
+	assert.True(t, ok)
+	var text models.TextData
+	assert.NoError(t, json.Unmarshal(item, &text))
+	assert.Equal(t, "text", text.Text)
+
+	_, err = Open(path, "other")
+	assert.ErrorIs(t, err, ErrWrongPassword)
+}
+
+func TestCachePending(t *testing.T) {
+	tests := []struct {
+		name        string
+		writes      func(c *Cache)
+		wantPending []string
+		// wantItems are the items left after the writes are sent,
+		// the sync brings the items created offline back with their server ids
+		wantItems int
+	}{
+		{
+			name: "#1 ok update of an item created offline goes into the create",
+			writes: func(c *Cache) {
+				assert.NoError(t, c.Create("card", json.RawMessage(`{"number":"1"}`)))
+				assert.NoError(t, c.Update("card", -1, json.RawMessage(`{"number":"2"}`)))
+			},
+			wantPending: []string{http.MethodPost + ` {"number":"2"}`},
+		},
+		{
+			name: "#2 ok item created and deleted offline is never sent",
+			writes: func(c *Cache) {
+				assert.NoError(t, c.Create("card", json.RawMessage(`{"number":"1"}`)))
+				assert.NoError(t, c.Delete("card", -1))
+			},
+		},
+		{
+			name: "#3 ok server item changes keep their order",
+			writes: func(c *Cache) {
+				assert.NoError(t, c.Update("card", 5, json.RawMessage(`{"number":"2"}`)))
+				assert.NoError(t, c.Delete("card", 6))
+			},
+			wantPending: []string{http.MethodPut + ` {"number":"2"}`, http.MethodDelete + ` `},
+			wantItems:   1,
+		},
+		{
+			name: "#4 ok repeated updates are sent once over the server item",
+			writes: func(c *Cache) {
+				assert.NoError(t, c.Apply(models.SyncChanges{Cards: []models.Card{{ID: 5, Version: 1, Number: "1"}}}))
+				assert.NoError(t, c.Update("card", 5, json.RawMessage(`{"number":"2"}`)))
+				assert.NoError(t, c.Update("card", 5, json.RawMessage(`{"number":"3"}`)))
+			},
+			wantPending: []string{http.MethodPut + ` {"number":"3"} over ` +
+				`{"id":5,"version":1,"number":"1","expired_at":"","cvv":"","title":"","note":""}`},
+			wantItems: 1,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			c, err := Open(filepath.Join(t.TempDir(), "user.cache"), "secret")
+			assert.NoError(t, err)
+			test.writes(c)
+
+			var pending []string
+			for {
+				w, ok := c.Next()
+				if !ok {
+					break
+				}
+				write := w.Method + " " + string(w.Body)
+				if len(w.Base) > 0 {
+					write += " over " + string(w.Base)
+				}
+				pending = append(pending, write)
+				assert.NoError(t, c.Done())
+			}
+			assert.Equal(t, test.wantPending, pending)
+			assert.Len(t, c.List("card"), test.wantItems)
+		})
+	}
+}
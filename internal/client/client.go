@@ -28,6 +28,18 @@ type Client struct {
 	card       *request.Card
 	text       *request.TextData
 	credential *request.Credential
+	otp        *request.OTP
+	sshKey     *request.SSHKey
+	folder     *request.Folder
+	tag        *request.Tag
+	search     *request.Search
+	sync       *request.Sync
+	share      *request.Share
+	org        *request.Organization
+	emergency  *request.Emergency
+	link       *request.ShareLink
+	recovery   *request.Recovery
+	vault      *request.Vault
 }
 
 func NewClient(addr string) *Client {
@@ -48,7 +60,9 @@ func (c *Client) Start() {
 	signal.Notify(c.work, syscall.SIGHUP, syscall.SIGINT, syscall.SIGTERM, syscall.SIGQUIT)
 	go func() {
 		for {
-			if !c.session.IsAuth() {
+			if c.session.Offline {
+				fmt.Print("(offline)")
+			} else if !c.session.IsAuth() {
 				fmt.Print("(no authorization)")
 			}
 			fmt.Print(">")
@@ -75,19 +89,42 @@ func (c *Client) Start() {
 	<-c.work
 }
 func (c *Client) init() {
-	r := request.NewRequest(c.httpClient, c.session)
+	r := request.NewRequest(c.httpClient, c.session, c.in)
 
 	c.auth = request.NewAuthorization(r)
 	c.binary = request.NewBinaryFile(r)
 	c.card = request.NewCard(r)
 	c.text = request.NewTextData(r)
 	c.credential = request.NewCredential(r)
+	c.otp = request.NewOTP(r)
+	c.sshKey = request.NewSSHKey(r)
+	c.folder = request.NewFolder(r)
+	c.tag = request.NewTag(r)
+	c.search = request.NewSearch(r)
+	c.sync = request.NewSync(r)
+	c.share = request.NewShare(r)
+	c.org = request.NewOrganization(r)
+	c.emergency = request.NewEmergency(r)
+	c.link = request.NewShareLink(r)
+	c.recovery = request.NewRecovery(r)
+	c.vault = request.NewVault(r)
 
 	c.registerCommandAuth()
 	c.registerCommandBinaryFile()
 	c.registerCommandCard()
 	c.registerCommandText()
 	c.registerCommandCredential()
+	c.registerCommandOTP()
+	c.registerCommandSSHKey()
+	c.registerCommandFolder()
+	c.registerCommandTag()
+	c.registerCommandSearch()
+	c.registerCommandSync()
+	c.registerCommandShare()
+	c.registerCommandOrganization()
+	c.registerCommandEmergency()
+	c.registerCommandShareLink()
+	c.registerCommandVault()
 }
 
 func (c *Client) registerCommandAuth() {
@@ -96,6 +133,12 @@ func (c *Client) registerCommandAuth() {
 		c.auth.SignIn, "login: <login> <password>", tag)
 	c.cm.RegisterCommand("registration", "new user and login",
 		c.auth.SignUp, "login: <login> <password>", tag)
+	c.cm.RegisterCommand("usage", "storage taken on the server and its limits",
+		c.auth.Usage, "usage", tag)
+	c.cm.RegisterCommand("recovery_key", "make a new recovery key, the key made before stops working",
+		c.recovery.Key, "", tag)
+	c.cm.RegisterCommand("recover", "set a new password with the recovery key",
+		c.recovery.Recover, "recover: <login> <recovery_key> <new_password>", tag)
 }
 
 func (c *Client) registerCommandBinaryFile() {
@@ -103,13 +146,19 @@ func (c *Client) registerCommandBinaryFile() {
 	c.cm.RegisterCommand("file_delete", "delete file from server",
 		c.binary.Delete, "file_delete: <id>", tag)
 	c.cm.RegisterCommand("files", "get data about files  on the server",
-		c.binary.Files, "", tag)
+		c.binary.Files, "files: "+request.ListOptionsHelp, tag)
 
-	c.cm.RegisterCommand("file_download", "download file from server",
-		c.binary.Download, "file_download: <id> <path>", tag)
+	c.cm.RegisterCommand("file_download", "download files from server, a file uploaded with its dir goes to its path",
+		c.binary.Download, "file_download: <id>... <path> [--parallel <n>]", tag)
 
-	c.cm.RegisterCommand("file_upload", "upload file in server",
-		c.binary.Upload, "file_upload: <name> <path>", tag)
+	c.cm.RegisterCommand("file_upload", "upload file in server, an interrupted upload of the file resumes",
+		c.binary.Upload, "file_upload: <name> <path> "+request.ItemOptionsHelp, tag)
+	c.cm.RegisterCommand("file_upload_dir", "upload the files of the dir keeping their paths in metadata",
+		c.binary.UploadDir, "file_upload_dir: <dir> [--parallel <n>] "+request.ItemOptionsHelp, tag)
+	c.cm.RegisterCommand("file_uploads", "get unfinished uploads on the server",
+		c.binary.Uploads, "", tag)
+	c.cm.RegisterCommand("file_upload_cancel", "remove unfinished upload from server",
+		c.binary.UploadCancel, "file_upload_cancel: <upload_id>", tag)
 }
 
 func (c *Client) registerCommandCard() {
@@ -117,13 +166,14 @@ func (c *Client) registerCommandCard() {
 	c.cm.RegisterCommand("card_delete", "delete card from server",
 		c.card.Delete, "card_delete: <id>", tag)
 	c.cm.RegisterCommand("cards", "get data about cards  on the server",
-		c.card.Cards, "", tag)
+		c.card.Cards, "cards: "+request.ListOptionsHelp, tag)
 
 	c.cm.RegisterCommand("card_create", "create card to server",
-		c.card.Create, "card_create: <number> <expired> <cvv>", tag)
+		c.card.Create, "card_create: <number> <expired> <cvv> "+request.ItemOptionsHelp, tag)
 
 	c.cm.RegisterCommand("card_update", "update card to server",
-		c.card.Update, "card_update: <id> and any fields in the format <number:1234> <expired:12/27> <cvv:567>", tag)
+		c.card.Update, "card_update: <id> and any fields in the format <number:1234> <expired:12/27> <cvv:567> "+
+			request.ItemOptionsHelp, tag)
 }
 
 func (c *Client) registerCommandText() {
@@ -131,13 +181,13 @@ func (c *Client) registerCommandText() {
 	c.cm.RegisterCommand("text_delete", "delete text from server",
 		c.text.Delete, "text_delete: <id>", tag)
 	c.cm.RegisterCommand("texts", "get data about texts  on the server",
-		c.text.Texts, "", tag)
+		c.text.Texts, "texts: "+request.ListOptionsHelp, tag)
 
 	c.cm.RegisterCommand("text_create", "create text to server",
-		c.text.Create, "text_create: <'text'>", tag)
+		c.text.Create, "text_create: <'text'> "+request.ItemOptionsHelp, tag)
 
 	c.cm.RegisterCommand("text_update", "update text to server",
-		c.text.Update, "text_update: <id> <'text'>", tag)
+		c.text.Update, "text_update: <id> <'text'> "+request.ItemOptionsHelp, tag)
 }
 
 func (c *Client) registerCommandCredential() {
@@ -145,13 +195,144 @@ func (c *Client) registerCommandCredential() {
 	c.cm.RegisterCommand("credential_delete", "delete credential from server",
 		c.credential.Delete, "credential_delete: <id>", tag)
 	c.cm.RegisterCommand("credentials", "get data about credentials  on the server",
-		c.credential.Credentials, "", tag)
+		c.credential.Credentials, "credentials: "+request.ListOptionsHelp, tag)
 
 	c.cm.RegisterCommand("credential_create", "create credential to server",
-		c.credential.Create, "credential_create: <login> <password>", tag)
+		c.credential.Create, "credential_create: <login> <password> "+request.ItemOptionsHelp, tag)
 
 	c.cm.RegisterCommand("credential_update", "update credential to server",
-		c.credential.Update, "credential_update: <id> and any fields in the format <login:password>  <password:password>", tag)
+		c.credential.Update, "credential_update: <id> and any fields in the format <login:password>  <password:password> "+
+			request.ItemOptionsHelp, tag)
+}
+
+func (c *Client) registerCommandFolder() {
+	tag := "Folder"
+	c.cm.RegisterCommand("folders", "get folders on the server",
+		c.folder.Folders, "", tag)
+	c.cm.RegisterCommand("folder_create", "create folder to server",
+		c.folder.Create, "folder_create: <name> [parent_id]", tag)
+	c.cm.RegisterCommand("folder_update", "rename or move folder",
+		c.folder.Update, "folder_update: <id> <name> [parent_id]", tag)
+	c.cm.RegisterCommand("folder_delete", "delete folder with its subfolders, items move to the root",
+		c.folder.Delete, "folder_delete: <id>", tag)
+	c.cm.RegisterCommand("move", "move item into folder",
+		c.folder.Move, "move: <card|credential|text|file|otp|ssh_key> <item_id> <folder_id|root>", tag)
+}
+
+func (c *Client) registerCommandTag() {
+	tag := "Tag"
+	c.cm.RegisterCommand("tags", "get tags on the server",
+		c.tag.Tags, "", tag)
+	c.cm.RegisterCommand("tag_delete", "delete tag from all items",
+		c.tag.Delete, "tag_delete: <id>", tag)
+	c.cm.RegisterCommand("tag_add", "tag item",
+		c.tag.Add, "tag_add: <card|credential|text|file|otp|ssh_key> <item_id> <tag>", tag)
+	c.cm.RegisterCommand("tag_remove", "remove tag from item",
+		c.tag.Remove, "tag_remove: <card|credential|text|file|otp|ssh_key> <item_id> <tag>", tag)
+}
+
+func (c *Client) registerCommandSearch() {
+	tag := "Search"
+	c.cm.RegisterCommand("search", "find items of every type by title, note and metadata",
+		c.search.Search, "search: <words>", tag)
+}
+
+func (c *Client) registerCommandSync() {
+	tag := "Sync"
+	c.cm.RegisterCommand("sync", "get items changed since the last sync",
+		c.sync.Sync, "sync: [--full]", tag)
+}
+
+func (c *Client) registerCommandShare() {
+	tag := "Share"
+	c.cm.RegisterCommand("share", "share item with another user, read only by default",
+		c.share.Create, "share: <card|credential|text|file|otp|ssh_key> <item_id> <login> [read|write]", tag)
+	c.cm.RegisterCommand("shares", "get the shares made by the user",
+		c.share.Shares, "", tag)
+	c.cm.RegisterCommand("shares_incoming", "get the items other users share with the user",
+		c.share.Incoming, "", tag)
+	c.cm.RegisterCommand("share_revoke", "revoke the share or leave the share made with the user",
+		c.share.Revoke, "share_revoke: <share_id>", tag)
+}
+
+func (c *Client) registerCommandOrganization() {
+	tag := "Organization"
+	c.cm.RegisterCommand("org_create", "create organization with its own vault, the folders of the vault are its collections",
+		c.org.Create, "org_create: <name>", tag)
+	c.cm.RegisterCommand("orgs", "get the organizations of the user with the roles",
+		c.org.Organizations, "", tag)
+	c.cm.RegisterCommand("org_delete", "delete organization with an empty vault, owners only",
+		c.org.Delete, "org_delete: <org_id>", tag)
+	c.cm.RegisterCommand("org_members", "get the members of the organization",
+		c.org.Members, "org_members: <org_id>", tag)
+	c.cm.RegisterCommand("org_invite", "add user to the organization, a member by default",
+		c.org.Invite, "org_invite: <org_id> <login> [owner|admin|member|read-only]", tag)
+	c.cm.RegisterCommand("org_role", "change the role of the member",
+		c.org.SetRole, "org_role: <org_id> <login> <owner|admin|member|read-only>", tag)
+	c.cm.RegisterCommand("org_remove", "remove member from the organization, the own login leaves it",
+		c.org.Remove, "org_remove: <org_id> <login>", tag)
+}
+
+func (c *Client) registerCommandEmergency() {
+	tag := "Emergency access"
+	c.cm.RegisterCommand("emergency_add", "name the trusted contact that may read the vault after the waiting period",
+		c.emergency.Add, "emergency_add: <login> <wait_hours>", tag)
+	c.cm.RegisterCommand("emergency", "get the contacts of the user and the owners that trust the user",
+		c.emergency.Accesses, "", tag)
+	c.cm.RegisterCommand("emergency_request", "ask the owner for the access to the vault",
+		c.emergency.Request, "emergency_request: <access_id>", tag)
+	c.cm.RegisterCommand("emergency_approve", "grant the requested access without waiting",
+		c.emergency.Approve, "emergency_approve: <access_id>", tag)
+	c.cm.RegisterCommand("emergency_reject", "reject the request or take back the access granted",
+		c.emergency.Reject, "emergency_reject: <access_id>", tag)
+	c.cm.RegisterCommand("emergency_remove", "remove the contact or stop being one",
+		c.emergency.Remove, "emergency_remove: <access_id>", tag)
+}
+
+func (c *Client) registerCommandOTP() {
+	tag := "OTP"
+	c.cm.RegisterCommand("otp_delete", "delete authenticator key from server",
+		c.otp.Delete, "otp_delete: <id>", tag)
+	c.cm.RegisterCommand("otps", "get data about authenticator keys on the server",
+		c.otp.OTPs, "otps: "+request.ListOptionsHelp, tag)
+	c.cm.RegisterCommand("otp_create", "create authenticator key of the otpauth:// uri or the base32 secret",
+		c.otp.Create, "otp_create: <otpauth_uri|secret> "+request.ItemOptionsHelp, tag)
+	c.cm.RegisterCommand("otp_code", "print the current code of the authenticator key",
+		c.otp.Code, "otp_code: <id>", tag)
+}
+
+func (c *Client) registerCommandSSHKey() {
+	tag := "SSH"
+	c.cm.RegisterCommand("ssh_delete", "delete ssh key from server",
+		c.sshKey.Delete, "ssh_delete: <id>", tag)
+	c.cm.RegisterCommand("ssh_keys", "get data about ssh keys on the server",
+		c.sshKey.SSHKeys, "ssh_keys: "+request.ListOptionsHelp, tag)
+	c.cm.RegisterCommand("ssh_add", "add ssh private key of the file with its .pub and -cert.pub files",
+		c.sshKey.Add, "ssh_add: <private_key_file> [--passphrase <passphrase>] [--comment <comment>] "+
+			request.ItemOptionsHelp, tag)
+	c.cm.RegisterCommand("ssh_write", "write ssh key to the file readable by the user only",
+		c.sshKey.Write, "ssh_write: <id> <file>", tag)
+}
+
+func (c *Client) registerCommandShareLink() {
+	tag := "Share links"
+	c.cm.RegisterCommand("link_create", "make a link to the text or credential that opens without an account",
+		c.link.Create, "link_create: <text|credential> <id> [--views n] [--expires duration]", tag)
+	c.cm.RegisterCommand("link_secret", "make a link to the text given",
+		c.link.Secret, "link_secret: <text> [--views n] [--expires duration]", tag)
+	c.cm.RegisterCommand("links", "get the links that still open",
+		c.link.Links, "", tag)
+	c.cm.RegisterCommand("link_delete", "remove the link before it expires",
+		c.link.Delete, "link_delete: <link_id>", tag)
+	c.cm.RegisterCommand("link_open", "open the link and show the secret",
+		c.link.Open, "link_open: <url>", tag)
+}
+
+func (c *Client) registerCommandVault() {
+	tag := "Vault"
+	c.cm.RegisterCommand("vault", "switch the item commands to the vault of the organization, "+
+		"of the owner of the emergency access or back",
+		c.vault.Switch, "vault: [personal|<org_id>|emergency <access_id>]", tag)
 }
 
 func commandParsing(in *bufio.Reader) ([]string, error) {
@@ -164,6 +345,44 @@ func commandParsing(in *bufio.Reader) ([]string, error) {
 		return nil, fmt.Errorf("empty choice")
 	}
 
-	return strings.Split(choice, " "), nil
+	return splitArgs(choice)
+}
 
+// splitArgs splits a command line by spaces, text in single or double
+// quotes is kept as one argument.
+func splitArgs(line string) ([]string, error) {
+	var args []string
+	var arg strings.Builder
+	var quote rune
+	inArg := false
+	for _, r := range line {
+		switch {
+		case quote != 0 && r == quote:
+			quote = 0
+		case quote != 0:
+			arg.WriteRune(r)
+		case r == '\'' || r == '"':
+			quote = r
+			inArg = true
+		case r == ' ':
+			if inArg {
+				args = append(args, arg.String())
+				arg.Reset()
+				inArg = false
+			}
+		default:
+			arg.WriteRune(r)
+			inArg = true
+		}
+	}
+	if quote != 0 {
+		return nil, fmt.Errorf("unclosed quote")
+	}
+	if inArg {
+		args = append(args, arg.String())
+	}
+	if len(args) == 0 {
+		return nil, fmt.Errorf("empty choice")
+	}
+	return args, nil
 }
@@ -5,4 +5,6 @@ import "errors"
 var (
 	ErrInvalidCommand = errors.New("invalid command usage")
 	ErrAuthorization  = errors.New("error authorization")
+	ErrQuotaExceeded  = errors.New("storage quota on the server exceeded")
+	ErrFileTooLarge   = errors.New("file is larger than the server takes")
 )
@@ -1,18 +1,30 @@
 package request
 
 import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
+	"io/fs"
 	"net/http"
 	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
 
-	"github.com/gorilla/websocket"
+	"github.com/go-resty/resty/v2"
 	error2 "github.com/zelas91/goph-keeper/internal/client/error"
+	"github.com/zelas91/goph-keeper/internal/client/vault"
 	"github.com/zelas91/goph-keeper/internal/server/models"
 )
 
+// chunkAttempts is how many times a chunk is sent before the upload stops.
+const chunkAttempts = 3
+
 type BinaryFile struct {
 	request *Request
 }
@@ -36,83 +48,331 @@ func (b *BinaryFile) Delete(args []string) error {
 	return nil
 }
 
+// Upload sends the file in chunks to an upload the server keeps. An upload
+// of the same name and size left by a dropped connection or a closed client
+// goes on from the chunks the server already has.
 func (b *BinaryFile) Upload(args []string) error {
+	args, opts, err := parseItemOptions(args)
+	if err != nil {
+		return err
+	}
 	if len(args) < 2 {
 		return error2.ErrInvalidCommand
 	}
+	bf := models.BinaryFile{FileName: args[0]}
+	bf.Metadata = opts.apply(&bf.Title, &bf.Note, bf.Metadata)
+	p := newProgress("upload", 1)
+	defer p.end()
+	return b.uploadFile(args[1], bf, p)
+}
+
+// UploadDir uploads the files of the directory tree, several at once. The
+// path of a file relative to the directory is kept in its metadata, so the
+// tree comes back on download.
+func (b *BinaryFile) UploadDir(args []string) error {
+	args, opts, err := parseItemOptions(args)
+	if err != nil {
+		return err
+	}
+	args, limit, err := parseParallel(args)
+	if err != nil {
+		return err
+	}
+	if len(args) < 1 {
+		return error2.ErrInvalidCommand
+	}
+	root := args[0]
+	var files []string
+	err = filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.Type().IsRegular() {
+			rel, err := filepath.Rel(root, path)
+			if err != nil {
+				return err
+			}
+			files = append(files, rel)
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("read dir err: %w", err)
+	}
 
-	file, err := os.Open(args[1])
+	p := newProgress("upload", len(files))
+	failures := runParallel(files, limit, func(rel string) error {
+		defer p.finish()
+		bf := models.BinaryFile{FileName: filepath.Base(rel)}
+		bf.Metadata = opts.apply(&bf.Title, &bf.Note, map[string]string{PathMetaKey: filepath.ToSlash(rel)})
+		return b.uploadFile(filepath.Join(root, rel), bf, p)
+	})
+	p.end()
+	return failureSummary("upload", len(files), failures)
+}
+
+// uploadFile sends the file at path as the item bf. The content is encrypted
+// with a new key of the file, the server keeps the key wrapped by the vault
+// key and never sees the content. The size and the SHA-256 the server checks
+// are the ones of the encrypted file.
+func (b *BinaryFile) uploadFile(path string, bf models.BinaryFile, p *progress) error {
+	if b.request.filesKey() == nil {
+		return error2.ErrAuthorization
+	}
+	file, err := os.Open(path)
 	if err != nil {
 		return fmt.Errorf("open file err:%w", err)
 	}
 	defer func() {
 		if err := file.Close(); err != nil {
-			fmt.Printf("file close err: %v\n", err)
+			p.note("file close err: %v", err)
 		}
 	}()
 	fInfo, err := file.Stat()
 	if err != nil {
 		return fmt.Errorf("file info err:%w", err)
 	}
-	bf := models.BinaryFile{
-		FileName: args[0],
-		Size:     int(fInfo.Size()),
-	}
-	conn, err := b.request.WebsocketConnect("/file/upload")
+
+	up, enc, err := b.findUpload(file, fInfo.Size(), bf)
 	if err != nil {
-		return fmt.Errorf("failed to connect to server: %w", err)
+		return err
 	}
-	defer func() {
-		if err := conn.Close(); err != nil {
-			fmt.Printf("websocket connect close err: %v\n", err)
+	if enc != nil {
+		p.note("resuming upload %s of %s", up.ID, path)
+	} else {
+		key, err := vault.NewFileKey()
+		if err != nil {
+			return err
+		}
+		if enc, err = vault.NewEncrypter(file, fInfo.Size(), key); err != nil {
+			return err
+		}
+		if bf.FileKey, err = vault.WrapKey(b.request.filesKey(), key); err != nil {
+			return err
+		}
+		bf.Size = int(enc.Size())
+		if bf.Sha256, err = encryptedSha256(enc); err != nil {
+			return err
+		}
+		if up, err = b.createUpload(bf); err != nil {
+			return err
 		}
-	}()
-
-	if err = conn.WriteJSON(bf); err != nil {
-		return fmt.Errorf("faile encode to json: %w", err)
 	}
-	var answer models.AnswerBinaryFile
-	if err = conn.ReadJSON(&answer); err != nil {
-		return fmt.Errorf("read  answer server err %w", err)
+	p.grow(int(enc.Size()))
 
+	for _, v := range up.Received {
+		p.add(v.End - v.Start)
 	}
-	if !answer.Confirm {
-		return errors.New("server did not confirm the download of file")
-	}
-	buffer := make([]byte, 1024)
-	counter := 0
-	for {
-		n, err := file.Read(buffer)
-		if err != nil {
-			if err != io.EOF {
+	size := int(enc.Size())
+	buffer := make([]byte, up.ChunkSize)
+	for _, gap := range missingRanges(up.Received, size) {
+		for offset := gap.Start; offset < gap.End; offset += up.ChunkSize {
+			n := up.ChunkSize
+			if offset+n > gap.End {
+				n = gap.End - offset
+			}
+			if _, err = enc.ReadAt(buffer[:n], int64(offset)); err != nil && err != io.EOF {
 				return fmt.Errorf("read file err: %w", err)
 			}
-			if err := conn.WriteMessage(websocket.TextMessage,
-				[]byte("Binary data transfer completed")); err != nil {
-				return fmt.Errorf("websocket send msg end file err: %w", err)
+			if err = b.sendChunk(up.ID, offset, buffer[:n]); err != nil {
+				return fmt.Errorf("upload %s stopped, run the command again to resume: %w", up.ID, err)
 			}
-			break
+			p.add(n)
 		}
-		err = conn.WriteMessage(websocket.BinaryMessage, buffer[:n])
+	}
+
+	resp, err := b.request.R().Post(fmt.Sprintf("/file/uploads/%s/finish", up.ID))
+	if err != nil {
+		return fmt.Errorf("request upload finish err: %w", err)
+	}
+	if resp.StatusCode() != http.StatusCreated {
+		return fmt.Errorf("request upload finish error status code = %d, body = %s",
+			resp.StatusCode(), string(resp.Body()))
+	}
+	return nil
+}
+
+// findUpload looks for an unfinished upload of the same file, the files
+// of a directory are told apart by their path as well. The content is
+// encrypted with the key of the upload to compare it with the upload.
+func (b *BinaryFile) findUpload(file *os.File, size int64,
+	bf models.BinaryFile) (models.Upload, *vault.Encrypter, error) {
+	uploads, err := b.uploads()
+	if err != nil {
+		return models.Upload{}, nil, err
+	}
+	for _, v := range uploads {
+		if v.File.FileName != bf.FileName || int64(v.File.Size) != vault.EncryptedSize(size) ||
+			v.File.FileKey == "" || v.File.Metadata[PathMetaKey] != bf.Metadata[PathMetaKey] {
+			continue
+		}
+		key, err := vault.UnwrapKey(b.request.filesKey(), v.File.FileKey)
+		if err != nil {
+			continue
+		}
+		enc, err := vault.NewEncrypter(file, size, key)
+		if err != nil {
+			return models.Upload{}, nil, err
+		}
+		sum, err := encryptedSha256(enc)
 		if err != nil {
-			return fmt.Errorf("websocket send msg err: %w", err)
+			return models.Upload{}, nil, err
 		}
-		counter = counter + len(buffer[:n])
-		fmt.Printf("\rupload: %dKB file size = %dKB", counter/1024, bf.Size/1024)
+		if sum == v.File.Sha256 {
+			return v, enc, nil
+		}
+	}
+	return models.Upload{}, nil, nil
+}
+
+func (b *BinaryFile) uploads() ([]models.Upload, error) {
+	resp, err := b.request.R().Get("/file/uploads")
+	if err != nil {
+		return nil, fmt.Errorf("request uploads err: %w", err)
 	}
-	fmt.Println("")
-	_, _, err = conn.ReadMessage()
-	if !websocket.IsCloseError(err, websocket.CloseNormalClosure) {
-		return fmt.Errorf("transfer completed with err: %w", err)
+	if resp.StatusCode() != http.StatusOK {
+		return nil, fmt.Errorf("request uploads error status code = %d, body = %s",
+			resp.StatusCode(), string(resp.Body()))
+	}
+	var uploads []models.Upload
+	if err = json.Unmarshal(resp.Body(), &uploads); err != nil {
+		return nil, fmt.Errorf("uploads decode err: %w", err)
+	}
+	return uploads, nil
+}
+
+func (b *BinaryFile) createUpload(bf models.BinaryFile) (models.Upload, error) {
+	resp, err := b.request.R().SetBody(bf).Post("/file/uploads")
+	if err != nil {
+		return models.Upload{}, fmt.Errorf("request upload create err: %w", err)
+	}
+	if resp.StatusCode() != http.StatusCreated {
+		return models.Upload{}, fmt.Errorf("request upload create error status code = %d, body = %s",
+			resp.StatusCode(), string(resp.Body()))
+	}
+	var up models.Upload
+	if err = json.Unmarshal(resp.Body(), &up); err != nil {
+		return models.Upload{}, fmt.Errorf("upload decode err: %w", err)
+	}
+	if up.ChunkSize <= 0 {
+		up.ChunkSize = models.UploadChunkSize
+	}
+	return up, nil
+}
+
+// sendChunk puts the chunk at the offset of the upload, a chunk the network
+// or the checksum fails is sent again.
+func (b *BinaryFile) sendChunk(uploadID string, offset int, data []byte) error {
+	sum := sha256.Sum256(data)
+	var err error
+	for attempt := 0; attempt < chunkAttempts; attempt++ {
+		if attempt > 0 {
+			time.Sleep(time.Duration(attempt) * time.Second)
+		}
+		var resp *resty.Response
+		resp, err = b.request.httClient.R().
+			SetCookie(b.request.session.GetJwt()).
+			SetHeader("Content-Type", "application/octet-stream").
+			SetHeader("Upload-Offset", strconv.Itoa(offset)).
+			SetHeader("Upload-Checksum", "sha256 "+base64.StdEncoding.EncodeToString(sum[:])).
+			SetBody(data).
+			Put("/file/uploads/" + uploadID)
+		if err != nil {
+			continue
+		}
+		switch resp.StatusCode() {
+		case http.StatusOK:
+			return nil
+		case http.StatusUnauthorized:
+			return error2.ErrAuthorization
+		case http.StatusBadRequest:
+			err = fmt.Errorf("chunk refused: %s", string(resp.Body()))
+		case http.StatusInsufficientStorage, http.StatusRequestEntityTooLarge:
+			return quotaError(resp)
+		default:
+			return fmt.Errorf("request upload chunk error status code = %d, body = %s",
+				resp.StatusCode(), string(resp.Body()))
+		}
+	}
+	return err
+}
+
+// UploadCancel removes the unfinished upload with its chunks from the server.
+func (b *BinaryFile) UploadCancel(args []string) error {
+	if len(args) < 1 {
+		return error2.ErrInvalidCommand
+	}
+	resp, err := b.request.R().Delete("/file/uploads/" + args[0])
+	if err != nil {
+		return fmt.Errorf("request upload cancel err: %w", err)
+	}
+	if resp.StatusCode() != http.StatusOK {
+		return fmt.Errorf("request upload cancel error status code = %d, body = %s",
+			resp.StatusCode(), string(resp.Body()))
 	}
 	return nil
 }
 
+// Uploads prints the unfinished uploads.
+func (b *BinaryFile) Uploads(_ []string) error {
+	resp, err := b.request.R().Get("/file/uploads")
+	if err != nil {
+		return err
+	}
+	str, err := prettyJSON(resp.Body())
+	if err != nil {
+		return err
+	}
+	fmt.Println(str)
+	return nil
+}
+
+// missingRanges returns the parts of the file of size not in the received ranges.
+func missingRanges(received []models.Range, size int) []models.Range {
+	var missing []models.Range
+	start := 0
+	for _, v := range received {
+		if v.Start > start {
+			missing = append(missing, models.Range{Start: start, End: v.Start})
+		}
+		if v.End > start {
+			start = v.End
+		}
+	}
+	if start < size {
+		missing = append(missing, models.Range{Start: start, End: size})
+	}
+	return missing
+}
+
+// Download reads the files into the dir, several at once. A file uploaded
+// with its directory goes to its path under the dir.
 func (b *BinaryFile) Download(args []string) error {
+	args, limit, err := parseParallel(args)
+	if err != nil {
+		return err
+	}
 	if len(args) < 2 {
 		return error2.ErrInvalidCommand
 	}
-	url := fmt.Sprintf("/file/%s", args[0])
+	ids, dir := args[:len(args)-1], args[len(args)-1]
+	p := newProgress("download", len(ids))
+	failures := runParallel(ids, limit, func(id string) error {
+		defer p.finish()
+		return b.downloadFile(id, dir, p)
+	})
+	p.end()
+	if len(ids) == 1 && len(failures) == 1 {
+		return failures[0].err
+	}
+	return failureSummary("download", len(ids), failures)
+}
+
+// downloadFile reads the file over HTTP into <path>.part and renames it
+// once complete. An interrupted download goes on from the part file while
+// the file on the server is the one the part was read from.
+func (b *BinaryFile) downloadFile(id, dir string, p *progress) error {
+	url := fmt.Sprintf("/file/%s", id)
 	resp, err := b.request.R().Get(url)
 	if err != nil {
 		return fmt.Errorf("request file information err: %w", err)
@@ -124,69 +384,196 @@ func (b *BinaryFile) Download(args []string) error {
 	if err := json.Unmarshal(resp.Body(), &bf); err != nil {
 		return fmt.Errorf("file information decode err: %w", err)
 	}
+	p.grow(bf.Size)
 
-	conn, err := b.request.WebsocketConnect("/file/download")
+	path := filepath.Join(dir, filepath.Base(bf.FileName))
+	if rel := filepath.FromSlash(bf.Metadata[PathMetaKey]); rel != "" && filepath.IsLocal(rel) {
+		path = filepath.Join(dir, rel)
+		if err = os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+			return fmt.Errorf("create dir err: %w", err)
+		}
+	}
+	part, etagPath := path+".part", path+".part.etag"
+	header := http.Header{}
+	offset := 0
+	if info, err := os.Stat(part); err == nil {
+		if etag, err := os.ReadFile(etagPath); err == nil && info.Size() > 0 {
+			offset = int(info.Size())
+			header.Set("Range", fmt.Sprintf("bytes=%d-", offset))
+			header.Set("If-Range", string(etag))
+		}
+	}
+
+	content, err := b.request.stream(http.MethodGet, url+"/content", header)
 	if err != nil {
-		return fmt.Errorf("failed to connect to server: %w", err)
+		return fmt.Errorf("request file content err: %w", err)
 	}
 	defer func() {
-		if err := conn.Close(); err != nil {
-			fmt.Printf("websocket connect close err: %v\n", err)
+		if err := content.Body.Close(); err != nil {
+			p.note("file content close err: %v", err)
 		}
 	}()
-
-	if err = conn.WriteJSON(bf); err != nil {
-		return fmt.Errorf("faile encode to json: %w", err)
+	flag := os.O_WRONLY | os.O_CREATE
+	switch content.StatusCode {
+	case http.StatusPartialContent:
+		flag |= os.O_APPEND
+		p.note("resuming download of %s from %dKB", path, offset/1024)
+		p.add(offset)
+	case http.StatusOK:
+		flag |= os.O_TRUNC
+		offset = 0
+	case http.StatusRequestedRangeNotSatisfiable:
+		if offset != bf.Size {
+			_ = os.Remove(part)
+			return fmt.Errorf("part file %s does not match the file, run the command again", part)
+		}
+		p.add(offset)
+	case http.StatusUnauthorized:
+		return error2.ErrAuthorization
+	default:
+		return fmt.Errorf("request file content error status code = %d", content.StatusCode)
+	}
+	if err = os.WriteFile(etagPath, []byte(content.Header.Get("ETag")), 0o600); err != nil {
+		return fmt.Errorf("write etag err: %w", err)
 	}
 
-	var answer models.AnswerBinaryFile
-	if err = conn.ReadJSON(&answer); err != nil {
-		return fmt.Errorf("read  answer server err %w", err)
+	if content.StatusCode != http.StatusRequestedRangeNotSatisfiable {
+		file, err := os.OpenFile(part, flag, 0o600)
+		if err != nil {
+			return fmt.Errorf("create file err:%w", err)
+		}
+		counter := offset
+		buffer := make([]byte, 32*1024)
+		for {
+			n, readErr := content.Body.Read(buffer)
+			if n > 0 {
+				if _, err = file.Write(buffer[:n]); err != nil {
+					_ = file.Close()
+					return fmt.Errorf("write file err: %w", err)
+				}
+				counter += n
+				p.add(n)
+			}
+			if readErr == io.EOF {
+				break
+			}
+			if readErr != nil {
+				_ = file.Close()
+				return fmt.Errorf("download stopped, run the command again to resume: %w", readErr)
+			}
+		}
+		if err = file.Close(); err != nil {
+			return fmt.Errorf("file close err: %w", err)
+		}
+		if counter != bf.Size {
+			return fmt.Errorf("downloaded %d bytes of %d, run the command again to resume", counter, bf.Size)
+		}
+	}
+	if err = verifyFile(part, bf.Sha256); err != nil {
+		_ = os.Remove(part)
+		_ = os.Remove(etagPath)
+		return err
+	}
+	if bf.FileKey == "" {
+		if err = os.Rename(part, path); err != nil {
+			return fmt.Errorf("rename part file err: %w", err)
+		}
+		return os.Remove(etagPath)
+	}
+	if err = b.decryptFile(part, path, bf.FileKey); err != nil {
+		return err
+	}
+	_ = os.Remove(part)
+	return os.Remove(etagPath)
+}
 
+// decryptFile writes the content of the encrypted part file to path, a part
+// that does not decrypt is removed to be downloaded again.
+func (b *BinaryFile) decryptFile(part, path, fileKey string) error {
+	key, err := b.request.openFileKey(fileKey)
+	if err != nil {
+		return err
 	}
-	if !answer.Confirm {
-		return errors.New("server did not confirm the download of file")
+	src, err := os.Open(part)
+	if err != nil {
+		return fmt.Errorf("open file err: %w", err)
 	}
-	file, err := os.Create(fmt.Sprintf("%s/%s", args[1], bf.FileName))
+	defer func() {
+		_ = src.Close()
+	}()
+	plain := path + ".plain"
+	dst, err := os.OpenFile(plain, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o600)
 	if err != nil {
 		return fmt.Errorf("create file err:%w", err)
 	}
+	err = vault.Decrypt(dst, src, key)
+	if closeErr := dst.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		_ = os.Remove(plain)
+		if errors.Is(err, vault.ErrWrongKey) {
+			_ = os.Remove(part)
+			_ = os.Remove(part + ".etag")
+		}
+		return fmt.Errorf("decrypt file err: %w", err)
+	}
+	if err = os.Rename(plain, path); err != nil {
+		return fmt.Errorf("rename file err: %w", err)
+	}
+	return nil
+}
+
+// encryptedSha256 returns the hex SHA-256 of the encrypted file.
+func encryptedSha256(enc *vault.Encrypter) (string, error) {
+	h := sha256.New()
+	if _, err := io.Copy(h, io.NewSectionReader(enc, 0, enc.Size())); err != nil {
+		return "", fmt.Errorf("file checksum err: %w", err)
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// fileSha256 returns the hex SHA-256 of the file and leaves it at the start.
+func fileSha256(file *os.File) (string, error) {
+	h := sha256.New()
+	if _, err := io.Copy(h, file); err != nil {
+		return "", fmt.Errorf("file checksum err: %w", err)
+	}
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return "", fmt.Errorf("file seek err: %w", err)
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// verifyFile checks the downloaded file against the SHA-256 the server keeps,
+// the files stored before the checksums have none.
+func verifyFile(path, want string) error {
+	if want == "" {
+		return nil
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("open file err: %w", err)
+	}
 	defer func() {
 		if err := file.Close(); err != nil {
 			fmt.Printf("file close err: %v\n", err)
 		}
 	}()
-	counter := 0
-	for {
-		mt, msg, err := conn.ReadMessage()
-		if err != nil {
-			if websocket.IsCloseError(err, websocket.CloseNormalClosure) {
-				break
-			}
-			return fmt.Errorf("failed to read message: %w", err)
-		}
-		if mt == websocket.BinaryMessage {
-			_, err := file.Write(msg)
-			if err != nil {
-				return fmt.Errorf("write file err: %w", err)
-			}
-		}
-		counter = counter + len(msg)
-		fmt.Printf("\rdownload: %dKB file size = %dKB", counter/1024, bf.Size/1024)
+	sum, err := fileSha256(file)
+	if err != nil {
+		return err
+	}
+	if !strings.EqualFold(sum, want) {
+		return fmt.Errorf("downloaded file is damaged: sha256 %s, want %s", sum, want)
 	}
-	fmt.Print("\n")
 	return nil
 }
 
 func (b *BinaryFile) Files(args []string) error {
-	resp, err := b.request.R().Get("/file")
-	if err != nil {
-		return err
-	}
-	str, err := prettyJSON(resp.Body())
+	params, err := parseListOptions(args)
 	if err != nil {
 		return err
 	}
-	fmt.Println(str)
-	return nil
+	return b.request.list("/file", params)
 }
@@ -34,7 +34,11 @@ func (c *Card) Delete(args []string) error {
 }
 
 func (c *Card) Update(args []string) error {
-	if len(args) < 2 {
+	args, opts, err := parseItemOptions(args)
+	if err != nil {
+		return err
+	}
+	if len(args) < 1 {
 		return error2.ErrInvalidCommand
 	}
 	cardID, err := strconv.Atoi(args[0])
@@ -73,12 +77,17 @@ func (c *Card) Update(args []string) error {
 	if err := json.Unmarshal(resp.Body(), &card); err != nil {
 		return fmt.Errorf("request card decode err: %w", err)
 	}
+	base := card
 
 	card = updateModelCard(card, number, cvv, expired)
+	card.Metadata = opts.apply(&card.Title, &card.Note, card.Metadata)
 	resp, err = c.request.R().SetBody(card).Put(url)
 	if err != nil {
 		return err
 	}
+	if resp.StatusCode() == http.StatusConflict {
+		return c.request.resolveConflict(url, base, card, resp)
+	}
 
 	if resp.StatusCode() != http.StatusOK {
 		return fmt.Errorf("request update card error status code = %d, body = %s",
@@ -101,6 +110,10 @@ func updateModelCard(card models.Card, number, cvv, expired string) models.Card
 	return card
 }
 func (c *Card) Create(args []string) error {
+	args, opts, err := parseItemOptions(args)
+	if err != nil {
+		return err
+	}
 	if len(args) < 3 {
 		return error2.ErrInvalidCommand
 	}
@@ -110,6 +123,7 @@ func (c *Card) Create(args []string) error {
 		ExpiredAt: args[1],
 		Cvv:       args[2],
 	}
+	card.Metadata = opts.apply(&card.Title, &card.Note, card.Metadata)
 	resp, err := c.request.R().SetBody(card).Post("/card")
 	if err != nil {
 		return err
@@ -123,14 +137,9 @@ func (c *Card) Create(args []string) error {
 }
 
 func (c *Card) Cards(args []string) error {
-	resp, err := c.request.R().Get("/card")
-	if err != nil {
-		return err
-	}
-	str, err := prettyJSON(resp.Body())
+	params, err := parseListOptions(args)
 	if err != nil {
 		return err
 	}
-	fmt.Println(str)
-	return nil
+	return c.request.list("/card", params)
 }
@@ -0,0 +1,180 @@
+package request
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/go-resty/resty/v2"
+	error2 "github.com/zelas91/goph-keeper/internal/client/error"
+)
+
+// maxConflictRounds stops the merge when the item keeps changing on the server.
+const maxConflictRounds = 5
+
+// mergeSkip are the fields the server owns or the update does not change.
+var mergeSkip = map[string]bool{
+	"id":        true,
+	"version":   true,
+	"folder_id": true,
+	"tags":      true,
+}
+
+// resolveConflict is called when the server answers 409 on the update of url.
+// base is the item the change was made on, mine is the changed item, the
+// current item comes with the answer. The user keeps one of them or merges
+// them field by field, the result is sent over the current version.
+func (r *Request) resolveConflict(url string, base, mine any, resp *resty.Response) error {
+	baseItem, err := toFields(base)
+	if err != nil {
+		return err
+	}
+	mineItem, err := toFields(mine)
+	if err != nil {
+		return err
+	}
+	for round := 0; round < maxConflictRounds; round++ {
+		var conflict struct {
+			Current map[string]any `json:"current"`
+		}
+		if err = json.Unmarshal(resp.Body(), &conflict); err != nil || conflict.Current == nil {
+			return fmt.Errorf("request conflict decode err: %v, body = %s", err, string(resp.Body()))
+		}
+		theirs := conflict.Current
+
+		fmt.Printf("%s was changed on the server since you read it\n", url)
+		var result map[string]any
+		switch r.ask("keep [m]ine, keep [t]heirs or merge [f]ield by field? [m/t/F]") {
+		case "m", "mine":
+			result = copyFields(mineItem)
+		case "t", "theirs":
+			return nil
+		default:
+			result = r.merge(baseItem, mineItem, theirs, "")
+		}
+		result["version"] = theirs["version"]
+
+		resp, err = r.httClient.R().
+			SetCookie(r.session.GetJwt()).
+			SetHeader("Content-type", "application/json").
+			SetBody(result).
+			Put(url)
+		if err != nil {
+			return fmt.Errorf("%w: %v", errOffline, err)
+		}
+		switch resp.StatusCode() {
+		case http.StatusOK:
+			r.pullAfter(http.MethodPut, url, resp)
+			return nil
+		case http.StatusUnauthorized:
+			return error2.ErrAuthorization
+		case http.StatusConflict:
+			baseItem, mineItem = theirs, result
+		default:
+			return fmt.Errorf("request update error status code = %d, body = %s",
+				resp.StatusCode(), string(resp.Body()))
+		}
+	}
+	return fmt.Errorf("request update %s: the item keeps changing on the server", url)
+}
+
+// merge makes a three-way merge of the item fields. A field changed on one
+// side only takes that change, a field changed on both sides differently is
+// asked from the user. Nested objects, like metadata, are merged by key.
+func (r *Request) merge(base, mine, theirs map[string]any, prefix string) map[string]any {
+	result := make(map[string]any)
+	seen := make(map[string]bool)
+	keys := make([]string, 0, len(theirs))
+	for _, fields := range []map[string]any{base, mine, theirs} {
+		for k := range fields {
+			if !seen[k] {
+				seen[k] = true
+				keys = append(keys, k)
+			}
+		}
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		if prefix == "" && mergeSkip[k] {
+			if v, ok := theirs[k]; ok {
+				result[k] = v
+			}
+			continue
+		}
+		b, bOK := base[k]
+		m, mOK := mine[k]
+		t, tOK := theirs[k]
+		bMap, bIsMap := b.(map[string]any)
+		mMap, mIsMap := m.(map[string]any)
+		tMap, tIsMap := t.(map[string]any)
+		if mIsMap && tIsMap && (bIsMap || !bOK) {
+			result[k] = r.merge(bMap, mMap, tMap, prefix+k+".")
+			continue
+		}
+
+		var v any
+		var ok bool
+		switch {
+		case mOK == tOK && reflect.DeepEqual(m, t):
+			v, ok = m, mOK
+		case mOK == bOK && reflect.DeepEqual(m, b):
+			v, ok = t, tOK
+		case tOK == bOK && reflect.DeepEqual(t, b):
+			v, ok = m, mOK
+		default:
+			fmt.Printf("%s%s: mine = %s, theirs = %s\n", prefix, k, fieldString(m, mOK), fieldString(t, tOK))
+			if answer := r.ask("keep [m]ine or [t]heirs? [m/T]"); answer == "m" || answer == "mine" {
+				v, ok = m, mOK
+			} else {
+				v, ok = t, tOK
+			}
+		}
+		if ok {
+			result[k] = v
+		}
+	}
+	return result
+}
+
+func (r *Request) ask(question string) string {
+	fmt.Print(question, " ")
+	answer, err := r.in.ReadString('\n')
+	if err != nil {
+		return ""
+	}
+	return strings.ToLower(strings.TrimSpace(answer))
+}
+
+func toFields(item any) (map[string]any, error) {
+	body, err := json.Marshal(item)
+	if err != nil {
+		return nil, fmt.Errorf("request conflict encode err: %w", err)
+	}
+	var fields map[string]any
+	if err = json.Unmarshal(body, &fields); err != nil {
+		return nil, fmt.Errorf("request conflict decode err: %w", err)
+	}
+	return fields, nil
+}
+
+func copyFields(fields map[string]any) map[string]any {
+	result := make(map[string]any, len(fields))
+	for k, v := range fields {
+		result[k] = v
+	}
+	return result
+}
+
+func fieldString(v any, ok bool) string {
+	if !ok {
+		return "<removed>"
+	}
+	body, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(body)
+}
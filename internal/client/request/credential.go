@@ -34,7 +34,11 @@ func (c *Credential) Delete(args []string) error {
 }
 
 func (c *Credential) Update(args []string) error {
-	if len(args) < 2 {
+	args, opts, err := parseItemOptions(args)
+	if err != nil {
+		return err
+	}
+	if len(args) < 1 {
 		return error2.ErrInvalidCommand
 	}
 	credentialID, err := strconv.Atoi(args[0])
@@ -71,12 +75,17 @@ func (c *Credential) Update(args []string) error {
 	if err := json.Unmarshal(resp.Body(), &credential); err != nil {
 		return fmt.Errorf("request credential decode err: %w", err)
 	}
+	base := credential
 
 	credential = updateModelCredential(credential, login, password)
+	credential.Metadata = opts.apply(&credential.Title, &credential.Note, credential.Metadata)
 	resp, err = c.request.R().SetBody(credential).Put(url)
 	if err != nil {
 		return err
 	}
+	if resp.StatusCode() == http.StatusConflict {
+		return c.request.resolveConflict(url, base, credential, resp)
+	}
 
 	if resp.StatusCode() != http.StatusOK {
 		return fmt.Errorf("request update credential error status code = %d, body = %s",
@@ -95,6 +104,10 @@ func updateModelCredential(credential models.UserCredentials, login, password st
 	return credential
 }
 func (c *Credential) Create(args []string) error {
+	args, opts, err := parseItemOptions(args)
+	if err != nil {
+		return err
+	}
 	if len(args) < 2 {
 		return error2.ErrInvalidCommand
 	}
@@ -103,6 +116,7 @@ func (c *Credential) Create(args []string) error {
 		Login:    args[0],
 		Password: args[1],
 	}
+	credential.Metadata = opts.apply(&credential.Title, &credential.Note, credential.Metadata)
 	resp, err := c.request.R().SetBody(credential).Post("/credential")
 	if err != nil {
 		return err
@@ -116,14 +130,9 @@ func (c *Credential) Create(args []string) error {
 }
 
 func (c *Credential) Credentials(args []string) error {
-	resp, err := c.request.R().Get("/credential")
-	if err != nil {
-		return err
-	}
-	str, err := prettyJSON(resp.Body())
+	params, err := parseListOptions(args)
 	if err != nil {
 		return err
 	}
-	fmt.Println(str)
-	return nil
+	return c.request.list("/credential", params)
 }
@@ -0,0 +1,161 @@
+package request
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/go-resty/resty/v2"
+	error2 "github.com/zelas91/goph-keeper/internal/client/error"
+	"github.com/zelas91/goph-keeper/internal/client/vault"
+	"github.com/zelas91/goph-keeper/internal/server/models"
+)
+
+type Emergency struct {
+	request *Request
+}
+
+func NewEmergency(request *Request) *Emergency {
+	return &Emergency{request: request}
+}
+
+// Add names the user of the login the trusted contact. The vault key is
+// sealed for the contact here, the server gives it to the contact once
+// the access is granted.
+func (e *Emergency) Add(args []string) error {
+	if len(args) < 2 {
+		return error2.ErrInvalidCommand
+	}
+	waitHours, err := strconv.Atoi(args[1])
+	if err != nil {
+		return fmt.Errorf("waiting period err:%w", err)
+	}
+	if e.request.vaultKey == nil {
+		return error2.ErrAuthorization
+	}
+	ea := models.EmergencyAccess{Contact: args[0], WaitHours: waitHours}
+	publicKey, err := e.request.publicKey(ea.Contact)
+	if err != nil {
+		return err
+	}
+	if ea.VaultKey, err = vault.SealKey(publicKey, e.request.vaultKey); err != nil {
+		return err
+	}
+	resp, err := e.request.R().SetBody(ea).Post("/emergency")
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode() != http.StatusCreated {
+		return fmt.Errorf("request add contact error status code = %d, body = %s",
+			resp.StatusCode(), string(resp.Body()))
+	}
+	fmt.Println(string(resp.Body()))
+	return nil
+}
+
+// Accesses prints the contacts of the user and the owners that name the
+// user their contact.
+func (e *Emergency) Accesses(_ []string) error {
+	accesses, err := e.request.emergencyAccesses()
+	if err != nil {
+		return err
+	}
+	pretty, err := json.MarshalIndent(accesses, "", "    ")
+	if err != nil {
+		return err
+	}
+	fmt.Println(string(pretty))
+	return nil
+}
+
+// Request asks the owner for the access, it is granted when the owner
+// does not reject it within the waiting period.
+func (e *Emergency) Request(args []string) error {
+	return e.change(args, "request", (*Query).Post)
+}
+
+func (e *Emergency) Approve(args []string) error {
+	return e.change(args, "approve", (*Query).Post)
+}
+
+// Reject turns down the request of the contact or takes back the access.
+func (e *Emergency) Reject(args []string) error {
+	return e.change(args, "reject", (*Query).Post)
+}
+
+// Remove removes the access, the owner and the contact do it.
+func (e *Emergency) Remove(args []string) error {
+	return e.change(args, "", (*Query).Delete)
+}
+
+func (e *Emergency) change(args []string, action string,
+	send func(q *Query, url string) (*resty.Response, error)) error {
+	if len(args) < 1 {
+		return error2.ErrInvalidCommand
+	}
+	id, err := parseID(args[0])
+	if err != nil {
+		return err
+	}
+	url := fmt.Sprintf("/emergency/%d", id)
+	if action != "" {
+		url += "/" + action
+	}
+	resp, err := send(e.request.R(), url)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode() != http.StatusOK {
+		return fmt.Errorf("request emergency access error status code = %d, body = %s",
+			resp.StatusCode(), string(resp.Body()))
+	}
+	return nil
+}
+
+func (r *Request) emergencyAccesses() (models.EmergencyAccesses, error) {
+	resp, err := r.R().Get("/emergency")
+	if err != nil {
+		return models.EmergencyAccesses{}, err
+	}
+	if resp.StatusCode() != http.StatusOK {
+		return models.EmergencyAccesses{}, fmt.Errorf("request emergency accesses error status code = %d, body = %s",
+			resp.StatusCode(), string(resp.Body()))
+	}
+	var accesses models.EmergencyAccesses
+	if err = json.Unmarshal(resp.Body(), &accesses); err != nil {
+		return models.EmergencyAccesses{}, fmt.Errorf("emergency accesses decode err: %w", err)
+	}
+	return accesses, nil
+}
+
+// openEmergency opens the vault of the owner that granted the access,
+// the vault key of the owner opens the files there.
+func (r *Request) openEmergency(id string) (*otherVault, error) {
+	accessID, err := parseID(id)
+	if err != nil {
+		return nil, err
+	}
+	accesses, err := r.emergencyAccesses()
+	if err != nil {
+		return nil, err
+	}
+	for _, v := range accesses.Owners {
+		if v.ID != accessID {
+			continue
+		}
+		if v.Status != models.EmergencyGranted {
+			return nil, fmt.Errorf("emergency access %d is %s, not granted", accessID, v.Status)
+		}
+		if r.keys == nil {
+			return nil, errKeysNotLoaded
+		}
+		key, err := vault.OpenFileKey(r.vaultKey, r.keys, v.VaultKey)
+		if err != nil {
+			return nil, fmt.Errorf("vault key of the owner err: %w", err)
+		}
+		return &otherVault{header: emergencyHeader, id: v.ID,
+			name: fmt.Sprintf("owner %q, read only", v.Owner), key: key}, nil
+	}
+	return nil, fmt.Errorf("emergency access %d not found", accessID)
+}
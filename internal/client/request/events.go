@@ -0,0 +1,115 @@
+package request
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/zelas91/goph-keeper/internal/server/models"
+	"github.com/zelas91/goph-keeper/internal/server/types"
+)
+
+const (
+	clientIDHeader = "Client-ID"
+	listenRetryMin = time.Second
+	listenRetryMax = 30 * time.Second
+)
+
+// newClientID names this client to the server, the server does not push
+// back the changes the client made itself.
+func newClientID() string {
+	id := make([]byte, 8)
+	if _, err := rand.Read(id); err != nil {
+		return fmt.Sprint(time.Now().UnixNano())
+	}
+	return hex.EncodeToString(id)
+}
+
+// listen follows the changes pushed by the server in the background while
+// the user is logged in. The local cache is pulled on every item change,
+// the connection is made again when it drops.
+func (r *Request) listen() {
+	r.stopListen()
+	stop := make(chan struct{})
+	done := make(chan struct{})
+	r.listenStop, r.listenDone = stop, done
+	go func() {
+		defer close(done)
+		retry := listenRetryMin
+		for r.session.IsAuth() {
+			if conn, err := r.WebsocketConnect("/events"); err == nil {
+				retry = listenRetryMin
+				if !r.readEvents(conn, stop) {
+					return
+				}
+			}
+			select {
+			case <-stop:
+				return
+			case <-time.After(retry):
+			}
+			if retry *= 2; retry > listenRetryMax {
+				retry = listenRetryMax
+			}
+		}
+	}()
+}
+
+// stopListen ends the background listener and waits for it.
+func (r *Request) stopListen() {
+	if r.listenStop == nil {
+		return
+	}
+	close(r.listenStop)
+	<-r.listenDone
+	r.listenStop, r.listenDone = nil, nil
+}
+
+// readEvents handles the events until the connection drops, false is
+// returned when the listener is stopped.
+func (r *Request) readEvents(conn *websocket.Conn, stop <-chan struct{}) bool {
+	closed := make(chan struct{})
+	defer close(closed)
+	go func() {
+		select {
+		case <-stop:
+		case <-closed:
+		}
+		_ = conn.Close()
+	}()
+
+	// the changes made while the connection was down
+	r.pullEvent()
+	for {
+		var event models.Event
+		if err := conn.ReadJSON(&event); err != nil {
+			select {
+			case <-stop:
+				return false
+			default:
+				return true
+			}
+		}
+		fmt.Printf("\nchanged on another device: %s\n", eventString(event))
+		switch event.ItemType {
+		case types.ItemCard, types.ItemCredential, types.ItemText, types.ItemFile, types.ItemOTP,
+			types.ItemSSHKey:
+			r.pullEvent()
+		}
+	}
+}
+
+func (r *Request) pullEvent() {
+	if r.cache == nil {
+		return
+	}
+	if _, err := r.Pull(); err != nil {
+		fmt.Printf("local cache is not updated: %v\n", err)
+	}
+}
+
+func eventString(event models.Event) string {
+	return fmt.Sprintf("%s %d %s", event.ItemType, event.ItemID, event.Action)
+}
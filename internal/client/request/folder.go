@@ -0,0 +1,136 @@
+package request
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	error2 "github.com/zelas91/goph-keeper/internal/client/error"
+	"github.com/zelas91/goph-keeper/internal/server/models"
+)
+
+type Folder struct {
+	request *Request
+}
+
+func NewFolder(request *Request) *Folder {
+	return &Folder{request: request}
+}
+
+func (f *Folder) Folders(args []string) error {
+	resp, err := f.request.R().Get("/folder")
+	if err != nil {
+		return err
+	}
+	str, err := prettyJSON(resp.Body())
+	if err != nil {
+		return err
+	}
+	fmt.Println(str)
+	return nil
+}
+
+func (f *Folder) Create(args []string) error {
+	if len(args) < 1 {
+		return error2.ErrInvalidCommand
+	}
+	fd := models.Folder{Name: args[0]}
+	if len(args) > 1 {
+		parentID, err := strconv.Atoi(args[1])
+		if err != nil {
+			return fmt.Errorf("parent folder id err:%w", err)
+		}
+		fd.ParentID = &parentID
+	}
+	resp, err := f.request.R().SetBody(fd).Post("/folder")
+	if err != nil {
+		return err
+	}
+
+	if resp.StatusCode() != http.StatusCreated {
+		return fmt.Errorf("request create folder error status code = %d, body = %s",
+			resp.StatusCode(), string(resp.Body()))
+	}
+	fmt.Println(string(resp.Body()))
+	return nil
+}
+
+func (f *Folder) Update(args []string) error {
+	if len(args) < 2 {
+		return error2.ErrInvalidCommand
+	}
+	folderID, err := strconv.Atoi(args[0])
+	if err != nil {
+		return fmt.Errorf("folder id err:%w", err)
+	}
+	fd := models.Folder{Name: args[1]}
+	if len(args) > 2 {
+		parentID, err := strconv.Atoi(args[2])
+		if err != nil {
+			return fmt.Errorf("parent folder id err:%w", err)
+		}
+		fd.ParentID = &parentID
+	}
+	resp, err := f.request.R().SetBody(fd).Put(fmt.Sprintf("/folder/%d", folderID))
+	if err != nil {
+		return err
+	}
+
+	if resp.StatusCode() != http.StatusOK {
+		return fmt.Errorf("request update folder error status code = %d, body = %s",
+			resp.StatusCode(), string(resp.Body()))
+	}
+	return nil
+}
+
+func (f *Folder) Delete(args []string) error {
+	if len(args) < 1 {
+		return error2.ErrInvalidCommand
+	}
+	url := fmt.Sprintf("/folder/%s", args[0])
+	resp, err := f.request.R().Delete(url)
+	if err != nil {
+		return fmt.Errorf("request folder delete err: %w", err)
+	}
+	if resp.StatusCode() != http.StatusOK {
+		return fmt.Errorf("request folder delete error status code = %d", resp.StatusCode())
+	}
+	return nil
+}
+
+// Move puts an item into a folder, "root" as the folder moves it out of any folder.
+func (f *Folder) Move(args []string) error {
+	if len(args) < 3 {
+		return error2.ErrInvalidCommand
+	}
+	item, err := newItemRef(args[0], args[1])
+	if err != nil {
+		return err
+	}
+	move := models.MoveItem{ItemRef: item}
+	if args[2] != "root" {
+		folderID, err := strconv.Atoi(args[2])
+		if err != nil {
+			return fmt.Errorf("folder id err:%w", err)
+		}
+		move.FolderID = &folderID
+	}
+	resp, err := f.request.R().SetBody(move).Put("/folder/move")
+	if err != nil {
+		return err
+	}
+
+	if resp.StatusCode() != http.StatusOK {
+		return fmt.Errorf("request move item error status code = %d, body = %s",
+			resp.StatusCode(), string(resp.Body()))
+	}
+	return nil
+}
+
+func newItemRef(itemType, itemID string) (models.ItemRef, error) {
+	id, err := strconv.Atoi(itemID)
+	if err != nil {
+		return models.ItemRef{}, fmt.Errorf("item id err:%w", err)
+	}
+	return models.ItemRef{ItemType: itemType, ItemID: id}, nil
+}
@@ -1,66 +1,163 @@
 package request
 
 import (
+	"bufio"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"net/url"
+	"strconv"
+	"sync"
 
 	"github.com/go-resty/resty/v2"
 	"github.com/gorilla/websocket"
+	"github.com/zelas91/goph-keeper/internal/client/cache"
 	error2 "github.com/zelas91/goph-keeper/internal/client/error"
 	"github.com/zelas91/goph-keeper/internal/client/session"
+	"github.com/zelas91/goph-keeper/internal/client/vault"
+	"github.com/zelas91/goph-keeper/internal/server/payload"
 )
 
 type Request struct {
 	httClient *resty.Client
 	session   *session.Session
+	in        *bufio.Reader
+	cache     *cache.Cache
+	clientID  string
+	pullMu    sync.Mutex
+	replayMu  sync.Mutex
+	// vaultKey is derived from the master password on login,
+	// it wraps the keys of the files.
+	vaultKey []byte
+	// keys open the keys of the files other users share,
+	// they are read from the server on login.
+	keys *vault.KeyPair
+	// other is the vault of an organization or of an owner of an emergency
+	// access the commands work in, personalCache is the local cache put
+	// aside meanwhile.
+	other         *otherVault
+	personalCache *cache.Cache
+	listenStop    chan struct{}
+	listenDone    chan struct{}
 }
 type Query struct {
-	req *resty.Request
+	req     *resty.Request
+	request *Request
 }
 
-func NewRequest(httClient *resty.Client, session *session.Session) *Request {
+func NewRequest(httClient *resty.Client, session *session.Session, in *bufio.Reader) *Request {
+	clientID := newClientID()
+	httClient.SetHeader(clientIDHeader, clientID)
 	return &Request{
 		httClient: httClient,
 		session:   session,
+		in:        in,
+		clientID:  clientID,
 	}
 }
 func (q *Query) SetBody(body interface{}) *Query {
 	q.req.SetBody(body)
 	return q
 }
+func (q *Query) SetQueryParams(params map[string]string) *Query {
+	q.req.SetQueryParams(params)
+	return q
+}
+
 func (q *Query) Post(url string) (*resty.Response, error) {
-	return q.isAuthorization(q.req.Post(url))
+	return q.checkStatus(q.execute(http.MethodPost, url))
 }
 
 func (q *Query) Get(url string) (*resty.Response, error) {
-	return q.isAuthorization(q.req.Get(url))
+	return q.checkStatus(q.execute(http.MethodGet, url))
 }
 
 func (q *Query) Delete(url string) (*resty.Response, error) {
-	return q.isAuthorization(q.req.Delete(url))
+	return q.checkStatus(q.execute(http.MethodDelete, url))
 }
 
 func (q *Query) Put(url string) (*resty.Response, error) {
-	return q.isAuthorization(q.req.Put(url))
+	return q.checkStatus(q.execute(http.MethodPut, url))
+}
+
+// execute sends the writes made offline before the request, when the server
+// is not reachable the items are read and written in the local cache.
+func (q *Query) execute(method, url string) (*resty.Response, error) {
+	err := q.request.replay()
+	if err != nil && !errors.Is(err, errOffline) {
+		return nil, err
+	}
+	var resp *resty.Response
+	if err == nil {
+		resp, err = q.req.Execute(method, url)
+		if err == nil {
+			q.request.pullAfter(method, url, resp)
+			return resp, nil
+		}
+	}
+	if offlineResp, ok := q.offline(method, url); ok {
+		return offlineResp, nil
+	}
+	return resp, err
 }
 
-func (q *Query) isAuthorization(resp *resty.Response, err error) (*resty.Response, error) {
+// checkStatus turns the answers every command handles the same way into errors.
+func (q *Query) checkStatus(resp *resty.Response, err error) (*resty.Response, error) {
 	if err != nil {
 		return resp, err
 	}
 	if resp.StatusCode() == http.StatusUnauthorized {
 		return resp, error2.ErrAuthorization
 	}
-	return resp, err
+	return resp, quotaError(resp)
+}
+
+// quotaError explains the refusal of the server to keep more data.
+func quotaError(resp *resty.Response) error {
+	var target error
+	switch resp.StatusCode() {
+	case http.StatusInsufficientStorage:
+		target = error2.ErrQuotaExceeded
+	case http.StatusRequestEntityTooLarge:
+		target = error2.ErrFileTooLarge
+	default:
+		return nil
+	}
+	var msg payload.ErrorMessage
+	if err := json.Unmarshal(resp.Body(), &msg); err != nil || msg.Message == "" {
+		return fmt.Errorf("%w, see the 'usage' command", target)
+	}
+	return fmt.Errorf("%w (%s), see the 'usage' command", target, msg.Message)
 }
 
 func (r *Request) R() *Query {
-	return &Query{
-		req: r.httClient.R().
-			SetCookie(r.session.GetJwt()).
-			SetHeader("Content-type", "application/json"),
+	req := r.httClient.R().
+		SetCookie(r.session.GetJwt()).
+		SetHeader("Content-type", "application/json")
+	if v := r.other; v != nil {
+		req.SetHeader(v.header, strconv.Itoa(v.id))
+	}
+	return &Query{req: req, request: r}
+}
+
+// stream sends the request without the timeout of the client, the body
+// of the answer is read by the caller as long as it takes.
+func (r *Request) stream(method, url string, header http.Header) (*http.Response, error) {
+	req, err := http.NewRequest(method, r.httClient.BaseURL+url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header = header
+	req.Header.Set(clientIDHeader, r.clientID)
+	if v := r.other; v != nil {
+		req.Header.Set(v.header, strconv.Itoa(v.id))
+	}
+	if r.session.IsAuth() {
+		req.AddCookie(r.session.GetJwt())
 	}
+	client := &http.Client{Transport: r.httClient.GetClient().Transport, Jar: r.httClient.GetClient().Jar}
+	return client.Do(req)
 }
 
 func (r *Request) WebsocketConnect(addr string) (*websocket.Conn, error) {
@@ -74,6 +171,7 @@ func (r *Request) WebsocketConnect(addr string) (*websocket.Conn, error) {
 	header := http.Header{}
 	header.Add("Content-Type", "application/json")
 	header.Add("jwt", r.session.GetJwt().Value)
+	header.Add(clientIDHeader, r.clientID)
 	conn, _, err := cfg.Dial(u.String(), header)
 	return conn, err
 }
@@ -5,7 +5,7 @@ import (
 )
 
 func prettyJSON(data []byte) (string, error) {
-	var result []interface{}
+	var result interface{}
 	if err := json.Unmarshal(data, &result); err != nil {
 		return "", err
 	}
@@ -0,0 +1,294 @@
+package request
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/go-resty/resty/v2"
+	"github.com/zelas91/goph-keeper/internal/client/cache"
+	error2 "github.com/zelas91/goph-keeper/internal/client/error"
+	"github.com/zelas91/goph-keeper/internal/server/models"
+	"github.com/zelas91/goph-keeper/internal/server/types"
+)
+
+var errOffline = errors.New("server is not reachable")
+
+// offlineWrites are the item types that can be created and updated offline,
+// files need the server to take their content.
+var offlineWrites = map[string]bool{
+	types.ItemCard:       true,
+	types.ItemCredential: true,
+	types.ItemText:       true,
+	types.ItemOTP:        true,
+	types.ItemSSHKey:     true,
+}
+
+// itemPaths are the paths of the item types the api serves under another
+// name, the other types are served under their own name.
+var itemPaths = map[string]string{
+	types.ItemSSHKey: "ssh",
+}
+
+func itemPath(itemType string) string {
+	if path, ok := itemPaths[itemType]; ok {
+		return "/" + path
+	}
+	return "/" + itemType
+}
+
+// OpenCache opens the local replica of the user. A replica that does not open
+// with the password the server has just accepted was made with an old
+// password, it is put aside and a new one is started.
+func (r *Request) OpenCache(login, password string, online bool) error {
+	path, err := cache.Path(r.session.Host, login)
+	if err != nil {
+		return err
+	}
+	c, err := cache.Open(path, password)
+	if errors.Is(err, cache.ErrWrongPassword) && online {
+		if err = os.Rename(path, path+".old"); err != nil {
+			return fmt.Errorf("cache: put aside err: %w", err)
+		}
+		fmt.Printf("local cache does not open with this password, moved to %s.old\n", path)
+		c, err = cache.Open(path, password)
+	}
+	if err != nil {
+		return err
+	}
+	r.cache = c
+	return nil
+}
+
+// Pull brings the changes of the server into the local replica, the pulls
+// of the commands and of the background listener go one by one.
+func (r *Request) Pull() (models.SyncChanges, error) {
+	r.pullMu.Lock()
+	defer r.pullMu.Unlock()
+	if r.cache == nil {
+		return models.SyncChanges{}, errors.New("local cache is not open")
+	}
+	changes, err := r.syncChanges(r.cache.Cursor())
+	if err != nil {
+		return changes, err
+	}
+	return changes, r.cache.Apply(changes)
+}
+
+func (r *Request) syncChanges(since string) (models.SyncChanges, error) {
+	resp, err := r.httClient.R().
+		SetCookie(r.session.GetJwt()).
+		SetQueryParam("since", since).
+		Get("/sync")
+	if err != nil {
+		return models.SyncChanges{}, fmt.Errorf("%w: %v", errOffline, err)
+	}
+	if resp.StatusCode() == http.StatusUnauthorized {
+		return models.SyncChanges{}, error2.ErrAuthorization
+	}
+	if resp.StatusCode() != http.StatusOK {
+		return models.SyncChanges{}, fmt.Errorf("request sync error status code = %d, body = %s",
+			resp.StatusCode(), string(resp.Body()))
+	}
+	var changes models.SyncChanges
+	if err = json.Unmarshal(resp.Body(), &changes); err != nil {
+		return models.SyncChanges{}, fmt.Errorf("request sync decode err: %w", err)
+	}
+	return changes, nil
+}
+
+// pullAfter keeps the replica fresh after an item is written on the server.
+func (r *Request) pullAfter(method, url string, resp *resty.Response) {
+	if r.cache == nil || method == http.MethodGet || !resp.IsSuccess() {
+		return
+	}
+	if itemType, _, ok := itemURL(url); ok {
+		if _, err := r.Pull(); err != nil {
+			fmt.Printf("local cache of %s items is not updated: %v\n", itemType, err)
+		}
+	}
+}
+
+// replay sends the writes made offline in their order. An update made over
+// an old version is merged by the user, another write the server refuses
+// is reported and dropped, the replay stops at the first write the server
+// does not get. The requests of the files sent at once replay one by one.
+func (r *Request) replay() error {
+	if r.cache == nil || !r.session.IsAuth() {
+		return nil
+	}
+	r.replayMu.Lock()
+	defer r.replayMu.Unlock()
+	replayed := false
+	for {
+		w, ok := r.cache.Next()
+		if !ok {
+			break
+		}
+		url := itemPath(w.ItemType)
+		if w.Method != http.MethodPost {
+			url += "/" + strconv.Itoa(w.ItemID)
+		}
+		req := r.httClient.R().
+			SetCookie(r.session.GetJwt()).
+			SetHeader("Content-type", "application/json")
+		if len(w.Body) > 0 {
+			req.SetBody([]byte(w.Body))
+		}
+		resp, err := req.Execute(w.Method, url)
+		if err != nil {
+			return fmt.Errorf("%w: %v", errOffline, err)
+		}
+		if resp.StatusCode() == http.StatusUnauthorized {
+			return error2.ErrAuthorization
+		}
+		if resp.StatusCode() == http.StatusConflict && w.Method == http.MethodPut {
+			fmt.Println("offline change conflicts with the server:")
+			err = r.resolveConflict(url, w.Base, w.Body, resp)
+			if errors.Is(err, errOffline) || errors.Is(err, error2.ErrAuthorization) {
+				return err
+			}
+			if err != nil {
+				fmt.Printf("offline change %s %s is dropped: %v\n", w.Method, url, err)
+			}
+		} else if !resp.IsSuccess() {
+			fmt.Printf("offline change %s %s is refused by the server: status code = %d, body = %s\n",
+				w.Method, url, resp.StatusCode(), string(resp.Body()))
+		}
+		if err = r.cache.Done(); err != nil {
+			return err
+		}
+		replayed = true
+	}
+	if replayed {
+		if _, err := r.Pull(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// offline answers the request from the local replica when the server
+// is not reachable, ok is false for the requests the replica can not serve.
+func (q *Query) offline(method, url string) (*resty.Response, bool) {
+	c := q.request.cache
+	if c == nil {
+		return nil, false
+	}
+	itemType, id, ok := itemURL(url)
+	if !ok {
+		return nil, false
+	}
+	status := http.StatusOK
+	switch {
+	case method == http.MethodGet && id == 0:
+		items, err := filterItems(c.List(itemType), q.req.QueryParam.Get("folder"), q.req.QueryParam.Get("tag"))
+		if err != nil {
+			return nil, false
+		}
+		body, err := json.Marshal(page{Items: items})
+		if err != nil {
+			return nil, false
+		}
+		fmt.Println(errOffline.Error() + ", the items are read from the local cache")
+		return offlineResponse(status, body), true
+	case method == http.MethodGet:
+		item, found := c.Item(itemType, id)
+		if !found {
+			return offlineResponse(http.StatusNotFound, []byte("item is not in the local cache")), true
+		}
+		return offlineResponse(status, item), true
+	case method == http.MethodDelete && id != 0:
+		if err := c.Delete(itemType, id); err != nil {
+			return nil, false
+		}
+	case method == http.MethodPost && id == 0 && offlineWrites[itemType]:
+		body, err := json.Marshal(q.req.Body)
+		if err != nil {
+			return nil, false
+		}
+		if err = c.Create(itemType, body); err != nil {
+			return nil, false
+		}
+		status = http.StatusCreated
+	case method == http.MethodPut && id != 0 && offlineWrites[itemType]:
+		body, err := json.Marshal(q.req.Body)
+		if err != nil {
+			return nil, false
+		}
+		if err = c.Update(itemType, id, body); err != nil {
+			return nil, false
+		}
+	default:
+		return nil, false
+	}
+	fmt.Println(errOffline.Error() + ", the change is kept in the local cache")
+	return offlineResponse(status, nil), true
+}
+
+func offlineResponse(status int, body []byte) *resty.Response {
+	resp := &resty.Response{RawResponse: &http.Response{StatusCode: status, Header: http.Header{}}}
+	return resp.SetBody(body)
+}
+
+// itemURL parses /<item type> and /<item type>/<id>.
+func itemURL(url string) (itemType string, id int, ok bool) {
+	parts := strings.Split(strings.Trim(url, "/"), "/")
+	for k, v := range itemPaths {
+		if parts[0] == v {
+			parts[0] = k
+		}
+	}
+	switch parts[0] {
+	case types.ItemCard, types.ItemCredential, types.ItemText, types.ItemFile, types.ItemOTP, types.ItemSSHKey:
+	default:
+		return "", 0, false
+	}
+	if len(parts) == 1 {
+		return parts[0], 0, true
+	}
+	if len(parts) == 2 {
+		id, err := strconv.Atoi(parts[1])
+		if err != nil {
+			return "", 0, false
+		}
+		return parts[0], id, true
+	}
+	return "", 0, false
+}
+
+// filterItems applies the folder and tag filters of the lists offline,
+// the folder filter does not reach the subfolders.
+func filterItems(items []json.RawMessage, folder, tag string) ([]interface{}, error) {
+	result := make([]interface{}, 0, len(items))
+	for _, v := range items {
+		var item struct {
+			FolderID *int     `json:"folder_id"`
+			Tags     []string `json:"tags"`
+		}
+		if err := json.Unmarshal(v, &item); err != nil {
+			return nil, err
+		}
+		if folder != "" && (item.FolderID == nil || strconv.Itoa(*item.FolderID) != folder) {
+			continue
+		}
+		if tag != "" && !contains(item.Tags, tag) {
+			continue
+		}
+		result = append(result, v)
+	}
+	return result, nil
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
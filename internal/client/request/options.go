@@ -0,0 +1,108 @@
+package request
+
+import (
+	"strings"
+
+	error2 "github.com/zelas91/goph-keeper/internal/client/error"
+)
+
+const (
+	ItemOptionsHelp = "[--title <title>] [--note <note>] [--meta <key=value>]..."
+	ListOptionsHelp = "[--folder <id>] [--tag <name>] [--since <RFC 3339 time>] " +
+		"[--sort [-]created|updated] [--limit <n>]"
+)
+
+// itemOptions are the optional flags accepted by every command
+// that creates or updates a vault item.
+type itemOptions struct {
+	title    *string
+	note     *string
+	metadata map[string]string
+}
+
+// parseItemOptions cuts the --title, --note and --meta flags out of args
+// and returns the remaining positional arguments.
+func parseItemOptions(args []string) ([]string, itemOptions, error) {
+	var opts itemOptions
+	rest := make([]string, 0, len(args))
+	for i := 0; i < len(args); i++ {
+		switch args[i] {
+		case "--title", "--note", "--meta":
+			if i+1 >= len(args) {
+				return nil, opts, error2.ErrInvalidCommand
+			}
+			value := args[i+1]
+			switch args[i] {
+			case "--title":
+				opts.title = &value
+			case "--note":
+				opts.note = &value
+			case "--meta":
+				key, val, ok := strings.Cut(value, "=")
+				if !ok || key == "" {
+					return nil, opts, error2.ErrInvalidCommand
+				}
+				if opts.metadata == nil {
+					opts.metadata = make(map[string]string)
+				}
+				opts.metadata[key] = val
+			}
+			i++
+		default:
+			rest = append(rest, args[i])
+		}
+	}
+	return rest, opts, nil
+}
+
+// apply writes the options over an item, "--meta key=" removes the key.
+func (o itemOptions) apply(title, note *string, metadata map[string]string) map[string]string {
+	if o.title != nil {
+		*title = *o.title
+	}
+	if o.note != nil {
+		*note = *o.note
+	}
+	if len(o.metadata) == 0 {
+		return metadata
+	}
+	// the map is copied, the item read before the change is the base of a merge
+	result := make(map[string]string, len(metadata)+len(o.metadata))
+	for key, val := range metadata {
+		result[key] = val
+	}
+	for key, val := range o.metadata {
+		if val == "" {
+			delete(result, key)
+			continue
+		}
+		result[key] = val
+	}
+	return result
+}
+
+// parseListOptions turns the --folder, --tag, --since, --sort and --limit flags
+// of the list commands into the query parameters of the request.
+func parseListOptions(args []string) (map[string]string, error) {
+	params := make(map[string]string)
+	for i := 0; i < len(args); i += 2 {
+		if i+1 >= len(args) {
+			return nil, error2.ErrInvalidCommand
+		}
+		switch args[i] {
+		case "--folder":
+			params["folder"] = args[i+1]
+		case "--tag":
+			params["tag"] = args[i+1]
+		case "--since":
+			params["updated_since"] = args[i+1]
+		case "--sort":
+			params["sort"] = args[i+1]
+		case "--limit":
+			params["limit"] = args[i+1]
+		default:
+			return nil, error2.ErrInvalidCommand
+		}
+	}
+	return params, nil
+}
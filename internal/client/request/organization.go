@@ -0,0 +1,201 @@
+package request
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	error2 "github.com/zelas91/goph-keeper/internal/client/error"
+	"github.com/zelas91/goph-keeper/internal/client/vault"
+	"github.com/zelas91/goph-keeper/internal/server/models"
+)
+
+var errKeysNotLoaded = errors.New("the keys of the user are not loaded, log in again")
+
+type Organization struct {
+	request *Request
+}
+
+func NewOrganization(request *Request) *Organization {
+	return &Organization{request: request}
+}
+
+// Create makes an organization with the user as its owner. The key of the
+// files of the organization is made here and sealed for the owner.
+func (o *Organization) Create(args []string) error {
+	if len(args) < 1 {
+		return error2.ErrInvalidCommand
+	}
+	if o.request.keys == nil {
+		return errKeysNotLoaded
+	}
+	key, err := vault.NewFileKey()
+	if err != nil {
+		return err
+	}
+	org := models.Organization{Name: args[0]}
+	if org.OrgKey, err = vault.SealKey(o.request.keys.PublicKey(), key); err != nil {
+		return err
+	}
+	resp, err := o.request.R().SetBody(org).Post("/org")
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode() != http.StatusCreated {
+		return fmt.Errorf("request create organization error status code = %d, body = %s",
+			resp.StatusCode(), string(resp.Body()))
+	}
+	fmt.Println(string(resp.Body()))
+	return nil
+}
+
+func (o *Organization) Organizations(_ []string) error {
+	return o.print("/org")
+}
+
+func (o *Organization) Members(args []string) error {
+	if len(args) < 1 {
+		return error2.ErrInvalidCommand
+	}
+	return o.print(fmt.Sprintf("/org/%s/members", args[0]))
+}
+
+func (o *Organization) print(url string) error {
+	resp, err := o.request.R().Get(url)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode() != http.StatusOK {
+		return fmt.Errorf("request organizations error status code = %d, body = %s",
+			resp.StatusCode(), string(resp.Body()))
+	}
+	str, err := prettyJSON(resp.Body())
+	if err != nil {
+		return err
+	}
+	fmt.Println(str)
+	return nil
+}
+
+// Delete removes the organization, its vault has to be empty.
+func (o *Organization) Delete(args []string) error {
+	if len(args) < 1 {
+		return error2.ErrInvalidCommand
+	}
+	orgID, err := strconv.Atoi(args[0])
+	if err != nil {
+		return fmt.Errorf("organization id err:%w", err)
+	}
+	resp, err := o.request.R().Delete(fmt.Sprintf("/org/%d", orgID))
+	if err != nil {
+		return fmt.Errorf("request organization delete err: %w", err)
+	}
+	if resp.StatusCode() != http.StatusOK {
+		return fmt.Errorf("request organization delete error status code = %d, body = %s",
+			resp.StatusCode(), string(resp.Body()))
+	}
+	if v := o.request.other; v != nil && v.header == vaultHeader && v.id == orgID {
+		o.request.switchVault(nil)
+	}
+	return nil
+}
+
+// Invite adds the user to the organization, the key of the files of the
+// organization is sealed for the user.
+func (o *Organization) Invite(args []string) error {
+	if len(args) < 2 {
+		return error2.ErrInvalidCommand
+	}
+	org, err := o.request.openOrg(args[0])
+	if err != nil {
+		return err
+	}
+	member := models.OrgMember{Login: args[1], Role: models.RoleMember}
+	if len(args) > 2 {
+		member.Role = args[2]
+	}
+	publicKey, err := o.request.publicKey(member.Login)
+	if err != nil {
+		return err
+	}
+	if member.OrgKey, err = vault.SealKey(publicKey, org.key); err != nil {
+		return err
+	}
+	resp, err := o.request.R().SetBody(member).Post(fmt.Sprintf("/org/%d/members", org.id))
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode() != http.StatusCreated {
+		return fmt.Errorf("request invite error status code = %d, body = %s",
+			resp.StatusCode(), string(resp.Body()))
+	}
+	return nil
+}
+
+func (o *Organization) SetRole(args []string) error {
+	if len(args) < 3 {
+		return error2.ErrInvalidCommand
+	}
+	resp, err := o.request.R().SetBody(models.OrgRole{Role: args[2]}).
+		Put(fmt.Sprintf("/org/%s/members/%s", args[0], args[1]))
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode() != http.StatusOK {
+		return fmt.Errorf("request set role error status code = %d, body = %s",
+			resp.StatusCode(), string(resp.Body()))
+	}
+	return nil
+}
+
+// Remove removes the member from the organization, the own login leaves it.
+func (o *Organization) Remove(args []string) error {
+	if len(args) < 2 {
+		return error2.ErrInvalidCommand
+	}
+	resp, err := o.request.R().Delete(fmt.Sprintf("/org/%s/members/%s", args[0], args[1]))
+	if err != nil {
+		return fmt.Errorf("request member remove err: %w", err)
+	}
+	if resp.StatusCode() != http.StatusOK {
+		return fmt.Errorf("request member remove error status code = %d, body = %s",
+			resp.StatusCode(), string(resp.Body()))
+	}
+	return nil
+}
+
+// openOrg reads the organization of the user and opens the key of its files.
+func (r *Request) openOrg(id string) (*otherVault, error) {
+	orgID, err := strconv.Atoi(id)
+	if err != nil {
+		return nil, fmt.Errorf("organization id err:%w", err)
+	}
+	resp, err := r.R().Get("/org")
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode() != http.StatusOK {
+		return nil, fmt.Errorf("request organizations error status code = %d", resp.StatusCode())
+	}
+	var orgs []models.Organization
+	if err = json.Unmarshal(resp.Body(), &orgs); err != nil {
+		return nil, fmt.Errorf("organizations decode err: %w", err)
+	}
+	for _, v := range orgs {
+		if v.ID != orgID {
+			continue
+		}
+		if r.keys == nil {
+			return nil, errKeysNotLoaded
+		}
+		key, err := vault.OpenFileKey(r.vaultKey, r.keys, v.OrgKey)
+		if err != nil {
+			return nil, fmt.Errorf("organization key err: %w", err)
+		}
+		return &otherVault{header: vaultHeader, id: v.ID,
+			name: fmt.Sprintf("organization %d %q", v.ID, v.Name), key: key}, nil
+	}
+	return nil, fmt.Errorf("organization %d not found", orgID)
+}
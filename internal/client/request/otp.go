@@ -0,0 +1,146 @@
+package request
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	error2 "github.com/zelas91/goph-keeper/internal/client/error"
+	"github.com/zelas91/goph-keeper/internal/server/models"
+	"github.com/zelas91/goph-keeper/internal/utils/otp"
+)
+
+type OTP struct {
+	request *Request
+}
+
+func NewOTP(request *Request) *OTP {
+	return &OTP{request: request}
+}
+
+// Create keeps the key of the otpauth:// URI or the base32 secret with the
+// parameters of the authenticators. The key is read here, so the keys made
+// offline give the codes as well.
+func (o *OTP) Create(args []string) error {
+	args, opts, err := parseItemOptions(args)
+	if err != nil {
+		return err
+	}
+	if len(args) != 1 {
+		return error2.ErrInvalidCommand
+	}
+	key := otp.Key{Secret: args[0]}.WithDefaults()
+	if strings.HasPrefix(args[0], "otpauth://") {
+		if key, err = otp.Parse(args[0]); err != nil {
+			return err
+		}
+	}
+	if err = key.Validate(); err != nil {
+		return err
+	}
+	item := models.OTP{
+		Type:      key.Type,
+		Issuer:    key.Issuer,
+		Account:   key.Account,
+		Secret:    key.Secret,
+		Algorithm: key.Algorithm,
+		Digits:    key.Digits,
+		Period:    key.Period,
+		Counter:   key.Counter,
+	}
+	item.Metadata = opts.apply(&item.Title, &item.Note, item.Metadata)
+	resp, err := o.request.R().SetBody(item).Post("/otp")
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode() != http.StatusCreated {
+		return fmt.Errorf("request create otp error status code = %d, body = %s",
+			resp.StatusCode(), string(resp.Body()))
+	}
+	return nil
+}
+
+func (o *OTP) OTPs(args []string) error {
+	params, err := parseListOptions(args)
+	if err != nil {
+		return err
+	}
+	return o.request.list("/otp", params)
+}
+
+func (o *OTP) Delete(args []string) error {
+	if len(args) < 1 {
+		return error2.ErrInvalidCommand
+	}
+	url := fmt.Sprintf("/otp/%s", args[0])
+	resp, err := o.request.R().Delete(url)
+	if err != nil {
+		return fmt.Errorf("request otp delete err: %w", err)
+	}
+	if resp.StatusCode() != http.StatusOK {
+		return fmt.Errorf("request otp delete error status code = %d", resp.StatusCode())
+	}
+	return nil
+}
+
+// Code prints the current TOTP code and how long it stays valid. A HOTP
+// code is used once, the counter of the key is moved on after it.
+func (o *OTP) Code(args []string) error {
+	if len(args) != 1 {
+		return error2.ErrInvalidCommand
+	}
+	id, err := parseID(args[0])
+	if err != nil {
+		return err
+	}
+	url := fmt.Sprintf("/otp/%d", id)
+	resp, err := o.request.R().Get(url)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode() != http.StatusOK {
+		return fmt.Errorf("request get otp id error status code = %d, body=%s",
+			resp.StatusCode(), string(resp.Body()))
+	}
+	var item models.OTP
+	if err = json.Unmarshal(resp.Body(), &item); err != nil {
+		return fmt.Errorf("request otp decode err: %w", err)
+	}
+	key := otp.Key{
+		Type:      item.Type,
+		Issuer:    item.Issuer,
+		Account:   item.Account,
+		Secret:    item.Secret,
+		Algorithm: item.Algorithm,
+		Digits:    item.Digits,
+		Period:    item.Period,
+		Counter:   item.Counter,
+	}.WithDefaults()
+	now := time.Now()
+	code, err := key.Code(now)
+	if err != nil {
+		return err
+	}
+	if key.Type != otp.TypeHOTP {
+		fmt.Printf("%s (%d seconds remaining)\n", code, int(key.Remaining(now).Seconds()))
+		return nil
+	}
+	next := item
+	next.Counter++
+	resp, err = o.request.R().SetBody(next).Put(url)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode() == http.StatusConflict {
+		if err = o.request.resolveConflict(url, item, next, resp); err != nil {
+			return err
+		}
+	} else if resp.StatusCode() != http.StatusOK {
+		return fmt.Errorf("request update otp counter error status code = %d, body = %s",
+			resp.StatusCode(), string(resp.Body()))
+	}
+	fmt.Printf("%s (counter %d)\n", code, item.Counter)
+	return nil
+}
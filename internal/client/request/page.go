@@ -0,0 +1,46 @@
+package request
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+// page is one page of the item lists of the server.
+type page struct {
+	Items      []interface{} `json:"items"`
+	NextCursor string        `json:"next_cursor"`
+}
+
+// list prints the items page after page, the next page is fetched
+// only when the user asks for it.
+func (r *Request) list(url string, params map[string]string) error {
+	for {
+		resp, err := r.R().SetQueryParams(params).Get(url)
+		if err != nil {
+			return err
+		}
+		if resp.StatusCode() != http.StatusOK {
+			return fmt.Errorf("request list error status code = %d, body = %s",
+				resp.StatusCode(), string(resp.Body()))
+		}
+		var p page
+		if err = json.Unmarshal(resp.Body(), &p); err != nil {
+			return fmt.Errorf("request list decode err: %w", err)
+		}
+		pretty, err := json.MarshalIndent(p.Items, "", "    ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(pretty))
+		if p.NextCursor == "" || !r.confirm("next page? [y/N]") {
+			return nil
+		}
+		params["cursor"] = p.NextCursor
+	}
+}
+
+func (r *Request) confirm(question string) bool {
+	answer := r.ask(question)
+	return answer == "y" || answer == "yes"
+}
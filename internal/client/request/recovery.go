@@ -0,0 +1,132 @@
+package request
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	error2 "github.com/zelas91/goph-keeper/internal/client/error"
+	"github.com/zelas91/goph-keeper/internal/client/vault"
+	"github.com/zelas91/goph-keeper/internal/server/models"
+)
+
+type Recovery struct {
+	request *Request
+}
+
+func NewRecovery(request *Request) *Recovery {
+	return &Recovery{request: request}
+}
+
+// Key makes a new recovery key of the user, the key made before does not
+// recover the account any more.
+func (rc *Recovery) Key(_ []string) error {
+	return rc.request.setRecoveryKey()
+}
+
+// Recover sets the new password with the recovery key. The vault key is
+// opened with the recovery key and wrapped with the new password, so the
+// files stay readable, and the recovery key is replaced with a new one.
+func (rc *Recovery) Recover(args []string) error {
+	if len(args) != 3 {
+		return error2.ErrInvalidCommand
+	}
+	login, password := args[0], args[2]
+	key, err := vault.ParseRecoveryKey(args[1])
+	if err != nil {
+		return err
+	}
+	proof := models.RecoveryProof{Login: login, Key: key.Proof}
+	resp, err := rc.request.R().SetBody(proof).Post("/recover/key")
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode() != http.StatusOK {
+		return fmt.Errorf("request recovery key error status code = %d, body = %s",
+			resp.StatusCode(), string(resp.Body()))
+	}
+	var wrapped models.RecoveryKey
+	if err = json.Unmarshal(resp.Body(), &wrapped); err != nil {
+		return err
+	}
+	vaultKey, err := vault.UnwrapKey(key.Key, wrapped.VaultKey)
+	if err != nil {
+		return err
+	}
+	rec := models.Recover{RecoveryProof: proof, Password: password}
+	if rec.VaultKey, err = vault.WrapKey(vault.DeriveKey(login, password), vaultKey); err != nil {
+		return err
+	}
+	next, err := vault.NewRecoveryKey()
+	if err != nil {
+		return err
+	}
+	if rec.Next, err = wrapRecoveryKey(next, vaultKey); err != nil {
+		return err
+	}
+	resp, err = rc.request.R().SetBody(rec).Post("/recover")
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode() != http.StatusOK {
+		return fmt.Errorf("request recover error status code = %d, body = %s",
+			resp.StatusCode(), string(resp.Body()))
+	}
+	fmt.Println("the password is set, login with it")
+	printRecoveryKey(next)
+	return nil
+}
+
+// setRecoveryKey makes the recovery key of the logged in user.
+func (r *Request) setRecoveryKey() error {
+	if r.vaultKey == nil {
+		return error2.ErrAuthorization
+	}
+	key, err := vault.NewRecoveryKey()
+	if err != nil {
+		return err
+	}
+	body, err := wrapRecoveryKey(key, r.vaultKey)
+	if err != nil {
+		return err
+	}
+	resp, err := r.R().SetBody(body).Put("/recovery")
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode() != http.StatusOK {
+		return fmt.Errorf("request set recovery key error status code = %d, body = %s",
+			resp.StatusCode(), string(resp.Body()))
+	}
+	printRecoveryKey(key)
+	return nil
+}
+
+func (r *Request) recoveryState() (models.RecoveryState, error) {
+	resp, err := r.R().Get("/recovery")
+	if err != nil {
+		return models.RecoveryState{}, err
+	}
+	if resp.StatusCode() != http.StatusOK {
+		return models.RecoveryState{}, fmt.Errorf("request recovery error status code = %d, body = %s",
+			resp.StatusCode(), string(resp.Body()))
+	}
+	var state models.RecoveryState
+	if err = json.Unmarshal(resp.Body(), &state); err != nil {
+		return models.RecoveryState{}, err
+	}
+	return state, nil
+}
+
+func wrapRecoveryKey(key *vault.RecoveryKey, vaultKey []byte) (models.RecoveryKey, error) {
+	wrapped, err := vault.WrapKey(key.Key, vaultKey)
+	if err != nil {
+		return models.RecoveryKey{}, err
+	}
+	return models.RecoveryKey{Key: key.Proof, VaultKey: wrapped}, nil
+}
+
+func printRecoveryKey(key *vault.RecoveryKey) {
+	fmt.Printf("write the recovery key down, it is shown once and sets a new password "+
+		"when the password is lost:\n%s\n", key.Text)
+}
@@ -0,0 +1,38 @@
+package request
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	error2 "github.com/zelas91/goph-keeper/internal/client/error"
+)
+
+type Search struct {
+	request *Request
+}
+
+func NewSearch(request *Request) *Search {
+	return &Search{request: request}
+}
+
+// Search prints the items of every type matching all words of the query.
+func (s *Search) Search(args []string) error {
+	if len(args) < 1 {
+		return error2.ErrInvalidCommand
+	}
+	resp, err := s.request.R().SetQueryParams(map[string]string{"q": strings.Join(args, " ")}).Get("/search")
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode() != http.StatusOK {
+		return fmt.Errorf("request search error status code = %d, body = %s",
+			resp.StatusCode(), string(resp.Body()))
+	}
+	str, err := prettyJSON(resp.Body())
+	if err != nil {
+		return err
+	}
+	fmt.Println(str)
+	return nil
+}
@@ -0,0 +1,202 @@
+package request
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	error2 "github.com/zelas91/goph-keeper/internal/client/error"
+	"github.com/zelas91/goph-keeper/internal/client/vault"
+	"github.com/zelas91/goph-keeper/internal/server/models"
+	"github.com/zelas91/goph-keeper/internal/server/types"
+)
+
+var errNoKeys = errors.New("the user has no keys, the user has to log in once before the files are shared")
+
+type Share struct {
+	request *Request
+}
+
+func NewShare(request *Request) *Share {
+	return &Share{request: request}
+}
+
+// Create shares the item with the user of the login. The key of a file is
+// sealed for the recipient here, the server never sees it open.
+func (s *Share) Create(args []string) error {
+	if len(args) < 3 {
+		return error2.ErrInvalidCommand
+	}
+	item, err := newItemRef(args[0], args[1])
+	if err != nil {
+		return err
+	}
+	sh := models.Share{Recipient: args[2], ItemType: item.ItemType, ItemID: item.ItemID,
+		Permission: models.PermissionRead}
+	if len(args) > 3 {
+		if args[3] != models.PermissionRead && args[3] != models.PermissionWrite {
+			return error2.ErrInvalidCommand
+		}
+		sh.Permission = args[3]
+	}
+	if sh.ItemType == types.ItemFile {
+		if sh.FileKey, err = s.sealFileKey(sh.ItemID, sh.Recipient); err != nil {
+			return err
+		}
+	}
+	resp, err := s.request.R().SetBody(sh).Post("/shares")
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode() != http.StatusCreated {
+		return fmt.Errorf("request create share error status code = %d, body = %s",
+			resp.StatusCode(), string(resp.Body()))
+	}
+	fmt.Println(string(resp.Body()))
+	return nil
+}
+
+// sealFileKey returns the key of the file sealed for the recipient, empty
+// for a file the server encrypts.
+func (s *Share) sealFileKey(fileID int, recipient string) (string, error) {
+	resp, err := s.request.R().Get(fmt.Sprintf("/file/%d", fileID))
+	if err != nil {
+		return "", fmt.Errorf("request file information err: %w", err)
+	}
+	if resp.StatusCode() != http.StatusOK {
+		return "", fmt.Errorf("request file information error status code = %d", resp.StatusCode())
+	}
+	var bf models.BinaryFile
+	if err = json.Unmarshal(resp.Body(), &bf); err != nil {
+		return "", fmt.Errorf("file information decode err: %w", err)
+	}
+	if bf.FileKey == "" {
+		return "", nil
+	}
+	key, err := s.request.openFileKey(bf.FileKey)
+	if err != nil {
+		return "", err
+	}
+
+	publicKey, err := s.request.publicKey(recipient)
+	if err != nil {
+		return "", err
+	}
+	return vault.SealKey(publicKey, key)
+}
+
+// publicKey returns the key the file keys are sealed with for the user of the login.
+func (r *Request) publicKey(login string) (string, error) {
+	resp, err := r.R().Get("/keys/" + login)
+	if err != nil {
+		return "", fmt.Errorf("request public key err: %w", err)
+	}
+	if resp.StatusCode() == http.StatusNotFound {
+		return "", errNoKeys
+	}
+	if resp.StatusCode() != http.StatusOK {
+		return "", fmt.Errorf("request public key error status code = %d", resp.StatusCode())
+	}
+	var keys models.UserKeys
+	if err = json.Unmarshal(resp.Body(), &keys); err != nil {
+		return "", fmt.Errorf("public key decode err: %w", err)
+	}
+	return keys.PublicKey, nil
+}
+
+// Shares prints the shares the user made.
+func (s *Share) Shares(_ []string) error {
+	return s.print("/shares")
+}
+
+// Incoming prints the items other users share with the user.
+func (s *Share) Incoming(_ []string) error {
+	return s.print("/shares/incoming")
+}
+
+func (s *Share) print(url string) error {
+	resp, err := s.request.R().Get(url)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode() != http.StatusOK {
+		return fmt.Errorf("request shares error status code = %d, body = %s",
+			resp.StatusCode(), string(resp.Body()))
+	}
+	str, err := prettyJSON(resp.Body())
+	if err != nil {
+		return err
+	}
+	fmt.Println(str)
+	return nil
+}
+
+// Revoke removes the share, the recipient uses it to leave the share.
+func (s *Share) Revoke(args []string) error {
+	if len(args) < 1 {
+		return error2.ErrInvalidCommand
+	}
+	resp, err := s.request.R().Delete(fmt.Sprintf("/shares/%s", args[0]))
+	if err != nil {
+		return fmt.Errorf("request share delete err: %w", err)
+	}
+	if resp.StatusCode() != http.StatusOK {
+		return fmt.Errorf("request share delete error status code = %d", resp.StatusCode())
+	}
+	return nil
+}
+
+// loadKeys reads the key pair of the user from the server, the pair is made
+// and kept on the server when the user has none.
+func (r *Request) loadKeys() (*vault.KeyPair, error) {
+	resp, err := r.R().Get("/keys")
+	if err != nil {
+		return nil, err
+	}
+	switch resp.StatusCode() {
+	case http.StatusOK:
+		var keys models.UserKeys
+		if err = json.Unmarshal(resp.Body(), &keys); err != nil {
+			return nil, fmt.Errorf("keys decode err: %w", err)
+		}
+		return vault.OpenKeyPair(r.vaultKey, keys.PublicKey, keys.PrivateKey)
+	case http.StatusNotFound:
+	default:
+		return nil, fmt.Errorf("request keys error status code = %d", resp.StatusCode())
+	}
+
+	pair, err := vault.NewKeyPair()
+	if err != nil {
+		return nil, err
+	}
+	private, err := pair.WrapPrivate(r.vaultKey)
+	if err != nil {
+		return nil, err
+	}
+	resp, err = r.R().SetBody(models.UserKeys{PublicKey: pair.PublicKey(), PrivateKey: private}).Put("/keys")
+	if err != nil {
+		return nil, err
+	}
+	switch resp.StatusCode() {
+	case http.StatusCreated:
+		return pair, nil
+	case http.StatusConflict:
+		// another client of the user made the pair at the same time
+		return r.loadKeys()
+	default:
+		return nil, fmt.Errorf("request set keys error status code = %d", resp.StatusCode())
+	}
+}
+
+// openFileKey decrypts the key of a file of the vault in use or of a shared file.
+func (r *Request) openFileKey(fileKey string) ([]byte, error) {
+	if r.filesKey() == nil {
+		return nil, error2.ErrAuthorization
+	}
+	key, err := vault.OpenFileKey(r.filesKey(), r.keys, fileKey)
+	if err != nil {
+		return nil, fmt.Errorf("file key err: %w", err)
+	}
+	return key, nil
+}
@@ -0,0 +1,192 @@
+package request
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	error2 "github.com/zelas91/goph-keeper/internal/client/error"
+	"github.com/zelas91/goph-keeper/internal/client/vault"
+	"github.com/zelas91/goph-keeper/internal/server/models"
+)
+
+const (
+	// linkViews and linkExpires are the limits of a link made without flags.
+	linkViews   = 1
+	linkExpires = 24 * time.Hour
+)
+
+type ShareLink struct {
+	request *Request
+}
+
+func NewShareLink(request *Request) *ShareLink {
+	return &ShareLink{request: request}
+}
+
+// Create makes a link to the text or credential item for a person without
+// an account, the link opens in the browser and with link_open.
+func (l *ShareLink) Create(args []string) error {
+	args, nl, err := parseLinkFlags(args)
+	if err != nil {
+		return err
+	}
+	if len(args) != 2 {
+		return error2.ErrInvalidCommand
+	}
+	if nl.ItemID, err = parseID(args[1]); err != nil {
+		return err
+	}
+	nl.ItemType = args[0]
+	return l.create(nl)
+}
+
+// Secret makes a link to the text given, nothing is kept in the vault.
+func (l *ShareLink) Secret(args []string) error {
+	args, nl, err := parseLinkFlags(args)
+	if err != nil {
+		return err
+	}
+	if len(args) == 0 {
+		return error2.ErrInvalidCommand
+	}
+	nl.Text = strings.Join(args, " ")
+	return l.create(nl)
+}
+
+func (l *ShareLink) create(nl models.NewShareLink) error {
+	resp, err := l.request.R().SetBody(nl).Post("/share-link")
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode() != http.StatusCreated {
+		return fmt.Errorf("request create link error status code = %d, body = %s",
+			resp.StatusCode(), string(resp.Body()))
+	}
+	var link models.ShareLink
+	if err = json.Unmarshal(resp.Body(), &link); err != nil {
+		return err
+	}
+	fmt.Printf("the link opens %s until %s, it is shown once:\n%s\n",
+		linkViewsText(link.MaxViews), link.ExpiresAt.Local().Format(time.RFC1123), link.URL)
+	return nil
+}
+
+// Links prints the links of the user that still open, without their keys.
+func (l *ShareLink) Links(_ []string) error {
+	resp, err := l.request.R().Get("/share-link")
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode() != http.StatusOK {
+		return fmt.Errorf("request links error status code = %d, body = %s",
+			resp.StatusCode(), string(resp.Body()))
+	}
+	pretty, err := prettyJSON(resp.Body())
+	if err != nil {
+		return err
+	}
+	fmt.Println(pretty)
+	return nil
+}
+
+// Delete removes the link before it expires.
+func (l *ShareLink) Delete(args []string) error {
+	if len(args) != 1 {
+		return error2.ErrInvalidCommand
+	}
+	resp, err := l.request.R().Delete("/share-link/" + url.PathEscape(args[0]))
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode() != http.StatusOK {
+		return fmt.Errorf("request delete link error status code = %d, body = %s",
+			resp.StatusCode(), string(resp.Body()))
+	}
+	return nil
+}
+
+// Open opens the link of any server and decrypts the secret here, it
+// takes a view of the link and needs no login.
+func (l *ShareLink) Open(args []string) error {
+	if len(args) != 1 {
+		return error2.ErrInvalidCommand
+	}
+	link, err := url.Parse(args[0])
+	if err != nil || link.Host == "" || link.Fragment == "" || !strings.HasPrefix(link.Path, "/s/") {
+		return fmt.Errorf("link err: want <scheme>://<host>/s/<id>#<key>")
+	}
+	id := strings.TrimPrefix(link.Path, "/s/")
+	open := url.URL{Scheme: link.Scheme, Host: link.Host, Path: "/api/share-link/" + id + "/open"}
+	resp, err := l.request.httClient.R().SetHeader("Content-Type", "application/json").Post(open.String())
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode() != http.StatusOK {
+		return fmt.Errorf("request open link error status code = %d, body = %s",
+			resp.StatusCode(), string(resp.Body()))
+	}
+	var sealed models.SealedSecret
+	if err = json.Unmarshal(resp.Body(), &sealed); err != nil {
+		return err
+	}
+	plain, err := vault.OpenLink(link.Fragment, sealed.Data)
+	if err != nil {
+		return err
+	}
+	var secret models.LinkSecret
+	if err = json.Unmarshal(plain, &secret); err != nil {
+		return err
+	}
+	pretty, err := json.MarshalIndent(secret, "", "    ")
+	if err != nil {
+		return err
+	}
+	fmt.Println(string(pretty))
+	return nil
+}
+
+// parseLinkFlags cuts the --views and --expires flags out of args.
+func parseLinkFlags(args []string) ([]string, models.NewShareLink, error) {
+	nl := models.NewShareLink{MaxViews: linkViews, ExpiresIn: int(linkExpires.Seconds())}
+	rest := make([]string, 0, len(args))
+	for i := 0; i < len(args); i++ {
+		if args[i] != "--views" && args[i] != "--expires" {
+			rest = append(rest, args[i])
+			continue
+		}
+		if i+1 >= len(args) {
+			return nil, nl, error2.ErrInvalidCommand
+		}
+		if args[i] == "--views" {
+			n, err := strconv.Atoi(args[i+1])
+			if err != nil || n < 0 {
+				return nil, nl, error2.ErrInvalidCommand
+			}
+			nl.MaxViews = n
+		} else {
+			d, err := time.ParseDuration(args[i+1])
+			if err != nil {
+				return nil, nl, error2.ErrInvalidCommand
+			}
+			nl.ExpiresIn = int(d.Seconds())
+		}
+		i++
+	}
+	return rest, nl, nil
+}
+
+func linkViewsText(views int) string {
+	switch views {
+	case 0:
+		return "any number of times"
+	case 1:
+		return "once"
+	default:
+		return fmt.Sprintf("%d times", views)
+	}
+}
@@ -0,0 +1,185 @@
+package request
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"os"
+	"path/filepath"
+
+	error2 "github.com/zelas91/goph-keeper/internal/client/error"
+	"github.com/zelas91/goph-keeper/internal/server/models"
+	"github.com/zelas91/goph-keeper/internal/utils/sshkey"
+)
+
+type SSHKey struct {
+	request *Request
+}
+
+func NewSSHKey(request *Request) *SSHKey {
+	return &SSHKey{request: request}
+}
+
+// Add keeps the private key of the file. The comment is taken from the .pub
+// file next to it when it is not given and the certificate from the
+// -cert.pub file when there is one, like ssh-add does. The key is read here,
+// so the keys added offline show their fingerprint as well.
+func (s *SSHKey) Add(args []string) error {
+	args, opts, err := parseItemOptions(args)
+	if err != nil {
+		return err
+	}
+	args, flags, err := parseSSHKeyFlags(args)
+	if err != nil {
+		return err
+	}
+	if len(args) != 1 {
+		return error2.ErrInvalidCommand
+	}
+	path := args[0]
+	privateKey, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("read ssh key err: %w", err)
+	}
+	item := models.SSHKey{
+		PrivateKey: string(privateKey),
+		Passphrase: flags.passphrase,
+		Title:      filepath.Base(path),
+	}
+	if flags.comment != nil {
+		item.Comment = *flags.comment
+	} else if public, err := os.ReadFile(path + ".pub"); err == nil {
+		item.Comment = sshkey.Comment(string(public))
+	}
+	if cert, err := os.ReadFile(path + "-cert.pub"); err == nil {
+		item.Certificate = string(cert)
+	}
+	parsed, err := sshkey.Parse(item.PrivateKey, item.Passphrase, item.Comment)
+	if err != nil {
+		return err
+	}
+	item.KeyType, item.PublicKey, item.Fingerprint = parsed.Type, parsed.PublicKey, parsed.Fingerprint
+	if item.Certificate != "" {
+		if err = sshkey.CheckCertificate(item.Certificate, item.PublicKey); err != nil {
+			return err
+		}
+	}
+	item.Metadata = opts.apply(&item.Title, &item.Note, item.Metadata)
+	resp, err := s.request.R().SetBody(item).Post("/ssh")
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode() != http.StatusCreated {
+		return fmt.Errorf("request create ssh key error status code = %d, body = %s",
+			resp.StatusCode(), string(resp.Body()))
+	}
+	fmt.Println(item.Fingerprint)
+	return nil
+}
+
+func (s *SSHKey) SSHKeys(args []string) error {
+	params, err := parseListOptions(args)
+	if err != nil {
+		return err
+	}
+	return s.request.list("/ssh", params)
+}
+
+func (s *SSHKey) Delete(args []string) error {
+	if len(args) < 1 {
+		return error2.ErrInvalidCommand
+	}
+	url := fmt.Sprintf("/ssh/%s", args[0])
+	resp, err := s.request.R().Delete(url)
+	if err != nil {
+		return fmt.Errorf("request ssh key delete err: %w", err)
+	}
+	if resp.StatusCode() != http.StatusOK {
+		return fmt.Errorf("request ssh key delete error status code = %d", resp.StatusCode())
+	}
+	return nil
+}
+
+// Write puts the private key to the file readable by the user only, ssh
+// refuses the keys others can read. The public key and the certificate are
+// written next to it as ssh-keygen names them.
+func (s *SSHKey) Write(args []string) error {
+	if len(args) != 2 {
+		return error2.ErrInvalidCommand
+	}
+	id, err := parseID(args[0])
+	if err != nil {
+		return err
+	}
+	resp, err := s.request.R().Get(fmt.Sprintf("/ssh/%d", id))
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode() != http.StatusOK {
+		return fmt.Errorf("request get ssh key id error status code = %d, body=%s",
+			resp.StatusCode(), string(resp.Body()))
+	}
+	var item models.SSHKey
+	if err = json.Unmarshal(resp.Body(), &item); err != nil {
+		return fmt.Errorf("request ssh key decode err: %w", err)
+	}
+	path := args[1]
+	if err = writeKeyFile(path, item.PrivateKey, 0o600); err != nil {
+		return err
+	}
+	if err = writeKeyFile(path+".pub", item.PublicKey+"\n", 0o644); err != nil {
+		return err
+	}
+	if item.Certificate != "" {
+		return writeKeyFile(path+"-cert.pub", item.Certificate, 0o644)
+	}
+	return nil
+}
+
+// writeKeyFile writes the file with the mode even over a file that is
+// there already, os.WriteFile keeps the mode of the file it truncates.
+func writeKeyFile(path, data string, mode fs.FileMode) error {
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, mode)
+	if err != nil {
+		return fmt.Errorf("write ssh key err: %w", err)
+	}
+	if err = file.Chmod(mode); err == nil {
+		_, err = file.WriteString(data)
+	}
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("write ssh key err: %w", err)
+	}
+	return nil
+}
+
+type sshKeyFlags struct {
+	passphrase string
+	comment    *string
+}
+
+// parseSSHKeyFlags cuts the --passphrase and --comment flags out of args.
+func parseSSHKeyFlags(args []string) ([]string, sshKeyFlags, error) {
+	var flags sshKeyFlags
+	rest := make([]string, 0, len(args))
+	for i := 0; i < len(args); i++ {
+		if args[i] != "--passphrase" && args[i] != "--comment" {
+			rest = append(rest, args[i])
+			continue
+		}
+		if i+1 >= len(args) {
+			return nil, flags, error2.ErrInvalidCommand
+		}
+		value := args[i+1]
+		if args[i] == "--passphrase" {
+			flags.passphrase = value
+		} else {
+			flags.comment = &value
+		}
+		i++
+	}
+	return rest, flags, nil
+}
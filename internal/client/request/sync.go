@@ -0,0 +1,65 @@
+package request
+
+import (
+	"encoding/json"
+	"fmt"
+
+	error2 "github.com/zelas91/goph-keeper/internal/client/error"
+	"github.com/zelas91/goph-keeper/internal/server/models"
+)
+
+type Sync struct {
+	request *Request
+	cursor  string
+}
+
+func NewSync(request *Request) *Sync {
+	return &Sync{request: request}
+}
+
+// Sync prints the items changed since the previous sync and keeps them
+// in the local cache, "--full" starts over and fetches every item.
+func (s *Sync) Sync(args []string) error {
+	full := false
+	if len(args) > 0 {
+		if args[0] != "--full" {
+			return error2.ErrInvalidCommand
+		}
+		full = true
+	}
+	changes, err := s.changes(full)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("cards: %d, credentials: %d, texts: %d, files: %d, deleted: %d\n",
+		len(changes.Cards), len(changes.Credentials), len(changes.Texts), len(changes.Files),
+		len(changes.Deleted))
+	pretty, err := json.MarshalIndent(changes, "", "    ")
+	if err != nil {
+		return err
+	}
+	fmt.Println(string(pretty))
+	return nil
+}
+
+// changes goes through the local cache when it is open,
+// without it the cursor lives as long as the session.
+func (s *Sync) changes(full bool) (models.SyncChanges, error) {
+	if c := s.request.cache; c != nil {
+		if full {
+			if err := c.Reset(); err != nil {
+				return models.SyncChanges{}, err
+			}
+		}
+		return s.request.Pull()
+	}
+	if full {
+		s.cursor = ""
+	}
+	changes, err := s.request.syncChanges(s.cursor)
+	if err != nil {
+		return changes, err
+	}
+	s.cursor = changes.Cursor
+	return changes, nil
+}
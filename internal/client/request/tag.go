@@ -0,0 +1,124 @@
+package request
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	error2 "github.com/zelas91/goph-keeper/internal/client/error"
+	"github.com/zelas91/goph-keeper/internal/server/models"
+)
+
+type Tag struct {
+	request *Request
+}
+
+func NewTag(request *Request) *Tag {
+	return &Tag{request: request}
+}
+
+func (t *Tag) Tags(args []string) error {
+	resp, err := t.request.R().Get("/tag")
+	if err != nil {
+		return err
+	}
+	str, err := prettyJSON(resp.Body())
+	if err != nil {
+		return err
+	}
+	fmt.Println(str)
+	return nil
+}
+
+func (t *Tag) Delete(args []string) error {
+	if len(args) < 1 {
+		return error2.ErrInvalidCommand
+	}
+	url := fmt.Sprintf("/tag/%s", args[0])
+	resp, err := t.request.R().Delete(url)
+	if err != nil {
+		return fmt.Errorf("request tag delete err: %w", err)
+	}
+	if resp.StatusCode() != http.StatusOK {
+		return fmt.Errorf("request tag delete error status code = %d", resp.StatusCode())
+	}
+	return nil
+}
+
+// Add attaches the tag to the item, the tag is created when the user has none with this name.
+func (t *Tag) Add(args []string) error {
+	if len(args) < 3 {
+		return error2.ErrInvalidCommand
+	}
+	item, err := newItemRef(args[0], args[1])
+	if err != nil {
+		return err
+	}
+	resp, err := t.request.R().SetBody(models.Tag{Name: args[2]}).Post("/tag")
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode() != http.StatusCreated {
+		return fmt.Errorf("request create tag error status code = %d, body = %s",
+			resp.StatusCode(), string(resp.Body()))
+	}
+	var tg models.Tag
+	if err = json.Unmarshal(resp.Body(), &tg); err != nil {
+		return fmt.Errorf("request tag decode err: %w", err)
+	}
+
+	resp, err = t.request.R().SetBody(item).Post(fmt.Sprintf("/tag/%d/items", tg.ID))
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode() != http.StatusOK {
+		return fmt.Errorf("request attach tag error status code = %d, body = %s",
+			resp.StatusCode(), string(resp.Body()))
+	}
+	return nil
+}
+
+func (t *Tag) Remove(args []string) error {
+	if len(args) < 3 {
+		return error2.ErrInvalidCommand
+	}
+	item, err := newItemRef(args[0], args[1])
+	if err != nil {
+		return err
+	}
+	tg, err := t.findByName(args[2])
+	if err != nil {
+		return err
+	}
+	resp, err := t.request.R().SetBody(item).Delete(fmt.Sprintf("/tag/%d/items", tg.ID))
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode() != http.StatusOK {
+		return fmt.Errorf("request detach tag error status code = %d, body = %s",
+			resp.StatusCode(), string(resp.Body()))
+	}
+	return nil
+}
+
+func (t *Tag) findByName(name string) (models.Tag, error) {
+	resp, err := t.request.R().Get("/tag")
+	if err != nil {
+		return models.Tag{}, err
+	}
+	if resp.StatusCode() != http.StatusOK {
+		return models.Tag{}, fmt.Errorf("request get tags error status code = %d, body = %s",
+			resp.StatusCode(), string(resp.Body()))
+	}
+	var tags []models.Tag
+	if err = json.Unmarshal(resp.Body(), &tags); err != nil {
+		return models.Tag{}, fmt.Errorf("request tags decode err: %w", err)
+	}
+	for _, tg := range tags {
+		if tg.Name == name {
+			return tg, nil
+		}
+	}
+	return models.Tag{}, errors.New("tag not found")
+}
@@ -34,7 +34,11 @@ func (t *TextData) Delete(args []string) error {
 }
 
 func (t *TextData) Update(args []string) error {
-	if len(args) < 2 {
+	args, opts, err := parseItemOptions(args)
+	if err != nil {
+		return err
+	}
+	if len(args) < 1 {
 		return error2.ErrInvalidCommand
 	}
 	textID, err := strconv.Atoi(args[0])
@@ -57,12 +61,16 @@ func (t *TextData) Update(args []string) error {
 	if err := json.Unmarshal(resp.Body(), &text); err != nil {
 		return fmt.Errorf("request text decode err: %w", err)
 	}
-	var tb strings.Builder
-	for i := 1; i < len(args); i++ {
-		tb.WriteString(args[i])
-		tb.WriteString(" ")
+	base := text
+	if len(args) > 1 {
+		var tb strings.Builder
+		for i := 1; i < len(args); i++ {
+			tb.WriteString(args[i])
+			tb.WriteString(" ")
+		}
+		text.Text = tb.String()
 	}
-	text.Text = tb.String()
+	text.Metadata = opts.apply(&text.Title, &text.Note, text.Metadata)
 
 	url = fmt.Sprintf("/text/%d", textID)
 
@@ -70,6 +78,9 @@ func (t *TextData) Update(args []string) error {
 	if err != nil {
 		return err
 	}
+	if resp.StatusCode() == http.StatusConflict {
+		return t.request.resolveConflict(url, base, text, resp)
+	}
 
 	if resp.StatusCode() != http.StatusOK {
 		return fmt.Errorf("request update text error status code = %d, body = %s",
@@ -79,6 +90,10 @@ func (t *TextData) Update(args []string) error {
 }
 
 func (t *TextData) Create(args []string) error {
+	args, opts, err := parseItemOptions(args)
+	if err != nil {
+		return err
+	}
 	if len(args) < 1 {
 		return error2.ErrInvalidCommand
 	}
@@ -90,6 +105,7 @@ func (t *TextData) Create(args []string) error {
 	text := models.TextData{
 		Text: tb.String(),
 	}
+	text.Metadata = opts.apply(&text.Title, &text.Note, text.Metadata)
 
 	resp, err := t.request.R().SetBody(text).Post("/text")
 	if err != nil {
@@ -104,14 +120,9 @@ func (t *TextData) Create(args []string) error {
 }
 
 func (t *TextData) Texts(args []string) error {
-	resp, err := t.request.R().Get("/text")
+	params, err := parseListOptions(args)
 	if err != nil {
 		return err
 	}
-	str, err := prettyJSON(resp.Body())
-	if err != nil {
-		return err
-	}
-	fmt.Println(str)
-	return nil
+	return t.request.list("/text", params)
 }
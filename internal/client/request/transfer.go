@@ -0,0 +1,174 @@
+package request
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	error2 "github.com/zelas91/goph-keeper/internal/client/error"
+)
+
+const (
+	// parallelTransfers is how many files a command sends or reads at once.
+	parallelTransfers = 4
+	// progressInterval is how often the progress line is printed again.
+	progressInterval = 200 * time.Millisecond
+	// PathMetaKey is the metadata key of the path of a file uploaded
+	// with its directory, relative to the directory and slash separated.
+	PathMetaKey = "path"
+)
+
+// parseParallel cuts the --parallel flag out of args.
+func parseParallel(args []string) ([]string, int, error) {
+	limit := parallelTransfers
+	rest := make([]string, 0, len(args))
+	for i := 0; i < len(args); i++ {
+		if args[i] != "--parallel" {
+			rest = append(rest, args[i])
+			continue
+		}
+		if i+1 >= len(args) {
+			return nil, 0, error2.ErrInvalidCommand
+		}
+		n, err := strconv.Atoi(args[i+1])
+		if err != nil || n < 1 {
+			return nil, 0, error2.ErrInvalidCommand
+		}
+		limit = n
+		i++
+	}
+	return rest, limit, nil
+}
+
+// progress prints one line for all the files of a command.
+type progress struct {
+	mu     sync.Mutex
+	action string
+	files  int
+	done   int
+	total  int64
+	bytes  int64
+	last   time.Time
+}
+
+func newProgress(action string, files int) *progress {
+	return &progress{action: action, files: files}
+}
+
+// grow adds the size of a file to the total, the size of a file to download
+// is known once its information is read.
+func (p *progress) grow(size int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.total += int64(size)
+	p.print(false)
+}
+
+func (p *progress) add(n int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.bytes += int64(n)
+	p.print(false)
+}
+
+// finish counts the file done whether it failed or not.
+func (p *progress) finish() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.done++
+	p.print(true)
+}
+
+// note prints the message on its own line above the progress line.
+func (p *progress) note(format string, args ...any) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	fmt.Printf("\r\033[K"+format+"\n", args...)
+	p.print(true)
+}
+
+// end leaves the progress line as it is.
+func (p *progress) end() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.print(true)
+	fmt.Println("")
+}
+
+func (p *progress) print(force bool) {
+	if !force && time.Since(p.last) < progressInterval {
+		return
+	}
+	p.last = time.Now()
+	fmt.Printf("\r\033[K%s: %d/%d files %dKB of %dKB", p.action, p.done, p.files, p.bytes/1024, p.total/1024)
+}
+
+// transferFailure is a file a command did not send or read.
+type transferFailure struct {
+	name string
+	err  error
+}
+
+// runParallel calls fn for every job with at most limit calls at once,
+// no job is started after the server refuses the session.
+func runParallel(jobs []string, limit int, fn func(job string) error) []transferFailure {
+	var (
+		mu       sync.Mutex
+		failures []transferFailure
+		denied   bool
+		wg       sync.WaitGroup
+	)
+	sem := make(chan struct{}, limit)
+	for _, job := range jobs {
+		sem <- struct{}{}
+		mu.Lock()
+		if denied {
+			failures = append(failures, transferFailure{name: job, err: error2.ErrAuthorization})
+			mu.Unlock()
+			<-sem
+			continue
+		}
+		mu.Unlock()
+		wg.Add(1)
+		go func(job string) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			if err := fn(job); err != nil {
+				mu.Lock()
+				denied = denied || errors.Is(err, error2.ErrAuthorization)
+				failures = append(failures, transferFailure{name: job, err: err})
+				mu.Unlock()
+			}
+		}(job)
+	}
+	wg.Wait()
+	sort.Slice(failures, func(i, j int) bool {
+		return failures[i].name < failures[j].name
+	})
+	return failures
+}
+
+// failureSummary prints the files that failed, the error keeps the refusal
+// of the session so the client asks to log in again.
+func failureSummary(action string, total int, failures []transferFailure) error {
+	if len(failures) == 0 {
+		return nil
+	}
+	fmt.Printf("%s failed for %d of %d files:\n", action, len(failures), total)
+	var cause error
+	for _, v := range failures {
+		fmt.Printf("  %s: %v\n", v.name, v.err)
+		if errors.Is(v.err, error2.ErrAuthorization) {
+			cause = error2.ErrAuthorization
+		}
+	}
+	if cause != nil {
+		return fmt.Errorf("%s of %d files failed: %w", action, len(failures), cause)
+	}
+	return fmt.Errorf("%s of %d files failed, run the command again to resume", action, len(failures))
+}
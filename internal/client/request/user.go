@@ -1,11 +1,14 @@
 package request
 
 import (
+	"encoding/json"
 	"fmt"
 	"net/http"
+	"strconv"
 	"strings"
 
 	error2 "github.com/zelas91/goph-keeper/internal/client/error"
+	"github.com/zelas91/goph-keeper/internal/client/vault"
 	"github.com/zelas91/goph-keeper/internal/server/models"
 )
 
@@ -17,25 +20,35 @@ func NewAuthorization(request *Request) *Authorization {
 	return &Authorization{request: request}
 }
 
+// SignIn logs in on the server and opens the local cache of the user,
+// with the server not reachable the local cache alone is opened.
 func (a *Authorization) SignIn(args []string) error {
 	user, err := newUserModels(args)
 	if err != nil {
 		return err
 	}
+	a.request.stopListen()
+	a.request.vaultKey = vault.DeriveKey(user.Login, user.Password)
+	a.request.keys = nil
+	a.request.switchVault(nil)
 
 	resp, err := a.request.R().SetBody(user).Post("/signin")
 	if err != nil {
-		return err
+		if cacheErr := a.request.OpenCache(user.Login, user.Password, false); cacheErr != nil {
+			return fmt.Errorf("%w, local cache: %v", err, cacheErr)
+		}
+		a.request.session.Offline = true
+		fmt.Println(errOffline.Error() + ", working with the local cache")
+		return nil
 	}
 	if resp.StatusCode() != http.StatusOK {
 		return fmt.Errorf("response fault %s", string(resp.Body()))
 	}
-	for _, cookie := range resp.Cookies() {
-		if strings.EqualFold(cookie.Name, "jwt") {
-			a.request.SetCookiesAuthorization(cookie)
-			break
-		}
-	}
+	a.setCookie(resp.Cookies())
+	a.loadVaultKey()
+	a.loadKeys()
+	a.openCache(user)
+	a.request.listen()
 	return nil
 }
 func (a *Authorization) SignUp(args []string) error {
@@ -43,6 +56,10 @@ func (a *Authorization) SignUp(args []string) error {
 	if err != nil {
 		return err
 	}
+	a.request.stopListen()
+	a.request.vaultKey = vault.DeriveKey(user.Login, user.Password)
+	a.request.keys = nil
+	a.request.switchVault(nil)
 	resp, err := a.request.R().SetBody(user).Post("/signup")
 	if err != nil {
 		return err
@@ -50,14 +67,115 @@ func (a *Authorization) SignUp(args []string) error {
 	if resp.StatusCode() != http.StatusOK {
 		return fmt.Errorf("response fault %s", string(resp.Body()))
 	}
-	for _, cookie := range resp.Cookies() {
+	a.setCookie(resp.Cookies())
+	a.loadKeys()
+	if err = a.request.setRecoveryKey(); err != nil {
+		fmt.Printf("recovery key is not set, make it with recovery_key: %v\n", err)
+	}
+	a.openCache(user)
+	a.request.listen()
+	return nil
+}
+
+func (a *Authorization) setCookie(cookies []*http.Cookie) {
+	for _, cookie := range cookies {
 		if strings.EqualFold(cookie.Name, "jwt") {
 			a.request.SetCookiesAuthorization(cookie)
 			break
 		}
 	}
+	a.request.session.Offline = false
+}
+
+// loadVaultKey reads the vault key the recovery of the account wrapped with
+// the new password, the vault key of the user that never recovered it is
+// the one derived from the password.
+func (a *Authorization) loadVaultKey() {
+	state, err := a.request.recoveryState()
+	if err != nil {
+		fmt.Printf("vault key of the recovered account is not available: %v\n", err)
+		return
+	}
+	if state.VaultKey != "" {
+		key, err := vault.UnwrapKey(a.request.vaultKey, state.VaultKey)
+		if err != nil {
+			fmt.Printf("vault key of the recovered account does not open: %v\n", err)
+			return
+		}
+		a.request.vaultKey = key
+	}
+	if !state.Set {
+		fmt.Println("there is no recovery key, make it with recovery_key")
+	}
+}
+
+// loadKeys reads the key pair of the user, the first login makes it. It is
+// not fatal for the login, the files shared with the user do not open without it.
+func (a *Authorization) loadKeys() {
+	keys, err := a.request.loadKeys()
+	if err != nil {
+		fmt.Printf("keys of the shared files are not available: %v\n", err)
+		return
+	}
+	a.request.keys = keys
+}
+
+// openCache is not fatal for the login, the client works online without the cache.
+func (a *Authorization) openCache(user *models.User) {
+	if err := a.request.OpenCache(user.Login, user.Password, true); err != nil {
+		fmt.Printf("local cache is not available: %v\n", err)
+		return
+	}
+	if err := a.request.replay(); err != nil {
+		fmt.Printf("offline changes are not sent: %v\n", err)
+		return
+	}
+	if _, err := a.request.Pull(); err != nil {
+		fmt.Printf("local cache is not updated: %v\n", err)
+	}
+}
+
+// Usage prints the storage the user takes on the server and its limits.
+func (a *Authorization) Usage(_ []string) error {
+	resp, err := a.request.R().Get("/user/usage")
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode() != http.StatusOK {
+		return fmt.Errorf("request usage error status code = %d, body = %s",
+			resp.StatusCode(), string(resp.Body()))
+	}
+	var usage models.Usage
+	if err = json.Unmarshal(resp.Body(), &usage); err != nil {
+		return fmt.Errorf("usage decode err: %w", err)
+	}
+	fmt.Printf("files: %s of %s\n", byteCount(usage.Bytes), limit(usage.Limits.Bytes, byteCount(usage.Limits.Bytes)))
+	fmt.Printf("items: %d of %s\n", usage.Items, limit(int64(usage.Limits.Items), strconv.Itoa(usage.Limits.Items)))
+	fmt.Printf("largest file: %s\n", limit(usage.Limits.FileSize, byteCount(usage.Limits.FileSize)))
 	return nil
 }
+
+// limit is the formatted limit, a zero limit is no limit.
+func limit(v int64, formatted string) string {
+	if v == 0 {
+		return "no limit"
+	}
+	return formatted
+}
+
+func byteCount(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for v := n / unit; v >= unit; v /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
+
 func newUserModels(args []string) (*models.User, error) {
 	if len(args) < 2 {
 		return nil, error2.ErrInvalidCommand
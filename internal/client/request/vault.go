@@ -0,0 +1,95 @@
+package request
+
+import (
+	"fmt"
+	"strconv"
+
+	error2 "github.com/zelas91/goph-keeper/internal/client/error"
+)
+
+// The headers that name the vault the server serves the request in.
+const (
+	vaultHeader     = "Vault-ID"
+	emergencyHeader = "Emergency-ID"
+)
+
+// otherVault is a vault of another user the commands work in, key is the
+// key the files of the vault are wrapped with.
+type otherVault struct {
+	header string
+	id     int
+	name   string
+	key    []byte
+}
+
+type Vault struct {
+	request *Request
+}
+
+func NewVault(request *Request) *Vault {
+	return &Vault{request: request}
+}
+
+// Switch moves the commands to the vault of the organization, to the vault
+// of the owner of the emergency access or back to the vault of the user.
+// Without arguments it prints the vault in use.
+func (v *Vault) Switch(args []string) error {
+	if len(args) < 1 {
+		if other := v.request.other; other != nil {
+			fmt.Printf("vault of the %s\n", other.name)
+		} else {
+			fmt.Println("personal vault")
+		}
+		return nil
+	}
+	var (
+		other *otherVault
+		err   error
+	)
+	switch {
+	case args[0] == "personal":
+		v.request.switchVault(nil)
+		return nil
+	case args[0] == "emergency" && len(args) > 1:
+		other, err = v.request.openEmergency(args[1])
+	case args[0] == "emergency":
+		return error2.ErrInvalidCommand
+	default:
+		other, err = v.request.openOrg(args[0])
+	}
+	if err != nil {
+		return err
+	}
+	v.request.switchVault(other)
+	fmt.Printf("vault of the %s, the local cache is not used in it\n", other.name)
+	return nil
+}
+
+// switchVault makes the commands work in the other vault, nil is the vault
+// of the user. The local cache is the one of the vault of the user, it is
+// put aside while the commands work in another vault.
+func (r *Request) switchVault(other *otherVault) {
+	switch {
+	case r.other == nil && other != nil:
+		r.personalCache, r.cache = r.cache, nil
+	case r.other != nil && other == nil:
+		r.cache, r.personalCache = r.personalCache, nil
+	}
+	r.other = other
+}
+
+// filesKey is the key the files of the vault in use are wrapped with.
+func (r *Request) filesKey() []byte {
+	if r.other != nil {
+		return r.other.key
+	}
+	return r.vaultKey
+}
+
+func parseID(id string) (int, error) {
+	n, err := strconv.Atoi(id)
+	if err != nil {
+		return 0, fmt.Errorf("id err:%w", err)
+	}
+	return n, nil
+}
@@ -7,6 +7,9 @@ import (
 type Session struct {
 	Jwt  *http.Cookie
 	Host string
+	// Offline is set when the user logged in to the local cache
+	// while the server was not reachable.
+	Offline bool
 }
 
 func NewSession(addr string) *Session {
@@ -22,6 +25,7 @@ func (s *Session) IsAuth() bool {
 
 func (s *Session) CleanToken() {
 	s.Jwt = nil
+	s.Offline = false
 }
 func (s *Session) GetJwt() *http.Cookie {
 	if s.Jwt == nil {
@@ -0,0 +1,94 @@
+package vault
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/nacl/box"
+)
+
+// sealedPrefix marks a file key sealed for the key pair of a user, the keys
+// without it are wrapped with the vault key of the owner.
+const sealedPrefix = "box:"
+
+// KeyPair lets other users share the keys of their files with the user. The
+// server keeps the public key as it is and the private key wrapped with the
+// vault key.
+type KeyPair struct {
+	Public  [KeySize]byte
+	Private [KeySize]byte
+}
+
+func NewKeyPair() (*KeyPair, error) {
+	public, private, err := box.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, fmt.Errorf("vault: key pair err: %w", err)
+	}
+	return &KeyPair{Public: *public, Private: *private}, nil
+}
+
+// PublicKey returns the public key in the form the server keeps.
+func (k *KeyPair) PublicKey() string {
+	return base64.StdEncoding.EncodeToString(k.Public[:])
+}
+
+// WrapPrivate encrypts the private key with the vault key.
+func (k *KeyPair) WrapPrivate(vaultKey []byte) (string, error) {
+	return WrapKey(vaultKey, k.Private[:])
+}
+
+// OpenKeyPair returns the key pair kept by the server.
+func OpenKeyPair(vaultKey []byte, publicKey, wrappedPrivate string) (*KeyPair, error) {
+	public, err := base64.StdEncoding.DecodeString(publicKey)
+	if err != nil || len(public) != KeySize {
+		return nil, ErrWrongKey
+	}
+	private, err := UnwrapKey(vaultKey, wrappedPrivate)
+	if err != nil {
+		return nil, err
+	}
+	k := &KeyPair{}
+	copy(k.Public[:], public)
+	copy(k.Private[:], private)
+	return k, nil
+}
+
+// SealKey encrypts the file key for the user of the public key, only the
+// private key of the user opens it.
+func SealKey(publicKey string, fileKey []byte) (string, error) {
+	public, err := base64.StdEncoding.DecodeString(publicKey)
+	if err != nil || len(public) != KeySize {
+		return "", fmt.Errorf("vault: public key is damaged")
+	}
+	var to [KeySize]byte
+	copy(to[:], public)
+	sealed, err := box.SealAnonymous(nil, fileKey, &to, rand.Reader)
+	if err != nil {
+		return "", fmt.Errorf("vault: seal key err: %w", err)
+	}
+	return sealedPrefix + base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// OpenFileKey decrypts the key of a file, the key of a file of the user with
+// the vault key and the key of a shared file with the key pair. The key pair
+// may be nil while nothing is shared with the user.
+func OpenFileKey(vaultKey []byte, keys *KeyPair, fileKey string) ([]byte, error) {
+	encoded, sealed := strings.CutPrefix(fileKey, sealedPrefix)
+	if !sealed {
+		return UnwrapKey(vaultKey, fileKey)
+	}
+	if keys == nil {
+		return nil, ErrWrongKey
+	}
+	data, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, ErrWrongKey
+	}
+	key, ok := box.OpenAnonymous(nil, data, &keys.Public, &keys.Private)
+	if !ok || len(key) != KeySize {
+		return nil, ErrWrongKey
+	}
+	return key, nil
+}
@@ -0,0 +1,24 @@
+package vault
+
+import "encoding/base64"
+
+// OpenLink decrypts the secret of a share link with the key of the fragment
+// of the link, the data is the nonce followed by the sealed secret.
+func OpenLink(key string, data []byte) ([]byte, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(key)
+	if err != nil || len(raw) != KeySize {
+		return nil, ErrWrongKey
+	}
+	gcm, err := newGCM(raw)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) < gcm.NonceSize() {
+		return nil, ErrWrongKey
+	}
+	plain, err := gcm.Open(nil, data[:gcm.NonceSize()], data[gcm.NonceSize():], nil)
+	if err != nil {
+		return nil, ErrWrongKey
+	}
+	return plain, nil
+}
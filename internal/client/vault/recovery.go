@@ -0,0 +1,60 @@
+package vault
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base32"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// recoveryGroup is the length of the groups the recovery key is written in.
+const recoveryGroup = 4
+
+var ErrRecoveryKey = errors.New("vault: the recovery key is not well formed")
+
+var recoveryEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// RecoveryKey opens the vault of the user that lost the password. The user
+// writes Text down, the server keeps Proof hashed and the vault key wrapped
+// with Key, neither tells the key itself.
+type RecoveryKey struct {
+	Text  string
+	Proof string
+	Key   []byte
+}
+
+// NewRecoveryKey returns a random recovery key.
+func NewRecoveryKey() (*RecoveryKey, error) {
+	raw := make([]byte, KeySize)
+	if _, err := rand.Read(raw); err != nil {
+		return nil, fmt.Errorf("vault: recovery key err: %w", err)
+	}
+	encoded := recoveryEncoding.EncodeToString(raw)
+	groups := make([]string, 0, len(encoded)/recoveryGroup+1)
+	for len(encoded) > recoveryGroup {
+		groups = append(groups, encoded[:recoveryGroup])
+		encoded = encoded[recoveryGroup:]
+	}
+	groups = append(groups, encoded)
+	return recoveryKey(strings.Join(groups, "-"), raw), nil
+}
+
+// ParseRecoveryKey reads the recovery key as the user wrote it down, the
+// case and the dashes do not matter.
+func ParseRecoveryKey(text string) (*RecoveryKey, error) {
+	cleaned := strings.ToUpper(strings.NewReplacer("-", "", " ", "").Replace(text))
+	raw, err := recoveryEncoding.DecodeString(cleaned)
+	if err != nil || len(raw) != KeySize {
+		return nil, ErrRecoveryKey
+	}
+	return recoveryKey(text, raw), nil
+}
+
+func recoveryKey(text string, raw []byte) *RecoveryKey {
+	proof := sha256.Sum256(append([]byte("goph-keeper/recovery/proof/"), raw...))
+	key := sha256.Sum256(append([]byte("goph-keeper/recovery/key/"), raw...))
+	return &RecoveryKey{Text: text, Proof: base64.StdEncoding.EncodeToString(proof[:]), Key: key[:]}
+}
@@ -0,0 +1,171 @@
+package vault
+
+import (
+	"bufio"
+	"crypto/cipher"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+)
+
+// The encrypted file is the magic followed by the segments of the content,
+// every segment is sealed with AES-GCM under the file key. The nonce is the
+// number of the segment with a flag on the last one, so the segments can not
+// be reordered, dropped or cut off at the end. The same key gives the same
+// bytes, an interrupted upload goes on with the key it was started with.
+const (
+	magic = "GKE1"
+	// SegmentSize is the size of the content sealed at once.
+	SegmentSize = 64 << 10
+	tagSize     = 16
+	sealedSize  = SegmentSize + tagSize
+)
+
+// EncryptedSize returns the size of the encrypted file of the content size,
+// an empty content is one empty segment.
+func EncryptedSize(size int64) int64 {
+	segments := size / SegmentSize
+	if size%SegmentSize != 0 || size == 0 {
+		segments++
+	}
+	return int64(len(magic)) + size + segments*tagSize
+}
+
+func segmentNonce(i int64, last bool) []byte {
+	nonce := make([]byte, 12)
+	binary.BigEndian.PutUint64(nonce[:8], uint64(i))
+	if last {
+		nonce[11] = 1
+	}
+	return nonce
+}
+
+// Encrypter reads the content as the encrypted file at any offset, the chunks
+// of an upload are read where the server misses them.
+type Encrypter struct {
+	mu      sync.Mutex
+	src     io.ReaderAt
+	size    int64
+	gcm     cipher.AEAD
+	segment int64
+	plain   []byte
+	sealed  []byte
+}
+
+func NewEncrypter(src io.ReaderAt, size int64, key []byte) (*Encrypter, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	return &Encrypter{
+		src:     src,
+		size:    size,
+		gcm:     gcm,
+		segment: -1,
+		plain:   make([]byte, SegmentSize),
+		sealed:  make([]byte, 0, sealedSize),
+	}, nil
+}
+
+// Size is the size of the encrypted file.
+func (e *Encrypter) Size() int64 {
+	return EncryptedSize(e.size)
+}
+
+func (e *Encrypter) ReadAt(p []byte, off int64) (int, error) {
+	if off < 0 {
+		return 0, errors.New("vault: negative offset")
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	size := e.Size()
+	n := 0
+	for n < len(p) && off < size {
+		if off < int64(len(magic)) {
+			c := copy(p[n:], magic[off:])
+			n += c
+			off += int64(c)
+			continue
+		}
+		pos := off - int64(len(magic))
+		i := pos / sealedSize
+		sealed, err := e.seal(i)
+		if err != nil {
+			return n, err
+		}
+		c := copy(p[n:], sealed[pos-i*sealedSize:])
+		n += c
+		off += int64(c)
+	}
+	if n < len(p) {
+		return n, io.EOF
+	}
+	return n, nil
+}
+
+// seal encrypts the segment i, the last segment sealed is kept for the next read.
+func (e *Encrypter) seal(i int64) ([]byte, error) {
+	if i == e.segment {
+		return e.sealed, nil
+	}
+	start := i * SegmentSize
+	end := start + SegmentSize
+	if end > e.size {
+		end = e.size
+	}
+	plain := e.plain[:end-start]
+	if n, err := e.src.ReadAt(plain, start); n < len(plain) {
+		if err == nil || err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return nil, fmt.Errorf("vault: read content err: %w", err)
+	}
+	e.sealed = e.gcm.Seal(e.sealed[:0], segmentNonce(i, end == e.size), plain, nil)
+	e.segment = i
+	return e.sealed, nil
+}
+
+// Decrypt writes the content of the encrypted file to dst, a segment that
+// does not open with the key stops it with ErrWrongKey. The segments written
+// before are authentic, the caller drops them all the same.
+func Decrypt(dst io.Writer, src io.Reader, key []byte) error {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return err
+	}
+	r := bufio.NewReaderSize(src, sealedSize)
+	head := make([]byte, len(magic))
+	if _, err = io.ReadFull(r, head); err != nil || string(head) != magic {
+		return ErrWrongKey
+	}
+	buf := make([]byte, sealedSize)
+	for i := int64(0); ; i++ {
+		n, err := io.ReadFull(r, buf)
+		switch {
+		case err == io.EOF:
+			return ErrWrongKey
+		case err != nil && !errors.Is(err, io.ErrUnexpectedEOF):
+			return fmt.Errorf("vault: read err: %w", err)
+		}
+		last := err != nil
+		if !last {
+			if _, err = r.Peek(1); err == io.EOF {
+				last = true
+			} else if err != nil {
+				return fmt.Errorf("vault: read err: %w", err)
+			}
+		}
+		plain, err := gcm.Open(buf[:0], segmentNonce(i, last), buf[:n], nil)
+		if err != nil {
+			return ErrWrongKey
+		}
+		if _, err = dst.Write(plain); err != nil {
+			return fmt.Errorf("vault: write err: %w", err)
+		}
+		if last {
+			return nil
+		}
+	}
+}
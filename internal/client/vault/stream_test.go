@@ -0,0 +1,160 @@
+package vault
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/base64"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEncryptDecrypt(t *testing.T) {
+	tests := []struct {
+		name string
+		size int
+	}{
+		{name: "#1 ok empty", size: 0},
+		{name: "#2 ok short", size: 100},
+		{name: "#3 ok whole segments", size: 2 * SegmentSize},
+		{name: "#4 ok part of a segment", size: 2*SegmentSize + 7},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			content := make([]byte, test.size)
+			_, err := rand.Read(content)
+			assert.NoError(t, err)
+			key, err := NewFileKey()
+			assert.NoError(t, err)
+
+			e, err := NewEncrypter(bytes.NewReader(content), int64(len(content)), key)
+			assert.NoError(t, err)
+			encrypted, err := io.ReadAll(io.NewSectionReader(e, 0, e.Size()))
+			assert.NoError(t, err)
+			assert.Equal(t, EncryptedSize(int64(len(content))), int64(len(encrypted)))
+
+			// the chunks read at any offset are the same bytes
+			for _, chunk := range []int{1000, SegmentSize + 3} {
+				var chunks []byte
+				buf := make([]byte, chunk)
+				for off := int64(0); off < e.Size(); off += int64(chunk) {
+					n, err := e.ReadAt(buf, off)
+					if err != io.EOF {
+						assert.NoError(t, err)
+					}
+					chunks = append(chunks, buf[:n]...)
+				}
+				assert.Equal(t, encrypted, chunks)
+			}
+
+			var plain bytes.Buffer
+			assert.NoError(t, Decrypt(&plain, bytes.NewReader(encrypted), key))
+			assert.True(t, bytes.Equal(content, plain.Bytes()))
+		})
+	}
+}
+
+func TestDecryptDamaged(t *testing.T) {
+	content := make([]byte, 3*SegmentSize)
+	key, err := NewFileKey()
+	assert.NoError(t, err)
+	e, err := NewEncrypter(bytes.NewReader(content), int64(len(content)), key)
+	assert.NoError(t, err)
+	encrypted, err := io.ReadAll(io.NewSectionReader(e, 0, e.Size()))
+	assert.NoError(t, err)
+	other, err := NewFileKey()
+	assert.NoError(t, err)
+
+	tests := []struct {
+		name      string
+		encrypted []byte
+		key       []byte
+	}{
+		{name: "#1 bad wrong key", encrypted: encrypted, key: other},
+		{name: "#2 bad changed byte", encrypted: func() []byte {
+			b := bytes.Clone(encrypted)
+			b[len(b)/2] ^= 1
+			return b
+		}(), key: key},
+		{name: "#3 bad cut after a segment", encrypted: encrypted[:len(magic)+2*sealedSize], key: key},
+		{name: "#4 bad segments swapped", encrypted: func() []byte {
+			b := bytes.Clone(encrypted)
+			first := bytes.Clone(b[len(magic) : len(magic)+sealedSize])
+			copy(b[len(magic):], b[len(magic)+sealedSize:len(magic)+2*sealedSize])
+			copy(b[len(magic)+sealedSize:], first)
+			return b
+		}(), key: key},
+		{name: "#5 bad not encrypted", encrypted: content, key: key},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.ErrorIs(t, Decrypt(io.Discard, bytes.NewReader(test.encrypted), test.key), ErrWrongKey)
+		})
+	}
+}
+
+func TestWrapKey(t *testing.T) {
+	vaultKey := DeriveKey("user", "password")
+	assert.Equal(t, vaultKey, DeriveKey("user", "password"))
+	assert.NotEqual(t, vaultKey, DeriveKey("other", "password"))
+
+	fileKey, err := NewFileKey()
+	assert.NoError(t, err)
+	wrapped, err := WrapKey(vaultKey, fileKey)
+	assert.NoError(t, err)
+	key, err := UnwrapKey(vaultKey, wrapped)
+	assert.NoError(t, err)
+	assert.Equal(t, fileKey, key)
+
+	_, err = UnwrapKey(DeriveKey("user", "other"), wrapped)
+	assert.ErrorIs(t, err, ErrWrongKey)
+}
+
+func TestSealKey(t *testing.T) {
+	ownerKey, recipientKey := DeriveKey("owner", "password"), DeriveKey("recipient", "password")
+	pair, err := NewKeyPair()
+	assert.NoError(t, err)
+	private, err := pair.WrapPrivate(recipientKey)
+	assert.NoError(t, err)
+	keys, err := OpenKeyPair(recipientKey, pair.PublicKey(), private)
+	assert.NoError(t, err)
+	assert.Equal(t, pair, keys)
+
+	fileKey, err := NewFileKey()
+	assert.NoError(t, err)
+	wrapped, err := WrapKey(ownerKey, fileKey)
+	assert.NoError(t, err)
+	sealed, err := SealKey(keys.PublicKey(), fileKey)
+	assert.NoError(t, err)
+
+	key, err := OpenFileKey(ownerKey, nil, wrapped)
+	assert.NoError(t, err)
+	assert.Equal(t, fileKey, key)
+	key, err = OpenFileKey(recipientKey, keys, sealed)
+	assert.NoError(t, err)
+	assert.Equal(t, fileKey, key)
+
+	_, err = OpenFileKey(recipientKey, nil, sealed)
+	assert.ErrorIs(t, err, ErrWrongKey)
+	other, err := NewKeyPair()
+	assert.NoError(t, err)
+	_, err = OpenFileKey(recipientKey, other, sealed)
+	assert.ErrorIs(t, err, ErrWrongKey)
+	_, err = OpenKeyPair(ownerKey, pair.PublicKey(), private)
+	assert.ErrorIs(t, err, ErrWrongKey)
+}
+
+func TestRecoveryKey(t *testing.T) {
+	recovery, err := NewRecoveryKey()
+	assert.NoError(t, err)
+	parsed, err := ParseRecoveryKey(strings.ToLower(recovery.Text))
+	assert.NoError(t, err)
+	assert.Equal(t, recovery.Proof, parsed.Proof)
+	assert.Equal(t, recovery.Key, parsed.Key)
+	assert.NotEqual(t, recovery.Proof, base64.StdEncoding.EncodeToString(recovery.Key))
+
+	_, err = ParseRecoveryKey(recovery.Text[:len(recovery.Text)-5])
+	assert.ErrorIs(t, err, ErrRecoveryKey)
+}
@@ -0,0 +1,80 @@
+package vault
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+
+	"golang.org/x/crypto/argon2"
+)
+
+// KeySize is the size of the vault key and of the file keys.
+const KeySize = 32
+
+var ErrWrongKey = errors.New("vault: wrong key or damaged data")
+
+// DeriveKey returns the vault key of the user. The key is derived from the
+// master password with the login as the salt, so every client of the user
+// gets the same key and the server never has it.
+func DeriveKey(login, password string) []byte {
+	salt := sha256.Sum256([]byte("goph-keeper/vault/" + login))
+	return argon2.IDKey([]byte(password), salt[:16], 1, 64*1024, 4, KeySize)
+}
+
+// NewFileKey returns a random key for the content of one file.
+func NewFileKey() ([]byte, error) {
+	key := make([]byte, KeySize)
+	if _, err := rand.Read(key); err != nil {
+		return nil, fmt.Errorf("vault: file key err: %w", err)
+	}
+	return key, nil
+}
+
+// WrapKey encrypts the file key with the vault key for the server to keep.
+func WrapKey(vaultKey, fileKey []byte) (string, error) {
+	gcm, err := newGCM(vaultKey)
+	if err != nil {
+		return "", err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err = rand.Read(nonce); err != nil {
+		return "", fmt.Errorf("vault: nonce err: %w", err)
+	}
+	return base64.StdEncoding.EncodeToString(gcm.Seal(nonce, nonce, fileKey, nil)), nil
+}
+
+// UnwrapKey decrypts the file key kept by the server.
+func UnwrapKey(vaultKey []byte, wrapped string) ([]byte, error) {
+	sealed, err := base64.StdEncoding.DecodeString(wrapped)
+	if err != nil {
+		return nil, ErrWrongKey
+	}
+	gcm, err := newGCM(vaultKey)
+	if err != nil {
+		return nil, err
+	}
+	if len(sealed) < gcm.NonceSize() {
+		return nil, ErrWrongKey
+	}
+	key, err := gcm.Open(nil, sealed[:gcm.NonceSize()], sealed[gcm.NonceSize():], nil)
+	if err != nil || len(key) != KeySize {
+		return nil, ErrWrongKey
+	}
+	return key, nil
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("vault: cipher err: %w", err)
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("vault: gcm err: %w", err)
+	}
+	return gcm, nil
+}
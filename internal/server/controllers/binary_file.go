@@ -2,7 +2,9 @@ package controllers
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
+	"mime"
 	"net/http"
 
 	"github.com/go-chi/chi/v5"
@@ -12,6 +14,9 @@ import (
 	"github.com/zelas91/goph-keeper/internal/server/helper"
 	"github.com/zelas91/goph-keeper/internal/server/models"
 	"github.com/zelas91/goph-keeper/internal/server/payload"
+	"github.com/zelas91/goph-keeper/internal/server/repository"
+	"github.com/zelas91/goph-keeper/internal/server/services"
+	"github.com/zelas91/goph-keeper/internal/server/storage"
 	"golang.org/x/net/context"
 	"golang.org/x/sync/errgroup"
 )
@@ -22,6 +27,10 @@ type binaryFile struct {
 	service binaryFileService
 }
 
+// wsRequestLimit is the largest message the file websockets take
+// besides the chunks of the upload.
+const wsRequestLimit = 64 << 10
+
 var upgrader = websocket.Upgrader{
 	WriteBufferSize: 1024,
 	ReadBufferSize:  1024,
@@ -36,8 +45,9 @@ type binaryFileService interface {
 	Upload(ctx context.Context, bf models.BinaryFile, reader <-chan []byte) error
 	Download(ctx context.Context, bf models.BinaryFile, write chan<- []byte) error
 	Delete(ctx context.Context, fileID int) error
-	Files(ctx context.Context) ([]models.BinaryFile, error)
+	Files(ctx context.Context, filter models.ItemFilter) ([]models.BinaryFile, string, error)
 	File(ctx context.Context, fileID int) (models.BinaryFile, error)
+	Content(ctx context.Context, fileID int) (models.FileContent, error)
 }
 
 func (b *binaryFile) upload() http.HandlerFunc {
@@ -48,6 +58,7 @@ func (b *binaryFile) upload() http.HandlerFunc {
 			b.log.Errorf("Failed to upgrade connection:", err)
 			return
 		}
+		conn.SetReadLimit(models.MaxUploadChunk)
 
 		defer func() {
 			if err := conn.Close(); err != nil {
@@ -104,12 +115,15 @@ func (b *binaryFile) upload() http.HandlerFunc {
 						b.log.Errorf("failed to read message: %v", err)
 						return
 					}
-					if mt == websocket.BinaryMessage {
-						reader <- msg
-					} else {
+					if mt != websocket.BinaryMessage {
 						b.log.Debugf("message websocket text : %s", string(msg))
 						return
 					}
+					select {
+					case reader <- msg:
+					case <-ctx.Done():
+						return
+					}
 				}
 
 			}
@@ -117,6 +131,13 @@ func (b *binaryFile) upload() http.HandlerFunc {
 
 		if err = g.Wait(); err != nil {
 			b.log.Errorf("upload service err: %v", err)
+			if errors.Is(err, services.ErrQuotaExceeded) || errors.Is(err, services.ErrFileTooLarge) {
+				if err = conn.WriteMessage(websocket.CloseMessage,
+					websocket.FormatCloseMessage(websocket.CloseMessageTooBig, closeReason(err.Error()))); err != nil {
+					b.log.Errorf("upload websocket send msg err: %v", err)
+				}
+				return
+			}
 
 			body, err := json.Marshal(payload.ErrorMessage{
 				Message:    fmt.Sprintf("service err :%v", err),
@@ -147,6 +168,8 @@ func (b *binaryFile) download() http.HandlerFunc {
 			return
 		}
 
+		conn.SetReadLimit(wsRequestLimit)
+
 		defer func() {
 			if err := conn.Close(); err != nil {
 				b.log.Errorf("download close websocket connect err: %v", err)
@@ -230,13 +253,19 @@ func (b *binaryFile) delete() http.HandlerFunc {
 
 func (b *binaryFile) Files() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		files, err := b.service.Files(r.Context())
+		filter, err := helper.ItemFilterFromRequest(r)
+		if err != nil {
+			b.log.Errorf("files: filter from request err: %v", err)
+			payload.NewErrorResponse(w, "files: filter from request err", http.StatusBadRequest)
+			return
+		}
+		files, next, err := b.service.Files(r.Context(), filter)
 		if err != nil {
 			b.log.Errorf("files: get binary files err:%v ", err)
 			payload.NewErrorResponse(w, "get binary files err", http.StatusInternalServerError)
 			return
 		}
-		if err = json.NewEncoder(w).Encode(files); err != nil {
+		if err = json.NewEncoder(w).Encode(models.Page[models.BinaryFile]{Items: files, NextCursor: next}); err != nil {
 			b.log.Errorf("files: files encode  err:%v ", err)
 			payload.NewErrorResponse(w, "files encode  err", http.StatusInternalServerError)
 			return
@@ -267,6 +296,46 @@ func (b *binaryFile) File() http.HandlerFunc {
 	}
 }
 
+// content serves the file over plain HTTP, Range and If-Range requests
+// let the clients resume the downloads and read parts of the file.
+func (b *binaryFile) content() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := helper.IDFromContext(r.Context())
+		if err != nil {
+			b.log.Errorf("content: get id from request err: %v", err)
+			payload.NewErrorResponse(w, "content: get id from request err", http.StatusBadRequest)
+			return
+		}
+		fc, err := b.service.Content(r.Context(), id)
+		if err != nil {
+			b.log.Errorf("content: get file err: %v", err)
+			contentErrorResponse(w, "content: get file err", err)
+			return
+		}
+		defer func() {
+			if err := fc.Content.Close(); err != nil {
+				b.log.Errorf("content: close file err: %v", err)
+			}
+		}()
+		w.Header().Set("ETag", fc.ETag)
+		w.Header().Set("Content-Type", "application/octet-stream")
+		w.Header().Set("Content-Disposition",
+			mime.FormatMediaType("attachment", map[string]string{"filename": fc.File.FileName}))
+		http.ServeContent(w, r, fc.File.FileName, fc.ModTime, fc.Content)
+	}
+}
+
+// contentErrorResponse answers 404 only for the file or the blob that is not
+// there, a failure of the db or the storage must not look like a removed file.
+func contentErrorResponse(w http.ResponseWriter, message string, err error) {
+	switch {
+	case errors.Is(err, repository.ErrNotFound), errors.Is(err, storage.ErrNotFound):
+		payload.NewErrorResponse(w, message+": not found", http.StatusNotFound)
+	default:
+		payload.NewErrorResponse(w, message, http.StatusInternalServerError)
+	}
+}
+
 func (b *binaryFile) createRoutes() http.Handler {
 	router := chi.NewRouter()
 	router.Route("/", func(r chi.Router) {
@@ -275,7 +344,17 @@ func (b *binaryFile) createRoutes() http.Handler {
 		r.Get("/download", b.download())
 		r.Get("/", b.Files())
 		r.Get("/{id}", b.File())
+		r.Get("/{id}/content", b.content())
 	})
 	return router
 
 }
+
+// closeReason cuts the reason to what fits in a close message.
+func closeReason(reason string) string {
+	const maxReason = 123
+	if len(reason) > maxReason {
+		return reason[:maxReason]
+	}
+	return reason
+}
@@ -15,6 +15,8 @@ import (
 	"github.com/zelas91/goph-keeper/internal/logger"
 	mock2 "github.com/zelas91/goph-keeper/internal/server/controllers/mocks"
 	"github.com/zelas91/goph-keeper/internal/server/models"
+	"github.com/zelas91/goph-keeper/internal/server/repository"
+	"github.com/zelas91/goph-keeper/internal/server/storage"
 	"golang.org/x/net/context"
 )
 
@@ -333,7 +335,7 @@ func TestFiles(t *testing.T) {
 			want:   http.StatusOK,
 			method: http.MethodGet,
 			mockBehaviorFilesService: func(s *mock2.MockbinaryFileService) {
-				s.EXPECT().Files(gomock.Any()).Return([]models.BinaryFile{
+				s.EXPECT().Files(gomock.Any(), models.ItemFilter{Sort: models.SortCreated, Limit: models.DefaultPageLimit}).Return([]models.BinaryFile{
 					{
 						FileName: "logg.log",
 						ID:       1,
@@ -346,7 +348,7 @@ func TestFiles(t *testing.T) {
 						FileName: "TCP.log",
 						ID:       3,
 					},
-				}, nil)
+				}, "", nil)
 			},
 		},
 		{
@@ -355,7 +357,7 @@ func TestFiles(t *testing.T) {
 			want:   http.StatusInternalServerError,
 			method: http.MethodGet,
 			mockBehaviorFilesService: func(s *mock2.MockbinaryFileService) {
-				s.EXPECT().Files(gomock.Any()).Return(nil, errors.New("repo error"))
+				s.EXPECT().Files(gomock.Any(), models.ItemFilter{Sort: models.SortCreated, Limit: models.DefaultPageLimit}).Return(nil, "", errors.New("repo error"))
 			},
 		},
 	}
@@ -448,3 +450,78 @@ func TestFile(t *testing.T) {
 		})
 	}
 }
+
+type nopSeekCloser struct {
+	*strings.Reader
+}
+
+func (nopSeekCloser) Close() error {
+	return nil
+}
+
+func TestFileContent(t *testing.T) {
+	const content = "0123456789"
+	tests := []struct {
+		name     string
+		header   map[string]string
+		err      error
+		want     int
+		wantBody string
+	}{
+		{name: "#1 ok whole file", want: http.StatusOK, wantBody: content},
+		{
+			name:     "#2 ok resume from offset",
+			header:   map[string]string{"Range": "bytes=6-"},
+			want:     http.StatusPartialContent,
+			wantBody: "6789",
+		},
+		{
+			name:     "#3 ok file changed since the part was read",
+			header:   map[string]string{"Range": "bytes=6-", "If-Range": `"1-1"`},
+			want:     http.StatusOK,
+			wantBody: content,
+		},
+		{
+			name:   "#4 ok not modified",
+			header: map[string]string{"If-None-Match": `"1-2"`},
+			want:   http.StatusNotModified,
+		},
+		{
+			name:   "#5 nok range out of the file",
+			header: map[string]string{"Range": "bytes=20-"},
+			want:   http.StatusRequestedRangeNotSatisfiable,
+		},
+		{name: "#6 nok file not found", err: repository.ErrNotFound, want: http.StatusNotFound},
+		{name: "#7 nok blob not found", err: storage.ErrNotFound, want: http.StatusNotFound},
+		{name: "#8 nok storage failed", err: errors.New("s3: connection refused"), want: http.StatusInternalServerError},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			service := mock2.NewMockbinaryFileService(ctrl)
+			service.EXPECT().Content(gomock.Any(), 1).Return(models.FileContent{
+				File:    models.BinaryFile{ID: 1, FileName: "digits.txt", Size: len(content)},
+				ETag:    `"1-2"`,
+				Content: nopSeekCloser{strings.NewReader(content)},
+			}, test.err)
+
+			handler := New(logger.New(""), WithBinaryFileUseService(service))
+			request := httptest.NewRequest(http.MethodGet, "/1/content", nil)
+			for k, v := range test.header {
+				request.Header.Set(k, v)
+			}
+			w := httptest.NewRecorder()
+			handler.binary.createRoutes().ServeHTTP(w, request)
+			res := w.Result()
+			defer res.Body.Close()
+
+			assert.Equal(t, test.want, res.StatusCode)
+			if test.wantBody != "" {
+				assert.Equal(t, test.wantBody, w.Body.String())
+				assert.Equal(t, `"1-2"`, res.Header.Get("ETag"))
+			}
+		})
+	}
+}
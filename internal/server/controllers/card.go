@@ -23,7 +23,7 @@ type сreditCard struct {
 //go:generate mockgen -package mocks -destination=./mocks/mock_card_service.go -source=card.go -package=mock
 type cardService interface {
 	Create(ctx context.Context, card models.Card) error
-	Cards(ctx context.Context) ([]models.Card, error)
+	Cards(ctx context.Context, filter models.ItemFilter) ([]models.Card, string, error)
 	Card(ctx context.Context, cardID int) (models.Card, error)
 	Delete(ctx context.Context, cardID int) error
 	Update(ctx context.Context, card models.Card) error
@@ -31,13 +31,19 @@ type cardService interface {
 
 func (c *сreditCard) cards() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		cards, err := c.service.Cards(r.Context())
+		filter, err := helper.ItemFilterFromRequest(r)
+		if err != nil {
+			c.log.Errorf("cards: filter from request err: %v", err)
+			payload.NewErrorResponse(w, "cards: filter from request err", http.StatusBadRequest)
+			return
+		}
+		cards, next, err := c.service.Cards(r.Context(), filter)
 		if err != nil {
 			c.log.Errorf("cards: get cards err %v", err)
 			payload.NewErrorResponse(w, "cards: get cards err", http.StatusInternalServerError)
 			return
 		}
-		if err = json.NewEncoder(w).Encode(cards); err != nil {
+		if err = json.NewEncoder(w).Encode(models.Page[models.Card]{Items: cards, NextCursor: next}); err != nil {
 			c.log.Errorf("cards: encode err %v", err)
 			payload.NewErrorResponse(w, "cards: encode err", http.StatusInternalServerError)
 			return
@@ -89,7 +95,8 @@ func (c *сreditCard) create() http.HandlerFunc {
 
 		if err = c.service.Create(r.Context(), card); err != nil {
 			c.log.Errorf("create: card save err: %v", err)
-			payload.NewErrorResponse(w, "create: card save err", http.StatusInternalServerError)
+			createErrorResponse(w, "create: card save err", err)
+			return
 		}
 		w.WriteHeader(http.StatusCreated)
 	}
@@ -131,7 +138,9 @@ func (c *сreditCard) update() http.HandlerFunc {
 
 		if err = c.service.Update(r.Context(), card); err != nil {
 			c.log.Errorf("update: card save err: %v", err)
-			payload.NewErrorResponse(w, "update: card save err", http.StatusInternalServerError)
+			updateErrorResponse(w, c.log, "update: card save err", err, func() (any, error) {
+				return c.service.Card(r.Context(), id)
+			})
 		}
 	}
 }
@@ -8,20 +8,26 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
 	"github.com/zelas91/goph-keeper/internal/logger"
 	mock2 "github.com/zelas91/goph-keeper/internal/server/controllers/mocks"
+	"github.com/zelas91/goph-keeper/internal/server/helper"
 	"github.com/zelas91/goph-keeper/internal/server/models"
+	"github.com/zelas91/goph-keeper/internal/server/repository"
+	"github.com/zelas91/goph-keeper/internal/server/services"
 )
 
 func TestCards(t *testing.T) {
+	updated := time.Date(2024, 1, 3, 12, 30, 0, 0, time.UTC)
 
 	tests := []struct {
 		name                     string
 		url                      string
 		want                     int
+		wantNext                 string
 		method                   string
 		mockBehaviorCardsService func(s *mock2.MockcardService)
 	}{
@@ -31,7 +37,7 @@ func TestCards(t *testing.T) {
 			want:   http.StatusOK,
 			method: http.MethodGet,
 			mockBehaviorCardsService: func(s *mock2.MockcardService) {
-				s.EXPECT().Cards(gomock.Any()).Return([]models.Card{
+				s.EXPECT().Cards(gomock.Any(), models.ItemFilter{Sort: models.SortCreated, Limit: models.DefaultPageLimit}).Return([]models.Card{
 					{
 						Number:    "5500126132422715",
 						Cvv:       "123",
@@ -52,7 +58,7 @@ func TestCards(t *testing.T) {
 						Cvv:       "345",
 						ExpiredAt: "01/28",
 					},
-				}, nil)
+				}, "", nil)
 			},
 		},
 		{
@@ -61,9 +67,73 @@ func TestCards(t *testing.T) {
 			want:   http.StatusInternalServerError,
 			method: http.MethodGet,
 			mockBehaviorCardsService: func(s *mock2.MockcardService) {
-				s.EXPECT().Cards(gomock.Any()).Return(nil, errors.New("repo error"))
+				s.EXPECT().Cards(gomock.Any(), models.ItemFilter{Sort: models.SortCreated, Limit: models.DefaultPageLimit}).Return(nil, "", errors.New("repo error"))
+			},
+		},
+		{
+			name:   "#3 ok get cards by folder and tag",
+			url:    "/?folder=3&tag=bank",
+			want:   http.StatusOK,
+			method: http.MethodGet,
+			mockBehaviorCardsService: func(s *mock2.MockcardService) {
+				s.EXPECT().Cards(gomock.Any(), models.ItemFilter{FolderID: 3, Tag: "bank",
+					Sort: models.SortCreated, Limit: models.DefaultPageLimit}).Return([]models.Card{
+					{
+						Number:    "5500126132422715",
+						Cvv:       "123",
+						ExpiredAt: "12/26",
+						Tags:      []string{"bank"},
+					},
+				}, "", nil)
+			},
+		},
+		{
+			name:   "#4 nok folder filter is not a number",
+			url:    "/?folder=abc",
+			want:   http.StatusBadRequest,
+			method: http.MethodGet,
+		},
+		{
+			name: "#5 ok get next page sorted by update",
+			url: "/?sort=-updated&limit=1&updated_since=2024-01-02T10:00:00Z&cursor=" +
+				helper.EncodeCursor(models.Cursor{Sort: "-updated", Time: updated, ID: 7}),
+			want:     http.StatusOK,
+			method:   http.MethodGet,
+			wantNext: "next",
+			mockBehaviorCardsService: func(s *mock2.MockcardService) {
+				s.EXPECT().Cards(gomock.Any(), models.ItemFilter{
+					Sort:         "-updated",
+					Limit:        1,
+					UpdatedSince: time.Date(2024, 1, 2, 10, 0, 0, 0, time.UTC),
+					After:        &models.Cursor{Sort: "-updated", Time: updated, ID: 7},
+				}).Return([]models.Card{
+					{
+						Number:    "5500126132422715",
+						Cvv:       "123",
+						ExpiredAt: "12/26",
+					},
+				}, "next", nil)
 			},
 		},
+		{
+			name:   "#6 nok limit out of range",
+			url:    "/?limit=100000",
+			want:   http.StatusBadRequest,
+			method: http.MethodGet,
+		},
+		{
+			name: "#7 nok cursor of another sort",
+			url: "/?sort=created&cursor=" +
+				helper.EncodeCursor(models.Cursor{Sort: "-updated", Time: updated, ID: 7}),
+			want:   http.StatusBadRequest,
+			method: http.MethodGet,
+		},
+		{
+			name:   "#8 nok unknown sort",
+			url:    "/?sort=number",
+			want:   http.StatusBadRequest,
+			method: http.MethodGet,
+		},
 	}
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
@@ -85,6 +155,11 @@ func TestCards(t *testing.T) {
 			defer res.Body.Close()
 
 			assert.Equal(t, test.want, res.StatusCode)
+			if test.want == http.StatusOK {
+				var page models.Page[models.Card]
+				assert.NoError(t, json.NewDecoder(res.Body).Decode(&page))
+				assert.Equal(t, test.wantNext, page.NextCursor)
+			}
 		})
 	}
 }
@@ -229,6 +304,20 @@ func TestCardCreate(t *testing.T) {
 				s.EXPECT().Create(gomock.Any(), card).Return(errors.New("save card err"))
 			},
 		},
+		{
+			name:   "#6 nok quota exceeded",
+			url:    "/",
+			want:   http.StatusInsufficientStorage,
+			method: http.MethodPost,
+			body: models.Card{
+				Number:    "5500126132422715",
+				Cvv:       "123",
+				ExpiredAt: "12/26",
+			},
+			mockBehaviorCreateService: func(s *mock2.MockcardService, card models.Card) {
+				s.EXPECT().Create(gomock.Any(), card).Return(fmt.Errorf("%w: 10 items", services.ErrQuotaExceeded))
+			},
+		},
 	}
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
@@ -355,6 +444,43 @@ func TestCardUpdate(t *testing.T) {
 				s.EXPECT().Update(gomock.Any(), card).Return(errors.New("save repo err"))
 			},
 		},
+		{
+			name:   "#7 nok version conflict",
+			url:    "/",
+			want:   http.StatusConflict,
+			method: http.MethodPut,
+			body: models.Card{
+				ID:        1,
+				Number:    "5500126132422715",
+				Cvv:       "123",
+				ExpiredAt: "12/26",
+				Version:   12,
+			},
+			mockBehaviorUpdateService: func(s *mock2.MockcardService, card models.Card) {
+				s.EXPECT().Update(gomock.Any(), card).
+					Return(fmt.Errorf("service: %w", repository.ErrVersionConflict))
+				current := card
+				current.Version = 13
+				current.Cvv = "321"
+				s.EXPECT().Card(gomock.Any(), card.ID).Return(current, nil)
+			},
+		},
+		{
+			name:   "#8 nok card not found",
+			url:    "/",
+			want:   http.StatusNotFound,
+			method: http.MethodPut,
+			body: models.Card{
+				ID:        1,
+				Number:    "5500126132422715",
+				Cvv:       "123",
+				ExpiredAt: "12/26",
+				Version:   12,
+			},
+			mockBehaviorUpdateService: func(s *mock2.MockcardService, card models.Card) {
+				s.EXPECT().Update(gomock.Any(), card).Return(repository.ErrNotFound)
+			},
+		},
 	}
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
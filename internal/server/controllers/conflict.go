@@ -0,0 +1,34 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/zelas91/goph-keeper/internal/logger"
+	"github.com/zelas91/goph-keeper/internal/server/payload"
+	"github.com/zelas91/goph-keeper/internal/server/repository"
+	"github.com/zelas91/goph-keeper/internal/server/services"
+)
+
+// updateErrorResponse answers a refused update. A version conflict carries
+// the current item so the client can merge its change into it, an item
+// shared for reading is forbidden.
+func updateErrorResponse(w http.ResponseWriter, log logger.Logger, message string, err error,
+	current func() (any, error)) {
+	switch {
+	case errors.Is(err, repository.ErrVersionConflict):
+		item, err := current()
+		if err != nil {
+			log.Errorf("%s: get current item err: %v", message, err)
+			payload.NewErrorResponse(w, message+": get current item err", http.StatusInternalServerError)
+			return
+		}
+		payload.NewConflictResponse(w, repository.ErrVersionConflict.Error(), item)
+	case errors.Is(err, repository.ErrNotFound):
+		payload.NewErrorResponse(w, message+": not found", http.StatusNotFound)
+	case errors.Is(err, services.ErrShareReadOnly):
+		payload.NewErrorResponse(w, message+": "+services.ErrShareReadOnly.Error(), http.StatusForbidden)
+	default:
+		payload.NewErrorResponse(w, message, http.StatusInternalServerError)
+	}
+}
@@ -23,7 +23,7 @@ type credential struct {
 //go:generate mockgen -package mocks -destination=./mocks/mock_credential_service.go -source=credential.go -package=mock
 type credentialService interface {
 	Create(ctx context.Context, user models.UserCredentials) error
-	Credentials(ctx context.Context) ([]models.UserCredentials, error)
+	Credentials(ctx context.Context, filter models.ItemFilter) ([]models.UserCredentials, string, error)
 	Credential(ctx context.Context, credentialID int) (models.UserCredentials, error)
 	Delete(ctx context.Context, credentialID int) error
 	Update(ctx context.Context, credential models.UserCredentials) error
@@ -31,13 +31,19 @@ type credentialService interface {
 
 func (c *credential) credentials() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		credentials, err := c.service.Credentials(r.Context())
+		filter, err := helper.ItemFilterFromRequest(r)
+		if err != nil {
+			c.log.Errorf("credentials: filter from request err: %v", err)
+			payload.NewErrorResponse(w, "credentials: filter from request err", http.StatusBadRequest)
+			return
+		}
+		credentials, next, err := c.service.Credentials(r.Context(), filter)
 		if err != nil {
 			c.log.Errorf("credentials: get credentials err %v", err)
 			payload.NewErrorResponse(w, "credentials: get credentials err", http.StatusInternalServerError)
 			return
 		}
-		if err = json.NewEncoder(w).Encode(credentials); err != nil {
+		if err = json.NewEncoder(w).Encode(models.Page[models.UserCredentials]{Items: credentials, NextCursor: next}); err != nil {
 			c.log.Errorf("credentials: encode err %v", err)
 			payload.NewErrorResponse(w, "credentials: encode err", http.StatusInternalServerError)
 			return
@@ -89,7 +95,8 @@ func (c *credential) create() http.HandlerFunc {
 
 		if err = c.service.Create(r.Context(), credential); err != nil {
 			c.log.Errorf("create: credential save err: %v", err)
-			payload.NewErrorResponse(w, "create: credential save err", http.StatusInternalServerError)
+			createErrorResponse(w, "create: credential save err", err)
+			return
 		}
 		w.WriteHeader(http.StatusCreated)
 	}
@@ -130,7 +137,9 @@ func (c *credential) update() http.HandlerFunc {
 
 		if err = c.service.Update(r.Context(), uc); err != nil {
 			c.log.Errorf("update: credentials save err: %v", err)
-			payload.NewErrorResponse(w, "update: credentials save err", http.StatusInternalServerError)
+			updateErrorResponse(w, c.log, "update: credentials save err", err, func() (any, error) {
+				return c.service.Credential(r.Context(), id)
+			})
 		}
 	}
 }
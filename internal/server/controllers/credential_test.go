@@ -31,7 +31,7 @@ func TestCredentials(t *testing.T) {
 			want:   http.StatusOK,
 			method: http.MethodGet,
 			mockBehaviorCredentialsService: func(s *mock2.MockcredentialService) {
-				s.EXPECT().Credentials(gomock.Any()).Return([]models.UserCredentials{
+				s.EXPECT().Credentials(gomock.Any(), models.ItemFilter{Sort: models.SortCreated, Limit: models.DefaultPageLimit}).Return([]models.UserCredentials{
 					{
 						Login:    "test",
 						Password: "12345678",
@@ -48,7 +48,7 @@ func TestCredentials(t *testing.T) {
 						Login:    "montgomery",
 						Password: "CC771212cC",
 					},
-				}, nil)
+				}, "", nil)
 			},
 		},
 		{
@@ -57,7 +57,7 @@ func TestCredentials(t *testing.T) {
 			want:   http.StatusInternalServerError,
 			method: http.MethodGet,
 			mockBehaviorCredentialsService: func(s *mock2.MockcredentialService) {
-				s.EXPECT().Credentials(gomock.Any()).Return(nil, errors.New("repo error"))
+				s.EXPECT().Credentials(gomock.Any(), models.ItemFilter{Sort: models.SortCreated, Limit: models.DefaultPageLimit}).Return(nil, "", errors.New("repo error"))
 			},
 		},
 	}
@@ -210,6 +210,33 @@ func TestCredentialCreate(t *testing.T) {
 				s.EXPECT().Create(gomock.Any(), credential).Return(errors.New("save credential err"))
 			},
 		},
+		{
+			name:   "#6 ok create credential with metadata",
+			url:    "/",
+			want:   http.StatusCreated,
+			method: http.MethodPost,
+			mockBehaviorCreateService: func(s *mock2.MockcredentialService, credential models.UserCredentials) {
+				s.EXPECT().Create(gomock.Any(), credential).Return(nil)
+			},
+			body: models.UserCredentials{
+				Login:    "montgomery",
+				Password: "Cc771212cC",
+				Title:    "github",
+				Note:     "work account",
+				Metadata: map[string]string{"site": "github.com"},
+			},
+		},
+		{
+			name:   "#7 nok validation error metadata key",
+			url:    "/",
+			want:   http.StatusBadRequest,
+			method: http.MethodPost,
+			body: models.UserCredentials{
+				Login:    "montgomery",
+				Password: "Cc771212cC",
+				Metadata: map[string]string{"": "github.com"},
+			},
+		},
 	}
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
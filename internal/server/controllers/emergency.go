@@ -0,0 +1,124 @@
+package controllers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-playground/validator/v10"
+	"github.com/zelas91/goph-keeper/internal/logger"
+	"github.com/zelas91/goph-keeper/internal/server/helper"
+	"github.com/zelas91/goph-keeper/internal/server/models"
+	"github.com/zelas91/goph-keeper/internal/server/payload"
+	"github.com/zelas91/goph-keeper/internal/server/repository"
+	"golang.org/x/net/context"
+)
+
+type emergency struct {
+	service emergencyService
+	valid   *validator.Validate
+	log     logger.Logger
+}
+
+//go:generate mockgen -package mocks -destination=./mocks/mock_emergency_service.go -source=emergency.go -package=mock
+type emergencyService interface {
+	Create(ctx context.Context, ea models.EmergencyAccess) (models.EmergencyAccess, error)
+	Accesses(ctx context.Context) (models.EmergencyAccesses, error)
+	Request(ctx context.Context, accessID int) error
+	Approve(ctx context.Context, accessID int) error
+	Reject(ctx context.Context, accessID int) error
+	Delete(ctx context.Context, accessID int) error
+	VaultUserID(ctx context.Context, accessID int, access string) (int, error)
+}
+
+func (e *emergency) accesses() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		accesses, err := e.service.Accesses(r.Context())
+		if err != nil {
+			e.log.Errorf("emergency: get accesses err %v", err)
+			payload.NewErrorResponse(w, "emergency: get accesses err", http.StatusInternalServerError)
+			return
+		}
+		if err = json.NewEncoder(w).Encode(accesses); err != nil {
+			e.log.Errorf("emergency: encode err %v", err)
+			payload.NewErrorResponse(w, "emergency: encode err", http.StatusInternalServerError)
+		}
+	}
+}
+
+func (e *emergency) create() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Body == nil {
+			payload.NewErrorResponse(w, "create: body is empty", http.StatusBadRequest)
+			return
+		}
+
+		defer func() {
+			if err := r.Body.Close(); err != nil {
+				e.log.Errorf("create: emergency access in body close err :%v", err)
+			}
+		}()
+
+		var ea models.EmergencyAccess
+		if err := decodeAndValid(r, e.valid, &ea); err != nil {
+			e.log.Errorf("create: decode or validation err:%v", err)
+			payload.NewErrorResponse(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		ea, err := e.service.Create(r.Context(), ea)
+		if err != nil {
+			e.log.Errorf("create: emergency access save err: %v", err)
+			emergencyErrorResponse(w, "create: emergency access save err", err)
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+		if err = json.NewEncoder(w).Encode(ea); err != nil {
+			e.log.Errorf("create: encode err %v", err)
+		}
+	}
+}
+
+// change calls the change of the state of the access of the id in the path.
+func (e *emergency) change(message string,
+	fn func(s emergencyService, ctx context.Context, accessID int) error) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := helper.IDFromContext(r.Context())
+		if err != nil {
+			e.log.Errorf("%s: get id emergency access err: %v", message, err)
+			payload.NewErrorResponse(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if err = fn(e.service, r.Context(), id); err != nil {
+			e.log.Errorf("%s: emergency access err: %v", message, err)
+			emergencyErrorResponse(w, message+": emergency access err", err)
+		}
+	}
+}
+
+// VaultUserID lets the contacts read the vaults of the owners that granted
+// them the access.
+func (e *emergency) VaultUserID(ctx context.Context, accessID int, access string) (int, error) {
+	return e.service.VaultUserID(ctx, accessID, access)
+}
+
+func emergencyErrorResponse(w http.ResponseWriter, message string, err error) {
+	if errors.Is(err, repository.ErrNotFound) {
+		payload.NewErrorResponse(w, message+": not found or not in this state", http.StatusNotFound)
+		return
+	}
+	payload.NewErrorResponse(w, message, http.StatusInternalServerError)
+}
+
+func (e *emergency) createRoutes() http.Handler {
+	router := chi.NewRouter()
+	router.Route("/", func(r chi.Router) {
+		r.Get("/", e.accesses())
+		r.Post("/", e.create())
+		r.Post("/{id}/request", e.change("request", emergencyService.Request))
+		r.Post("/{id}/approve", e.change("approve", emergencyService.Approve))
+		r.Post("/{id}/reject", e.change("reject", emergencyService.Reject))
+		r.Delete("/{id}", e.change("delete", emergencyService.Delete))
+	})
+	return router
+}
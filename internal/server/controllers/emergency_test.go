@@ -0,0 +1,73 @@
+package controllers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/zelas91/goph-keeper/internal/logger"
+	mock2 "github.com/zelas91/goph-keeper/internal/server/controllers/mocks"
+	"github.com/zelas91/goph-keeper/internal/server/repository"
+)
+
+func TestEmergencyChange(t *testing.T) {
+	tests := []struct {
+		name                         string
+		url                          string
+		want                         int
+		mockBehaviorEmergencyService func(s *mock2.MockemergencyService)
+	}{
+		{
+			name: "#1 ok request",
+			url:  "/3/request",
+			want: http.StatusOK,
+			mockBehaviorEmergencyService: func(s *mock2.MockemergencyService) {
+				s.EXPECT().Request(gomock.Any(), 3).Return(nil)
+			},
+		},
+		{
+			name: "#2 nok approve not requested",
+			url:  "/3/approve",
+			want: http.StatusNotFound,
+			mockBehaviorEmergencyService: func(s *mock2.MockemergencyService) {
+				s.EXPECT().Approve(gomock.Any(), 3).Return(repository.ErrNotFound)
+			},
+		},
+		{
+			name: "#3 ok reject",
+			url:  "/3/reject",
+			want: http.StatusOK,
+			mockBehaviorEmergencyService: func(s *mock2.MockemergencyService) {
+				s.EXPECT().Reject(gomock.Any(), 3).Return(nil)
+			},
+		},
+		{
+			name: "#4 nok invalid id",
+			url:  "/abc/reject",
+			want: http.StatusBadRequest,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			service := mock2.NewMockemergencyService(ctrl)
+			if test.mockBehaviorEmergencyService != nil {
+				test.mockBehaviorEmergencyService(service)
+			}
+
+			handler := New(logger.New(""), WithEmergencyUseService(service))
+
+			request := httptest.NewRequest(http.MethodPost, test.url, nil)
+			w := httptest.NewRecorder()
+			handler.emergency.createRoutes().ServeHTTP(w, request)
+			res := w.Result()
+			defer res.Body.Close()
+
+			assert.Equal(t, test.want, res.StatusCode)
+		})
+	}
+}
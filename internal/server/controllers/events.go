@@ -0,0 +1,103 @@
+package controllers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-playground/validator/v10"
+	"github.com/gorilla/websocket"
+	"github.com/zelas91/goph-keeper/internal/logger"
+	"github.com/zelas91/goph-keeper/internal/server/models"
+	"github.com/zelas91/goph-keeper/internal/server/types"
+	"golang.org/x/net/context"
+)
+
+const (
+	eventsPingPeriod   = 30 * time.Second
+	eventsWriteTimeout = 10 * time.Second
+	eventsReadLimit    = 512
+)
+
+type events struct {
+	service eventService
+	valid   *validator.Validate
+	log     logger.Logger
+}
+
+//go:generate mockgen -package mocks -destination=./mocks/mock_event_service.go -source=events.go -package=mock
+type eventService interface {
+	Subscribe(ctx context.Context) (<-chan models.Event, func())
+}
+
+// subscribe pushes the changes of the user vault over the websocket until
+// the client goes away. The changes made by the client itself are not sent.
+func (e *events) subscribe() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			e.log.Errorf("events: upgrade connection err: %v", err)
+			return
+		}
+		conn.SetReadLimit(eventsReadLimit)
+		defer func() {
+			if err := conn.Close(); err != nil {
+				e.log.Errorf("events: close websocket connect err: %v", err)
+			}
+		}()
+
+		ch, cancel := e.service.Subscribe(r.Context())
+		defer cancel()
+		clientID, _ := r.Context().Value(types.ClientIDKey).(string)
+
+		// the client sends nothing, reading only notices that it went away
+		closed := make(chan struct{})
+		go func() {
+			defer close(closed)
+			for {
+				if _, _, err := conn.ReadMessage(); err != nil {
+					return
+				}
+			}
+		}()
+
+		ping := time.NewTicker(eventsPingPeriod)
+		defer ping.Stop()
+		for {
+			select {
+			case <-closed:
+				return
+			case <-r.Context().Done():
+				return
+			case <-ping.C:
+				if err = conn.WriteControl(websocket.PingMessage, nil,
+					time.Now().Add(eventsWriteTimeout)); err != nil {
+					e.log.Errorf("events: ping err: %v", err)
+					return
+				}
+			case event, ok := <-ch:
+				if !ok {
+					return
+				}
+				if clientID != "" && event.Origin == clientID {
+					continue
+				}
+				if err = conn.SetWriteDeadline(time.Now().Add(eventsWriteTimeout)); err != nil {
+					return
+				}
+				if err = conn.WriteJSON(event); err != nil {
+					e.log.Errorf("events: send event err: %v", err)
+					return
+				}
+			}
+		}
+	}
+}
+
+func (e *events) createRoutes() http.Handler {
+	router := chi.NewRouter()
+	router.Route("/", func(r chi.Router) {
+		r.Get("/", e.subscribe())
+	})
+	return router
+}
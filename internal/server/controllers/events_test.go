@@ -0,0 +1,84 @@
+package controllers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/zelas91/goph-keeper/internal/logger"
+	mock2 "github.com/zelas91/goph-keeper/internal/server/controllers/mocks"
+	"github.com/zelas91/goph-keeper/internal/server/middleware"
+	"github.com/zelas91/goph-keeper/internal/server/models"
+)
+
+func TestEventsSubscribe(t *testing.T) {
+	tests := []struct {
+		name     string
+		clientID string
+		events   []models.Event
+		want     []models.Event
+	}{
+		{
+			name: "#1 ok events are pushed",
+			events: []models.Event{
+				{Action: models.EventCreated, ItemType: "card"},
+				{Action: models.EventDeleted, ItemType: "text", ItemID: 3},
+			},
+			want: []models.Event{
+				{Action: models.EventCreated, ItemType: "card"},
+				{Action: models.EventDeleted, ItemType: "text", ItemID: 3},
+			},
+		},
+		{
+			name:     "#2 ok own changes are not sent back",
+			clientID: "laptop",
+			events: []models.Event{
+				{Action: models.EventUpdated, ItemType: "card", ItemID: 1, Origin: "laptop"},
+				{Action: models.EventUpdated, ItemType: "card", ItemID: 2, Origin: "phone"},
+			},
+			want: []models.Event{
+				{Action: models.EventUpdated, ItemType: "card", ItemID: 2},
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			ch := make(chan models.Event, len(test.events))
+			for _, v := range test.events {
+				ch <- v
+			}
+			close(ch)
+			service := mock2.NewMockeventService(ctrl)
+			service.EXPECT().Subscribe(gomock.Any()).Return(ch, func() {})
+
+			handler := New(logger.New(""), WithEventsUseService(service))
+			srv := httptest.NewServer(middleware.ClientID(handler.events.createRoutes()))
+			defer srv.Close()
+
+			header := http.Header{}
+			if test.clientID != "" {
+				header.Set("Client-ID", test.clientID)
+			}
+			conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(srv.URL, "http"), header)
+			assert.NoError(t, err)
+			defer conn.Close()
+
+			var got []models.Event
+			for {
+				var event models.Event
+				if err = conn.ReadJSON(&event); err != nil {
+					break
+				}
+				got = append(got, event)
+			}
+			assert.Equal(t, test.want, got)
+		})
+	}
+}
@@ -0,0 +1,194 @@
+package controllers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-playground/validator/v10"
+	"github.com/zelas91/goph-keeper/internal/logger"
+	"github.com/zelas91/goph-keeper/internal/server/helper"
+	"github.com/zelas91/goph-keeper/internal/server/models"
+	"github.com/zelas91/goph-keeper/internal/server/payload"
+	"github.com/zelas91/goph-keeper/internal/server/repository"
+	"github.com/zelas91/goph-keeper/internal/server/services"
+	"golang.org/x/net/context"
+)
+
+type folder struct {
+	service folderService
+	valid   *validator.Validate
+	log     logger.Logger
+}
+
+//go:generate mockgen -package mocks -destination=./mocks/mock_folder_service.go -source=folder.go -package=mock
+type folderService interface {
+	Create(ctx context.Context, fd models.Folder) (models.Folder, error)
+	Folders(ctx context.Context) ([]models.Folder, error)
+	Update(ctx context.Context, fd models.Folder) error
+	Delete(ctx context.Context, folderID int) error
+	MoveItem(ctx context.Context, folderID *int, item models.ItemRef) error
+}
+
+func (f *folder) folders() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		folders, err := f.service.Folders(r.Context())
+		if err != nil {
+			f.log.Errorf("folders: get folders err %v", err)
+			payload.NewErrorResponse(w, "folders: get folders err", http.StatusInternalServerError)
+			return
+		}
+		if err = json.NewEncoder(w).Encode(folders); err != nil {
+			f.log.Errorf("folders: encode err %v", err)
+			payload.NewErrorResponse(w, "folders: encode err", http.StatusInternalServerError)
+			return
+		}
+	}
+}
+
+func (f *folder) create() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Body == nil {
+			payload.NewErrorResponse(w, "create: body is empty", http.StatusBadRequest)
+			return
+		}
+
+		defer func() {
+			if err := r.Body.Close(); err != nil {
+				f.log.Errorf("create: folder in body close err :%v", err)
+			}
+		}()
+
+		var fd models.Folder
+		if err := decodeAndValid(r, f.valid, &fd); err != nil {
+			f.log.Errorf("create: decode or validation err:%v", err)
+			payload.NewErrorResponse(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		fd, err := f.service.Create(r.Context(), fd)
+		if err != nil {
+			f.log.Errorf("create: folder save err: %v", err)
+			folderErrorResponse(w, "create: folder save err", err)
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+		if err = json.NewEncoder(w).Encode(fd); err != nil {
+			f.log.Errorf("create: encode err %v", err)
+		}
+	}
+}
+
+func (f *folder) update() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Body == nil {
+			payload.NewErrorResponse(w, "update: body is empty", http.StatusBadRequest)
+			return
+		}
+
+		defer func() {
+			if err := r.Body.Close(); err != nil {
+				f.log.Errorf("update: folder in body close err :%v", err)
+			}
+		}()
+
+		id, err := helper.IDFromContext(r.Context())
+		if err != nil {
+			f.log.Errorf("update: get id folder err: %v", err)
+			payload.NewErrorResponse(w, "update: get id folder err:", http.StatusBadRequest)
+			return
+		}
+
+		var fd models.Folder
+		if err = decodeAndValid(r, f.valid, &fd); err != nil {
+			f.log.Errorf("update: decode or validation err:%v", err)
+			payload.NewErrorResponse(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		fd.ID = id
+
+		if err = f.service.Update(r.Context(), fd); err != nil {
+			f.log.Errorf("update: folder save err: %v", err)
+			folderErrorResponse(w, "update: folder save err", err)
+		}
+	}
+}
+
+func (f *folder) delete() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := helper.IDFromContext(r.Context())
+		if err != nil {
+			f.log.Errorf("get id folder err: %v", err)
+			payload.NewErrorResponse(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if err = f.service.Delete(r.Context(), id); err != nil {
+			f.log.Errorf("delete folder err: %v", err)
+			folderErrorResponse(w, "delete folder err", err)
+			return
+		}
+	}
+}
+
+func (f *folder) move() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Body == nil {
+			payload.NewErrorResponse(w, "move: body is empty", http.StatusBadRequest)
+			return
+		}
+
+		defer func() {
+			if err := r.Body.Close(); err != nil {
+				f.log.Errorf("move: item in body close err :%v", err)
+			}
+		}()
+
+		var move models.MoveItem
+		if err := decodeAndValid(r, f.valid, &move); err != nil {
+			f.log.Errorf("move: decode or validation err:%v", err)
+			payload.NewErrorResponse(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		if err := f.service.MoveItem(r.Context(), move.FolderID, move.ItemRef); err != nil {
+			f.log.Errorf("move: item move err: %v", err)
+			folderErrorResponse(w, "move: item move err", err)
+		}
+	}
+}
+
+func folderErrorResponse(w http.ResponseWriter, message string, err error) {
+	switch {
+	case errors.Is(err, repository.ErrNotFound):
+		payload.NewErrorResponse(w, message+": not found", http.StatusNotFound)
+	case errors.Is(err, services.ErrFolderParent):
+		payload.NewErrorResponse(w, message+": "+services.ErrFolderParent.Error(), http.StatusBadRequest)
+	default:
+		payload.NewErrorResponse(w, message, http.StatusInternalServerError)
+	}
+}
+
+// decodeAndValid decodes the JSON body into v and validates it.
+func decodeAndValid(r *http.Request, valid *validator.Validate, v any) error {
+	if err := json.NewDecoder(r.Body).Decode(v); err != nil {
+		return fmt.Errorf("json decode err:%v", err)
+	}
+	if err := valid.Struct(v); err != nil {
+		return fmt.Errorf("validate err: %v", err)
+	}
+	return nil
+}
+
+func (f *folder) createRoutes() http.Handler {
+	router := chi.NewRouter()
+	router.Route("/", func(r chi.Router) {
+		r.Get("/", f.folders())
+		r.Post("/", f.create())
+		r.Put("/move", f.move())
+		r.Put("/{id}", f.update())
+		r.Delete("/{id}", f.delete())
+	})
+	return router
+}
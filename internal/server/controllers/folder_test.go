@@ -0,0 +1,150 @@
+package controllers
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/zelas91/goph-keeper/internal/logger"
+	mock2 "github.com/zelas91/goph-keeper/internal/server/controllers/mocks"
+	"github.com/zelas91/goph-keeper/internal/server/models"
+	"github.com/zelas91/goph-keeper/internal/server/repository"
+	"github.com/zelas91/goph-keeper/internal/server/services"
+)
+
+func TestFolderCreate(t *testing.T) {
+	parentID := 1
+	tests := []struct {
+		name                      string
+		want                      int
+		mockBehaviorCreateService func(s *mock2.MockfolderService, fd models.Folder)
+		body                      models.Folder
+	}{
+		{
+			name: "#1 ok create folder",
+			want: http.StatusCreated,
+			body: models.Folder{Name: "bank", ParentID: &parentID},
+			mockBehaviorCreateService: func(s *mock2.MockfolderService, fd models.Folder) {
+				created := fd
+				created.ID = 2
+				s.EXPECT().Create(gomock.Any(), fd).Return(created, nil)
+			},
+		},
+		{
+			name: "#2 nok validation error name",
+			want: http.StatusBadRequest,
+			body: models.Folder{},
+		},
+		{
+			name: "#3 nok parent folder err",
+			want: http.StatusBadRequest,
+			body: models.Folder{Name: "bank", ParentID: &parentID},
+			mockBehaviorCreateService: func(s *mock2.MockfolderService, fd models.Folder) {
+				s.EXPECT().Create(gomock.Any(), fd).Return(models.Folder{}, services.ErrFolderParent)
+			},
+		},
+		{
+			name: "#4 nok folder save err",
+			want: http.StatusInternalServerError,
+			body: models.Folder{Name: "bank"},
+			mockBehaviorCreateService: func(s *mock2.MockfolderService, fd models.Folder) {
+				s.EXPECT().Create(gomock.Any(), fd).Return(models.Folder{}, errors.New("save folder err"))
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			service := mock2.NewMockfolderService(ctrl)
+			if test.mockBehaviorCreateService != nil {
+				test.mockBehaviorCreateService(service, test.body)
+			}
+
+			handler := New(logger.New(""), WithFolderUseService(service))
+
+			body, err := json.Marshal(test.body)
+			assert.NoError(t, err, "Body write error")
+
+			request := httptest.NewRequest(http.MethodPost, "/", bytes.NewReader(body))
+			w := httptest.NewRecorder()
+			handler.folder.createRoutes().ServeHTTP(w, request)
+			res := w.Result()
+			defer res.Body.Close()
+
+			assert.Equal(t, test.want, res.StatusCode)
+		})
+	}
+}
+
+func TestFolderMove(t *testing.T) {
+	folderID := 3
+	tests := []struct {
+		name                    string
+		want                    int
+		mockBehaviorMoveService func(s *mock2.MockfolderService, move models.MoveItem)
+		body                    models.MoveItem
+	}{
+		{
+			name: "#1 ok move item into folder",
+			want: http.StatusOK,
+			body: models.MoveItem{ItemRef: models.ItemRef{ItemType: "card", ItemID: 5}, FolderID: &folderID},
+			mockBehaviorMoveService: func(s *mock2.MockfolderService, move models.MoveItem) {
+				s.EXPECT().MoveItem(gomock.Any(), move.FolderID, move.ItemRef).Return(nil)
+			},
+		},
+		{
+			name: "#2 ok move item to root",
+			want: http.StatusOK,
+			body: models.MoveItem{ItemRef: models.ItemRef{ItemType: "text", ItemID: 5}},
+			mockBehaviorMoveService: func(s *mock2.MockfolderService, move models.MoveItem) {
+				s.EXPECT().MoveItem(gomock.Any(), nil, move.ItemRef).Return(nil)
+			},
+		},
+		{
+			name: "#3 nok unknown item type",
+			want: http.StatusBadRequest,
+			body: models.MoveItem{ItemRef: models.ItemRef{ItemType: "car", ItemID: 5}},
+		},
+		{
+			name: "#4 nok item not found",
+			want: http.StatusNotFound,
+			body: models.MoveItem{ItemRef: models.ItemRef{ItemType: "file", ItemID: 5}, FolderID: &folderID},
+			mockBehaviorMoveService: func(s *mock2.MockfolderService, move models.MoveItem) {
+				s.EXPECT().MoveItem(gomock.Any(), move.FolderID, move.ItemRef).
+					Return(fmt.Errorf("move item err: %w", repository.ErrNotFound))
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			service := mock2.NewMockfolderService(ctrl)
+			if test.mockBehaviorMoveService != nil {
+				test.mockBehaviorMoveService(service, test.body)
+			}
+
+			handler := New(logger.New(""), WithFolderUseService(service))
+
+			body, err := json.Marshal(test.body)
+			assert.NoError(t, err, "Body write error")
+
+			request := httptest.NewRequest(http.MethodPut, "/move", bytes.NewReader(body))
+			w := httptest.NewRecorder()
+			handler.folder.createRoutes().ServeHTTP(w, request)
+			res := w.Result()
+			defer res.Body.Close()
+
+			assert.Equal(t, test.want, res.StatusCode)
+		})
+	}
+}
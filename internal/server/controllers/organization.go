@@ -0,0 +1,232 @@
+package controllers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-playground/validator/v10"
+	"github.com/zelas91/goph-keeper/internal/logger"
+	"github.com/zelas91/goph-keeper/internal/server/helper"
+	"github.com/zelas91/goph-keeper/internal/server/models"
+	"github.com/zelas91/goph-keeper/internal/server/payload"
+	"github.com/zelas91/goph-keeper/internal/server/repository"
+	"github.com/zelas91/goph-keeper/internal/server/services"
+	"golang.org/x/net/context"
+)
+
+type organization struct {
+	service organizationService
+	valid   *validator.Validate
+	log     logger.Logger
+}
+
+//go:generate mockgen -package mocks -destination=./mocks/mock_organization_service.go -source=organization.go -package=mock
+type organizationService interface {
+	Create(ctx context.Context, org models.Organization) (models.Organization, error)
+	Organizations(ctx context.Context) ([]models.Organization, error)
+	Delete(ctx context.Context, orgID int) error
+	Members(ctx context.Context, orgID int) ([]models.OrgMember, error)
+	AddMember(ctx context.Context, orgID int, member models.OrgMember) error
+	SetRole(ctx context.Context, orgID int, login, role string) error
+	RemoveMember(ctx context.Context, orgID int, login string) error
+	VaultUserID(ctx context.Context, orgID int, access string) (int, error)
+}
+
+func (o *organization) organizations() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		orgs, err := o.service.Organizations(r.Context())
+		if err != nil {
+			o.log.Errorf("organizations: get organizations err %v", err)
+			payload.NewErrorResponse(w, "organizations: get organizations err", http.StatusInternalServerError)
+			return
+		}
+		o.encode(w, "organizations", orgs)
+	}
+}
+
+func (o *organization) create() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Body == nil {
+			payload.NewErrorResponse(w, "create: body is empty", http.StatusBadRequest)
+			return
+		}
+
+		defer func() {
+			if err := r.Body.Close(); err != nil {
+				o.log.Errorf("create: organization in body close err :%v", err)
+			}
+		}()
+
+		var org models.Organization
+		if err := decodeAndValid(r, o.valid, &org); err != nil {
+			o.log.Errorf("create: decode or validation err:%v", err)
+			payload.NewErrorResponse(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		org, err := o.service.Create(r.Context(), org)
+		if err != nil {
+			o.log.Errorf("create: organization save err: %v", err)
+			payload.NewErrorResponse(w, "create: organization save err", http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+		o.encode(w, "create", org)
+	}
+}
+
+func (o *organization) delete() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := helper.IDFromContext(r.Context())
+		if err != nil {
+			o.log.Errorf("delete: get id organization err: %v", err)
+			payload.NewErrorResponse(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if err = o.service.Delete(r.Context(), id); err != nil {
+			o.log.Errorf("delete: organization delete err: %v", err)
+			orgErrorResponse(w, "delete: organization delete err", err)
+		}
+	}
+}
+
+func (o *organization) members() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := helper.IDFromContext(r.Context())
+		if err != nil {
+			o.log.Errorf("members: get id organization err: %v", err)
+			payload.NewErrorResponse(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		members, err := o.service.Members(r.Context(), id)
+		if err != nil {
+			o.log.Errorf("members: get members err: %v", err)
+			orgErrorResponse(w, "members: get members err", err)
+			return
+		}
+		o.encode(w, "members", members)
+	}
+}
+
+func (o *organization) addMember() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := helper.IDFromContext(r.Context())
+		if err != nil {
+			o.log.Errorf("add member: get id organization err: %v", err)
+			payload.NewErrorResponse(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if r.Body == nil {
+			payload.NewErrorResponse(w, "add member: body is empty", http.StatusBadRequest)
+			return
+		}
+
+		defer func() {
+			if err := r.Body.Close(); err != nil {
+				o.log.Errorf("add member: member in body close err :%v", err)
+			}
+		}()
+
+		var member models.OrgMember
+		if err = decodeAndValid(r, o.valid, &member); err != nil {
+			o.log.Errorf("add member: decode or validation err:%v", err)
+			payload.NewErrorResponse(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if err = o.service.AddMember(r.Context(), id, member); err != nil {
+			o.log.Errorf("add member: member save err: %v", err)
+			orgErrorResponse(w, "add member: member save err", err)
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+	}
+}
+
+func (o *organization) setRole() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := helper.IDFromContext(r.Context())
+		if err != nil {
+			o.log.Errorf("set role: get id organization err: %v", err)
+			payload.NewErrorResponse(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if r.Body == nil {
+			payload.NewErrorResponse(w, "set role: body is empty", http.StatusBadRequest)
+			return
+		}
+
+		defer func() {
+			if err := r.Body.Close(); err != nil {
+				o.log.Errorf("set role: role in body close err :%v", err)
+			}
+		}()
+
+		var role models.OrgRole
+		if err = decodeAndValid(r, o.valid, &role); err != nil {
+			o.log.Errorf("set role: decode or validation err:%v", err)
+			payload.NewErrorResponse(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if err = o.service.SetRole(r.Context(), id, chi.URLParam(r, "login"), role.Role); err != nil {
+			o.log.Errorf("set role: role save err: %v", err)
+			orgErrorResponse(w, "set role: role save err", err)
+		}
+	}
+}
+
+func (o *organization) removeMember() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := helper.IDFromContext(r.Context())
+		if err != nil {
+			o.log.Errorf("remove member: get id organization err: %v", err)
+			payload.NewErrorResponse(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if err = o.service.RemoveMember(r.Context(), id, chi.URLParam(r, "login")); err != nil {
+			o.log.Errorf("remove member: member remove err: %v", err)
+			orgErrorResponse(w, "remove member: member remove err", err)
+		}
+	}
+}
+
+// VaultUserID lets the requests in the vault of an organization through
+// the roles of its members.
+func (o *organization) VaultUserID(ctx context.Context, orgID int, access string) (int, error) {
+	return o.service.VaultUserID(ctx, orgID, access)
+}
+
+func (o *organization) encode(w http.ResponseWriter, message string, v any) {
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		o.log.Errorf("%s: encode err %v", message, err)
+		payload.NewErrorResponse(w, message+": encode err", http.StatusInternalServerError)
+	}
+}
+
+func orgErrorResponse(w http.ResponseWriter, message string, err error) {
+	switch {
+	case errors.Is(err, repository.ErrNotFound):
+		payload.NewErrorResponse(w, message+": not found", http.StatusNotFound)
+	case errors.Is(err, services.ErrOrgForbidden):
+		payload.NewErrorResponse(w, message+": "+services.ErrOrgForbidden.Error(), http.StatusForbidden)
+	case errors.Is(err, services.ErrLastOwner), errors.Is(err, repository.ErrMemberExists),
+		errors.Is(err, repository.ErrNotEmpty):
+		payload.NewErrorResponse(w, message+": "+err.Error(), http.StatusConflict)
+	default:
+		payload.NewErrorResponse(w, message, http.StatusInternalServerError)
+	}
+}
+
+func (o *organization) createRoutes() http.Handler {
+	router := chi.NewRouter()
+	router.Route("/", func(r chi.Router) {
+		r.Get("/", o.organizations())
+		r.Post("/", o.create())
+		r.Delete("/{id}", o.delete())
+		r.Get("/{id}/members", o.members())
+		r.Post("/{id}/members", o.addMember())
+		r.Put("/{id}/members/{login}", o.setRole())
+		r.Delete("/{id}/members/{login}", o.removeMember())
+	})
+	return router
+}
@@ -0,0 +1,85 @@
+package controllers
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/zelas91/goph-keeper/internal/logger"
+	mock2 "github.com/zelas91/goph-keeper/internal/server/controllers/mocks"
+	"github.com/zelas91/goph-keeper/internal/server/models"
+	"github.com/zelas91/goph-keeper/internal/server/repository"
+	"github.com/zelas91/goph-keeper/internal/server/services"
+)
+
+func TestAddMember(t *testing.T) {
+	tests := []struct {
+		name                   string
+		body                   string
+		want                   int
+		mockBehaviorOrgService func(s *mock2.MockorganizationService)
+	}{
+		{
+			name: "#1 ok add member",
+			body: `{"login":"bob","role":"member","org_key":"Ym94"}`,
+			want: http.StatusCreated,
+			mockBehaviorOrgService: func(s *mock2.MockorganizationService) {
+				s.EXPECT().AddMember(gomock.Any(), 3, models.OrgMember{Login: "bob", Role: models.RoleMember,
+					OrgKey: "Ym94"}).Return(nil)
+			},
+		},
+		{
+			name: "#2 nok unknown role",
+			body: `{"login":"bob","role":"guest"}`,
+			want: http.StatusBadRequest,
+		},
+		{
+			name: "#3 nok role does not allow",
+			body: `{"login":"bob","role":"owner"}`,
+			want: http.StatusForbidden,
+			mockBehaviorOrgService: func(s *mock2.MockorganizationService) {
+				s.EXPECT().AddMember(gomock.Any(), 3, gomock.Any()).Return(services.ErrOrgForbidden)
+			},
+		},
+		{
+			name: "#4 nok member already",
+			body: `{"login":"bob","role":"member"}`,
+			want: http.StatusConflict,
+			mockBehaviorOrgService: func(s *mock2.MockorganizationService) {
+				s.EXPECT().AddMember(gomock.Any(), 3, gomock.Any()).Return(repository.ErrMemberExists)
+			},
+		},
+		{
+			name: "#5 nok no such user",
+			body: `{"login":"nobody","role":"member"}`,
+			want: http.StatusNotFound,
+			mockBehaviorOrgService: func(s *mock2.MockorganizationService) {
+				s.EXPECT().AddMember(gomock.Any(), 3, gomock.Any()).Return(repository.ErrNotFound)
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			service := mock2.NewMockorganizationService(ctrl)
+			if test.mockBehaviorOrgService != nil {
+				test.mockBehaviorOrgService(service)
+			}
+
+			handler := New(logger.New(""), WithOrganizationUseService(service))
+
+			request := httptest.NewRequest(http.MethodPost, "/3/members", bytes.NewBufferString(test.body))
+			w := httptest.NewRecorder()
+			handler.org.createRoutes().ServeHTTP(w, request)
+			res := w.Result()
+			defer res.Body.Close()
+
+			assert.Equal(t, test.want, res.StatusCode)
+		})
+	}
+}
@@ -0,0 +1,201 @@
+package controllers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-playground/validator/v10"
+	"github.com/zelas91/goph-keeper/internal/logger"
+	"github.com/zelas91/goph-keeper/internal/server/helper"
+	"github.com/zelas91/goph-keeper/internal/server/models"
+	"github.com/zelas91/goph-keeper/internal/server/payload"
+	otp2 "github.com/zelas91/goph-keeper/internal/utils/otp"
+	"golang.org/x/net/context"
+)
+
+type otp struct {
+	service otpService
+	valid   *validator.Validate
+	log     logger.Logger
+}
+
+//go:generate mockgen -package mocks -destination=./mocks/mock_otp_service.go -source=otp.go -package=mock
+type otpService interface {
+	Create(ctx context.Context, key models.OTP) error
+	OTPs(ctx context.Context, filter models.ItemFilter) ([]models.OTP, string, error)
+	OTP(ctx context.Context, otpID int) (models.OTP, error)
+	Delete(ctx context.Context, otpID int) error
+	Update(ctx context.Context, key models.OTP) error
+}
+
+func (o *otp) otps() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		filter, err := helper.ItemFilterFromRequest(r)
+		if err != nil {
+			o.log.Errorf("otps: filter from request err: %v", err)
+			payload.NewErrorResponse(w, "otps: filter from request err", http.StatusBadRequest)
+			return
+		}
+		keys, next, err := o.service.OTPs(r.Context(), filter)
+		if err != nil {
+			o.log.Errorf("otps: get otp keys err %v", err)
+			payload.NewErrorResponse(w, "otps: get otp keys err", http.StatusInternalServerError)
+			return
+		}
+		if err = json.NewEncoder(w).Encode(models.Page[models.OTP]{Items: keys, NextCursor: next}); err != nil {
+			o.log.Errorf("otps: encode err %v", err)
+			payload.NewErrorResponse(w, "otps: encode err", http.StatusInternalServerError)
+			return
+		}
+	}
+}
+
+func (o *otp) otp() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := helper.IDFromContext(r.Context())
+		if err != nil {
+			o.log.Errorf("otp: get id from request err: %v", err)
+			payload.NewErrorResponse(w, "otp: get id from request err", http.StatusBadRequest)
+			return
+		}
+		key, err := o.service.OTP(r.Context(), id)
+		if err != nil {
+			o.log.Errorf("otp: get otp err: %v", err)
+			payload.NewErrorResponse(w, "otp: get otp err", http.StatusNotFound)
+			return
+		}
+		if err = json.NewEncoder(w).Encode(key); err != nil {
+			o.log.Errorf("otp: encode err %v", err)
+			payload.NewErrorResponse(w, "otp: encode err", http.StatusInternalServerError)
+			return
+		}
+	}
+}
+
+func (o *otp) create() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Body == nil {
+			payload.NewErrorResponse(w, "create: body is empty", http.StatusBadRequest)
+			return
+		}
+
+		defer func() {
+			if err := r.Body.Close(); err != nil {
+				o.log.Errorf("create: otp in body close err :%v", err)
+			}
+		}()
+
+		key, err := o.fromRequestAndValid(r)
+		if err != nil {
+			o.log.Errorf("create: decode or validation err:%v", err)
+			payload.NewErrorResponse(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		if err = o.service.Create(r.Context(), key); err != nil {
+			o.log.Errorf("create: otp save err: %v", err)
+			createErrorResponse(w, "create: otp save err", err)
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+	}
+}
+
+func (o *otp) update() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Body == nil {
+			payload.NewErrorResponse(w, "update: body is empty", http.StatusBadRequest)
+			return
+		}
+
+		defer func() {
+			if err := r.Body.Close(); err != nil {
+				o.log.Errorf("update: otp in body close err :%v", err)
+			}
+		}()
+
+		id, err := helper.IDFromContext(r.Context())
+		if err != nil {
+			o.log.Errorf("update: get id otp err: %v", err)
+			payload.NewErrorResponse(w, "update: get id otp err:", http.StatusBadRequest)
+			return
+		}
+
+		key, err := o.fromRequestAndValid(r)
+		if err != nil {
+			o.log.Errorf("update: decode or validation err:%v", err)
+			payload.NewErrorResponse(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if key.Version == 0 {
+			o.log.Error("update: otp version == 0")
+			payload.NewErrorResponse(w, "update: otp version == 0", http.StatusBadRequest)
+			return
+		}
+		key.ID = id
+
+		if err = o.service.Update(r.Context(), key); err != nil {
+			o.log.Errorf("update: otp save err: %v", err)
+			updateErrorResponse(w, o.log, "update: otp save err", err, func() (any, error) {
+				return o.service.OTP(r.Context(), id)
+			})
+		}
+	}
+}
+
+func (o *otp) delete() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := helper.IDFromContext(r.Context())
+		if err != nil {
+			o.log.Errorf("get id otp err: %v", err)
+			payload.NewErrorResponse(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if err = o.service.Delete(r.Context(), id); err != nil {
+			o.log.Errorf("delete otp err: %v", err)
+			payload.NewErrorResponse(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	}
+}
+
+// fromRequestAndValid reads the key from the otpauth:// URI when it is
+// given, the parameters not given take the defaults of the authenticators.
+func (o *otp) fromRequestAndValid(r *http.Request) (key models.OTP, err error) {
+	if err = json.NewDecoder(r.Body).Decode(&key); err != nil {
+		return key, fmt.Errorf("otp json decode err:%v", err)
+	}
+	parsed := otp2.Key{Type: key.Type, Issuer: key.Issuer, Account: key.Account, Secret: key.Secret,
+		Algorithm: key.Algorithm, Digits: key.Digits, Period: key.Period, Counter: key.Counter}
+	if key.URI != "" {
+		if parsed, err = otp2.Parse(key.URI); err != nil {
+			return key, fmt.Errorf("otp uri err: %v", err)
+		}
+		key.URI = ""
+	}
+	parsed = parsed.WithDefaults()
+	if err = parsed.Validate(); err != nil {
+		return key, fmt.Errorf("otp validate err: %v", err)
+	}
+	key.Type, key.Issuer, key.Account, key.Secret = parsed.Type, parsed.Issuer, parsed.Account, parsed.Secret
+	key.Algorithm, key.Digits, key.Period, key.Counter = parsed.Algorithm, parsed.Digits, parsed.Period, parsed.Counter
+
+	if err = o.valid.Struct(key); err != nil {
+		return key, fmt.Errorf("otp validate err: %v", err)
+	}
+	return
+}
+
+func (o *otp) createRoutes() http.Handler {
+	router := chi.NewRouter()
+	router.Route("/", func(r chi.Router) {
+		r.Get("/", o.otps())
+		r.Get("/{id}", o.otp())
+		r.Post("/", o.create())
+		r.Put("/{id}", o.update())
+		r.Delete("/{id}", o.delete())
+	})
+	return router
+}
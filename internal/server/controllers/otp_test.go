@@ -0,0 +1,78 @@
+package controllers
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/zelas91/goph-keeper/internal/logger"
+	mock2 "github.com/zelas91/goph-keeper/internal/server/controllers/mocks"
+	"github.com/zelas91/goph-keeper/internal/server/models"
+)
+
+func TestCreateOTP(t *testing.T) {
+	tests := []struct {
+		name                   string
+		body                   string
+		want                   int
+		mockBehaviorOTPService func(s *mock2.MockotpService)
+	}{
+		{
+			name: "#1 ok otpauth uri",
+			body: `{"uri":"otpauth://totp/ACME:john?secret=JBSWY3DPEHPK3PXP&digits=8","title":"acme"}`,
+			want: http.StatusCreated,
+			mockBehaviorOTPService: func(s *mock2.MockotpService) {
+				s.EXPECT().Create(gomock.Any(), models.OTP{Type: "totp", Issuer: "ACME", Account: "john",
+					Secret: "JBSWY3DPEHPK3PXP", Algorithm: "SHA1", Digits: 8, Period: 30, Title: "acme"}).Return(nil)
+			},
+		},
+		{
+			name: "#2 ok fields with defaults",
+			body: `{"issuer":"ACME","account":"john","secret":"JBSWY3DPEHPK3PXP"}`,
+			want: http.StatusCreated,
+			mockBehaviorOTPService: func(s *mock2.MockotpService) {
+				s.EXPECT().Create(gomock.Any(), models.OTP{Type: "totp", Issuer: "ACME", Account: "john",
+					Secret: "JBSWY3DPEHPK3PXP", Algorithm: "SHA1", Digits: 6, Period: 30}).Return(nil)
+			},
+		},
+		{
+			name: "#3 nok not otpauth uri",
+			body: `{"uri":"https://example.com/?secret=JBSWY3DPEHPK3PXP"}`,
+			want: http.StatusBadRequest,
+		},
+		{
+			name: "#4 nok secret not base32",
+			body: `{"secret":"not base32!"}`,
+			want: http.StatusBadRequest,
+		},
+		{
+			name: "#5 nok digits",
+			body: `{"secret":"JBSWY3DPEHPK3PXP","digits":12}`,
+			want: http.StatusBadRequest,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			service := mock2.NewMockotpService(ctrl)
+			if test.mockBehaviorOTPService != nil {
+				test.mockBehaviorOTPService(service)
+			}
+
+			handler := New(logger.New(""), WithOTPUseService(service))
+
+			request := httptest.NewRequest(http.MethodPost, "/", bytes.NewBufferString(test.body))
+			w := httptest.NewRecorder()
+			handler.otp.createRoutes().ServeHTTP(w, request)
+			res := w.Result()
+			defer res.Body.Close()
+
+			assert.Equal(t, test.want, res.StatusCode)
+		})
+	}
+}
@@ -0,0 +1,162 @@
+package controllers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-playground/validator/v10"
+	"github.com/zelas91/goph-keeper/internal/logger"
+	"github.com/zelas91/goph-keeper/internal/server/models"
+	"github.com/zelas91/goph-keeper/internal/server/payload"
+	"github.com/zelas91/goph-keeper/internal/server/repository"
+	"github.com/zelas91/goph-keeper/internal/server/services"
+	"golang.org/x/net/context"
+)
+
+type recovery struct {
+	service recoveryService
+	valid   *validator.Validate
+	log     logger.Logger
+}
+
+//go:generate mockgen -package mocks -destination=./mocks/mock_recovery_service.go -source=recovery.go -package=mock
+type recoveryService interface {
+	Set(ctx context.Context, key models.RecoveryKey) error
+	State(ctx context.Context) (models.RecoveryState, error)
+	VaultKey(ctx context.Context, proof models.RecoveryProof) (models.RecoveryKey, error)
+	Recover(ctx context.Context, rec models.Recover) error
+}
+
+func (rc *recovery) state() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		state, err := rc.service.State(r.Context())
+		if err != nil {
+			rc.log.Errorf("recovery: get state err: %v", err)
+			recoveryErrorResponse(w, "recovery: get state err", err)
+			return
+		}
+		rc.encode(w, "recovery", state)
+	}
+}
+
+func (rc *recovery) set() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Body == nil {
+			payload.NewErrorResponse(w, "recovery: body is empty", http.StatusBadRequest)
+			return
+		}
+
+		defer func() {
+			if err := r.Body.Close(); err != nil {
+				rc.log.Errorf("recovery: key in body close err :%v", err)
+			}
+		}()
+
+		var key models.RecoveryKey
+		if err := decodeAndValid(r, rc.valid, &key); err != nil {
+			rc.log.Errorf("recovery: decode or validation err:%v", err)
+			payload.NewErrorResponse(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if err := rc.service.Set(r.Context(), key); err != nil {
+			rc.log.Errorf("recovery: set key err: %v", err)
+			recoveryErrorResponse(w, "recovery: set key err", err)
+		}
+	}
+}
+
+// vaultKey gives the vault key wrapped with the recovery key, the client
+// opens it with the key and wraps it with the new password.
+func (rc *recovery) vaultKey() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Body == nil {
+			payload.NewErrorResponse(w, "recover: body is empty", http.StatusBadRequest)
+			return
+		}
+
+		defer func() {
+			if err := r.Body.Close(); err != nil {
+				rc.log.Errorf("recover: proof in body close err :%v", err)
+			}
+		}()
+
+		var proof models.RecoveryProof
+		if err := decodeAndValid(r, rc.valid, &proof); err != nil {
+			rc.log.Errorf("recover: decode or validation err:%v", err)
+			payload.NewErrorResponse(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		key, err := rc.service.VaultKey(r.Context(), proof)
+		if err != nil {
+			rc.log.Errorf("recover: get vault key err: %v", err)
+			recoveryErrorResponse(w, "recover: get vault key err", err)
+			return
+		}
+		rc.encode(w, "recover", key)
+	}
+}
+
+func (rc *recovery) recover() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Body == nil {
+			payload.NewErrorResponse(w, "recover: body is empty", http.StatusBadRequest)
+			return
+		}
+
+		defer func() {
+			if err := r.Body.Close(); err != nil {
+				rc.log.Errorf("recover: recover in body close err :%v", err)
+			}
+		}()
+
+		var rec models.Recover
+		if err := decodeAndValid(r, rc.valid, &rec); err != nil {
+			rc.log.Errorf("recover: decode or validation err:%v", err)
+			payload.NewErrorResponse(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if err := rc.service.Recover(r.Context(), rec); err != nil {
+			rc.log.Errorf("recover: recover err: %v", err)
+			recoveryErrorResponse(w, "recover: recover err", err)
+		}
+	}
+}
+
+func (rc *recovery) encode(w http.ResponseWriter, message string, v any) {
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		rc.log.Errorf("%s: encode err %v", message, err)
+		payload.NewErrorResponse(w, message+": encode err", http.StatusInternalServerError)
+	}
+}
+
+func recoveryErrorResponse(w http.ResponseWriter, message string, err error) {
+	switch {
+	case errors.Is(err, services.ErrRecoveryKey):
+		payload.NewErrorResponse(w, message+": "+services.ErrRecoveryKey.Error(), http.StatusForbidden)
+	case errors.Is(err, repository.ErrNotFound):
+		payload.NewErrorResponse(w, message+": not found", http.StatusNotFound)
+	default:
+		payload.NewErrorResponse(w, message, http.StatusInternalServerError)
+	}
+}
+
+// createRoutes are the routes of the users that lost the password.
+func (rc *recovery) createRoutes() http.Handler {
+	router := chi.NewRouter()
+	router.Route("/", func(r chi.Router) {
+		r.Post("/", rc.recover())
+		r.Post("/key", rc.vaultKey())
+	})
+	return router
+}
+
+func (rc *recovery) createKeyRoutes() http.Handler {
+	router := chi.NewRouter()
+	router.Route("/", func(r chi.Router) {
+		r.Get("/", rc.state())
+		r.Put("/", rc.set())
+	})
+	return router
+}
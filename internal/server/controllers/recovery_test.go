@@ -0,0 +1,80 @@
+package controllers
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/zelas91/goph-keeper/internal/logger"
+	mock2 "github.com/zelas91/goph-keeper/internal/server/controllers/mocks"
+	"github.com/zelas91/goph-keeper/internal/server/models"
+	"github.com/zelas91/goph-keeper/internal/server/services"
+)
+
+func TestRecover(t *testing.T) {
+	tests := []struct {
+		name                        string
+		body                        string
+		want                        int
+		mockBehaviorRecoveryService func(s *mock2.MockrecoveryService)
+	}{
+		{
+			name: "#1 ok",
+			body: `{"login":"test","key":"proof","password":"12345678","vault_key":"wrapped",
+				"next":{"key":"next","vault_key":"wrapped next"}}`,
+			want: http.StatusOK,
+			mockBehaviorRecoveryService: func(s *mock2.MockrecoveryService) {
+				s.EXPECT().Recover(gomock.Any(), models.Recover{
+					RecoveryProof: models.RecoveryProof{Login: "test", Key: "proof"},
+					Password:      "12345678",
+					VaultKey:      "wrapped",
+					Next:          models.RecoveryKey{Key: "next", VaultKey: "wrapped next"},
+				}).Return(nil)
+			},
+		},
+		{
+			name: "#2 nok no next recovery key",
+			body: `{"login":"test","key":"proof","password":"12345678","vault_key":"wrapped"}`,
+			want: http.StatusBadRequest,
+		},
+		{
+			name: "#3 nok short password",
+			body: `{"login":"test","key":"proof","password":"1234","vault_key":"wrapped",
+				"next":{"key":"next","vault_key":"wrapped next"}}`,
+			want: http.StatusBadRequest,
+		},
+		{
+			name: "#4 nok wrong key",
+			body: `{"login":"test","key":"proof","password":"12345678","vault_key":"wrapped",
+				"next":{"key":"next","vault_key":"wrapped next"}}`,
+			want: http.StatusForbidden,
+			mockBehaviorRecoveryService: func(s *mock2.MockrecoveryService) {
+				s.EXPECT().Recover(gomock.Any(), gomock.Any()).Return(services.ErrRecoveryKey)
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			service := mock2.NewMockrecoveryService(ctrl)
+			if test.mockBehaviorRecoveryService != nil {
+				test.mockBehaviorRecoveryService(service)
+			}
+
+			handler := New(logger.New(""), WithRecoveryUseService(service))
+
+			request := httptest.NewRequest(http.MethodPost, "/", bytes.NewBufferString(test.body))
+			w := httptest.NewRecorder()
+			handler.recovery.createRoutes().ServeHTTP(w, request)
+			res := w.Result()
+			defer res.Body.Close()
+
+			assert.Equal(t, test.want, res.StatusCode)
+		})
+	}
+}
@@ -21,8 +21,22 @@ type Controllers struct {
 	auth       *auth
 	card       *сreditCard
 	credential *credential
+	otp        *otp
+	sshKey     *sshKey
 	textData   *textData
 	binary     *binaryFile
+	folder     *folder
+	tag        *tag
+	search     *search
+	sync       *syncer
+	events     *events
+	upload     *uploadSession
+	usage      *usage
+	share      *share
+	org        *organization
+	emergency  *emergency
+	link       *shareLink
+	recovery   *recovery
 	log        logger.Logger
 	valid      *validator.Validate
 }
@@ -56,6 +70,18 @@ func WithUserCredentialUseService(cs credentialService) func(c *Controllers) {
 	}
 }
 
+func WithOTPUseService(os otpService) func(c *Controllers) {
+	return func(c *Controllers) {
+		c.otp = &otp{service: os, valid: c.valid, log: c.log}
+	}
+}
+
+func WithSSHKeyUseService(ss sshKeyService) func(c *Controllers) {
+	return func(c *Controllers) {
+		c.sshKey = &sshKey{service: ss, valid: c.valid, log: c.log}
+	}
+}
+
 func WithTextUseService(td textDataService) func(c *Controllers) {
 	return func(c *Controllers) {
 		c.textData = &textData{service: td, valid: c.valid, log: c.log}
@@ -67,6 +93,79 @@ func WithBinaryFileUseService(bs binaryFileService) func(c *Controllers) {
 		c.binary = &binaryFile{service: bs, valid: c.valid, log: c.log}
 	}
 }
+
+func WithFolderUseService(fs folderService) func(c *Controllers) {
+	return func(c *Controllers) {
+		c.folder = &folder{service: fs, valid: c.valid, log: c.log}
+	}
+}
+
+func WithTagUseService(ts tagService) func(c *Controllers) {
+	return func(c *Controllers) {
+		c.tag = &tag{service: ts, valid: c.valid, log: c.log}
+	}
+}
+
+func WithSearchUseService(ss searchService) func(c *Controllers) {
+	return func(c *Controllers) {
+		c.search = &search{service: ss, valid: c.valid, log: c.log}
+	}
+}
+
+func WithSyncUseService(ss syncService) func(c *Controllers) {
+	return func(c *Controllers) {
+		c.sync = &syncer{service: ss, valid: c.valid, log: c.log}
+	}
+}
+
+func WithEventsUseService(es eventService) func(c *Controllers) {
+	return func(c *Controllers) {
+		c.events = &events{service: es, valid: c.valid, log: c.log}
+	}
+}
+
+func WithUploadUseService(us uploadService) func(c *Controllers) {
+	return func(c *Controllers) {
+		c.upload = &uploadSession{service: us, valid: c.valid, log: c.log}
+	}
+}
+
+func WithUsageUseService(us usageService) func(c *Controllers) {
+	return func(c *Controllers) {
+		c.usage = &usage{service: us, valid: c.valid, log: c.log}
+	}
+}
+
+func WithShareUseService(ss shareService) func(c *Controllers) {
+	return func(c *Controllers) {
+		c.share = &share{service: ss, valid: c.valid, log: c.log}
+	}
+}
+
+func WithOrganizationUseService(os organizationService) func(c *Controllers) {
+	return func(c *Controllers) {
+		c.org = &organization{service: os, valid: c.valid, log: c.log}
+	}
+}
+
+func WithEmergencyUseService(es emergencyService) func(c *Controllers) {
+	return func(c *Controllers) {
+		c.emergency = &emergency{service: es, valid: c.valid, log: c.log}
+	}
+}
+
+func WithShareLinkUseService(ls shareLinkService) func(c *Controllers) {
+	return func(c *Controllers) {
+		c.link = &shareLink{service: ls, valid: c.valid, log: c.log}
+	}
+}
+
+func WithRecoveryUseService(rs recoveryService) func(c *Controllers) {
+	return func(c *Controllers) {
+		c.recovery = &recovery{service: rs, valid: c.valid, log: c.log}
+	}
+}
+
 func listFilesHandler(w http.ResponseWriter, r *http.Request) {
 	files, err := os.ReadDir(clientAppDir)
 	if err != nil {
@@ -108,17 +207,36 @@ func (c *Controllers) CreateRoutes() http.Handler {
 	router := chi.NewRouter()
 	router.Get("/files/client", listFilesHandler)
 	router.Get("/files/client/{fileName}", downloadFileHandler)
+	router.Get("/s/{id}", c.link.page())
 	router.Route("/api", func(r chi.Router) {
 		r.Use(middleware.ContentTypeJSON(c.log), middleware2.Recoverer)
 		r.Mount("/", c.auth.createRoutes())
+		r.Mount("/recover", c.recovery.createRoutes())
+		r.Mount("/share-link", c.link.createRoutes(
+			middleware.AuthorizationHandler(c.log, c.auth.service), middleware.ClientID))
 		r.Group(func(r chi.Router) {
-			r.Use(middleware.AuthorizationHandler(c.log, c.auth.service))
+			r.Use(middleware.AuthorizationHandler(c.log, c.auth.service), middleware.ClientID)
 			r.Group(func(r chi.Router) {
+				r.Use(middleware.OrgVault(c.log, c.org), middleware.EmergencyVault(c.log, c.emergency))
 				r.Mount("/card", c.card.createRoutes())
 				r.Mount("/credential", c.credential.createRoutes())
+				r.Mount("/otp", c.otp.createRoutes())
+				r.Mount("/ssh", c.sshKey.createRoutes())
 				r.Mount("/text", c.textData.createRoutes())
+				r.Mount("/file/uploads", c.upload.createRoutes())
 				r.Mount("/file", c.binary.createRoutes())
+				r.Mount("/folder", c.folder.createRoutes())
+				r.Mount("/tag", c.tag.createRoutes())
+				r.Mount("/search", c.search.createRoutes())
+				r.Mount("/sync", c.sync.createRoutes())
+				r.Mount("/events", c.events.createRoutes())
+				r.Mount("/user", c.usage.createRoutes())
 			})
+			r.Mount("/shares", c.share.createRoutes())
+			r.Mount("/keys", c.share.createKeyRoutes())
+			r.Mount("/org", c.org.createRoutes())
+			r.Mount("/emergency", c.emergency.createRoutes())
+			r.Mount("/recovery", c.recovery.createKeyRoutes())
 		})
 	})
 	return router
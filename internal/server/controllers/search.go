@@ -0,0 +1,54 @@
+package controllers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-playground/validator/v10"
+	"github.com/zelas91/goph-keeper/internal/logger"
+	"github.com/zelas91/goph-keeper/internal/server/models"
+	"github.com/zelas91/goph-keeper/internal/server/payload"
+	"github.com/zelas91/goph-keeper/internal/server/services"
+	"golang.org/x/net/context"
+)
+
+type search struct {
+	service searchService
+	valid   *validator.Validate
+	log     logger.Logger
+}
+
+//go:generate mockgen -package mocks -destination=./mocks/mock_search_service.go -source=search.go -package=mock
+type searchService interface {
+	Search(ctx context.Context, query string) ([]models.SearchResult, error)
+}
+
+func (s *search) search() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		results, err := s.service.Search(r.Context(), r.URL.Query().Get("q"))
+		if err != nil {
+			if errors.Is(err, services.ErrSearchQuery) {
+				payload.NewErrorResponse(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			s.log.Errorf("search: search err %v", err)
+			payload.NewErrorResponse(w, "search: search err", http.StatusInternalServerError)
+			return
+		}
+		if err = json.NewEncoder(w).Encode(results); err != nil {
+			s.log.Errorf("search: encode err %v", err)
+			payload.NewErrorResponse(w, "search: encode err", http.StatusInternalServerError)
+			return
+		}
+	}
+}
+
+func (s *search) createRoutes() http.Handler {
+	router := chi.NewRouter()
+	router.Route("/", func(r chi.Router) {
+		r.Get("/", s.search())
+	})
+	return router
+}
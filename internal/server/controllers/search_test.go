@@ -0,0 +1,78 @@
+package controllers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/zelas91/goph-keeper/internal/logger"
+	mock2 "github.com/zelas91/goph-keeper/internal/server/controllers/mocks"
+	"github.com/zelas91/goph-keeper/internal/server/models"
+	"github.com/zelas91/goph-keeper/internal/server/services"
+)
+
+func TestSearch(t *testing.T) {
+	tests := []struct {
+		name                      string
+		url                       string
+		want                      int
+		wantResults               []models.SearchResult
+		mockBehaviorSearchService func(s *mock2.MocksearchService)
+	}{
+		{
+			name:        "#1 ok search",
+			url:         "/?q=aws",
+			want:        http.StatusOK,
+			wantResults: []models.SearchResult{{ItemType: "credential", ItemID: 3, Title: "AWS"}},
+			mockBehaviorSearchService: func(s *mock2.MocksearchService) {
+				s.EXPECT().Search(gomock.Any(), "aws").
+					Return([]models.SearchResult{{ItemType: "credential", ItemID: 3, Title: "AWS"}}, nil)
+			},
+		},
+		{
+			name: "#2 nok short query",
+			url:  "/?q=a",
+			want: http.StatusBadRequest,
+			mockBehaviorSearchService: func(s *mock2.MocksearchService) {
+				s.EXPECT().Search(gomock.Any(), "a").Return(nil, services.ErrSearchQuery)
+			},
+		},
+		{
+			name: "#3 nok search err",
+			url:  "/?q=aws",
+			want: http.StatusInternalServerError,
+			mockBehaviorSearchService: func(s *mock2.MocksearchService) {
+				s.EXPECT().Search(gomock.Any(), "aws").Return(nil, fmt.Errorf("search err: %w", errors.New("repo err")))
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			service := mock2.NewMocksearchService(ctrl)
+			test.mockBehaviorSearchService(service)
+
+			handler := New(logger.New(""), WithSearchUseService(service))
+
+			request := httptest.NewRequest(http.MethodGet, test.url, nil)
+			w := httptest.NewRecorder()
+			handler.search.createRoutes().ServeHTTP(w, request)
+			res := w.Result()
+			defer res.Body.Close()
+
+			assert.Equal(t, test.want, res.StatusCode)
+			if test.wantResults != nil {
+				var results []models.SearchResult
+				assert.NoError(t, json.NewDecoder(res.Body).Decode(&results))
+				assert.Equal(t, test.wantResults, results)
+			}
+		})
+	}
+}
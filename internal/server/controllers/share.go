@@ -0,0 +1,196 @@
+package controllers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-playground/validator/v10"
+	"github.com/zelas91/goph-keeper/internal/logger"
+	"github.com/zelas91/goph-keeper/internal/server/helper"
+	"github.com/zelas91/goph-keeper/internal/server/models"
+	"github.com/zelas91/goph-keeper/internal/server/payload"
+	"github.com/zelas91/goph-keeper/internal/server/repository"
+	"github.com/zelas91/goph-keeper/internal/server/services"
+	"golang.org/x/net/context"
+)
+
+type share struct {
+	service shareService
+	valid   *validator.Validate
+	log     logger.Logger
+}
+
+//go:generate mockgen -package mocks -destination=./mocks/mock_share_service.go -source=share.go -package=mock
+type shareService interface {
+	Create(ctx context.Context, sh models.Share) (models.Share, error)
+	Shares(ctx context.Context) ([]models.Share, error)
+	Incoming(ctx context.Context) (models.SharedItems, error)
+	Delete(ctx context.Context, shareID int) error
+	Keys(ctx context.Context) (models.UserKeys, error)
+	SetKeys(ctx context.Context, keys models.UserKeys) error
+	PublicKey(ctx context.Context, login string) (models.UserKeys, error)
+}
+
+func (s *share) shares() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		shares, err := s.service.Shares(r.Context())
+		if err != nil {
+			s.log.Errorf("shares: get shares err %v", err)
+			payload.NewErrorResponse(w, "shares: get shares err", http.StatusInternalServerError)
+			return
+		}
+		s.encode(w, "shares", shares)
+	}
+}
+
+func (s *share) incoming() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		items, err := s.service.Incoming(r.Context())
+		if err != nil {
+			s.log.Errorf("incoming: get shared items err %v", err)
+			payload.NewErrorResponse(w, "incoming: get shared items err", http.StatusInternalServerError)
+			return
+		}
+		s.encode(w, "incoming", items)
+	}
+}
+
+func (s *share) create() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Body == nil {
+			payload.NewErrorResponse(w, "create: body is empty", http.StatusBadRequest)
+			return
+		}
+
+		defer func() {
+			if err := r.Body.Close(); err != nil {
+				s.log.Errorf("create: share in body close err :%v", err)
+			}
+		}()
+
+		var sh models.Share
+		if err := decodeAndValid(r, s.valid, &sh); err != nil {
+			s.log.Errorf("create: decode or validation err:%v", err)
+			payload.NewErrorResponse(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		sh, err := s.service.Create(r.Context(), sh)
+		if err != nil {
+			s.log.Errorf("create: share save err: %v", err)
+			shareErrorResponse(w, "create: share save err", err)
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+		s.encode(w, "create", sh)
+	}
+}
+
+func (s *share) delete() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := helper.IDFromContext(r.Context())
+		if err != nil {
+			s.log.Errorf("delete: get id share err: %v", err)
+			payload.NewErrorResponse(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if err = s.service.Delete(r.Context(), id); err != nil {
+			s.log.Errorf("delete: share delete err: %v", err)
+			shareErrorResponse(w, "delete: share delete err", err)
+		}
+	}
+}
+
+func (s *share) keys() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		keys, err := s.service.Keys(r.Context())
+		if err != nil {
+			s.log.Errorf("keys: get keys err: %v", err)
+			shareErrorResponse(w, "keys: get keys err", err)
+			return
+		}
+		s.encode(w, "keys", keys)
+	}
+}
+
+func (s *share) setKeys() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Body == nil {
+			payload.NewErrorResponse(w, "keys: body is empty", http.StatusBadRequest)
+			return
+		}
+
+		defer func() {
+			if err := r.Body.Close(); err != nil {
+				s.log.Errorf("keys: keys in body close err :%v", err)
+			}
+		}()
+
+		var keys models.UserKeys
+		if err := decodeAndValid(r, s.valid, &keys); err != nil {
+			s.log.Errorf("keys: decode or validation err:%v", err)
+			payload.NewErrorResponse(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if err := s.service.SetKeys(r.Context(), keys); err != nil {
+			s.log.Errorf("keys: set keys err: %v", err)
+			shareErrorResponse(w, "keys: set keys err", err)
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+	}
+}
+
+func (s *share) publicKey() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		key, err := s.service.PublicKey(r.Context(), chi.URLParam(r, "login"))
+		if err != nil {
+			s.log.Errorf("public key: get key err: %v", err)
+			shareErrorResponse(w, "public key: get key err", err)
+			return
+		}
+		s.encode(w, "public key", key)
+	}
+}
+
+func (s *share) encode(w http.ResponseWriter, message string, v any) {
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		s.log.Errorf("%s: encode err %v", message, err)
+		payload.NewErrorResponse(w, message+": encode err", http.StatusInternalServerError)
+	}
+}
+
+func shareErrorResponse(w http.ResponseWriter, message string, err error) {
+	switch {
+	case errors.Is(err, repository.ErrNotFound):
+		payload.NewErrorResponse(w, message+": not found", http.StatusNotFound)
+	case errors.Is(err, repository.ErrKeysExist):
+		payload.NewErrorResponse(w, message+": "+repository.ErrKeysExist.Error(), http.StatusConflict)
+	case errors.Is(err, services.ErrShareFileKey):
+		payload.NewErrorResponse(w, message+": "+services.ErrShareFileKey.Error(), http.StatusBadRequest)
+	default:
+		payload.NewErrorResponse(w, message, http.StatusInternalServerError)
+	}
+}
+
+func (s *share) createRoutes() http.Handler {
+	router := chi.NewRouter()
+	router.Route("/", func(r chi.Router) {
+		r.Get("/", s.shares())
+		r.Get("/incoming", s.incoming())
+		r.Post("/", s.create())
+		r.Delete("/{id}", s.delete())
+	})
+	return router
+}
+
+func (s *share) createKeyRoutes() http.Handler {
+	router := chi.NewRouter()
+	router.Route("/", func(r chi.Router) {
+		r.Get("/", s.keys())
+		r.Put("/", s.setKeys())
+		r.Get("/{login}", s.publicKey())
+	})
+	return router
+}
@@ -0,0 +1,203 @@
+package controllers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-playground/validator/v10"
+	"github.com/zelas91/goph-keeper/internal/logger"
+	"github.com/zelas91/goph-keeper/internal/server/models"
+	"github.com/zelas91/goph-keeper/internal/server/payload"
+	"github.com/zelas91/goph-keeper/internal/server/repository"
+	"golang.org/x/net/context"
+)
+
+type shareLink struct {
+	service shareLinkService
+	valid   *validator.Validate
+	log     logger.Logger
+}
+
+//go:generate mockgen -package mocks -destination=./mocks/mock_share_link_service.go -source=share_link.go -package=mock
+type shareLinkService interface {
+	Create(ctx context.Context, nl models.NewShareLink, base string) (models.ShareLink, error)
+	Links(ctx context.Context) ([]models.ShareLink, error)
+	Delete(ctx context.Context, linkID string) error
+	Open(ctx context.Context, linkID string) (models.SealedSecret, error)
+}
+
+func (l *shareLink) links() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		links, err := l.service.Links(r.Context())
+		if err != nil {
+			l.log.Errorf("links: get links err %v", err)
+			payload.NewErrorResponse(w, "links: get links err", http.StatusInternalServerError)
+			return
+		}
+		l.encode(w, "links", links)
+	}
+}
+
+func (l *shareLink) create() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Body == nil {
+			payload.NewErrorResponse(w, "create: body is empty", http.StatusBadRequest)
+			return
+		}
+
+		defer func() {
+			if err := r.Body.Close(); err != nil {
+				l.log.Errorf("create: share link in body close err :%v", err)
+			}
+		}()
+
+		var nl models.NewShareLink
+		if err := decodeAndValid(r, l.valid, &nl); err != nil {
+			l.log.Errorf("create: decode or validation err:%v", err)
+			payload.NewErrorResponse(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		link, err := l.service.Create(r.Context(), nl, baseURL(r))
+		if err != nil {
+			l.log.Errorf("create: share link save err: %v", err)
+			linkErrorResponse(w, "create: share link save err", err)
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+		l.encode(w, "create", link)
+	}
+}
+
+func (l *shareLink) delete() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if err := l.service.Delete(r.Context(), chi.URLParam(r, "id")); err != nil {
+			l.log.Errorf("delete: share link delete err: %v", err)
+			linkErrorResponse(w, "delete: share link delete err", err)
+		}
+	}
+}
+
+// open gives the sealed secret to anybody with the id of the link. It is
+// a POST for the previews of the chats and mails not to spend the views.
+func (l *shareLink) open() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		secret, err := l.service.Open(r.Context(), chi.URLParam(r, "id"))
+		if err != nil {
+			l.log.Errorf("open: share link open err: %v", err)
+			linkErrorResponse(w, "open: share link open err", err)
+			return
+		}
+		l.encode(w, "open", secret)
+	}
+}
+
+// page is the page of the link for the browsers, it opens the link and
+// decrypts the secret with the key in the fragment of the address.
+func (l *shareLink) page() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Header().Set("Cache-Control", "no-store")
+		w.Header().Set("Referrer-Policy", "no-referrer")
+		if _, err := w.Write([]byte(linkPage)); err != nil {
+			l.log.Errorf("page: write err %v", err)
+		}
+	}
+}
+
+func (l *shareLink) encode(w http.ResponseWriter, message string, v any) {
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		l.log.Errorf("%s: encode err %v", message, err)
+		payload.NewErrorResponse(w, message+": encode err", http.StatusInternalServerError)
+	}
+}
+
+// baseURL is the address of the server the client asked, behind a proxy
+// the proxy tells the scheme.
+func baseURL(r *http.Request) string {
+	scheme := "http"
+	if r.TLS != nil {
+		scheme = "https"
+	}
+	if proto := r.Header.Get("X-Forwarded-Proto"); proto == "http" || proto == "https" {
+		scheme = proto
+	}
+	return scheme + "://" + r.Host
+}
+
+func linkErrorResponse(w http.ResponseWriter, message string, err error) {
+	if errors.Is(err, repository.ErrNotFound) {
+		payload.NewErrorResponse(w, message+": not found, expired or opened", http.StatusNotFound)
+		return
+	}
+	payload.NewErrorResponse(w, message, http.StatusInternalServerError)
+}
+
+// createRoutes opens the links to anybody, the rest of the routes go
+// through the authorization middlewares.
+func (l *shareLink) createRoutes(authorization ...func(http.Handler) http.Handler) http.Handler {
+	router := chi.NewRouter()
+	router.Route("/", func(r chi.Router) {
+		r.Post("/{id}/open", l.open())
+		r.Group(func(r chi.Router) {
+			r.Use(authorization...)
+			r.Get("/", l.links())
+			r.Post("/", l.create())
+			r.Delete("/{id}", l.delete())
+		})
+	})
+	return router
+}
+
+const linkPage = `<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<meta name="robots" content="noindex">
+<title>GophKeeper secret</title>
+</head>
+<body>
+<h1>GophKeeper secret</h1>
+<p id="note">The link opens a limited number of times.</p>
+<button id="open">Show the secret</button>
+<pre id="secret"></pre>
+<script>
+function fromBase64(s) {
+  s = s.replace(/-/g, "+").replace(/_/g, "/");
+  while (s.length % 4) s += "=";
+  return Uint8Array.from(atob(s), c => c.charCodeAt(0));
+}
+document.getElementById("open").onclick = async () => {
+  const note = document.getElementById("note");
+  const id = location.pathname.split("/").pop();
+  const resp = await fetch("/api/share-link/" + encodeURIComponent(id) + "/open", {
+    method: "POST", headers: {"Content-Type": "application/json"}
+  });
+  if (!resp.ok) {
+    note.textContent = "The link is expired or was opened already.";
+    return;
+  }
+  try {
+    const sealed = await resp.json();
+    const data = fromBase64(sealed.data);
+    const key = await crypto.subtle.importKey("raw", fromBase64(location.hash.slice(1)),
+      "AES-GCM", false, ["decrypt"]);
+    const plain = await crypto.subtle.decrypt({name: "AES-GCM", iv: data.slice(0, 12)}, key, data.slice(12));
+    const secret = JSON.parse(new TextDecoder().decode(plain));
+    const lines = [];
+    if (secret.title) lines.push("Title: " + secret.title);
+    if (secret.login) lines.push("Login: " + secret.login);
+    if (secret.password) lines.push("Password: " + secret.password);
+    if (secret.text) lines.push(secret.text);
+    document.getElementById("secret").textContent = lines.join("\n");
+    note.textContent = "Keep the secret, the link may not open again.";
+  } catch (e) {
+    note.textContent = "The key of the link is wrong.";
+  }
+  document.getElementById("open").remove();
+};
+</script>
+</body>
+</html>
+`
@@ -0,0 +1,93 @@
+package controllers
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/zelas91/goph-keeper/internal/logger"
+	mock2 "github.com/zelas91/goph-keeper/internal/server/controllers/mocks"
+	"github.com/zelas91/goph-keeper/internal/server/models"
+	"github.com/zelas91/goph-keeper/internal/server/repository"
+)
+
+func TestCreateShareLink(t *testing.T) {
+	tests := []struct {
+		name                         string
+		body                         string
+		want                         int
+		mockBehaviorShareLinkService func(s *mock2.MockshareLinkService)
+	}{
+		{
+			name: "#1 ok text",
+			body: `{"text":"secret","max_views":1,"expires_in":3600}`,
+			want: http.StatusCreated,
+			mockBehaviorShareLinkService: func(s *mock2.MockshareLinkService) {
+				s.EXPECT().Create(gomock.Any(), models.NewShareLink{Text: "secret", MaxViews: 1, ExpiresIn: 3600},
+					"https://keeper.example").Return(models.ShareLink{ID: "ab"}, nil)
+			},
+		},
+		{
+			name: "#2 nok item without id",
+			body: `{"item_type":"text","expires_in":3600}`,
+			want: http.StatusBadRequest,
+		},
+		{
+			name: "#3 nok no expiry",
+			body: `{"text":"secret"}`,
+			want: http.StatusBadRequest,
+		},
+		{
+			name: "#4 nok item not found",
+			body: `{"item_type":"credential","item_id":4,"expires_in":60}`,
+			want: http.StatusNotFound,
+			mockBehaviorShareLinkService: func(s *mock2.MockshareLinkService) {
+				s.EXPECT().Create(gomock.Any(), gomock.Any(), gomock.Any()).
+					Return(models.ShareLink{}, repository.ErrNotFound)
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			service := mock2.NewMockshareLinkService(ctrl)
+			if test.mockBehaviorShareLinkService != nil {
+				test.mockBehaviorShareLinkService(service)
+			}
+
+			handler := New(logger.New(""), WithShareLinkUseService(service))
+
+			request := httptest.NewRequest(http.MethodPost, "http://keeper.example/", bytes.NewBufferString(test.body))
+			request.Header.Set("X-Forwarded-Proto", "https")
+			w := httptest.NewRecorder()
+			handler.link.createRoutes().ServeHTTP(w, request)
+			res := w.Result()
+			defer res.Body.Close()
+
+			assert.Equal(t, test.want, res.StatusCode)
+		})
+	}
+}
+
+func TestOpenShareLink(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	service := mock2.NewMockshareLinkService(ctrl)
+	service.EXPECT().Open(gomock.Any(), "ab").Return(models.SealedSecret{}, repository.ErrNotFound)
+
+	handler := New(logger.New(""), WithShareLinkUseService(service))
+
+	request := httptest.NewRequest(http.MethodPost, "/ab/open", nil)
+	w := httptest.NewRecorder()
+	handler.link.createRoutes().ServeHTTP(w, request)
+	res := w.Result()
+	defer res.Body.Close()
+
+	assert.Equal(t, http.StatusNotFound, res.StatusCode)
+}
@@ -0,0 +1,129 @@
+package controllers
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/zelas91/goph-keeper/internal/logger"
+	mock2 "github.com/zelas91/goph-keeper/internal/server/controllers/mocks"
+	"github.com/zelas91/goph-keeper/internal/server/models"
+	"github.com/zelas91/goph-keeper/internal/server/repository"
+	"github.com/zelas91/goph-keeper/internal/server/services"
+)
+
+func TestCreateShare(t *testing.T) {
+	tests := []struct {
+		name                     string
+		body                     string
+		want                     int
+		mockBehaviorShareService func(s *mock2.MockshareService)
+	}{
+		{
+			name: "#1 ok create",
+			body: `{"recipient":"bob","item_type":"card","item_id":3,"permission":"read"}`,
+			want: http.StatusCreated,
+			mockBehaviorShareService: func(s *mock2.MockshareService) {
+				s.EXPECT().Create(gomock.Any(), models.Share{Recipient: "bob", ItemType: "card", ItemID: 3,
+					Permission: models.PermissionRead}).Return(models.Share{ID: 1}, nil)
+			},
+		},
+		{
+			name: "#2 nok unknown permission",
+			body: `{"recipient":"bob","item_type":"card","item_id":3,"permission":"admin"}`,
+			want: http.StatusBadRequest,
+		},
+		{
+			name: "#3 nok no such item or recipient",
+			body: `{"recipient":"bob","item_type":"text","item_id":3,"permission":"write"}`,
+			want: http.StatusNotFound,
+			mockBehaviorShareService: func(s *mock2.MockshareService) {
+				s.EXPECT().Create(gomock.Any(), gomock.Any()).Return(models.Share{}, repository.ErrNotFound)
+			},
+		},
+		{
+			name: "#4 nok file key not wrapped for the recipient",
+			body: `{"recipient":"bob","item_type":"file","item_id":3,"permission":"read"}`,
+			want: http.StatusBadRequest,
+			mockBehaviorShareService: func(s *mock2.MockshareService) {
+				s.EXPECT().Create(gomock.Any(), gomock.Any()).Return(models.Share{}, services.ErrShareFileKey)
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			service := mock2.NewMockshareService(ctrl)
+			if test.mockBehaviorShareService != nil {
+				test.mockBehaviorShareService(service)
+			}
+
+			handler := New(logger.New(""), WithShareUseService(service))
+
+			request := httptest.NewRequest(http.MethodPost, "/", bytes.NewBufferString(test.body))
+			w := httptest.NewRecorder()
+			handler.share.createRoutes().ServeHTTP(w, request)
+			res := w.Result()
+			defer res.Body.Close()
+
+			assert.Equal(t, test.want, res.StatusCode)
+		})
+	}
+}
+
+func TestSetKeys(t *testing.T) {
+	tests := []struct {
+		name                     string
+		body                     string
+		want                     int
+		mockBehaviorShareService func(s *mock2.MockshareService)
+	}{
+		{
+			name: "#1 ok set keys",
+			body: `{"public_key":"cHVi","private_key":"cHJpdg=="}`,
+			want: http.StatusCreated,
+			mockBehaviorShareService: func(s *mock2.MockshareService) {
+				s.EXPECT().SetKeys(gomock.Any(), models.UserKeys{PublicKey: "cHVi", PrivateKey: "cHJpdg=="}).Return(nil)
+			},
+		},
+		{
+			name: "#2 nok no private key",
+			body: `{"public_key":"cHVi"}`,
+			want: http.StatusBadRequest,
+		},
+		{
+			name: "#3 nok keys set before",
+			body: `{"public_key":"cHVi","private_key":"cHJpdg=="}`,
+			want: http.StatusConflict,
+			mockBehaviorShareService: func(s *mock2.MockshareService) {
+				s.EXPECT().SetKeys(gomock.Any(), gomock.Any()).Return(repository.ErrKeysExist)
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			service := mock2.NewMockshareService(ctrl)
+			if test.mockBehaviorShareService != nil {
+				test.mockBehaviorShareService(service)
+			}
+
+			handler := New(logger.New(""), WithShareUseService(service))
+
+			request := httptest.NewRequest(http.MethodPut, "/", bytes.NewBufferString(test.body))
+			w := httptest.NewRecorder()
+			handler.share.createKeyRoutes().ServeHTTP(w, request)
+			res := w.Result()
+			defer res.Body.Close()
+
+			assert.Equal(t, test.want, res.StatusCode)
+		})
+	}
+}
@@ -0,0 +1,197 @@
+package controllers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-playground/validator/v10"
+	"github.com/zelas91/goph-keeper/internal/logger"
+	"github.com/zelas91/goph-keeper/internal/server/helper"
+	"github.com/zelas91/goph-keeper/internal/server/models"
+	"github.com/zelas91/goph-keeper/internal/server/payload"
+	"github.com/zelas91/goph-keeper/internal/utils/sshkey"
+	"golang.org/x/net/context"
+)
+
+type sshKey struct {
+	service sshKeyService
+	valid   *validator.Validate
+	log     logger.Logger
+}
+
+//go:generate mockgen -package mocks -destination=./mocks/mock_ssh_key_service.go -source=ssh_key.go -package=mock
+type sshKeyService interface {
+	Create(ctx context.Context, key models.SSHKey) error
+	SSHKeys(ctx context.Context, filter models.ItemFilter) ([]models.SSHKey, string, error)
+	SSHKey(ctx context.Context, sshKeyID int) (models.SSHKey, error)
+	Delete(ctx context.Context, sshKeyID int) error
+	Update(ctx context.Context, key models.SSHKey) error
+}
+
+func (s *sshKey) sshKeys() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		filter, err := helper.ItemFilterFromRequest(r)
+		if err != nil {
+			s.log.Errorf("ssh keys: filter from request err: %v", err)
+			payload.NewErrorResponse(w, "ssh keys: filter from request err", http.StatusBadRequest)
+			return
+		}
+		keys, next, err := s.service.SSHKeys(r.Context(), filter)
+		if err != nil {
+			s.log.Errorf("ssh keys: get ssh keys err %v", err)
+			payload.NewErrorResponse(w, "ssh keys: get ssh keys err", http.StatusInternalServerError)
+			return
+		}
+		if err = json.NewEncoder(w).Encode(models.Page[models.SSHKey]{Items: keys, NextCursor: next}); err != nil {
+			s.log.Errorf("ssh keys: encode err %v", err)
+			payload.NewErrorResponse(w, "ssh keys: encode err", http.StatusInternalServerError)
+			return
+		}
+	}
+}
+
+func (s *sshKey) sshKey() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := helper.IDFromContext(r.Context())
+		if err != nil {
+			s.log.Errorf("ssh key: get id from request err: %v", err)
+			payload.NewErrorResponse(w, "ssh key: get id from request err", http.StatusBadRequest)
+			return
+		}
+		key, err := s.service.SSHKey(r.Context(), id)
+		if err != nil {
+			s.log.Errorf("ssh key: get ssh key err: %v", err)
+			payload.NewErrorResponse(w, "ssh key: get ssh key err", http.StatusNotFound)
+			return
+		}
+		if err = json.NewEncoder(w).Encode(key); err != nil {
+			s.log.Errorf("ssh key: encode err %v", err)
+			payload.NewErrorResponse(w, "ssh key: encode err", http.StatusInternalServerError)
+			return
+		}
+	}
+}
+
+func (s *sshKey) create() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Body == nil {
+			payload.NewErrorResponse(w, "create: body is empty", http.StatusBadRequest)
+			return
+		}
+
+		defer func() {
+			if err := r.Body.Close(); err != nil {
+				s.log.Errorf("create: ssh key in body close err :%v", err)
+			}
+		}()
+
+		key, err := s.fromRequestAndValid(r)
+		if err != nil {
+			s.log.Errorf("create: decode or validation err:%v", err)
+			payload.NewErrorResponse(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		if err = s.service.Create(r.Context(), key); err != nil {
+			s.log.Errorf("create: ssh key save err: %v", err)
+			createErrorResponse(w, "create: ssh key save err", err)
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+	}
+}
+
+func (s *sshKey) update() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Body == nil {
+			payload.NewErrorResponse(w, "update: body is empty", http.StatusBadRequest)
+			return
+		}
+
+		defer func() {
+			if err := r.Body.Close(); err != nil {
+				s.log.Errorf("update: ssh key in body close err :%v", err)
+			}
+		}()
+
+		id, err := helper.IDFromContext(r.Context())
+		if err != nil {
+			s.log.Errorf("update: get id ssh key err: %v", err)
+			payload.NewErrorResponse(w, "update: get id ssh key err:", http.StatusBadRequest)
+			return
+		}
+
+		key, err := s.fromRequestAndValid(r)
+		if err != nil {
+			s.log.Errorf("update: decode or validation err:%v", err)
+			payload.NewErrorResponse(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if key.Version == 0 {
+			s.log.Error("update: ssh key version == 0")
+			payload.NewErrorResponse(w, "update: ssh key version == 0", http.StatusBadRequest)
+			return
+		}
+		key.ID = id
+
+		if err = s.service.Update(r.Context(), key); err != nil {
+			s.log.Errorf("update: ssh key save err: %v", err)
+			updateErrorResponse(w, s.log, "update: ssh key save err", err, func() (any, error) {
+				return s.service.SSHKey(r.Context(), id)
+			})
+		}
+	}
+}
+
+func (s *sshKey) delete() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := helper.IDFromContext(r.Context())
+		if err != nil {
+			s.log.Errorf("get id ssh key err: %v", err)
+			payload.NewErrorResponse(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if err = s.service.Delete(r.Context(), id); err != nil {
+			s.log.Errorf("delete ssh key err: %v", err)
+			payload.NewErrorResponse(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	}
+}
+
+// fromRequestAndValid checks that the private key parses and opens with
+// the passphrase, the type, the public key and the fingerprint are taken
+// from it whatever the request says.
+func (s *sshKey) fromRequestAndValid(r *http.Request) (key models.SSHKey, err error) {
+	if err = json.NewDecoder(r.Body).Decode(&key); err != nil {
+		return key, fmt.Errorf("ssh key json decode err:%v", err)
+	}
+	if err = s.valid.Struct(key); err != nil {
+		return key, fmt.Errorf("ssh key validate err: %v", err)
+	}
+	parsed, err := sshkey.Parse(key.PrivateKey, key.Passphrase, key.Comment)
+	if err != nil {
+		return key, fmt.Errorf("ssh key validate err: %v", err)
+	}
+	key.KeyType, key.PublicKey, key.Fingerprint = parsed.Type, parsed.PublicKey, parsed.Fingerprint
+	if key.Certificate != "" {
+		if err = sshkey.CheckCertificate(key.Certificate, key.PublicKey); err != nil {
+			return key, fmt.Errorf("ssh key validate err: %v", err)
+		}
+	}
+	return
+}
+
+func (s *sshKey) createRoutes() http.Handler {
+	router := chi.NewRouter()
+	router.Route("/", func(r chi.Router) {
+		r.Get("/", s.sshKeys())
+		r.Get("/{id}", s.sshKey())
+		r.Post("/", s.create())
+		r.Put("/{id}", s.update())
+		r.Delete("/{id}", s.delete())
+	})
+	return router
+}
@@ -0,0 +1,92 @@
+package controllers
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/json"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zelas91/goph-keeper/internal/logger"
+	mock2 "github.com/zelas91/goph-keeper/internal/server/controllers/mocks"
+	"github.com/zelas91/goph-keeper/internal/server/models"
+	"golang.org/x/crypto/ssh"
+)
+
+func TestCreateSSHKey(t *testing.T) {
+	public, private, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	sshPublic, err := ssh.NewPublicKey(public)
+	require.NoError(t, err)
+	block, err := ssh.MarshalPrivateKeyWithPassphrase(private, "", []byte("secret"))
+	require.NoError(t, err)
+	privateKey := string(pem.EncodeToMemory(block))
+	body := func(key models.SSHKey) string {
+		data, err := json.Marshal(key)
+		require.NoError(t, err)
+		return string(data)
+	}
+	authorized := strings.TrimSpace(string(ssh.MarshalAuthorizedKey(sshPublic)))
+
+	tests := []struct {
+		name                      string
+		body                      string
+		want                      int
+		mockBehaviorSSHKeyService func(s *mock2.MocksshKeyService)
+	}{
+		{
+			name: "#1 ok public key and fingerprint of the private key",
+			body: body(models.SSHKey{PrivateKey: privateKey, Passphrase: "secret", Comment: "john@host",
+				PublicKey: "ssh-ed25519 forged", Fingerprint: "SHA256:forged", Title: "prod"}),
+			want: http.StatusCreated,
+			mockBehaviorSSHKeyService: func(s *mock2.MocksshKeyService) {
+				s.EXPECT().Create(gomock.Any(), models.SSHKey{KeyType: ssh.KeyAlgoED25519, PrivateKey: privateKey,
+					PublicKey: authorized + " john@host", Fingerprint: ssh.FingerprintSHA256(sshPublic),
+					Comment: "john@host", Passphrase: "secret", Title: "prod"}).Return(nil)
+			},
+		},
+		{
+			name: "#2 nok wrong passphrase",
+			body: body(models.SSHKey{PrivateKey: privateKey, Passphrase: "guess"}),
+			want: http.StatusBadRequest,
+		},
+		{
+			name: "#3 nok not a private key",
+			body: body(models.SSHKey{PrivateKey: authorized}),
+			want: http.StatusBadRequest,
+		},
+		{
+			name: "#4 nok certificate of another key",
+			body: body(models.SSHKey{PrivateKey: privateKey, Passphrase: "secret", Certificate: authorized}),
+			want: http.StatusBadRequest,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			service := mock2.NewMocksshKeyService(ctrl)
+			if test.mockBehaviorSSHKeyService != nil {
+				test.mockBehaviorSSHKeyService(service)
+			}
+
+			handler := New(logger.New(""), WithSSHKeyUseService(service))
+
+			request := httptest.NewRequest(http.MethodPost, "/", bytes.NewBufferString(test.body))
+			w := httptest.NewRecorder()
+			handler.sshKey.createRoutes().ServeHTTP(w, request)
+			res := w.Result()
+			defer res.Body.Close()
+
+			assert.Equal(t, test.want, res.StatusCode)
+		})
+	}
+}
@@ -0,0 +1,54 @@
+package controllers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-playground/validator/v10"
+	"github.com/zelas91/goph-keeper/internal/logger"
+	"github.com/zelas91/goph-keeper/internal/server/models"
+	"github.com/zelas91/goph-keeper/internal/server/payload"
+	"github.com/zelas91/goph-keeper/internal/server/services"
+	"golang.org/x/net/context"
+)
+
+type syncer struct {
+	service syncService
+	valid   *validator.Validate
+	log     logger.Logger
+}
+
+//go:generate mockgen -package mocks -destination=./mocks/mock_sync_service.go -source=sync.go -package=mock
+type syncService interface {
+	Changes(ctx context.Context, since string) (models.SyncChanges, error)
+}
+
+func (s *syncer) changes() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		changes, err := s.service.Changes(r.Context(), r.URL.Query().Get("since"))
+		if err != nil {
+			if errors.Is(err, services.ErrSyncCursor) {
+				payload.NewErrorResponse(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			s.log.Errorf("sync: get changes err %v", err)
+			payload.NewErrorResponse(w, "sync: get changes err", http.StatusInternalServerError)
+			return
+		}
+		if err = json.NewEncoder(w).Encode(changes); err != nil {
+			s.log.Errorf("sync: encode err %v", err)
+			payload.NewErrorResponse(w, "sync: encode err", http.StatusInternalServerError)
+			return
+		}
+	}
+}
+
+func (s *syncer) createRoutes() http.Handler {
+	router := chi.NewRouter()
+	router.Route("/", func(r chi.Router) {
+		r.Get("/", s.changes())
+	})
+	return router
+}
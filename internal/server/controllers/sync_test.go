@@ -0,0 +1,84 @@
+package controllers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/zelas91/goph-keeper/internal/logger"
+	mock2 "github.com/zelas91/goph-keeper/internal/server/controllers/mocks"
+	"github.com/zelas91/goph-keeper/internal/server/models"
+	"github.com/zelas91/goph-keeper/internal/server/services"
+)
+
+func TestSyncChanges(t *testing.T) {
+	tests := []struct {
+		name                    string
+		url                     string
+		want                    int
+		wantChanges             models.SyncChanges
+		mockBehaviorSyncService func(s *mock2.MocksyncService)
+	}{
+		{
+			name: "#1 ok changes since cursor",
+			url:  "/?since=12",
+			want: http.StatusOK,
+			wantChanges: models.SyncChanges{
+				Texts:   []models.TextData{{ID: 4, Text: "text"}},
+				Deleted: []models.ItemRef{{ItemType: "card", ItemID: 2}},
+				Cursor:  "15",
+			},
+			mockBehaviorSyncService: func(s *mock2.MocksyncService) {
+				s.EXPECT().Changes(gomock.Any(), "12").Return(models.SyncChanges{
+					Texts:   []models.TextData{{ID: 4, Text: "text"}},
+					Deleted: []models.ItemRef{{ItemType: "card", ItemID: 2}},
+					Cursor:  "15",
+				}, nil)
+			},
+		},
+		{
+			name: "#2 nok bad cursor",
+			url:  "/?since=abc",
+			want: http.StatusBadRequest,
+			mockBehaviorSyncService: func(s *mock2.MocksyncService) {
+				s.EXPECT().Changes(gomock.Any(), "abc").Return(models.SyncChanges{}, services.ErrSyncCursor)
+			},
+		},
+		{
+			name: "#3 nok changes err",
+			url:  "/",
+			want: http.StatusInternalServerError,
+			mockBehaviorSyncService: func(s *mock2.MocksyncService) {
+				s.EXPECT().Changes(gomock.Any(), "").Return(models.SyncChanges{}, errors.New("repo err"))
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			service := mock2.NewMocksyncService(ctrl)
+			test.mockBehaviorSyncService(service)
+
+			handler := New(logger.New(""), WithSyncUseService(service))
+
+			request := httptest.NewRequest(http.MethodGet, test.url, nil)
+			w := httptest.NewRecorder()
+			handler.sync.createRoutes().ServeHTTP(w, request)
+			res := w.Result()
+			defer res.Body.Close()
+
+			assert.Equal(t, test.want, res.StatusCode)
+			if test.want == http.StatusOK {
+				var changes models.SyncChanges
+				assert.NoError(t, json.NewDecoder(res.Body).Decode(&changes))
+				assert.Equal(t, test.wantChanges, changes)
+			}
+		})
+	}
+}
@@ -0,0 +1,163 @@
+package controllers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-playground/validator/v10"
+	"github.com/zelas91/goph-keeper/internal/logger"
+	"github.com/zelas91/goph-keeper/internal/server/helper"
+	"github.com/zelas91/goph-keeper/internal/server/models"
+	"github.com/zelas91/goph-keeper/internal/server/payload"
+	"github.com/zelas91/goph-keeper/internal/server/repository"
+	"golang.org/x/net/context"
+)
+
+type tag struct {
+	service tagService
+	valid   *validator.Validate
+	log     logger.Logger
+}
+
+//go:generate mockgen -package mocks -destination=./mocks/mock_tag_service.go -source=tag.go -package=mock
+type tagService interface {
+	Create(ctx context.Context, tg models.Tag) (models.Tag, error)
+	Tags(ctx context.Context) ([]models.Tag, error)
+	Delete(ctx context.Context, tagID int) error
+	Attach(ctx context.Context, tagID int, item models.ItemRef) error
+	Detach(ctx context.Context, tagID int, item models.ItemRef) error
+}
+
+func (t *tag) tags() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		tags, err := t.service.Tags(r.Context())
+		if err != nil {
+			t.log.Errorf("tags: get tags err %v", err)
+			payload.NewErrorResponse(w, "tags: get tags err", http.StatusInternalServerError)
+			return
+		}
+		if err = json.NewEncoder(w).Encode(tags); err != nil {
+			t.log.Errorf("tags: encode err %v", err)
+			payload.NewErrorResponse(w, "tags: encode err", http.StatusInternalServerError)
+			return
+		}
+	}
+}
+
+func (t *tag) create() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Body == nil {
+			payload.NewErrorResponse(w, "create: body is empty", http.StatusBadRequest)
+			return
+		}
+
+		defer func() {
+			if err := r.Body.Close(); err != nil {
+				t.log.Errorf("create: tag in body close err :%v", err)
+			}
+		}()
+
+		var tg models.Tag
+		if err := decodeAndValid(r, t.valid, &tg); err != nil {
+			t.log.Errorf("create: decode or validation err:%v", err)
+			payload.NewErrorResponse(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		tg, err := t.service.Create(r.Context(), tg)
+		if err != nil {
+			t.log.Errorf("create: tag save err: %v", err)
+			payload.NewErrorResponse(w, "create: tag save err", http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+		if err = json.NewEncoder(w).Encode(tg); err != nil {
+			t.log.Errorf("create: encode err %v", err)
+		}
+	}
+}
+
+func (t *tag) delete() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := helper.IDFromContext(r.Context())
+		if err != nil {
+			t.log.Errorf("get id tag err: %v", err)
+			payload.NewErrorResponse(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if err = t.service.Delete(r.Context(), id); err != nil {
+			t.log.Errorf("delete tag err: %v", err)
+			tagErrorResponse(w, "delete tag err", err)
+			return
+		}
+	}
+}
+
+func (t *tag) attach() http.HandlerFunc {
+	return t.itemHandler("attach", func(ctx context.Context, tagID int, item models.ItemRef) error {
+		return t.service.Attach(ctx, tagID, item)
+	})
+}
+
+func (t *tag) detach() http.HandlerFunc {
+	return t.itemHandler("detach", func(ctx context.Context, tagID int, item models.ItemRef) error {
+		return t.service.Detach(ctx, tagID, item)
+	})
+}
+
+func (t *tag) itemHandler(name string,
+	action func(ctx context.Context, tagID int, item models.ItemRef) error) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Body == nil {
+			payload.NewErrorResponse(w, name+": body is empty", http.StatusBadRequest)
+			return
+		}
+
+		defer func() {
+			if err := r.Body.Close(); err != nil {
+				t.log.Errorf("%s: item in body close err :%v", name, err)
+			}
+		}()
+
+		id, err := helper.IDFromContext(r.Context())
+		if err != nil {
+			t.log.Errorf("%s: get id tag err: %v", name, err)
+			payload.NewErrorResponse(w, name+": get id tag err", http.StatusBadRequest)
+			return
+		}
+
+		var item models.ItemRef
+		if err = decodeAndValid(r, t.valid, &item); err != nil {
+			t.log.Errorf("%s: decode or validation err:%v", name, err)
+			payload.NewErrorResponse(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		if err = action(r.Context(), id, item); err != nil {
+			t.log.Errorf("%s: tag err: %v", name, err)
+			tagErrorResponse(w, name+": tag err", err)
+		}
+	}
+}
+
+func tagErrorResponse(w http.ResponseWriter, message string, err error) {
+	if errors.Is(err, repository.ErrNotFound) {
+		payload.NewErrorResponse(w, message+": not found", http.StatusNotFound)
+		return
+	}
+	payload.NewErrorResponse(w, message, http.StatusInternalServerError)
+}
+
+func (t *tag) createRoutes() http.Handler {
+	router := chi.NewRouter()
+	router.Route("/", func(r chi.Router) {
+		r.Get("/", t.tags())
+		r.Post("/", t.create())
+		r.Delete("/{id}", t.delete())
+		r.Post("/{id}/items", t.attach())
+		r.Delete("/{id}/items", t.detach())
+	})
+	return router
+}
@@ -0,0 +1,108 @@
+package controllers
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/zelas91/goph-keeper/internal/logger"
+	mock2 "github.com/zelas91/goph-keeper/internal/server/controllers/mocks"
+	"github.com/zelas91/goph-keeper/internal/server/models"
+	"github.com/zelas91/goph-keeper/internal/server/repository"
+)
+
+func TestTagAttach(t *testing.T) {
+	tests := []struct {
+		name                      string
+		url                       string
+		method                    string
+		want                      int
+		mockBehaviorAttachService func(s *mock2.MocktagService, item models.ItemRef)
+		body                      models.ItemRef
+	}{
+		{
+			name:   "#1 ok attach tag",
+			url:    "/4/items",
+			method: http.MethodPost,
+			want:   http.StatusOK,
+			body:   models.ItemRef{ItemType: "credential", ItemID: 7},
+			mockBehaviorAttachService: func(s *mock2.MocktagService, item models.ItemRef) {
+				s.EXPECT().Attach(gomock.Any(), 4, item).Return(nil)
+			},
+		},
+		{
+			name:   "#2 ok detach tag",
+			url:    "/4/items",
+			method: http.MethodDelete,
+			want:   http.StatusOK,
+			body:   models.ItemRef{ItemType: "credential", ItemID: 7},
+			mockBehaviorAttachService: func(s *mock2.MocktagService, item models.ItemRef) {
+				s.EXPECT().Detach(gomock.Any(), 4, item).Return(nil)
+			},
+		},
+		{
+			name:   "#3 nok get id tag err",
+			url:    "/a/items",
+			method: http.MethodPost,
+			want:   http.StatusBadRequest,
+			body:   models.ItemRef{ItemType: "credential", ItemID: 7},
+		},
+		{
+			name:   "#4 nok validation error item id",
+			url:    "/4/items",
+			method: http.MethodPost,
+			want:   http.StatusBadRequest,
+			body:   models.ItemRef{ItemType: "credential"},
+		},
+		{
+			name:   "#5 nok tag or item not found",
+			url:    "/4/items",
+			method: http.MethodPost,
+			want:   http.StatusNotFound,
+			body:   models.ItemRef{ItemType: "card", ItemID: 7},
+			mockBehaviorAttachService: func(s *mock2.MocktagService, item models.ItemRef) {
+				s.EXPECT().Attach(gomock.Any(), 4, item).Return(fmt.Errorf("attach tag err: %w", repository.ErrNotFound))
+			},
+		},
+		{
+			name:   "#6 nok attach err",
+			url:    "/4/items",
+			method: http.MethodPost,
+			want:   http.StatusInternalServerError,
+			body:   models.ItemRef{ItemType: "card", ItemID: 7},
+			mockBehaviorAttachService: func(s *mock2.MocktagService, item models.ItemRef) {
+				s.EXPECT().Attach(gomock.Any(), 4, item).Return(errors.New("repo err"))
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			service := mock2.NewMocktagService(ctrl)
+			if test.mockBehaviorAttachService != nil {
+				test.mockBehaviorAttachService(service, test.body)
+			}
+
+			handler := New(logger.New(""), WithTagUseService(service))
+
+			body, err := json.Marshal(test.body)
+			assert.NoError(t, err, "Body write error")
+
+			request := httptest.NewRequest(test.method, test.url, bytes.NewReader(body))
+			w := httptest.NewRecorder()
+			handler.tag.createRoutes().ServeHTTP(w, request)
+			res := w.Result()
+			defer res.Body.Close()
+
+			assert.Equal(t, test.want, res.StatusCode)
+		})
+	}
+}
@@ -23,7 +23,7 @@ type textData struct {
 //go:generate mockgen -package mocks -destination=./mocks/mock_text_data_service.go -source=text_data.go -package=mock
 type textDataService interface {
 	Create(ctx context.Context, text models.TextData) error
-	Texts(ctx context.Context) ([]models.TextData, error)
+	Texts(ctx context.Context, filter models.ItemFilter) ([]models.TextData, string, error)
 	Text(ctx context.Context, textID int) (models.TextData, error)
 	Delete(ctx context.Context, textID int) error
 	Update(ctx context.Context, text models.TextData) error
@@ -31,13 +31,19 @@ type textDataService interface {
 
 func (t *textData) texts() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		texts, err := t.service.Texts(r.Context())
+		filter, err := helper.ItemFilterFromRequest(r)
+		if err != nil {
+			t.log.Errorf("texts: filter from request err: %v", err)
+			payload.NewErrorResponse(w, "texts: filter from request err", http.StatusBadRequest)
+			return
+		}
+		texts, next, err := t.service.Texts(r.Context(), filter)
 		if err != nil {
 			t.log.Errorf("text: get texts err %v", err)
 			payload.NewErrorResponse(w, "texts: get texts err", http.StatusInternalServerError)
 			return
 		}
-		if err = json.NewEncoder(w).Encode(texts); err != nil {
+		if err = json.NewEncoder(w).Encode(models.Page[models.TextData]{Items: texts, NextCursor: next}); err != nil {
 			t.log.Errorf("texts: encode err %v", err)
 			payload.NewErrorResponse(w, "texts: encode err", http.StatusInternalServerError)
 			return
@@ -89,7 +95,8 @@ func (t *textData) create() http.HandlerFunc {
 
 		if err = t.service.Create(r.Context(), text); err != nil {
 			t.log.Errorf("create: text save err: %v", err)
-			payload.NewErrorResponse(w, "create: text save err", http.StatusInternalServerError)
+			createErrorResponse(w, "create: text save err", err)
+			return
 		}
 		w.WriteHeader(http.StatusCreated)
 	}
@@ -132,7 +139,9 @@ func (t *textData) update() http.HandlerFunc {
 
 		if err = t.service.Update(r.Context(), text); err != nil {
 			t.log.Errorf("update: text save err: %v", err)
-			payload.NewErrorResponse(w, "update: text save err", http.StatusInternalServerError)
+			updateErrorResponse(w, t.log, "update: text save err", err, func() (any, error) {
+				return t.service.Text(r.Context(), id)
+			})
 		}
 	}
 }
@@ -31,7 +31,7 @@ func TestTexts(t *testing.T) {
 			want:   http.StatusOK,
 			method: http.MethodGet,
 			mockBehaviorTextsService: func(s *mock2.MocktextDataService) {
-				s.EXPECT().Texts(gomock.Any()).Return([]models.TextData{
+				s.EXPECT().Texts(gomock.Any(), models.ItemFilter{Sort: models.SortCreated, Limit: models.DefaultPageLimit}).Return([]models.TextData{
 					{
 						Text: `Prepared by experienced English teachers, 
 							the texts, articles and conversations are brief and appropriate 
@@ -40,7 +40,7 @@ func TestTexts(t *testing.T) {
 					{
 						Text: `English texts for beginners to practice reading and comprehension online and for free.`,
 					},
-				}, nil)
+				}, "", nil)
 			},
 		},
 		{
@@ -49,7 +49,7 @@ func TestTexts(t *testing.T) {
 			want:   http.StatusInternalServerError,
 			method: http.MethodGet,
 			mockBehaviorTextsService: func(s *mock2.MocktextDataService) {
-				s.EXPECT().Texts(gomock.Any()).Return(nil, errors.New("repo error"))
+				s.EXPECT().Texts(gomock.Any(), models.ItemFilter{Sort: models.SortCreated, Limit: models.DefaultPageLimit}).Return(nil, "", errors.New("repo error"))
 			},
 		},
 	}
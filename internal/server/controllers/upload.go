@@ -0,0 +1,187 @@
+package controllers
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-playground/validator/v10"
+	"github.com/zelas91/goph-keeper/internal/logger"
+	"github.com/zelas91/goph-keeper/internal/server/models"
+	"github.com/zelas91/goph-keeper/internal/server/payload"
+	"github.com/zelas91/goph-keeper/internal/server/repository"
+	"github.com/zelas91/goph-keeper/internal/server/services"
+	"golang.org/x/net/context"
+)
+
+const (
+	uploadOffsetHeader   = "Upload-Offset"
+	uploadChecksumHeader = "Upload-Checksum"
+)
+
+type uploadSession struct {
+	service uploadService
+	valid   *validator.Validate
+	log     logger.Logger
+}
+
+//go:generate mockgen -package mocks -destination=./mocks/mock_upload_service.go -source=upload.go -package=mock
+type uploadService interface {
+	Create(ctx context.Context, bf models.BinaryFile) (models.Upload, error)
+	Uploads(ctx context.Context) ([]models.Upload, error)
+	Upload(ctx context.Context, uploadID string) (models.Upload, error)
+	WriteChunk(ctx context.Context, uploadID string, offset int, checksum, data []byte) error
+	Finish(ctx context.Context, uploadID string) error
+	Delete(ctx context.Context, uploadID string) error
+}
+
+func (u *uploadSession) create() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var bf models.BinaryFile
+		if err := decodeAndValid(r, u.valid, &bf); err != nil {
+			u.log.Errorf("upload create: decode or validation err:%v", err)
+			payload.NewErrorResponse(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		up, err := u.service.Create(r.Context(), bf)
+		if err != nil {
+			u.log.Errorf("upload create: save err: %v", err)
+			createErrorResponse(w, "upload create: save err", err)
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+		if err = json.NewEncoder(w).Encode(up); err != nil {
+			u.log.Errorf("upload create: encode err %v", err)
+		}
+	}
+}
+
+func (u *uploadSession) uploads() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		uploads, err := u.service.Uploads(r.Context())
+		if err != nil {
+			u.log.Errorf("uploads: get uploads err %v", err)
+			payload.NewErrorResponse(w, "uploads: get uploads err", http.StatusInternalServerError)
+			return
+		}
+		if err = json.NewEncoder(w).Encode(uploads); err != nil {
+			u.log.Errorf("uploads: encode err %v", err)
+			payload.NewErrorResponse(w, "uploads: encode err", http.StatusInternalServerError)
+			return
+		}
+	}
+}
+
+func (u *uploadSession) upload() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		up, err := u.service.Upload(r.Context(), chi.URLParam(r, "uploadID"))
+		if err != nil {
+			u.log.Errorf("upload: get upload err: %v", err)
+			uploadErrorResponse(w, "upload: get upload err", err)
+			return
+		}
+		if err = json.NewEncoder(w).Encode(up); err != nil {
+			u.log.Errorf("upload: encode err %v", err)
+			payload.NewErrorResponse(w, "upload: encode err", http.StatusInternalServerError)
+			return
+		}
+	}
+}
+
+// chunk takes the bytes of the body at the Upload-Offset of the file,
+// Upload-Checksum is "sha256 <base64 of the digest>" as in tus.
+func (u *uploadSession) chunk() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		offset, err := strconv.Atoi(r.Header.Get(uploadOffsetHeader))
+		if err != nil {
+			payload.NewErrorResponse(w, "chunk: "+uploadOffsetHeader+" header is not a number", http.StatusBadRequest)
+			return
+		}
+		checksum, err := parseChecksum(r.Header.Get(uploadChecksumHeader))
+		if err != nil {
+			payload.NewErrorResponse(w, "chunk: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+		data, err := io.ReadAll(http.MaxBytesReader(w, r.Body, models.MaxUploadChunk))
+		if err != nil {
+			u.log.Errorf("chunk: read body err: %v", err)
+			payload.NewErrorResponse(w, "chunk: read body err", http.StatusRequestEntityTooLarge)
+			return
+		}
+		if err = u.service.WriteChunk(r.Context(), chi.URLParam(r, "uploadID"), offset, checksum, data); err != nil {
+			u.log.Errorf("chunk: write err: %v", err)
+			uploadErrorResponse(w, "chunk: write err", err)
+			return
+		}
+	}
+}
+
+func (u *uploadSession) finish() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if err := u.service.Finish(r.Context(), chi.URLParam(r, "uploadID")); err != nil {
+			u.log.Errorf("upload finish err: %v", err)
+			uploadErrorResponse(w, "upload finish err", err)
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+	}
+}
+
+func (u *uploadSession) delete() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if err := u.service.Delete(r.Context(), chi.URLParam(r, "uploadID")); err != nil {
+			u.log.Errorf("upload delete err: %v", err)
+			uploadErrorResponse(w, "upload delete err", err)
+			return
+		}
+	}
+}
+
+func parseChecksum(header string) ([]byte, error) {
+	algorithm, value, ok := strings.Cut(header, " ")
+	if !ok || algorithm != "sha256" {
+		return nil, fmt.Errorf("%s header must be \"sha256 <base64>\"", uploadChecksumHeader)
+	}
+	checksum, err := base64.StdEncoding.DecodeString(value)
+	if err != nil || len(checksum) != sha256.Size {
+		return nil, fmt.Errorf("%s header is not a sha256 digest", uploadChecksumHeader)
+	}
+	return checksum, nil
+}
+
+func uploadErrorResponse(w http.ResponseWriter, message string, err error) {
+	switch {
+	case errors.Is(err, repository.ErrNotFound):
+		payload.NewErrorResponse(w, message+": not found", http.StatusNotFound)
+	case errors.Is(err, services.ErrUploadChunk):
+		payload.NewErrorResponse(w, message+": "+services.ErrUploadChunk.Error(), http.StatusBadRequest)
+	case errors.Is(err, services.ErrUploadChecksum):
+		payload.NewErrorResponse(w, message+": "+services.ErrUploadChecksum.Error(), http.StatusBadRequest)
+	case errors.Is(err, services.ErrFileChecksum):
+		payload.NewErrorResponse(w, message+": "+services.ErrFileChecksum.Error(), http.StatusBadRequest)
+	case errors.Is(err, services.ErrUploadIncomplete):
+		payload.NewErrorResponse(w, message+": "+services.ErrUploadIncomplete.Error(), http.StatusConflict)
+	default:
+		createErrorResponse(w, message, err)
+	}
+}
+
+func (u *uploadSession) createRoutes() http.Handler {
+	router := chi.NewRouter()
+	router.Route("/", func(r chi.Router) {
+		r.Get("/", u.uploads())
+		r.Post("/", u.create())
+		r.Get("/{uploadID}", u.upload())
+		r.Put("/{uploadID}", u.chunk())
+		r.Post("/{uploadID}/finish", u.finish())
+		r.Delete("/{uploadID}", u.delete())
+	})
+	return router
+}
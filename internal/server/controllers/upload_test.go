@@ -0,0 +1,129 @@
+package controllers
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/zelas91/goph-keeper/internal/logger"
+	mock2 "github.com/zelas91/goph-keeper/internal/server/controllers/mocks"
+	"github.com/zelas91/goph-keeper/internal/server/repository"
+	"github.com/zelas91/goph-keeper/internal/server/services"
+)
+
+func TestUploadChunk(t *testing.T) {
+	data := []byte("chunk of the file")
+	sum := sha256.Sum256(data)
+	checksum := "sha256 " + base64.StdEncoding.EncodeToString(sum[:])
+
+	tests := []struct {
+		name                string
+		url                 string
+		offset              string
+		checksum            string
+		want                int
+		mockBehaviorService func(s *mock2.MockuploadService)
+	}{
+		{
+			name:     "#1 ok chunk written",
+			url:      "/abc",
+			offset:   "1024",
+			checksum: checksum,
+			want:     http.StatusOK,
+			mockBehaviorService: func(s *mock2.MockuploadService) {
+				s.EXPECT().WriteChunk(gomock.Any(), "abc", 1024, sum[:], data).Return(nil)
+			},
+		},
+		{
+			name:     "#2 nok offset is not a number",
+			url:      "/abc",
+			offset:   "first",
+			checksum: checksum,
+			want:     http.StatusBadRequest,
+		},
+		{
+			name:     "#3 nok checksum of other algorithm",
+			url:      "/abc",
+			offset:   "0",
+			checksum: "md5 " + base64.StdEncoding.EncodeToString(sum[:16]),
+			want:     http.StatusBadRequest,
+		},
+		{
+			name:     "#4 nok checksum does not match",
+			url:      "/abc",
+			offset:   "0",
+			checksum: checksum,
+			want:     http.StatusBadRequest,
+			mockBehaviorService: func(s *mock2.MockuploadService) {
+				s.EXPECT().WriteChunk(gomock.Any(), "abc", 0, sum[:], data).Return(services.ErrUploadChecksum)
+			},
+		},
+		{
+			name:     "#5 nok upload not found",
+			url:      "/abc",
+			offset:   "0",
+			checksum: checksum,
+			want:     http.StatusNotFound,
+			mockBehaviorService: func(s *mock2.MockuploadService) {
+				s.EXPECT().WriteChunk(gomock.Any(), "abc", 0, sum[:], data).
+					Return(fmt.Errorf("get upload err: %w", repository.ErrNotFound))
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			service := mock2.NewMockuploadService(ctrl)
+			if test.mockBehaviorService != nil {
+				test.mockBehaviorService(service)
+			}
+			handler := New(logger.New(""), WithUploadUseService(service))
+
+			request := httptest.NewRequest(http.MethodPut, test.url, bytes.NewReader(data))
+			request.Header.Set("Content-Type", "application/octet-stream")
+			request.Header.Set(uploadOffsetHeader, test.offset)
+			request.Header.Set(uploadChecksumHeader, test.checksum)
+			w := httptest.NewRecorder()
+			handler.upload.createRoutes().ServeHTTP(w, request)
+			res := w.Result()
+			defer res.Body.Close()
+			assert.Equal(t, test.want, res.StatusCode)
+		})
+	}
+}
+
+func TestUploadFinish(t *testing.T) {
+	tests := []struct {
+		name      string
+		finishErr error
+		want      int
+	}{
+		{name: "#1 ok file stored", want: http.StatusCreated},
+		{name: "#2 nok chunks missing", finishErr: services.ErrUploadIncomplete, want: http.StatusConflict},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			service := mock2.NewMockuploadService(ctrl)
+			service.EXPECT().Finish(gomock.Any(), "abc").Return(test.finishErr)
+			handler := New(logger.New(""), WithUploadUseService(service))
+
+			request := httptest.NewRequest(http.MethodPost, "/abc/finish", nil)
+			w := httptest.NewRecorder()
+			handler.upload.createRoutes().ServeHTTP(w, request)
+			res := w.Result()
+			defer res.Body.Close()
+			assert.Equal(t, test.want, res.StatusCode)
+		})
+	}
+}
@@ -0,0 +1,63 @@
+package controllers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-playground/validator/v10"
+	"github.com/zelas91/goph-keeper/internal/logger"
+	"github.com/zelas91/goph-keeper/internal/server/models"
+	"github.com/zelas91/goph-keeper/internal/server/payload"
+	"github.com/zelas91/goph-keeper/internal/server/services"
+	"golang.org/x/net/context"
+)
+
+type usage struct {
+	service usageService
+	valid   *validator.Validate
+	log     logger.Logger
+}
+
+//go:generate mockgen -package mocks -destination=./mocks/mock_usage_service.go -source=usage.go -package=mock
+type usageService interface {
+	Usage(ctx context.Context) (models.Usage, error)
+}
+
+func (u *usage) usage() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		result, err := u.service.Usage(r.Context())
+		if err != nil {
+			u.log.Errorf("usage: get usage err %v", err)
+			payload.NewErrorResponse(w, "usage: get usage err", http.StatusInternalServerError)
+			return
+		}
+		if err = json.NewEncoder(w).Encode(result); err != nil {
+			u.log.Errorf("usage: encode err %v", err)
+			payload.NewErrorResponse(w, "usage: encode err", http.StatusInternalServerError)
+			return
+		}
+	}
+}
+
+// createErrorResponse answers the error of a create, 507 when the user
+// is out of the quota and 413 when the file is larger than allowed.
+func createErrorResponse(w http.ResponseWriter, message string, err error) {
+	switch {
+	case errors.Is(err, services.ErrQuotaExceeded):
+		payload.NewErrorResponse(w, message+": "+err.Error(), http.StatusInsufficientStorage)
+	case errors.Is(err, services.ErrFileTooLarge):
+		payload.NewErrorResponse(w, message+": "+err.Error(), http.StatusRequestEntityTooLarge)
+	default:
+		payload.NewErrorResponse(w, message, http.StatusInternalServerError)
+	}
+}
+
+func (u *usage) createRoutes() http.Handler {
+	router := chi.NewRouter()
+	router.Route("/", func(r chi.Router) {
+		r.Get("/usage", u.usage())
+	})
+	return router
+}
@@ -0,0 +1,63 @@
+package controllers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/zelas91/goph-keeper/internal/logger"
+	mock2 "github.com/zelas91/goph-keeper/internal/server/controllers/mocks"
+	"github.com/zelas91/goph-keeper/internal/server/models"
+)
+
+func TestUsage(t *testing.T) {
+	usage := models.Usage{Bytes: 2048, Items: 3, Limits: models.Limits{Bytes: 1 << 20, Items: 10}}
+	tests := []struct {
+		name                     string
+		want                     int
+		mockBehaviorUsageService func(s *mock2.MockusageService)
+	}{
+		{
+			name: "#1 ok usage",
+			want: http.StatusOK,
+			mockBehaviorUsageService: func(s *mock2.MockusageService) {
+				s.EXPECT().Usage(gomock.Any()).Return(usage, nil)
+			},
+		},
+		{
+			name: "#2 nok usage err",
+			want: http.StatusInternalServerError,
+			mockBehaviorUsageService: func(s *mock2.MockusageService) {
+				s.EXPECT().Usage(gomock.Any()).Return(models.Usage{}, errors.New("repo err"))
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			service := mock2.NewMockusageService(ctrl)
+			test.mockBehaviorUsageService(service)
+
+			handler := New(logger.New(""), WithUsageUseService(service))
+
+			request := httptest.NewRequest(http.MethodGet, "/usage", nil)
+			w := httptest.NewRecorder()
+			handler.usage.createRoutes().ServeHTTP(w, request)
+			res := w.Result()
+			defer res.Body.Close()
+
+			assert.Equal(t, test.want, res.StatusCode)
+			if test.want == http.StatusOK {
+				var got models.Usage
+				assert.NoError(t, json.NewDecoder(res.Body).Decode(&got))
+				assert.Equal(t, usage, got)
+			}
+		})
+	}
+}
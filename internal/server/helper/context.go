@@ -3,9 +3,13 @@ package helper
 import (
 	"errors"
 	"fmt"
+	"net/http"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/go-chi/chi/v5"
+	"github.com/zelas91/goph-keeper/internal/server/models"
 	"golang.org/x/net/context"
 )
 
@@ -23,3 +27,56 @@ func IDFromContext(ctx context.Context) (id int, err error) {
 	}
 	return
 }
+
+// ItemFilterFromRequest reads the filter and the page of the item lists:
+// ?folder=<id>&tag=<name>&updated_since=<RFC 3339>&sort=[-]created|updated&limit=<n>&cursor=<next_cursor>.
+func ItemFilterFromRequest(r *http.Request) (filter models.ItemFilter, err error) {
+	query := r.URL.Query()
+	if folder := query.Get("folder"); folder != "" {
+		filter.FolderID, err = strconv.Atoi(folder)
+		if err != nil {
+			return filter, fmt.Errorf("convert folder=%s, to int err: %w", folder, err)
+		}
+	}
+	filter.Tag = query.Get("tag")
+	if since := query.Get("updated_since"); since != "" {
+		updatedSince, err := time.Parse(time.RFC3339, since)
+		if err != nil {
+			return filter, fmt.Errorf("parse updated_since=%s err: %w", since, err)
+		}
+		filter.UpdatedSince = updatedSince.UTC()
+	}
+
+	filter.Sort = models.SortCreated
+	if sort := query.Get("sort"); sort != "" {
+		switch strings.TrimPrefix(sort, "-") {
+		case models.SortCreated, models.SortUpdated:
+			filter.Sort = sort
+		default:
+			return filter, fmt.Errorf("unknown sort=%s", sort)
+		}
+	}
+
+	filter.Limit = models.DefaultPageLimit
+	if limit := query.Get("limit"); limit != "" {
+		filter.Limit, err = strconv.Atoi(limit)
+		if err != nil {
+			return filter, fmt.Errorf("convert limit=%s, to int err: %w", limit, err)
+		}
+		if filter.Limit < 1 || filter.Limit > models.MaxPageLimit {
+			return filter, fmt.Errorf("limit=%d out of range 1..%d", filter.Limit, models.MaxPageLimit)
+		}
+	}
+
+	if c := query.Get("cursor"); c != "" {
+		after, err := DecodeCursor(c)
+		if err != nil {
+			return filter, err
+		}
+		if after.Sort != filter.Sort {
+			return filter, errors.New("cursor was made for another sort")
+		}
+		filter.After = &after
+	}
+	return filter, nil
+}
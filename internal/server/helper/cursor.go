@@ -0,0 +1,27 @@
+package helper
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+
+	"github.com/zelas91/goph-keeper/internal/server/models"
+)
+
+// EncodeCursor makes the opaque ?cursor= value of the next page.
+func EncodeCursor(c models.Cursor) string {
+	body, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(body)
+}
+
+func DecodeCursor(s string) (models.Cursor, error) {
+	var c models.Cursor
+	body, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return c, fmt.Errorf("cursor decode err: %w", err)
+	}
+	if err = json.Unmarshal(body, &c); err != nil {
+		return c, fmt.Errorf("cursor decode err: %w", err)
+	}
+	return c, nil
+}
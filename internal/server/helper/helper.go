@@ -1,6 +1,8 @@
 package helper
 
 import (
+	"strings"
+
 	"github.com/zelas91/goph-keeper/internal/server/models"
 	"github.com/zelas91/goph-keeper/internal/server/repository/entities"
 )
@@ -30,6 +32,10 @@ func ToModelBinaryFile(b entities.BinaryFile) models.BinaryFile {
 		FileName: b.FileName,
 		Path:     b.Path,
 		Size:     b.Size,
+		Sha256:   b.Sha256,
+		FileKey:  b.FileKey,
+		FolderID: b.FolderID,
+		Tags:     b.Tags,
 	}
 
 }
@@ -41,6 +47,53 @@ func ToEntitiesBinaryFile(b models.BinaryFile) entities.BinaryFile {
 		Path:     b.Path,
 		FileName: b.FileName,
 		Size:     b.Size,
+		Sha256:   b.Sha256,
+		FileKey:  b.FileKey,
+		FolderID: b.FolderID,
+	}
+
+}
+
+func ToEntitiesItemFilter(f models.ItemFilter) entities.ItemFilter {
+	filter := entities.ItemFilter{
+		FolderID:     f.FolderID,
+		Tag:          f.Tag,
+		UpdatedSince: f.UpdatedSince,
+		SortColumn:   "created_at",
+		Desc:         strings.HasPrefix(f.Sort, "-"),
+		Limit:        f.Limit,
+	}
+	if strings.TrimPrefix(f.Sort, "-") == models.SortUpdated {
+		filter.SortColumn = "update_at"
 	}
+	if f.After != nil {
+		filter.After = &entities.ItemCursor{Time: f.After.Time, ID: f.After.ID}
+	}
+	return filter
+}
 
+func ToModelFolder(f entities.Folder) models.Folder {
+	return models.Folder{
+		ID:       f.ID,
+		UserId:   f.UserId,
+		ParentID: f.ParentID,
+		Name:     f.Name,
+	}
+}
+
+func ToEntitiesFolder(f models.Folder) entities.Folder {
+	return entities.Folder{
+		ID:       f.ID,
+		UserId:   f.UserId,
+		ParentID: f.ParentID,
+		Name:     f.Name,
+	}
+}
+
+func ToModelTag(t entities.Tag) models.Tag {
+	return models.Tag{
+		ID:     t.ID,
+		UserId: t.UserId,
+		Name:   t.Name,
+	}
 }
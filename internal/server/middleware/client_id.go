@@ -0,0 +1,20 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/zelas91/goph-keeper/internal/server/types"
+	"golang.org/x/net/context"
+)
+
+const clientIDHeader = "Client-ID"
+
+// ClientID keeps the id the client sends with its requests in the context.
+func ClientID(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if id := r.Header.Get(clientIDHeader); id != "" {
+			r = r.WithContext(context.WithValue(r.Context(), types.ClientIDKey, id))
+		}
+		next.ServeHTTP(w, r)
+	})
+}
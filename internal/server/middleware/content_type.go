@@ -8,15 +8,20 @@ import (
 )
 
 const (
-	content     = "Content-Type"
-	contentJSON = "application/json"
+	content       = "Content-Type"
+	contentJSON   = "application/json"
+	contentBinary = "application/octet-stream"
 )
 
+// ContentTypeJSON takes the JSON requests and the binary chunks of the file uploads,
+// GET and HEAD have no body and may come without a content type, as from curl.
 func ContentTypeJSON(log logger.Logger) func(next http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			if r.Header.Get(content) != contentJSON {
+			t := r.Header.Get(content)
+			noBody := (r.Method == http.MethodGet || r.Method == http.MethodHead) && t == ""
+			if !noBody && t != contentJSON && t != contentBinary {
 				log.Error("invalid content type")
 				payload.NewErrorResponse(w, "invalid content type", http.StatusUnsupportedMediaType)
 				return
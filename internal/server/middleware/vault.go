@@ -0,0 +1,95 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/zelas91/goph-keeper/internal/logger"
+	"github.com/zelas91/goph-keeper/internal/server/models"
+	"github.com/zelas91/goph-keeper/internal/server/payload"
+	"github.com/zelas91/goph-keeper/internal/server/repository"
+	"github.com/zelas91/goph-keeper/internal/server/services"
+	"github.com/zelas91/goph-keeper/internal/server/types"
+	"golang.org/x/net/context"
+)
+
+const (
+	vaultHeader     = "Vault-ID"
+	emergencyHeader = "Emergency-ID"
+)
+
+// writeSockets are the routes that write the vault though they are opened
+// with GET, the websocket of the upload creates the file.
+var writeSockets = []string{"/file/upload"}
+
+type vaultResolver interface {
+	VaultUserID(ctx context.Context, id int, access string) (int, error)
+}
+
+// OrgVault serves the request in the vault of the organization the client
+// names in the header, as the user of the vault. The role of the user in
+// the organization has to allow the request, without the header the
+// request is served in the vault of the user.
+func OrgVault(log logger.Logger, resolver vaultResolver) func(next http.Handler) http.Handler {
+	return otherVault(log, vaultHeader, "organization not found", resolver)
+}
+
+// EmergencyVault serves the request in the vault of the owner of the
+// emergency access the client names in the header, the access has to be
+// granted to the user and the request has to read. The websocket of the
+// upload is a write though it is opened with GET and is refused.
+func EmergencyVault(log logger.Logger, resolver vaultResolver) func(next http.Handler) http.Handler {
+	return otherVault(log, emergencyHeader, "emergency access not granted", resolver)
+}
+
+func otherVault(log logger.Logger, header, notFound string, resolver vaultResolver) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			value := r.Header.Get(header)
+			if value == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+			id, err := strconv.Atoi(value)
+			if err != nil {
+				payload.NewErrorResponse(w, "invalid "+header, http.StatusBadRequest)
+				return
+			}
+			vaultUserID, err := resolver.VaultUserID(r.Context(), id, vaultAccess(r))
+			switch {
+			case errors.Is(err, repository.ErrNotFound):
+				payload.NewErrorResponse(w, notFound, http.StatusNotFound)
+				return
+			case errors.Is(err, services.ErrOrgForbidden), errors.Is(err, services.ErrEmergencyReadOnly):
+				payload.NewErrorResponse(w, err.Error(), http.StatusForbidden)
+				return
+			case err != nil:
+				log.Errorf("%s vault err: %v", header, err)
+				payload.NewErrorResponse(w, "vault err", http.StatusInternalServerError)
+				return
+			}
+			r = r.WithContext(context.WithValue(r.Context(), types.UserIDKey, vaultUserID))
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// vaultAccess tells what the request does in the vault by its route, not
+// only by its method.
+func vaultAccess(r *http.Request) string {
+	switch r.Method {
+	case http.MethodGet, http.MethodHead:
+		path := strings.TrimSuffix(r.URL.Path, "/")
+		for _, v := range writeSockets {
+			if strings.HasSuffix(path, v) {
+				return models.AccessWrite
+			}
+		}
+		return models.AccessRead
+	case http.MethodDelete:
+		return models.AccessDelete
+	}
+	return models.AccessWrite
+}
@@ -0,0 +1,122 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/zelas91/goph-keeper/internal/logger"
+	"github.com/zelas91/goph-keeper/internal/server/models"
+	"github.com/zelas91/goph-keeper/internal/server/repository/entities"
+	"github.com/zelas91/goph-keeper/internal/server/services"
+	mock "github.com/zelas91/goph-keeper/internal/server/services/mocks"
+	"github.com/zelas91/goph-keeper/internal/server/types"
+	"golang.org/x/net/context"
+)
+
+func TestOrgVault(t *testing.T) {
+	tests := []struct {
+		name      string
+		role      string
+		method    string
+		path      string
+		websocket bool
+		want      int
+	}{
+		{name: "#1 ok read-only member lists the files", role: models.RoleReadOnly,
+			method: http.MethodGet, path: "/api/file", want: http.StatusOK},
+		{name: "#2 nok read-only member uploads over the websocket", role: models.RoleReadOnly,
+			method: http.MethodGet, path: "/api/file/upload", websocket: true, want: http.StatusForbidden},
+		{name: "#3 nok read-only member uploads without the upgrade", role: models.RoleReadOnly,
+			method: http.MethodGet, path: "/api/file/upload/", want: http.StatusForbidden},
+		{name: "#4 ok member uploads over the websocket", role: models.RoleMember,
+			method: http.MethodGet, path: "/api/file/upload", websocket: true, want: http.StatusOK},
+		{name: "#5 nok member deletes", role: models.RoleMember,
+			method: http.MethodDelete, path: "/api/card/1", want: http.StatusForbidden},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			repo := mock.NewMockorganizationRepo(ctrl)
+			repo.EXPECT().FindByIDAndUserID(gomock.Any(), 3, 1).
+				Return(entities.Organization{ID: 3, VaultUserID: 42, Role: test.role}, nil)
+			s := services.New(services.WithOrganizationUseRepository(repo))
+
+			var vaultUserID any
+			next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				vaultUserID = r.Context().Value(types.UserIDKey)
+			})
+			request := httptest.NewRequest(test.method, test.path, nil)
+			request.Header.Set(vaultHeader, "3")
+			if test.websocket {
+				request.Header.Set("Connection", "Upgrade")
+				request.Header.Set("Upgrade", "websocket")
+			}
+			request = request.WithContext(context.WithValue(request.Context(), types.UserIDKey, 1))
+			w := httptest.NewRecorder()
+			OrgVault(logger.New(""), s.Org)(next).ServeHTTP(w, request)
+
+			assert.Equal(t, test.want, w.Code)
+			if test.want == http.StatusOK {
+				assert.Equal(t, 42, vaultUserID)
+			} else {
+				assert.Nil(t, vaultUserID, "the request does not reach the vault")
+			}
+		})
+	}
+}
+
+func TestEmergencyVault(t *testing.T) {
+	tests := []struct {
+		name      string
+		method    string
+		path      string
+		websocket bool
+		want      int
+	}{
+		{name: "#1 ok contact reads the file", method: http.MethodGet, path: "/api/file/1/content",
+			want: http.StatusOK},
+		{name: "#2 ok contact downloads over the websocket", method: http.MethodGet, path: "/api/file/download",
+			websocket: true, want: http.StatusOK},
+		{name: "#3 nok contact uploads over the websocket", method: http.MethodGet, path: "/api/file/upload",
+			websocket: true, want: http.StatusForbidden},
+		{name: "#4 nok contact creates a card", method: http.MethodPost, path: "/api/card",
+			want: http.StatusForbidden},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			repo := mock.NewMockemergencyRepo(ctrl)
+			repo.EXPECT().FindGranted(gomock.Any(), 3, 2).
+				Return(entities.EmergencyAccess{ID: 3, OwnerID: 1, Status: models.EmergencyGranted}, nil).AnyTimes()
+			s := services.New(services.WithEmergencyUseRepository(repo, nil))
+
+			var vaultUserID any
+			next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				vaultUserID = r.Context().Value(types.UserIDKey)
+			})
+			request := httptest.NewRequest(test.method, test.path, nil)
+			request.Header.Set(emergencyHeader, "3")
+			if test.websocket {
+				request.Header.Set("Connection", "Upgrade")
+				request.Header.Set("Upgrade", "websocket")
+			}
+			request = request.WithContext(context.WithValue(request.Context(), types.UserIDKey, 2))
+			w := httptest.NewRecorder()
+			EmergencyVault(logger.New(""), s.Emergency)(next).ServeHTTP(w, request)
+
+			assert.Equal(t, test.want, w.Code)
+			if test.want == http.StatusOK {
+				assert.Equal(t, 1, vaultUserID)
+			} else {
+				assert.Nil(t, vaultUserID, "the request does not reach the vault")
+			}
+		})
+	}
+}
@@ -1,11 +1,21 @@
 package models
 
+// BinaryFile is a file item. A file encrypted by the client has FileKey,
+// the key of its content wrapped by the vault key of the user, the server
+// keeps it as it is and never sees the content.
 type BinaryFile struct {
-	ID       int    `json:"id"`
-	UserId   int    `json:"-"`
-	Path     string `json:"-"`
-	FileName string `json:"file_name" validate:"required"`
-	Size     int    `json:"size" validate:"required"`
+	ID       int               `json:"id"`
+	UserId   int               `json:"-"`
+	Path     string            `json:"-"`
+	FileName string            `json:"file_name" validate:"required"`
+	Size     int               `json:"size" validate:"required"`
+	Sha256   string            `json:"sha256,omitempty" validate:"omitempty,len=64,hexadecimal"`
+	FileKey  string            `json:"file_key,omitempty" validate:"omitempty,base64,max=256"`
+	Title    string            `json:"title" validate:"max=256"`
+	Note     string            `json:"note"`
+	Metadata map[string]string `json:"metadata,omitempty" validate:"dive,keys,required,max=64,endkeys,max=1024"`
+	FolderID *int              `json:"folder_id,omitempty"`
+	Tags     []string          `json:"tags,omitempty"`
 }
 
 type AnswerBinaryFile struct {
@@ -1,10 +1,15 @@
 package models
 
 type Card struct {
-	ID        int    `json:"id"`
-	Version   int    `json:"version"`
-	Number    string `json:"number" validate:"required,credit_card"`
-	ExpiredAt string `json:"expired_at" validate:"required,expired_credit_card"`
-	Cvv       string `json:"cvv" validate:"required,len=3"`
-	UserId    int    `json:"-"`
+	ID        int               `json:"id"`
+	Version   int               `json:"version"`
+	Number    string            `json:"number" validate:"required,credit_card"`
+	ExpiredAt string            `json:"expired_at" validate:"required,expired_credit_card"`
+	Cvv       string            `json:"cvv" validate:"required,len=3"`
+	UserId    int               `json:"-"`
+	Title     string            `json:"title" validate:"max=256"`
+	Note      string            `json:"note"`
+	Metadata  map[string]string `json:"metadata,omitempty" validate:"dive,keys,required,max=64,endkeys,max=1024"`
+	FolderID  *int              `json:"folder_id,omitempty"`
+	Tags      []string          `json:"tags,omitempty"`
 }
@@ -0,0 +1,15 @@
+package models
+
+import (
+	"io"
+	"time"
+)
+
+// FileContent is the decompressed content of a file item served over HTTP,
+// ETag changes with every change of the file.
+type FileContent struct {
+	File    BinaryFile
+	ETag    string
+	ModTime time.Time
+	Content io.ReadSeekCloser
+}
@@ -0,0 +1,34 @@
+package models
+
+import "time"
+
+// The states of an emergency access: the contact requests the access and
+// gets it when the owner approves it or lets the waiting period pass, the
+// owner rejects it back to idle.
+const (
+	EmergencyIdle      = "idle"
+	EmergencyRequested = "requested"
+	EmergencyGranted   = "granted"
+)
+
+// EmergencyAccess names the contact that may get read access to the vault
+// of the owner. VaultKey is the vault key of the owner sealed for the
+// contact by the client of the owner, the contact gets it once granted.
+type EmergencyAccess struct {
+	ID          int        `json:"id"`
+	Owner       string     `json:"owner"`
+	Contact     string     `json:"contact" validate:"required"`
+	WaitHours   int        `json:"wait_hours" validate:"required,min=1,max=720"`
+	Status      string     `json:"status"`
+	VaultKey    string     `json:"vault_key,omitempty" validate:"max=512"`
+	RequestedAt *time.Time `json:"requested_at,omitempty"`
+	GrantedAt   *time.Time `json:"granted_at,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+}
+
+// EmergencyAccesses are the contacts of the user and the owners that name
+// the user their contact.
+type EmergencyAccesses struct {
+	Contacts []EmergencyAccess `json:"contacts"`
+	Owners   []EmergencyAccess `json:"owners"`
+}
@@ -0,0 +1,17 @@
+package models
+
+// Actions of the change events.
+const (
+	EventCreated = "created"
+	EventUpdated = "updated"
+	EventDeleted = "deleted"
+)
+
+// Event is a change of the user vault pushed to the connected clients.
+type Event struct {
+	Action   string `json:"action"`
+	ItemType string `json:"item_type"`
+	ItemID   int    `json:"item_id"`
+	// Origin is the client that made the change.
+	Origin string `json:"-"`
+}
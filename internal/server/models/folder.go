@@ -0,0 +1,26 @@
+package models
+
+type Folder struct {
+	ID       int    `json:"id"`
+	UserId   int    `json:"-"`
+	ParentID *int   `json:"parent_id,omitempty"`
+	Name     string `json:"name" validate:"required,max=128"`
+}
+
+type Tag struct {
+	ID     int    `json:"id"`
+	UserId int    `json:"-"`
+	Name   string `json:"name" validate:"required,max=64"`
+}
+
+// ItemRef points to a vault item of any type.
+type ItemRef struct {
+	ItemType string `json:"item_type" validate:"required,oneof=card credential text file otp ssh_key"`
+	ItemID   int    `json:"item_id" validate:"required"`
+}
+
+// MoveItem puts the item into the folder, nil FolderID moves it to the root.
+type MoveItem struct {
+	ItemRef
+	FolderID *int `json:"folder_id"`
+}
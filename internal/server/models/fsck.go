@@ -0,0 +1,17 @@
+package models
+
+// StorageReport is what the storage check found. The orphan blobs are
+// kept by no file or upload, the missing files point to no blob and
+// the stale refs are the blobs counted wrong.
+type StorageReport struct {
+	OrphanBlobs  []string      `json:"orphan_blobs"`
+	MissingFiles []MissingFile `json:"missing_files"`
+	StaleRefs    []string      `json:"stale_refs"`
+	Fixed        bool          `json:"fixed"`
+}
+
+type MissingFile struct {
+	UserID int    `json:"user_id"`
+	FileID int    `json:"file_id"`
+	Key    string `json:"key"`
+}
@@ -0,0 +1,44 @@
+package models
+
+import "time"
+
+// The roles of the members of an organization, every role may do what
+// the roles after it do.
+const (
+	RoleOwner    = "owner"
+	RoleAdmin    = "admin"
+	RoleMember   = "member"
+	RoleReadOnly = "read-only"
+)
+
+// What a request does in the vault of an organization or of the owner of
+// an emergency access, the role of the user has to allow it.
+const (
+	AccessRead   = "read"
+	AccessWrite  = "write"
+	AccessDelete = "delete"
+)
+
+// Organization owns a vault of its members. The folders of the vault are
+// its collections. OrgKey is the key of the files of the vault sealed for
+// the member, the client sets it for the owner on create.
+type Organization struct {
+	ID        int       `json:"id"`
+	Name      string    `json:"name" validate:"required,max=128"`
+	Role      string    `json:"role"`
+	OrgKey    string    `json:"org_key,omitempty" validate:"max=512"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// OrgMember is a user of the organization with the role.
+type OrgMember struct {
+	Login     string    `json:"login" validate:"required"`
+	Role      string    `json:"role" validate:"required,oneof=owner admin member read-only"`
+	OrgKey    string    `json:"org_key,omitempty" validate:"max=512"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// OrgRole changes the role of a member.
+type OrgRole struct {
+	Role string `json:"role" validate:"required,oneof=owner admin member read-only"`
+}
@@ -0,0 +1,25 @@
+package models
+
+// OTP is the seed of an authenticator of a two-factor login. URI is the
+// otpauth:// key the services show as a QR code, the other fields are read
+// from it when it is given and it is not kept. Counter is the counter of
+// the next HOTP code, Period the time step of TOTP in seconds.
+type OTP struct {
+	ID        int               `json:"id"`
+	Version   int               `json:"version"`
+	UserId    int               `json:"-"`
+	URI       string            `json:"uri,omitempty" validate:"max=2048"`
+	Type      string            `json:"type" validate:"required,oneof=totp hotp"`
+	Issuer    string            `json:"issuer" validate:"max=256"`
+	Account   string            `json:"account" validate:"max=256"`
+	Secret    string            `json:"secret" validate:"required,max=256"`
+	Algorithm string            `json:"algorithm" validate:"required,oneof=SHA1 SHA256 SHA512"`
+	Digits    int               `json:"digits" validate:"min=6,max=8"`
+	Period    int               `json:"period" validate:"min=0,max=3600"`
+	Counter   int64             `json:"counter" validate:"min=0"`
+	Title     string            `json:"title" validate:"max=256"`
+	Note      string            `json:"note"`
+	Metadata  map[string]string `json:"metadata,omitempty" validate:"dive,keys,required,max=64,endkeys,max=1024"`
+	FolderID  *int              `json:"folder_id,omitempty"`
+	Tags      []string          `json:"tags,omitempty"`
+}
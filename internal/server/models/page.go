@@ -0,0 +1,35 @@
+package models
+
+import "time"
+
+const (
+	SortCreated = "created"
+	SortUpdated = "updated"
+
+	DefaultPageLimit = 50
+	MaxPageLimit     = 500
+)
+
+// ItemFilter narrows the lists of vault items, zero values are ignored.
+// Sort is SortCreated or SortUpdated, a "-" prefix reverses the order.
+type ItemFilter struct {
+	FolderID     int
+	Tag          string
+	UpdatedSince time.Time
+	Sort         string
+	Limit        int
+	After        *Cursor
+}
+
+// Cursor is the position of the last item of a page in the sort order it was made for.
+type Cursor struct {
+	Sort string    `json:"s"`
+	Time time.Time `json:"t"`
+	ID   int       `json:"id"`
+}
+
+// Page is one page of a list, NextCursor is empty on the last page.
+type Page[T any] struct {
+	Items      []T    `json:"items"`
+	NextCursor string `json:"next_cursor,omitempty"`
+}
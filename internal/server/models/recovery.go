@@ -0,0 +1,32 @@
+package models
+
+// RecoveryKey is the recovery key made by the client. Key is the proof of
+// the key, the server keeps its hash, and VaultKey is the vault key wrapped
+// with the key. The server never sees the key itself.
+type RecoveryKey struct {
+	Key      string `json:"key,omitempty" validate:"required,max=72"`
+	VaultKey string `json:"vault_key" validate:"required,max=512"`
+}
+
+// RecoveryState tells the client whether the user has a recovery key and
+// gives the vault key wrapped with the password the recovery set.
+type RecoveryState struct {
+	Set      bool   `json:"set"`
+	VaultKey string `json:"vault_key,omitempty"`
+}
+
+// RecoveryProof proves the user of the login has the recovery key.
+type RecoveryProof struct {
+	Login string `json:"login" validate:"required"`
+	Key   string `json:"key" validate:"required,max=72"`
+}
+
+// Recover sets the password of the user that lost it. VaultKey is the
+// vault key wrapped with the new password and Next is the recovery key
+// that replaces the one used.
+type Recover struct {
+	RecoveryProof
+	Password string      `json:"password" validate:"required,min=8"`
+	VaultKey string      `json:"vault_key" validate:"required,max=512"`
+	Next     RecoveryKey `json:"next"`
+}
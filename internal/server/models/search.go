@@ -0,0 +1,7 @@
+package models
+
+type SearchResult struct {
+	ItemType string `json:"item_type"`
+	ItemID   int    `json:"item_id"`
+	Title    string `json:"title"`
+}
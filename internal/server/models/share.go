@@ -0,0 +1,40 @@
+package models
+
+import "time"
+
+const (
+	PermissionRead  = "read"
+	PermissionWrite = "write"
+)
+
+// Share gives another user access to an item. The owner reads and writes
+// the item as before, the recipient reads it and with the write permission
+// updates it, only the owner deletes it.
+type Share struct {
+	ID         int       `json:"id"`
+	Owner      string    `json:"owner"`
+	Recipient  string    `json:"recipient" validate:"required"`
+	ItemType   string    `json:"item_type" validate:"required,oneof=card credential text file otp ssh_key"`
+	ItemID     int       `json:"item_id" validate:"required"`
+	Permission string    `json:"permission" validate:"required,oneof=read write"`
+	FileKey    string    `json:"file_key,omitempty" validate:"max=512"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// SharedItems are the items other users share with the user.
+type SharedItems struct {
+	Shares      []Share           `json:"shares"`
+	Cards       []Card            `json:"cards,omitempty"`
+	Credentials []UserCredentials `json:"credentials,omitempty"`
+	Texts       []TextData        `json:"texts,omitempty"`
+	Files       []BinaryFile      `json:"files,omitempty"`
+	OTPs        []OTP             `json:"otps,omitempty"`
+	SSHKeys     []SSHKey          `json:"ssh_keys,omitempty"`
+}
+
+// UserKeys is the key pair of the user for the shares, the private key
+// is wrapped by the vault key of the user and only the client opens it.
+type UserKeys struct {
+	PublicKey  string `json:"public_key" validate:"required,base64,max=128"`
+	PrivateKey string `json:"private_key,omitempty" validate:"required,base64,max=256"`
+}
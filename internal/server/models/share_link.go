@@ -0,0 +1,41 @@
+package models
+
+import "time"
+
+// NewShareLink asks for a link to a text or credential item of the user or
+// to the text given. The link opens MaxViews times, 0 is until it expires,
+// and expires in ExpiresIn seconds.
+type NewShareLink struct {
+	ItemType  string `json:"item_type,omitempty" validate:"omitempty,oneof=text credential"`
+	ItemID    int    `json:"item_id,omitempty" validate:"required_with=ItemType"`
+	Text      string `json:"text,omitempty" validate:"required_without=ItemType,max=65536"`
+	MaxViews  int    `json:"max_views" validate:"min=0,max=100"`
+	ExpiresIn int    `json:"expires_in" validate:"required,min=60,max=2592000"`
+}
+
+// ShareLink is a link made by the user. URL, with the key in the fragment,
+// is returned once when the link is made.
+type ShareLink struct {
+	ID        string    `json:"id"`
+	URL       string    `json:"url,omitempty"`
+	Kind      string    `json:"kind"`
+	MaxViews  int       `json:"max_views"`
+	Views     int       `json:"views"`
+	ExpiresAt time.Time `json:"expires_at"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// LinkSecret is the content of a link, sealed as JSON.
+type LinkSecret struct {
+	Title    string `json:"title,omitempty"`
+	Text     string `json:"text,omitempty"`
+	Login    string `json:"login,omitempty"`
+	Password string `json:"password,omitempty"`
+}
+
+// SealedSecret is what an opened link gives, the key in the fragment of the
+// link opens Data: the nonce followed by the AES-GCM sealed LinkSecret.
+type SealedSecret struct {
+	Kind string `json:"kind"`
+	Data []byte `json:"data"`
+}
@@ -0,0 +1,23 @@
+package models
+
+// SSHKey is a private key of SSH with what is known of it. PublicKey and
+// Fingerprint are taken from the private key and are not read from the
+// request, Certificate is an optional certificate of the key in the
+// authorized_keys format. Passphrase opens an encrypted private key.
+type SSHKey struct {
+	ID          int               `json:"id"`
+	Version     int               `json:"version"`
+	UserId      int               `json:"-"`
+	KeyType     string            `json:"key_type"`
+	PrivateKey  string            `json:"private_key" validate:"required,max=16384"`
+	PublicKey   string            `json:"public_key"`
+	Fingerprint string            `json:"fingerprint"`
+	Certificate string            `json:"certificate,omitempty" validate:"max=16384"`
+	Comment     string            `json:"comment" validate:"max=1024"`
+	Passphrase  string            `json:"passphrase,omitempty" validate:"max=1024"`
+	Title       string            `json:"title" validate:"max=256"`
+	Note        string            `json:"note"`
+	Metadata    map[string]string `json:"metadata,omitempty" validate:"dive,keys,required,max=64,endkeys,max=1024"`
+	FolderID    *int              `json:"folder_id,omitempty"`
+	Tags        []string          `json:"tags,omitempty"`
+}
@@ -0,0 +1,14 @@
+package models
+
+// SyncChanges are the items created, updated and deleted since the cursor
+// of the previous sync, Cursor is passed as ?since= to the next one.
+type SyncChanges struct {
+	Cards       []Card            `json:"cards"`
+	Credentials []UserCredentials `json:"credentials"`
+	Texts       []TextData        `json:"texts"`
+	Files       []BinaryFile      `json:"files"`
+	OTPs        []OTP             `json:"otps"`
+	SSHKeys     []SSHKey          `json:"ssh_keys"`
+	Deleted     []ItemRef         `json:"deleted"`
+	Cursor      string            `json:"cursor"`
+}
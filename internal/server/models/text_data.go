@@ -1,8 +1,13 @@
 package models
 
 type TextData struct {
-	ID      int    `json:"id"`
-	Version int    `json:"version"`
-	UserId  int    `json:"-"`
-	Text    string `json:"text" validate:"required"`
+	ID       int               `json:"id"`
+	Version  int               `json:"version"`
+	UserId   int               `json:"-"`
+	Text     string            `json:"text" validate:"required"`
+	Title    string            `json:"title" validate:"max=256"`
+	Note     string            `json:"note"`
+	Metadata map[string]string `json:"metadata,omitempty" validate:"dive,keys,required,max=64,endkeys,max=1024"`
+	FolderID *int              `json:"folder_id,omitempty"`
+	Tags     []string          `json:"tags,omitempty"`
 }
@@ -0,0 +1,26 @@
+package models
+
+import "time"
+
+const (
+	// UploadChunkSize is the chunk size the server advises to the clients.
+	UploadChunkSize = 1 << 20
+	// MaxUploadChunk is the largest chunk the server takes.
+	MaxUploadChunk = 8 << 20
+)
+
+// Range is the byte range [Start, End) of a file.
+type Range struct {
+	Start int `json:"start"`
+	End   int `json:"end"`
+}
+
+// Upload is a resumable upload of a file. The chunks may be sent in any
+// order and sent again, Received are the ranges the server already has.
+type Upload struct {
+	ID        string     `json:"id"`
+	File      BinaryFile `json:"file"`
+	ChunkSize int        `json:"chunk_size"`
+	Received  []Range    `json:"received"`
+	CreatedAt time.Time  `json:"created_at"`
+}
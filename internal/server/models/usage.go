@@ -0,0 +1,15 @@
+package models
+
+// Limits are the quotas of every user, a zero limit is no limit.
+type Limits struct {
+	Bytes    int64 `json:"quota_bytes"`
+	Items    int   `json:"quota_items"`
+	FileSize int64 `json:"max_file_size"`
+}
+
+// Usage is what the user keeps on the server against the limits.
+type Usage struct {
+	Bytes int64 `json:"bytes"`
+	Items int   `json:"items"`
+	Limits
+}
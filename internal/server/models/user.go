@@ -1,7 +1,9 @@
 package models
 
+// User signs up and in with the login, the logins starting with "org:"
+// are kept for the vaults of the organizations.
 type User struct {
 	ID       int    `json:"-"`
-	Login    string `json:"login" validate:"required,min=4"`
+	Login    string `json:"login" validate:"required,min=4,startsnotwith=org:"`
 	Password string `json:"password" validate:"required,min=8"`
 }
@@ -1,9 +1,14 @@
 package models
 
 type UserCredentials struct {
-	ID       int    `json:"id"`
-	Version  int    `json:"version"`
-	UserId   int    `json:"-"`
-	Login    string `json:"login" validate:"required,min=4"`
-	Password string `json:"password" validate:"required,min=8"`
+	ID       int               `json:"id"`
+	Version  int               `json:"version"`
+	UserId   int               `json:"-"`
+	Login    string            `json:"login" validate:"required,min=4"`
+	Password string            `json:"password" validate:"required,min=8"`
+	Title    string            `json:"title" validate:"max=256"`
+	Note     string            `json:"note"`
+	Metadata map[string]string `json:"metadata,omitempty" validate:"dive,keys,required,max=64,endkeys,max=1024"`
+	FolderID *int              `json:"folder_id,omitempty"`
+	Tags     []string          `json:"tags,omitempty"`
 }
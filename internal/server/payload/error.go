@@ -18,3 +18,19 @@ func NewErrorResponse(w http.ResponseWriter, message string, statusCode int) {
 		return
 	}
 }
+
+// Conflict is the answer to an update made over an old version of the item,
+// Current is the item as it is on the server now.
+type Conflict struct {
+	ErrorMessage
+	Current any `json:"current"`
+}
+
+func NewConflictResponse(w http.ResponseWriter, message string, current any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusConflict)
+	conflict := Conflict{ErrorMessage: ErrorMessage{Message: message, StatusCode: http.StatusConflict}, Current: current}
+	if err := json.NewEncoder(w).Encode(conflict); err != nil {
+		return
+	}
+}
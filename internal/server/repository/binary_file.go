@@ -1,9 +1,12 @@
 package repository
 
 import (
+	"database/sql"
+	"errors"
 	"fmt"
 
 	"github.com/zelas91/goph-keeper/internal/server/repository/entities"
+	"github.com/zelas91/goph-keeper/internal/server/types"
 	"golang.org/x/net/context"
 )
 
@@ -11,37 +14,124 @@ type binaryFile struct {
 	tm transactionManager
 }
 
-func (b binaryFile) Create(ctx context.Context, bf entities.BinaryFile) error {
-	query := `insert into binary_file (path, file_name, user_id, size)
-		values (:path,:file_name,:user_id, :size);`
-	if _, err := b.tm.getConn(ctx).NamedExecContext(ctx, query, bf); err != nil {
-		return fmt.Errorf("repo binary file create err: %w", err)
+// Create saves the file, takes a reference to the blob of the user with its
+// content and returns the id of the file and the key of the blob the file
+// points to. When the user has the content already the file points to the
+// blob kept before, takes its codec and the blob of bf.Path is not needed.
+// The upload of the file is claimed in the same transaction, a file of an
+// upload that is gone already is not created again and ErrNotFound is
+// returned.
+func (b binaryFile) Create(ctx context.Context, bf entities.BinaryFile, blobSize int64) (int, string, error) {
+	var id int
+	err := b.tm.do(ctx, func(ctx context.Context) error {
+		if bf.UploadID != "" {
+			query := `delete from uploads where id=$1 and user_id=$2`
+			result, err := b.tm.getConn(ctx).ExecContext(ctx, query, bf.UploadID, bf.UserId)
+			if err != nil {
+				return fmt.Errorf("repo upload claim err: %w", err)
+			}
+			if err = affected(result); err != nil {
+				return err
+			}
+		}
+		query := `insert into blobs (user_id, sha256, key, size, codec, refs) values ($1, $2, $3, $4, $5, 1)
+			on conflict (user_id, sha256) do update set refs = blobs.refs + 1 returning key, codec;`
+		var blob struct {
+			Key   string `db:"key"`
+			Codec string `db:"codec"`
+		}
+		if err := b.tm.getConn(ctx).GetContext(ctx, &blob, query,
+			bf.UserId, bf.Sha256, bf.Path, blobSize, bf.Codec); err != nil {
+			return fmt.Errorf("repo blob acquire err: %w", err)
+		}
+		bf.Path, bf.Codec = blob.Key, blob.Codec
+		seq, err := nextChangeSeq(ctx, b.tm, bf.UserId)
+		if err != nil {
+			return err
+		}
+		bf.ChangeSeq = seq
+		query = `insert into binary_file (path, file_name, user_id, size, sha256, codec, file_key, title, note,
+				metadata, change_seq, folder_id)
+			values (:path,:file_name,:user_id, :size, :sha256, :codec, :file_key, :title, :note, :metadata, :change_seq,
+				(select id from folders where id=:folder_id and user_id=:user_id)) returning id;`
+		if err = namedGet(ctx, b.tm, &id, query, bf); err != nil {
+			return fmt.Errorf("repo binary file create err: %w", err)
+		}
+		return saveSearchTokens(ctx, b.tm, bf.UserId, types.ItemFile, id, bf.SearchTokens)
+	})
+	if err != nil {
+		return 0, "", err
 	}
-	return nil
+	return id, bf.Path, nil
 }
 
 func (b binaryFile) FindByIDAndUserID(ctx context.Context, fileID, userID int) (entities.BinaryFile, error) {
-	query := `select * from binary_file where id=$1 and user_id=$2`
+	// update_at has no time zone, the content is served with the instant
+	// of the last change.
+	query := fmt.Sprintf(`select i.*, %s, i.update_at at time zone current_setting('TimeZone') as mod_time
+		from %s i where i.id=$1 and i.user_id=$2`, itemTagsColumn(types.ItemFile), itemTables[types.ItemFile])
 	var bf entities.BinaryFile
 	if err := b.tm.getConn(ctx).GetContext(ctx, &bf, query, fileID, userID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return bf, ErrNotFound
+		}
 		return bf, fmt.Errorf("repo: binary file get id=%d  err: %w", fileID, err)
 	}
 	return bf, nil
 }
 
-func (b binaryFile) FindAllByUserID(ctx context.Context, userID int) ([]entities.BinaryFile, error) {
-	query := `select * from binary_file where user_id=$1`
+func (b binaryFile) FindAllByUserID(ctx context.Context, userID int,
+	filter entities.ItemFilter) ([]entities.BinaryFile, error) {
+	query, args := itemListSelect(types.ItemFile, userID, filter)
 	var files []entities.BinaryFile
-	if err := b.tm.getConn(ctx).SelectContext(ctx, &files, query, userID); err != nil {
+	if err := b.tm.getConn(ctx).SelectContext(ctx, &files, query, args...); err != nil {
 		return files, fmt.Errorf("repo: get binary files err %w", err)
 	}
 	return files, nil
 }
 
-func (b binaryFile) Delete(ctx context.Context, userID, fileID int) error {
-	query := `delete from binary_file where id=$1 and user_id=$2`
-	if _, err := b.tm.getConn(ctx).ExecContext(ctx, query, fileID, userID); err != nil {
-		return fmt.Errorf("repo binary file delete err: %w", err)
+// Delete removes the file and drops its reference to the blob, the key
+// of the blob nobody points to any more is returned to be removed. The
+// files stored before the blobs were counted own their blob alone.
+func (b binaryFile) Delete(ctx context.Context, userID, fileID int) (string, error) {
+	var orphan string
+	err := b.tm.do(ctx, func(ctx context.Context) error {
+		if err := deleteItemLinks(ctx, b.tm, types.ItemFile, fileID, userID); err != nil {
+			return err
+		}
+		query := `delete from binary_file where id=$1 and user_id=$2 returning path`
+		var key string
+		if err := b.tm.getConn(ctx).GetContext(ctx, &key, query, fileID, userID); err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return nil
+			}
+			return fmt.Errorf("repo binary file delete err: %w", err)
+		}
+		if err := addTombstone(ctx, b.tm, userID, types.ItemFile, fileID); err != nil {
+			return err
+		}
+
+		query = `update blobs set refs = refs - 1 where user_id=$1 and key=$2 returning refs`
+		var refs int
+		err := b.tm.getConn(ctx).GetContext(ctx, &refs, query, userID, key)
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			orphan = key
+			return nil
+		case err != nil:
+			return fmt.Errorf("repo blob release err: %w", err)
+		case refs > 0:
+			return nil
+		}
+		query = `delete from blobs where user_id=$1 and key=$2`
+		if _, err = b.tm.getConn(ctx).ExecContext(ctx, query, userID, key); err != nil {
+			return fmt.Errorf("repo blob delete err: %w", err)
+		}
+		orphan = key
+		return nil
+	})
+	if err != nil {
+		return "", err
 	}
-	return nil
+	return orphan, nil
 }
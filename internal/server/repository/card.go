@@ -1,10 +1,10 @@
 package repository
 
 import (
-	"errors"
 	"fmt"
 
 	"github.com/zelas91/goph-keeper/internal/server/repository/entities"
+	"github.com/zelas91/goph-keeper/internal/server/types"
 	"golang.org/x/net/context"
 )
 
@@ -12,26 +12,40 @@ type creditCard struct {
 	tm transactionManager
 }
 
-func (c creditCard) Create(ctx context.Context, card entities.Card) error {
-	query := `insert into cards (number,expired_at,cvv,user_id)
-		values (:number,:expired_at,:cvv,:user_id);`
-	if _, err := c.tm.getConn(ctx).NamedExecContext(ctx, query, card); err != nil {
-		return fmt.Errorf("repo card create err: %w", err)
+func (c creditCard) Create(ctx context.Context, card entities.Card) (int, error) {
+	var id int
+	err := c.tm.do(ctx, func(ctx context.Context) error {
+		seq, err := nextChangeSeq(ctx, c.tm, card.UserId)
+		if err != nil {
+			return err
+		}
+		card.ChangeSeq = seq
+		query := `insert into cards (number,expired_at,cvv,user_id,title,note,metadata,change_seq,folder_id)
+			values (:number,:expired_at,:cvv,:user_id,:title,:note,:metadata,:change_seq,
+				(select id from folders where id=:folder_id and user_id=:user_id)) returning id;`
+		if err = namedGet(ctx, c.tm, &id, query, card); err != nil {
+			return fmt.Errorf("repo card create err: %w", err)
+		}
+		return saveSearchTokens(ctx, c.tm, card.UserId, types.ItemCard, id, card.SearchTokens)
+	})
+	if err != nil {
+		return 0, err
 	}
-	return nil
+	return id, nil
 }
 
-func (c creditCard) FindAllByUserID(ctx context.Context, userID int) ([]entities.Card, error) {
-	query := `select * from cards where user_id=$1`
+func (c creditCard) FindAllByUserID(ctx context.Context, userID int,
+	filter entities.ItemFilter) ([]entities.Card, error) {
+	query, args := itemListSelect(types.ItemCard, userID, filter)
 	var cards []entities.Card
-	if err := c.tm.getConn(ctx).SelectContext(ctx, &cards, query, userID); err != nil {
+	if err := c.tm.getConn(ctx).SelectContext(ctx, &cards, query, args...); err != nil {
 		return cards, fmt.Errorf("repo: get cards err %w", err)
 	}
 	return cards, nil
 }
 
 func (c creditCard) FindByIDAndUserID(ctx context.Context, cardID, userID int) (entities.Card, error) {
-	query := `select * from cards where id=$1 and user_id=$2`
+	query := itemSelect(types.ItemCard)
 	var card entities.Card
 	if err := c.tm.getConn(ctx).GetContext(ctx, &card, query, cardID, userID); err != nil {
 		return card, fmt.Errorf("repo: card get id=%d  err: %w", cardID, err)
@@ -40,11 +54,20 @@ func (c creditCard) FindByIDAndUserID(ctx context.Context, cardID, userID int) (
 }
 
 func (c creditCard) Delete(ctx context.Context, cardID, userID int) error {
-	query := `delete from cards where id=$1 and user_id=$2`
-	if _, err := c.tm.getConn(ctx).ExecContext(ctx, query, cardID, userID); err != nil {
-		return fmt.Errorf("repo card delete err: %w", err)
-	}
-	return nil
+	return c.tm.do(ctx, func(ctx context.Context) error {
+		if err := deleteItemLinks(ctx, c.tm, types.ItemCard, cardID, userID); err != nil {
+			return err
+		}
+		query := `delete from cards where id=$1 and user_id=$2`
+		result, err := c.tm.getConn(ctx).ExecContext(ctx, query, cardID, userID)
+		if err != nil {
+			return fmt.Errorf("repo card delete err: %w", err)
+		}
+		if rows, err := result.RowsAffected(); err != nil || rows == 0 {
+			return err
+		}
+		return addTombstone(ctx, c.tm, userID, types.ItemCard, cardID)
+	})
 }
 
 func (c creditCard) Update(ctx context.Context, card entities.Card) error {
@@ -53,10 +76,19 @@ func (c creditCard) Update(ctx context.Context, card entities.Card) error {
 		if _, err := c.tm.getConn(ctx).ExecContext(ctx, query, card.ID); err != nil {
 			return fmt.Errorf("repo card update block err :%w", err)
 		}
+		seq, err := nextChangeSeq(ctx, c.tm, card.UserId)
+		if err != nil {
+			return err
+		}
+		card.ChangeSeq = seq
 		query = `update cards set
 				number=:number,
 				cvv=:cvv,
-				expired_at=:expired_at
+				expired_at=:expired_at,
+				title=:title,
+				note=:note,
+				metadata=:metadata,
+				change_seq=:change_seq
 			where
 				id=:id and user_id=:user_id and version=:version;`
 		result, err := c.tm.getConn(ctx).NamedExecContext(ctx, query, card)
@@ -68,9 +100,9 @@ func (c creditCard) Update(ctx context.Context, card entities.Card) error {
 			return fmt.Errorf("repo card update result err: %w", err)
 		}
 		if rowsAffected == 0 {
-			return errors.New("the versions on the server and client do not match")
+			return updateMissed(ctx, c.tm, types.ItemCard, card.ID, card.UserId)
 		}
-		return nil
+		return saveSearchTokens(ctx, c.tm, card.UserId, types.ItemCard, card.ID, card.SearchTokens)
 	})
 
 	return err
@@ -0,0 +1,73 @@
+package repository
+
+import (
+	"fmt"
+
+	"github.com/zelas91/goph-keeper/internal/server/repository/entities"
+	"golang.org/x/net/context"
+)
+
+type change struct {
+	tm transactionManager
+}
+
+// ChangeSeq returns the last committed change sequence of the user.
+func (c change) ChangeSeq(ctx context.Context, userID int) (int64, error) {
+	query := `select change_seq from users where id=$1`
+	var seq int64
+	if err := c.tm.getConn(ctx).GetContext(ctx, &seq, query, userID); err != nil {
+		return 0, fmt.Errorf("repo: get change seq err %w", err)
+	}
+	return seq, nil
+}
+
+// Tombstones returns the items of the user deleted in (after, upTo].
+func (c change) Tombstones(ctx context.Context, userID int, after, upTo int64) ([]entities.Tombstone, error) {
+	query := `select * from tombstones where user_id=$1 and change_seq > $2 and change_seq <= $3
+		order by change_seq`
+	var tombstones []entities.Tombstone
+	if err := c.tm.getConn(ctx).SelectContext(ctx, &tombstones, query, userID, after, upTo); err != nil {
+		return tombstones, fmt.Errorf("repo: get tombstones err %w", err)
+	}
+	return tombstones, nil
+}
+
+// nextChangeSeq moves the change sequence of the user on. The users row stays
+// locked until the transaction ends, so the changes of one user commit
+// in the order of their sequence numbers.
+func nextChangeSeq(ctx context.Context, tm transactionManager, userID int) (int64, error) {
+	query := `update users set change_seq = change_seq + 1 where id=$1 returning change_seq`
+	var seq int64
+	if err := tm.getConn(ctx).GetContext(ctx, &seq, query, userID); err != nil {
+		return 0, fmt.Errorf("repo next change seq err: %w", err)
+	}
+	return seq, nil
+}
+
+// touchItems marks the user items selected by the where clause as changed,
+// where is written over the item table and its args start at $3.
+func touchItems(ctx context.Context, tm transactionManager, userID int, itemType string,
+	where string, args ...any) error {
+	seq, err := nextChangeSeq(ctx, tm, userID)
+	if err != nil {
+		return err
+	}
+	query := fmt.Sprintf(`update %s set change_seq=$1 where user_id=$2 and %s`, itemTables[itemType], where)
+	if _, err = tm.getConn(ctx).ExecContext(ctx, query, append([]any{seq, userID}, args...)...); err != nil {
+		return fmt.Errorf("repo touch %s err: %w", itemType, err)
+	}
+	return nil
+}
+
+// addTombstone records the deletion of the item.
+func addTombstone(ctx context.Context, tm transactionManager, userID int, itemType string, itemID int) error {
+	seq, err := nextChangeSeq(ctx, tm, userID)
+	if err != nil {
+		return err
+	}
+	query := `insert into tombstones (user_id, item_type, item_id, change_seq) values ($1, $2, $3, $4)`
+	if _, err = tm.getConn(ctx).ExecContext(ctx, query, userID, itemType, itemID, seq); err != nil {
+		return fmt.Errorf("repo tombstone save err: %w", err)
+	}
+	return nil
+}
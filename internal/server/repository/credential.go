@@ -1,10 +1,10 @@
 package repository
 
 import (
-	"errors"
 	"fmt"
 
 	"github.com/zelas91/goph-keeper/internal/server/repository/entities"
+	"github.com/zelas91/goph-keeper/internal/server/types"
 	"golang.org/x/net/context"
 )
 
@@ -12,26 +12,40 @@ type credential struct {
 	tm transactionManager
 }
 
-func (c credential) Create(ctx context.Context, uc entities.UserCredentials) error {
-	query := `insert into user_credentials (login,password,user_id)
-		values (:login,:password,:user_id);`
-	if _, err := c.tm.getConn(ctx).NamedExecContext(ctx, query, uc); err != nil {
-		return fmt.Errorf("repo credentials create err: %w", err)
+func (c credential) Create(ctx context.Context, uc entities.UserCredentials) (int, error) {
+	var id int
+	err := c.tm.do(ctx, func(ctx context.Context) error {
+		seq, err := nextChangeSeq(ctx, c.tm, uc.UserId)
+		if err != nil {
+			return err
+		}
+		uc.ChangeSeq = seq
+		query := `insert into user_credentials (login,password,user_id,title,note,metadata,change_seq,folder_id)
+			values (:login,:password,:user_id,:title,:note,:metadata,:change_seq,
+				(select id from folders where id=:folder_id and user_id=:user_id)) returning id;`
+		if err = namedGet(ctx, c.tm, &id, query, uc); err != nil {
+			return fmt.Errorf("repo credentials create err: %w", err)
+		}
+		return saveSearchTokens(ctx, c.tm, uc.UserId, types.ItemCredential, id, uc.SearchTokens)
+	})
+	if err != nil {
+		return 0, err
 	}
-	return nil
+	return id, nil
 }
 
-func (c credential) FindAllByUserID(ctx context.Context, userID int) ([]entities.UserCredentials, error) {
-	query := `select * from user_credentials where user_id=$1`
+func (c credential) FindAllByUserID(ctx context.Context, userID int,
+	filter entities.ItemFilter) ([]entities.UserCredentials, error) {
+	query, args := itemListSelect(types.ItemCredential, userID, filter)
 	var ucs []entities.UserCredentials
-	if err := c.tm.getConn(ctx).SelectContext(ctx, &ucs, query, userID); err != nil {
+	if err := c.tm.getConn(ctx).SelectContext(ctx, &ucs, query, args...); err != nil {
 		return ucs, fmt.Errorf("repo: get credentials err %w", err)
 	}
 	return ucs, nil
 }
 
 func (c credential) FindByIDAndUserID(ctx context.Context, ucID, userID int) (entities.UserCredentials, error) {
-	query := `select * from user_credentials where id=$1 and user_id=$2`
+	query := itemSelect(types.ItemCredential)
 	var uc entities.UserCredentials
 	if err := c.tm.getConn(ctx).GetContext(ctx, &uc, query, ucID, userID); err != nil {
 		return uc, fmt.Errorf("repo: credentials get id=%d  err: %w", ucID, err)
@@ -40,11 +54,20 @@ func (c credential) FindByIDAndUserID(ctx context.Context, ucID, userID int) (en
 }
 
 func (c credential) Delete(ctx context.Context, ucID, userID int) error {
-	query := `delete from user_credentials where id=$1 and user_id=$2`
-	if _, err := c.tm.getConn(ctx).ExecContext(ctx, query, ucID, userID); err != nil {
-		return fmt.Errorf("repo credentials delete err: %w", err)
-	}
-	return nil
+	return c.tm.do(ctx, func(ctx context.Context) error {
+		if err := deleteItemLinks(ctx, c.tm, types.ItemCredential, ucID, userID); err != nil {
+			return err
+		}
+		query := `delete from user_credentials where id=$1 and user_id=$2`
+		result, err := c.tm.getConn(ctx).ExecContext(ctx, query, ucID, userID)
+		if err != nil {
+			return fmt.Errorf("repo credentials delete err: %w", err)
+		}
+		if rows, err := result.RowsAffected(); err != nil || rows == 0 {
+			return err
+		}
+		return addTombstone(ctx, c.tm, userID, types.ItemCredential, ucID)
+	})
 }
 
 func (c credential) Update(ctx context.Context, uc entities.UserCredentials) error {
@@ -53,9 +76,18 @@ func (c credential) Update(ctx context.Context, uc entities.UserCredentials) err
 		if _, err := c.tm.getConn(ctx).ExecContext(ctx, query, uc.ID); err != nil {
 			return fmt.Errorf("repo credentials update block err :%w", err)
 		}
+		seq, err := nextChangeSeq(ctx, c.tm, uc.UserId)
+		if err != nil {
+			return err
+		}
+		uc.ChangeSeq = seq
 		query = `update user_credentials set
 				login=:login,
-				password=:password
+				password=:password,
+				title=:title,
+				note=:note,
+				metadata=:metadata,
+				change_seq=:change_seq
 			where
 				id=:id and user_id=:user_id and version=:version;`
 		result, err := c.tm.getConn(ctx).NamedExecContext(ctx, query, uc)
@@ -67,9 +99,9 @@ func (c credential) Update(ctx context.Context, uc entities.UserCredentials) err
 			return fmt.Errorf("repo credentials update result err: %w", err)
 		}
 		if rowsAffected == 0 {
-			return errors.New("the versions on the server and client do not match")
+			return updateMissed(ctx, c.tm, types.ItemCredential, uc.ID, uc.UserId)
 		}
-		return nil
+		return saveSearchTokens(ctx, c.tm, uc.UserId, types.ItemCredential, uc.ID, uc.SearchTokens)
 	})
 
 	return err
@@ -0,0 +1,132 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/zelas91/goph-keeper/internal/server/repository/entities"
+	"golang.org/x/net/context"
+)
+
+type emergency struct {
+	tm transactionManager
+}
+
+const emergencySelect = `select e.*, o.login as owner, c.login as contact from emergency_access e
+	join users o on o.id = e.owner_id join users c on c.id = e.contact_id`
+
+// Create names the user of the login the contact of the owner, naming the
+// contact again sets the new waiting period and key and starts over.
+// ErrNotFound is returned when there is no other user of the login.
+func (e emergency) Create(ctx context.Context, ea entities.EmergencyAccess) (int, error) {
+	query := `insert into emergency_access (owner_id, contact_id, wait_hours, vault_key)
+		select $1, u.id, $2, $3 from users u where u.login = $4 and u.id <> $1
+		on conflict (owner_id, contact_id) do update set wait_hours = excluded.wait_hours,
+			vault_key = excluded.vault_key, status = 'idle', requested_at = null, granted_at = null
+		returning id`
+	var id int
+	err := e.tm.getConn(ctx).GetContext(ctx, &id, query, ea.OwnerID, ea.WaitHours, ea.VaultKey, ea.Contact)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, ErrNotFound
+	}
+	if err != nil {
+		return 0, fmt.Errorf("repo emergency access create err: %w", err)
+	}
+	return id, nil
+}
+
+// FindByOwner returns the contacts of the owner.
+func (e emergency) FindByOwner(ctx context.Context, ownerID int) ([]entities.EmergencyAccess, error) {
+	var accesses []entities.EmergencyAccess
+	query := emergencySelect + ` where e.owner_id=$1 order by e.id`
+	if err := e.tm.getConn(ctx).SelectContext(ctx, &accesses, query, ownerID); err != nil {
+		return nil, fmt.Errorf("repo emergency accesses get err: %w", err)
+	}
+	return accesses, nil
+}
+
+// FindByContact returns the owners that name the user their contact.
+func (e emergency) FindByContact(ctx context.Context, contactID int) ([]entities.EmergencyAccess, error) {
+	var accesses []entities.EmergencyAccess
+	query := emergencySelect + ` where e.contact_id=$1 order by e.id`
+	if err := e.tm.getConn(ctx).SelectContext(ctx, &accesses, query, contactID); err != nil {
+		return nil, fmt.Errorf("repo emergency accesses get err: %w", err)
+	}
+	return accesses, nil
+}
+
+// FindGranted returns the access granted to the contact, ErrNotFound for
+// the accesses not granted.
+func (e emergency) FindGranted(ctx context.Context, accessID, contactID int) (entities.EmergencyAccess, error) {
+	var ea entities.EmergencyAccess
+	query := emergencySelect + ` where e.id=$1 and e.contact_id=$2 and e.status='granted'`
+	err := e.tm.getConn(ctx).GetContext(ctx, &ea, query, accessID, contactID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ea, ErrNotFound
+	}
+	if err != nil {
+		return ea, fmt.Errorf("repo emergency access get err: %w", err)
+	}
+	return ea, nil
+}
+
+// Request starts the waiting period of the idle access of the contact.
+func (e emergency) Request(ctx context.Context, accessID, contactID int, now time.Time) error {
+	query := `update emergency_access set status='requested', requested_at=$3
+		where id=$1 and contact_id=$2 and status='idle'`
+	result, err := e.tm.getConn(ctx).ExecContext(ctx, query, accessID, contactID, now)
+	if err != nil {
+		return fmt.Errorf("repo emergency access request err: %w", err)
+	}
+	return affected(result)
+}
+
+// Approve grants the requested access before the waiting period ends.
+func (e emergency) Approve(ctx context.Context, accessID, ownerID int, now time.Time) error {
+	query := `update emergency_access set status='granted', granted_at=$3
+		where id=$1 and owner_id=$2 and status='requested'`
+	result, err := e.tm.getConn(ctx).ExecContext(ctx, query, accessID, ownerID, now)
+	if err != nil {
+		return fmt.Errorf("repo emergency access approve err: %w", err)
+	}
+	return affected(result)
+}
+
+// Reject turns the requested or granted access back to idle.
+func (e emergency) Reject(ctx context.Context, accessID, ownerID int) error {
+	query := `update emergency_access set status='idle', requested_at=null, granted_at=null
+		where id=$1 and owner_id=$2 and status<>'idle'`
+	result, err := e.tm.getConn(ctx).ExecContext(ctx, query, accessID, ownerID)
+	if err != nil {
+		return fmt.Errorf("repo emergency access reject err: %w", err)
+	}
+	return affected(result)
+}
+
+// Delete removes the access, the owner and the contact do it.
+func (e emergency) Delete(ctx context.Context, accessID, userID int) error {
+	query := `delete from emergency_access where id=$1 and (owner_id=$2 or contact_id=$2)`
+	result, err := e.tm.getConn(ctx).ExecContext(ctx, query, accessID, userID)
+	if err != nil {
+		return fmt.Errorf("repo emergency access delete err: %w", err)
+	}
+	return affected(result)
+}
+
+// GrantExpired grants the requests whose waiting period has passed and
+// returns how many it granted.
+func (e emergency) GrantExpired(ctx context.Context, now time.Time) (int64, error) {
+	query := `update emergency_access set status='granted', granted_at=$1
+		where status='requested' and requested_at + make_interval(hours => wait_hours) <= $1`
+	result, err := e.tm.getConn(ctx).ExecContext(ctx, query, now)
+	if err != nil {
+		return 0, fmt.Errorf("repo emergency access grant err: %w", err)
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("rows affected err: %w", err)
+	}
+	return rows, nil
+}
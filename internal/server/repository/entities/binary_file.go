@@ -1,12 +1,31 @@
 package entities
 
-import "time"
+import (
+	"time"
+
+	"github.com/lib/pq"
+)
 
 type BinaryFile struct {
-	ID        int       `db:"id"`
-	UserId    int       `db:"user_id"`
-	Path      string    `db:"path"`
-	FileName  string    `db:"file_name"`
-	Size      int       `db:"size"`
-	CreatedAt time.Time `db:"created_at"`
+	ID        int            `db:"id"`
+	UserId    int            `db:"user_id"`
+	Path      string         `db:"path"`
+	FileName  string         `db:"file_name"`
+	Size      int            `db:"size"`
+	Sha256    string         `db:"sha256"`
+	Codec     string         `db:"codec"`
+	FileKey   string         `db:"file_key"`
+	CreatedAt time.Time      `db:"created_at"`
+	UpdateAt  time.Time      `db:"update_at"`
+	Title     []byte         `db:"title"`
+	Note      []byte         `db:"note"`
+	Metadata  []byte         `db:"metadata"`
+	FolderID  *int           `db:"folder_id"`
+	Tags      pq.StringArray `db:"tags"`
+	ChangeSeq int64          `db:"change_seq"`
+	// ModTime is update_at read in the time zone of the db session, it is
+	// set by FindByIDAndUserID only.
+	ModTime      time.Time     `db:"mod_time"`
+	SearchTokens pq.ByteaArray `db:"-"`
+	UploadID     string        `db:"-"`
 }
@@ -0,0 +1,9 @@
+package entities
+
+// BlobRefs is a counted blob with the count of the files pointing to it.
+type BlobRefs struct {
+	Key    string `db:"key"`
+	UserId int    `db:"user_id"`
+	Refs   int    `db:"refs"`
+	Files  int    `db:"files"`
+}
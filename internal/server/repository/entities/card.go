@@ -2,15 +2,24 @@ package entities
 
 import (
 	"time"
+
+	"github.com/lib/pq"
 )
 
 type Card struct {
-	ID        int       `db:"id"`
-	Version   int       `db:"version"`
-	Number    []byte    `db:"number"`
-	ExpiredAt []byte    `db:"expired_at"`
-	Cvv       []byte    `db:"cvv"`
-	UserId    int       `db:"user_id"`
-	UpdateAt  time.Time `db:"update_at"`
-	CreatedAt time.Time `db:"created_at"`
+	ID           int            `db:"id"`
+	Version      int            `db:"version"`
+	Number       []byte         `db:"number"`
+	ExpiredAt    []byte         `db:"expired_at"`
+	Cvv          []byte         `db:"cvv"`
+	UserId       int            `db:"user_id"`
+	UpdateAt     time.Time      `db:"update_at"`
+	CreatedAt    time.Time      `db:"created_at"`
+	Title        []byte         `db:"title"`
+	Note         []byte         `db:"note"`
+	Metadata     []byte         `db:"metadata"`
+	FolderID     *int           `db:"folder_id"`
+	Tags         pq.StringArray `db:"tags"`
+	ChangeSeq    int64          `db:"change_seq"`
+	SearchTokens pq.ByteaArray  `db:"-"`
 }
@@ -0,0 +1,20 @@
+package entities
+
+import "time"
+
+// EmergencyAccess lets the contact read the vault of the owner once the
+// owner approves the request of the contact or does not reject it in time.
+// VaultKey is the vault key of the owner sealed for the contact.
+type EmergencyAccess struct {
+	ID          int        `db:"id"`
+	OwnerID     int        `db:"owner_id"`
+	ContactID   int        `db:"contact_id"`
+	Owner       string     `db:"owner"`
+	Contact     string     `db:"contact"`
+	WaitHours   int        `db:"wait_hours"`
+	Status      string     `db:"status"`
+	VaultKey    string     `db:"vault_key"`
+	RequestedAt *time.Time `db:"requested_at"`
+	GrantedAt   *time.Time `db:"granted_at"`
+	CreatedAt   time.Time  `db:"created_at"`
+}
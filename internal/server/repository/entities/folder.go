@@ -0,0 +1,18 @@
+package entities
+
+import "time"
+
+type Folder struct {
+	ID        int       `db:"id"`
+	UserId    int       `db:"user_id"`
+	ParentID  *int      `db:"parent_id"`
+	Name      string    `db:"name"`
+	CreatedAt time.Time `db:"created_at"`
+}
+
+type Tag struct {
+	ID        int       `db:"id"`
+	UserId    int       `db:"user_id"`
+	Name      string    `db:"name"`
+	CreatedAt time.Time `db:"created_at"`
+}
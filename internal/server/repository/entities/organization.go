@@ -0,0 +1,27 @@
+package entities
+
+import "time"
+
+// Organization keeps its items in the vault of its own user, the members
+// read and write them as that user. Role and OrgKey are the ones of the
+// member the organization is read for.
+type Organization struct {
+	ID          int       `db:"id"`
+	Name        string    `db:"name"`
+	VaultUserID int       `db:"vault_user_id"`
+	VaultLogin  string    `db:"vault_login"`
+	Role        string    `db:"role"`
+	OrgKey      string    `db:"org_key"`
+	CreatedAt   time.Time `db:"created_at"`
+}
+
+// OrgMember is a user of the organization, OrgKey is the key of the files
+// of the organization sealed for the user.
+type OrgMember struct {
+	OrgID     int       `db:"org_id"`
+	UserID    int       `db:"user_id"`
+	Login     string    `db:"login"`
+	Role      string    `db:"role"`
+	OrgKey    string    `db:"org_key"`
+	CreatedAt time.Time `db:"created_at"`
+}
@@ -0,0 +1,30 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/lib/pq"
+)
+
+type OTP struct {
+	ID           int            `db:"id"`
+	Version      int            `db:"version"`
+	UserId       int            `db:"user_id"`
+	UpdateAt     time.Time      `db:"update_at"`
+	CreatedAt    time.Time      `db:"created_at"`
+	Type         string         `db:"type"`
+	Issuer       []byte         `db:"issuer"`
+	Account      []byte         `db:"account"`
+	Secret       []byte         `db:"secret"`
+	Algorithm    string         `db:"algorithm"`
+	Digits       int            `db:"digits"`
+	Period       int            `db:"period"`
+	Counter      int64          `db:"counter"`
+	Title        []byte         `db:"title"`
+	Note         []byte         `db:"note"`
+	Metadata     []byte         `db:"metadata"`
+	FolderID     *int           `db:"folder_id"`
+	Tags         pq.StringArray `db:"tags"`
+	ChangeSeq    int64          `db:"change_seq"`
+	SearchTokens pq.ByteaArray  `db:"-"`
+}
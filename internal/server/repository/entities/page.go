@@ -0,0 +1,24 @@
+package entities
+
+import "time"
+
+// ItemFilter narrows the lists of vault items, zero values are ignored.
+// SortColumn is created_at or update_at, Limit 0 returns all the items.
+// ChangedUpTo above zero keeps the items changed in (ChangedAfter, ChangedUpTo].
+type ItemFilter struct {
+	FolderID     int
+	Tag          string
+	UpdatedSince time.Time
+	ChangedAfter int64
+	ChangedUpTo  int64
+	SortColumn   string
+	Desc         bool
+	Limit        int
+	After        *ItemCursor
+}
+
+// ItemCursor is the sort key of the last item of the previous page.
+type ItemCursor struct {
+	Time time.Time
+	ID   int
+}
@@ -0,0 +1,20 @@
+package entities
+
+import "time"
+
+// RecoveryKey is the recovery key of the user as the server keeps it: the
+// hash of the proof of the key and the vault key wrapped with the key.
+type RecoveryKey struct {
+	UserID    int       `db:"user_id"`
+	KeyHash   string    `db:"key_hash"`
+	VaultKey  string    `db:"vault_key"`
+	CreatedAt time.Time `db:"created_at"`
+}
+
+// RecoveryState tells whether the user has a recovery key. VaultKey is the
+// vault key wrapped with the password set by the recovery, it is empty
+// until the user recovers the account.
+type RecoveryState struct {
+	Set      bool   `db:"set"`
+	VaultKey string `db:"vault_key"`
+}
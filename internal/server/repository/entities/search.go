@@ -0,0 +1,14 @@
+package entities
+
+type SearchResult struct {
+	ItemType string `db:"item_type"`
+	ItemID   int    `db:"item_id"`
+	Title    []byte `db:"title"`
+}
+
+// UnindexedItem is an item that has no search tokens, like the items saved
+// before the search was added.
+type UnindexedItem struct {
+	ID     int `db:"id"`
+	UserID int `db:"user_id"`
+}
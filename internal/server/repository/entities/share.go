@@ -0,0 +1,25 @@
+package entities
+
+import "time"
+
+// Share gives the recipient access to an item of the owner, FileKey is
+// the key of a file encrypted by the client wrapped for the recipient.
+type Share struct {
+	ID          int       `db:"id"`
+	OwnerID     int       `db:"owner_id"`
+	RecipientID int       `db:"recipient_id"`
+	Owner       string    `db:"owner"`
+	Recipient   string    `db:"recipient"`
+	ItemType    string    `db:"item_type"`
+	ItemID      int       `db:"item_id"`
+	Permission  string    `db:"permission"`
+	FileKey     string    `db:"file_key"`
+	CreatedAt   time.Time `db:"created_at"`
+}
+
+// UserKeys is the key pair of the user for the shares, the private key
+// is wrapped by the vault key the server never has.
+type UserKeys struct {
+	PublicKey  string `db:"public_key"`
+	PrivateKey string `db:"private_key"`
+}
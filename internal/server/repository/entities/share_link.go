@@ -0,0 +1,16 @@
+package entities
+
+import "time"
+
+// ShareLink is a secret sealed with a key the server does not keep, the
+// key is in the fragment of the link only.
+type ShareLink struct {
+	ID        string    `db:"id"`
+	UserID    int       `db:"user_id"`
+	Kind      string    `db:"kind"`
+	Data      []byte    `db:"data"`
+	MaxViews  int       `db:"max_views"`
+	Views     int       `db:"views"`
+	ExpiresAt time.Time `db:"expires_at"`
+	CreatedAt time.Time `db:"created_at"`
+}
@@ -0,0 +1,29 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/lib/pq"
+)
+
+type SSHKey struct {
+	ID           int            `db:"id"`
+	Version      int            `db:"version"`
+	UserId       int            `db:"user_id"`
+	UpdateAt     time.Time      `db:"update_at"`
+	CreatedAt    time.Time      `db:"created_at"`
+	KeyType      string         `db:"key_type"`
+	PrivateKey   []byte         `db:"private_key"`
+	PublicKey    string         `db:"public_key"`
+	Fingerprint  string         `db:"fingerprint"`
+	Certificate  string         `db:"certificate"`
+	Comment      []byte         `db:"comment"`
+	Passphrase   []byte         `db:"passphrase"`
+	Title        []byte         `db:"title"`
+	Note         []byte         `db:"note"`
+	Metadata     []byte         `db:"metadata"`
+	FolderID     *int           `db:"folder_id"`
+	Tags         pq.StringArray `db:"tags"`
+	ChangeSeq    int64          `db:"change_seq"`
+	SearchTokens pq.ByteaArray  `db:"-"`
+}
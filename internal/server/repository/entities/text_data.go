@@ -1,12 +1,23 @@
 package entities
 
-import "time"
+import (
+	"time"
+
+	"github.com/lib/pq"
+)
 
 type TextData struct {
-	ID        int       `db:"id"`
-	Version   int       `db:"version"`
-	UserId    int       `db:"user_id"`
-	UpdateAt  time.Time `db:"update_at"`
-	CreatedAt time.Time `db:"created_at"`
-	Text      []byte    `db:"large_text"`
+	ID           int            `db:"id"`
+	Version      int            `db:"version"`
+	UserId       int            `db:"user_id"`
+	UpdateAt     time.Time      `db:"update_at"`
+	CreatedAt    time.Time      `db:"created_at"`
+	Text         []byte         `db:"large_text"`
+	Title        []byte         `db:"title"`
+	Note         []byte         `db:"note"`
+	Metadata     []byte         `db:"metadata"`
+	FolderID     *int           `db:"folder_id"`
+	Tags         pq.StringArray `db:"tags"`
+	ChangeSeq    int64          `db:"change_seq"`
+	SearchTokens pq.ByteaArray  `db:"-"`
 }
@@ -0,0 +1,12 @@
+package entities
+
+import "time"
+
+// Tombstone records the deletion of an item for the clients that sync later.
+type Tombstone struct {
+	UserId    int       `db:"user_id"`
+	ItemType  string    `db:"item_type"`
+	ItemID    int       `db:"item_id"`
+	ChangeSeq int64     `db:"change_seq"`
+	DeletedAt time.Time `db:"deleted_at"`
+}
@@ -0,0 +1,21 @@
+package entities
+
+import "time"
+
+// Upload is a resumable upload, Info keeps the encrypted description
+// of the file until the upload is finished.
+type Upload struct {
+	ID        string    `db:"id"`
+	UserId    int       `db:"user_id"`
+	FileName  string    `db:"file_name"`
+	Size      int       `db:"size"`
+	Info      []byte    `db:"info"`
+	CreatedAt time.Time `db:"created_at"`
+	UpdateAt  time.Time `db:"update_at"`
+}
+
+// UploadChunk is the byte range [Start, End) the server has received.
+type UploadChunk struct {
+	Start int `db:"start_at"`
+	End   int `db:"end_at"`
+}
@@ -0,0 +1,7 @@
+package entities
+
+// Usage is what the user keeps on the server.
+type Usage struct {
+	Bytes int64 `db:"bytes"`
+	Items int   `db:"items"`
+}
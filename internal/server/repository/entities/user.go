@@ -9,4 +9,6 @@ type User struct {
 	Login     string    `db:"login"`
 	Password  string    `db:"password"`
 	CreatedAt time.Time `db:"created_at"`
+	ChangeSeq int64     `db:"change_seq"`
+	VaultKey  string    `db:"vault_key"`
 }
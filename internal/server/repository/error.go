@@ -1,9 +1,30 @@
 package repository
 
 import (
+	"database/sql"
 	"errors"
+	"fmt"
 )
 
 var (
 	ErrDuplicate = errors.New("login is already taken")
+	ErrNotFound  = errors.New("not found")
+	// ErrVersionConflict is returned by the updates made over an old version of the item.
+	ErrVersionConflict = errors.New("the versions on the server and client do not match")
+	ErrKeysExist       = errors.New("the keys of the user are set already")
+	ErrMemberExists    = errors.New("the user is a member of the organization already")
+	// ErrNotEmpty is returned for an organization deleted with items in its vault.
+	ErrNotEmpty = errors.New("the vault of the organization is not empty")
 )
+
+// affected reports ErrNotFound when the statement did not touch any row.
+func affected(result sql.Result) error {
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("rows affected err: %w", err)
+	}
+	if rows == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
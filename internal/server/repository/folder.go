@@ -0,0 +1,81 @@
+package repository
+
+import (
+	"fmt"
+
+	"github.com/zelas91/goph-keeper/internal/server/repository/entities"
+	"golang.org/x/net/context"
+)
+
+type folder struct {
+	tm transactionManager
+}
+
+func (f folder) Create(ctx context.Context, fd entities.Folder) (int, error) {
+	query := `insert into folders (user_id, parent_id, name) values ($1, $2, $3) returning id;`
+	var id int
+	if err := f.tm.getConn(ctx).GetContext(ctx, &id, query, fd.UserId, fd.ParentID, fd.Name); err != nil {
+		return 0, fmt.Errorf("repo folder create err: %w", err)
+	}
+	return id, nil
+}
+
+func (f folder) FindAllByUserID(ctx context.Context, userID int) ([]entities.Folder, error) {
+	query := `select * from folders where user_id=$1 order by id`
+	var folders []entities.Folder
+	if err := f.tm.getConn(ctx).SelectContext(ctx, &folders, query, userID); err != nil {
+		return folders, fmt.Errorf("repo: get folders err %w", err)
+	}
+	return folders, nil
+}
+
+func (f folder) Update(ctx context.Context, fd entities.Folder) error {
+	query := `update folders set name=:name, parent_id=:parent_id where id=:id and user_id=:user_id`
+	result, err := f.tm.getConn(ctx).NamedExecContext(ctx, query, fd)
+	if err != nil {
+		return fmt.Errorf("repo folder update err: %w", err)
+	}
+	return affected(result)
+}
+
+// Delete removes the folder with its subfolders, their items move to the root.
+func (f folder) Delete(ctx context.Context, folderID, userID int) error {
+	return f.tm.do(ctx, func(ctx context.Context) error {
+		for itemType := range itemTables {
+			if err := touchItems(ctx, f.tm, userID, itemType, `folder_id in (
+				with recursive sub as (
+					select id from folders where id=$3 and user_id=$2
+					union all
+					select f.id from folders f join sub on f.parent_id = sub.id
+				) select id from sub)`, folderID); err != nil {
+				return err
+			}
+		}
+		query := `delete from folders where id=$1 and user_id=$2`
+		result, err := f.tm.getConn(ctx).ExecContext(ctx, query, folderID, userID)
+		if err != nil {
+			return fmt.Errorf("repo folder delete err: %w", err)
+		}
+		return affected(result)
+	})
+}
+
+// MoveItem puts the item into the folder, nil folderID moves it to the root.
+func (f folder) MoveItem(ctx context.Context, userID int, folderID *int, itemType string, itemID int) error {
+	table, err := itemTable(itemType)
+	if err != nil {
+		return err
+	}
+	return f.tm.do(ctx, func(ctx context.Context) error {
+		seq, err := nextChangeSeq(ctx, f.tm, userID)
+		if err != nil {
+			return err
+		}
+		query := fmt.Sprintf(`update %s set folder_id=$1, change_seq=$2 where id=$3 and user_id=$4`, table)
+		result, err := f.tm.getConn(ctx).ExecContext(ctx, query, folderID, seq, itemID, userID)
+		if err != nil {
+			return fmt.Errorf("repo folder move item err: %w", err)
+		}
+		return affected(result)
+	})
+}
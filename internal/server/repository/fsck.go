@@ -0,0 +1,75 @@
+package repository
+
+import (
+	"fmt"
+
+	"github.com/zelas91/goph-keeper/internal/server/repository/entities"
+	"golang.org/x/net/context"
+)
+
+// fsck reads the references to the blobs for the storage check.
+type fsck struct {
+	tm transactionManager
+}
+
+// Keys returns the keys of the blobs the files and the blob counts point to.
+func (f fsck) Keys(ctx context.Context) ([]string, error) {
+	query := `select path from binary_file union select key from blobs`
+	var keys []string
+	if err := f.tm.getConn(ctx).SelectContext(ctx, &keys, query); err != nil {
+		return keys, fmt.Errorf("repo: fsck keys err %w", err)
+	}
+	return keys, nil
+}
+
+func (f fsck) UploadIDs(ctx context.Context) ([]string, error) {
+	query := `select id from uploads`
+	var ids []string
+	if err := f.tm.getConn(ctx).SelectContext(ctx, &ids, query); err != nil {
+		return ids, fmt.Errorf("repo: fsck uploads err %w", err)
+	}
+	return ids, nil
+}
+
+// Files returns the id, the user and the blob key of every file.
+func (f fsck) Files(ctx context.Context) ([]entities.BinaryFile, error) {
+	query := `select id, user_id, path from binary_file order by id`
+	var files []entities.BinaryFile
+	if err := f.tm.getConn(ctx).SelectContext(ctx, &files, query); err != nil {
+		return files, fmt.Errorf("repo: fsck files err %w", err)
+	}
+	return files, nil
+}
+
+// StaleRefs returns the blobs counted other than the files pointing to them.
+func (f fsck) StaleRefs(ctx context.Context) ([]entities.BlobRefs, error) {
+	query := `select b.key, b.user_id, b.refs, count(f.id) as files from blobs b
+			left join binary_file f on f.user_id = b.user_id and f.path = b.key
+		group by b.key, b.user_id, b.refs
+		having b.refs <> count(f.id)
+		order by b.key`
+	var refs []entities.BlobRefs
+	if err := f.tm.getConn(ctx).SelectContext(ctx, &refs, query); err != nil {
+		return refs, fmt.Errorf("repo: fsck stale refs err %w", err)
+	}
+	return refs, nil
+}
+
+// FixRefs counts the blobs again by the files, the blobs no file points
+// to are forgotten and their content becomes an orphan.
+func (f fsck) FixRefs(ctx context.Context) error {
+	return f.tm.do(ctx, func(ctx context.Context) error {
+		query := `update blobs b set refs = c.files from (
+				select b2.key, count(f.id) as files from blobs b2
+					left join binary_file f on f.user_id = b2.user_id and f.path = b2.key
+				group by b2.key) c
+			where c.key = b.key and b.refs <> c.files`
+		if _, err := f.tm.getConn(ctx).ExecContext(ctx, query); err != nil {
+			return fmt.Errorf("repo: fsck fix refs err %w", err)
+		}
+		if _, err := f.tm.getConn(ctx).ExecContext(ctx, `delete from blobs where refs = 0`); err != nil {
+			return fmt.Errorf("repo: fsck delete blobs err %w", err)
+		}
+		return nil
+	})
+}
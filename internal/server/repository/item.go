@@ -0,0 +1,125 @@
+package repository
+
+import (
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/zelas91/goph-keeper/internal/server/repository/entities"
+	"github.com/zelas91/goph-keeper/internal/server/types"
+	"golang.org/x/net/context"
+)
+
+var itemTables = map[string]string{
+	types.ItemCard:       "cards",
+	types.ItemCredential: "user_credentials",
+	types.ItemText:       "text_data",
+	types.ItemFile:       "binary_file",
+	types.ItemOTP:        "otp_keys",
+	types.ItemSSHKey:     "ssh_keys",
+}
+
+func itemTable(itemType string) (string, error) {
+	table, ok := itemTables[itemType]
+	if !ok {
+		return "", fmt.Errorf("unknown item type %q", itemType)
+	}
+	return table, nil
+}
+
+// itemTagsColumn selects the tag names of the item aliased as "i".
+func itemTagsColumn(itemType string) string {
+	return fmt.Sprintf(`array(select t.name from item_tags it join tags t on t.id = it.tag_id
+		where it.item_type = '%s' and it.item_id = i.id order by t.name) as tags`, itemType)
+}
+
+// itemSelect returns the select of one item of the user with its tags.
+func itemSelect(itemType string) string {
+	return fmt.Sprintf(`select i.*, %s from %s i where i.id=$1 and i.user_id=$2`,
+		itemTagsColumn(itemType), itemTables[itemType])
+}
+
+// itemListSelect returns the select of one page of the user items narrowed by filter,
+// a folder filter matches the items of its subfolders as well.
+func itemListSelect(itemType string, userID int, filter entities.ItemFilter) (string, []any) {
+	query := fmt.Sprintf(`select i.*, %s from %s i where i.user_id=$1`,
+		itemTagsColumn(itemType), itemTables[itemType])
+	args := []any{userID}
+	if filter.FolderID != 0 {
+		args = append(args, filter.FolderID)
+		query += fmt.Sprintf(` and i.folder_id in (
+			with recursive sub as (
+				select id from folders where id=$%[1]d and user_id=$1
+				union all
+				select f.id from folders f join sub on f.parent_id = sub.id
+			) select id from sub)`, len(args))
+	}
+	if filter.Tag != "" {
+		args = append(args, filter.Tag)
+		query += fmt.Sprintf(` and exists (select 1 from item_tags it join tags t on t.id = it.tag_id
+			where it.item_type = '%s' and it.item_id = i.id and t.name=$%d)`, itemType, len(args))
+	}
+	if !filter.UpdatedSince.IsZero() {
+		// update_at is filled by now() as the time of the session zone without
+		// the zone, the instant is turned into that zone before the compare.
+		args = append(args, filter.UpdatedSince)
+		query += fmt.Sprintf(" and i.update_at >= ($%d::timestamptz at time zone current_setting('TimeZone'))",
+			len(args))
+	}
+
+	if filter.ChangedUpTo > 0 {
+		args = append(args, filter.ChangedAfter, filter.ChangedUpTo)
+		query += fmt.Sprintf(" and i.change_seq > $%d and i.change_seq <= $%d", len(args)-1, len(args))
+	}
+
+	column, order, cmp := "created_at", "asc", ">"
+	if filter.SortColumn == "update_at" {
+		column = "update_at"
+	}
+	if filter.Desc {
+		order, cmp = "desc", "<"
+	}
+	if filter.After != nil {
+		args = append(args, filter.After.Time, filter.After.ID)
+		query += fmt.Sprintf(" and (i.%s, i.id) %s ($%d, $%d)", column, cmp, len(args)-1, len(args))
+	}
+	query += fmt.Sprintf(" order by i.%[1]s %[2]s, i.id %[2]s", column, order)
+	if filter.Limit > 0 {
+		args = append(args, filter.Limit)
+		query += fmt.Sprintf(" limit $%d", len(args))
+	}
+	return query, args
+}
+
+// namedGet runs a named query returning one row, like insert ... returning id.
+func namedGet(ctx context.Context, tm transactionManager, dest any, query string, arg any) error {
+	query, args, err := sqlx.Named(query, arg)
+	if err != nil {
+		return err
+	}
+	return tm.getConn(ctx).GetContext(ctx, dest, sqlx.Rebind(sqlx.DOLLAR, query), args...)
+}
+
+// deleteItemLinks drops the tag links, search tokens and shares of the user item before it is deleted.
+func deleteItemLinks(ctx context.Context, tm transactionManager, itemType string, itemID, userID int) error {
+	for _, table := range []string{"item_tags", "search_tokens", "shares"} {
+		query := fmt.Sprintf(`delete from %s where item_type=$1 and item_id=$2
+			and exists (select 1 from %s where id=$2 and user_id=$3)`, table, itemTables[itemType])
+		if _, err := tm.getConn(ctx).ExecContext(ctx, query, itemType, itemID, userID); err != nil {
+			return fmt.Errorf("repo %s delete err: %w", table, err)
+		}
+	}
+	return nil
+}
+
+// updateMissed tells why an update with a version check touched no row.
+func updateMissed(ctx context.Context, tm transactionManager, itemType string, itemID, userID int) error {
+	query := fmt.Sprintf(`select exists (select 1 from %s where id=$1 and user_id=$2)`, itemTables[itemType])
+	var exists bool
+	if err := tm.getConn(ctx).GetContext(ctx, &exists, query, itemID, userID); err != nil {
+		return fmt.Errorf("repo %s update check err: %w", itemType, err)
+	}
+	if exists {
+		return ErrVersionConflict
+	}
+	return ErrNotFound
+}
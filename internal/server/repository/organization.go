@@ -0,0 +1,152 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/lib/pq"
+	"github.com/zelas91/goph-keeper/internal/server/repository/entities"
+	"golang.org/x/net/context"
+)
+
+type organization struct {
+	tm transactionManager
+}
+
+const orgSelect = `select o.*, v.login as vault_login, m.role, m.org_key from organizations o
+	join org_members m on m.org_id = o.id join users v on v.id = o.vault_user_id`
+
+// Create makes the organization with the user of its vault and the owner
+// as its first member.
+func (o organization) Create(ctx context.Context, org entities.Organization, owner entities.OrgMember) (int, error) {
+	err := o.tm.do(ctx, func(ctx context.Context) error {
+		if err := o.tm.getConn(ctx).GetContext(ctx, &org.VaultUserID,
+			`insert into users (login, password) values ($1, '') returning id`, org.VaultLogin); err != nil {
+			return fmt.Errorf("vault user create err: %w", err)
+		}
+		if err := o.tm.getConn(ctx).GetContext(ctx, &org.ID,
+			`insert into organizations (name, vault_user_id) values ($1, $2) returning id`,
+			org.Name, org.VaultUserID); err != nil {
+			return fmt.Errorf("organization create err: %w", err)
+		}
+		_, err := o.tm.getConn(ctx).ExecContext(ctx,
+			`insert into org_members (org_id, user_id, role, org_key) values ($1, $2, $3, $4)`,
+			org.ID, owner.UserID, owner.Role, owner.OrgKey)
+		return err
+	})
+	if err != nil {
+		return 0, fmt.Errorf("repo organization create err: %w", err)
+	}
+	return org.ID, nil
+}
+
+// FindByUser returns the organizations of the member.
+func (o organization) FindByUser(ctx context.Context, userID int) ([]entities.Organization, error) {
+	var orgs []entities.Organization
+	query := orgSelect + ` where m.user_id=$1 order by o.id`
+	if err := o.tm.getConn(ctx).SelectContext(ctx, &orgs, query, userID); err != nil {
+		return nil, fmt.Errorf("repo organizations get err: %w", err)
+	}
+	return orgs, nil
+}
+
+// FindByIDAndUserID returns the organization of the member, ErrNotFound for
+// the organizations the user is not a member of.
+func (o organization) FindByIDAndUserID(ctx context.Context, orgID, userID int) (entities.Organization, error) {
+	var org entities.Organization
+	query := orgSelect + ` where o.id=$1 and m.user_id=$2`
+	err := o.tm.getConn(ctx).GetContext(ctx, &org, query, orgID, userID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return org, ErrNotFound
+	}
+	if err != nil {
+		return org, fmt.Errorf("repo organization get err: %w", err)
+	}
+	return org, nil
+}
+
+// Delete removes the organization with an empty vault, the user of the
+// vault stays for the change feed of the items it had.
+func (o organization) Delete(ctx context.Context, orgID int) error {
+	err := o.tm.do(ctx, func(ctx context.Context) error {
+		var vaultUserID int
+		err := o.tm.getConn(ctx).GetContext(ctx, &vaultUserID,
+			`select vault_user_id from organizations where id=$1 for update`, orgID)
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrNotFound
+		}
+		if err != nil {
+			return err
+		}
+		exists := make([]string, 0, len(itemTables))
+		for _, table := range itemTables {
+			exists = append(exists, fmt.Sprintf("exists (select 1 from %s where user_id=$1)", table))
+		}
+		var notEmpty bool
+		if err = o.tm.getConn(ctx).GetContext(ctx, &notEmpty,
+			"select "+strings.Join(exists, " or "), vaultUserID); err != nil {
+			return err
+		}
+		if notEmpty {
+			return ErrNotEmpty
+		}
+		_, err = o.tm.getConn(ctx).ExecContext(ctx, `delete from organizations where id=$1`, orgID)
+		return err
+	})
+	if errors.Is(err, ErrNotFound) || errors.Is(err, ErrNotEmpty) {
+		return err
+	}
+	if err != nil {
+		return fmt.Errorf("repo organization delete err: %w", err)
+	}
+	return nil
+}
+
+// Members returns the members of the organization in the order they joined.
+func (o organization) Members(ctx context.Context, orgID int) ([]entities.OrgMember, error) {
+	var members []entities.OrgMember
+	query := `select m.*, u.login from org_members m join users u on u.id = m.user_id
+		where m.org_id=$1 order by m.created_at, m.user_id`
+	if err := o.tm.getConn(ctx).SelectContext(ctx, &members, query, orgID); err != nil {
+		return nil, fmt.Errorf("repo members get err: %w", err)
+	}
+	return members, nil
+}
+
+// AddMember adds the user of the login to the organization, ErrNotFound is
+// returned when there is no such user.
+func (o organization) AddMember(ctx context.Context, member entities.OrgMember) error {
+	query := `insert into org_members (org_id, user_id, role, org_key)
+		select $1, u.id, $3, $4 from users u
+		where u.login=$2 and not exists (select 1 from organizations where vault_user_id = u.id)`
+	result, err := o.tm.getConn(ctx).ExecContext(ctx, query, member.OrgID, member.Login, member.Role, member.OrgKey)
+	if pqErr := new(pq.Error); errors.As(err, &pqErr) && pqErr.Code == "23505" {
+		return ErrMemberExists
+	}
+	if err != nil {
+		return fmt.Errorf("repo member add err: %w", err)
+	}
+	return affected(result)
+}
+
+func (o organization) SetRole(ctx context.Context, orgID int, login, role string) error {
+	query := `update org_members m set role=$3 from users u
+		where u.id = m.user_id and m.org_id=$1 and u.login=$2`
+	result, err := o.tm.getConn(ctx).ExecContext(ctx, query, orgID, login, role)
+	if err != nil {
+		return fmt.Errorf("repo member role set err: %w", err)
+	}
+	return affected(result)
+}
+
+func (o organization) RemoveMember(ctx context.Context, orgID int, login string) error {
+	query := `delete from org_members m using users u
+		where u.id = m.user_id and m.org_id=$1 and u.login=$2`
+	result, err := o.tm.getConn(ctx).ExecContext(ctx, query, orgID, login)
+	if err != nil {
+		return fmt.Errorf("repo member remove err: %w", err)
+	}
+	return affected(result)
+}
@@ -0,0 +1,116 @@
+package repository
+
+import (
+	"fmt"
+
+	"github.com/zelas91/goph-keeper/internal/server/repository/entities"
+	"github.com/zelas91/goph-keeper/internal/server/types"
+	"golang.org/x/net/context"
+)
+
+type otp struct {
+	tm transactionManager
+}
+
+func (o otp) Create(ctx context.Context, key entities.OTP) (int, error) {
+	var id int
+	err := o.tm.do(ctx, func(ctx context.Context) error {
+		seq, err := nextChangeSeq(ctx, o.tm, key.UserId)
+		if err != nil {
+			return err
+		}
+		key.ChangeSeq = seq
+		query := `insert into otp_keys (type,issuer,account,secret,algorithm,digits,period,counter,
+				user_id,title,note,metadata,change_seq,folder_id)
+			values (:type,:issuer,:account,:secret,:algorithm,:digits,:period,:counter,
+				:user_id,:title,:note,:metadata,:change_seq,
+				(select id from folders where id=:folder_id and user_id=:user_id)) returning id;`
+		if err = namedGet(ctx, o.tm, &id, query, key); err != nil {
+			return fmt.Errorf("repo otp create err: %w", err)
+		}
+		return saveSearchTokens(ctx, o.tm, key.UserId, types.ItemOTP, id, key.SearchTokens)
+	})
+	if err != nil {
+		return 0, err
+	}
+	return id, nil
+}
+
+func (o otp) FindAllByUserID(ctx context.Context, userID int,
+	filter entities.ItemFilter) ([]entities.OTP, error) {
+	query, args := itemListSelect(types.ItemOTP, userID, filter)
+	var keys []entities.OTP
+	if err := o.tm.getConn(ctx).SelectContext(ctx, &keys, query, args...); err != nil {
+		return keys, fmt.Errorf("repo: get otp keys err %w", err)
+	}
+	return keys, nil
+}
+
+func (o otp) FindByIDAndUserID(ctx context.Context, otpID, userID int) (entities.OTP, error) {
+	query := itemSelect(types.ItemOTP)
+	var key entities.OTP
+	if err := o.tm.getConn(ctx).GetContext(ctx, &key, query, otpID, userID); err != nil {
+		return key, fmt.Errorf("repo: otp get id=%d  err: %w", otpID, err)
+	}
+	return key, nil
+}
+
+func (o otp) Delete(ctx context.Context, otpID, userID int) error {
+	return o.tm.do(ctx, func(ctx context.Context) error {
+		if err := deleteItemLinks(ctx, o.tm, types.ItemOTP, otpID, userID); err != nil {
+			return err
+		}
+		query := `delete from otp_keys where id=$1 and user_id=$2`
+		result, err := o.tm.getConn(ctx).ExecContext(ctx, query, otpID, userID)
+		if err != nil {
+			return fmt.Errorf("repo otp delete err: %w", err)
+		}
+		if rows, err := result.RowsAffected(); err != nil || rows == 0 {
+			return err
+		}
+		return addTombstone(ctx, o.tm, userID, types.ItemOTP, otpID)
+	})
+}
+
+func (o otp) Update(ctx context.Context, key entities.OTP) error {
+	err := o.tm.do(ctx, func(ctx context.Context) error {
+		query := `select id from otp_keys where id=$1 for update;`
+		if _, err := o.tm.getConn(ctx).ExecContext(ctx, query, key.ID); err != nil {
+			return fmt.Errorf("repo otp update block err :%w", err)
+		}
+		seq, err := nextChangeSeq(ctx, o.tm, key.UserId)
+		if err != nil {
+			return err
+		}
+		key.ChangeSeq = seq
+		query = `update otp_keys set
+				type=:type,
+				issuer=:issuer,
+				account=:account,
+				secret=:secret,
+				algorithm=:algorithm,
+				digits=:digits,
+				period=:period,
+				counter=:counter,
+				title=:title,
+				note=:note,
+				metadata=:metadata,
+				change_seq=:change_seq
+			where
+				id=:id and user_id=:user_id and version=:version;`
+		result, err := o.tm.getConn(ctx).NamedExecContext(ctx, query, key)
+		if err != nil {
+			return fmt.Errorf("repo otp update err: %w", err)
+		}
+		rowsAffected, err := result.RowsAffected()
+		if err != nil {
+			return fmt.Errorf("repo otp update result err: %w", err)
+		}
+		if rowsAffected == 0 {
+			return updateMissed(ctx, o.tm, types.ItemOTP, key.ID, key.UserId)
+		}
+		return saveSearchTokens(ctx, o.tm, key.UserId, types.ItemOTP, key.ID, key.SearchTokens)
+	})
+
+	return err
+}
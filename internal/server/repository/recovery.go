@@ -0,0 +1,74 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/zelas91/goph-keeper/internal/server/repository/entities"
+	"golang.org/x/net/context"
+)
+
+type recovery struct {
+	tm transactionManager
+}
+
+// Set keeps the recovery key of the user, a key kept before is replaced.
+func (r recovery) Set(ctx context.Context, key entities.RecoveryKey) error {
+	query := `insert into recovery_keys (user_id, key_hash, vault_key) values ($1, $2, $3)
+		on conflict (user_id) do update
+			set key_hash = excluded.key_hash, vault_key = excluded.vault_key, created_at = now()`
+	if _, err := r.tm.getConn(ctx).ExecContext(ctx, query, key.UserID, key.KeyHash, key.VaultKey); err != nil {
+		return fmt.Errorf("repo recovery key set err: %w", err)
+	}
+	return nil
+}
+
+// FindByLogin returns the recovery key of the user of the login.
+func (r recovery) FindByLogin(ctx context.Context, login string) (entities.RecoveryKey, error) {
+	var key entities.RecoveryKey
+	query := `select k.* from recovery_keys k join users u on u.id = k.user_id where u.login=$1`
+	err := r.tm.getConn(ctx).GetContext(ctx, &key, query, login)
+	if errors.Is(err, sql.ErrNoRows) {
+		return key, ErrNotFound
+	}
+	if err != nil {
+		return key, fmt.Errorf("repo recovery key get err: %w", err)
+	}
+	return key, nil
+}
+
+func (r recovery) State(ctx context.Context, userID int) (entities.RecoveryState, error) {
+	var state entities.RecoveryState
+	query := `select exists (select 1 from recovery_keys where user_id=u.id) as set, u.vault_key
+		from users u where u.id=$1`
+	err := r.tm.getConn(ctx).GetContext(ctx, &state, query, userID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return state, ErrNotFound
+	}
+	if err != nil {
+		return state, fmt.Errorf("repo recovery state get err: %w", err)
+	}
+	return state, nil
+}
+
+// Recover sets the password and the wrapped vault key of the user and
+// replaces the recovery key used with the next one at once.
+func (r recovery) Recover(ctx context.Context, user entities.User, next entities.RecoveryKey) error {
+	err := r.tm.do(ctx, func(ctx context.Context) error {
+		result, err := r.tm.getConn(ctx).ExecContext(ctx,
+			`update users set password=$2, vault_key=$3 where id=$1`, user.ID, user.Password, user.VaultKey)
+		if err != nil {
+			return err
+		}
+		if err = affected(result); err != nil {
+			return err
+		}
+		next.UserID = user.ID
+		return r.Set(ctx, next)
+	})
+	if err != nil {
+		return fmt.Errorf("repo recover err: %w", err)
+	}
+	return nil
+}
@@ -10,8 +10,22 @@ type Repository struct {
 	Auth       *auth
 	CreditCard *creditCard
 	Credential *credential
+	OTP        *otp
+	SSHKey     *sshKey
 	TextData   *textData
 	BinaryFile *binaryFile
+	Folder     *folder
+	Tag        *tag
+	Search     *search
+	Change     *change
+	Upload     *upload
+	Usage      *usage
+	Fsck       *fsck
+	Share      *share
+	Org        *organization
+	Emergency  *emergency
+	ShareLink  *shareLink
+	Recovery   *recovery
 }
 
 func New(log logger.Logger, db *sqlx.DB) *Repository {
@@ -20,8 +34,22 @@ func New(log logger.Logger, db *sqlx.DB) *Repository {
 		Auth:       &auth{tm: manager},
 		CreditCard: &creditCard{tm: manager},
 		Credential: &credential{tm: manager},
+		OTP:        &otp{tm: manager},
+		SSHKey:     &sshKey{tm: manager},
 		TextData:   &textData{tm: manager},
 		BinaryFile: &binaryFile{tm: manager},
+		Folder:     &folder{tm: manager},
+		Tag:        &tag{tm: manager},
+		Search:     &search{tm: manager},
+		Change:     &change{tm: manager},
+		Upload:     &upload{tm: manager},
+		Usage:      &usage{tm: manager},
+		Fsck:       &fsck{tm: manager},
+		Share:      &share{tm: manager},
+		Org:        &organization{tm: manager},
+		Emergency:  &emergency{tm: manager},
+		ShareLink:  &shareLink{tm: manager},
+		Recovery:   &recovery{tm: manager},
 	}
 }
 
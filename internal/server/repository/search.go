@@ -0,0 +1,83 @@
+package repository
+
+import (
+	"fmt"
+
+	"github.com/lib/pq"
+	"github.com/zelas91/goph-keeper/internal/server/repository/entities"
+	"golang.org/x/net/context"
+)
+
+type search struct {
+	tm transactionManager
+}
+
+// Search finds the user items indexed with every one of the tokens.
+func (s search) Search(ctx context.Context, userID int, tokens [][]byte, limit int) ([]entities.SearchResult, error) {
+	query := `with hits as (
+			select item_type, item_id from search_tokens
+			where user_id=$1 and token = any($2)
+			group by item_type, item_id
+			having count(distinct token) = $3
+		)
+		select h.item_type, h.item_id, coalesce(c.title, u.title, t.title, f.title, o.title, k.title) as title
+		from hits h
+			left join cards c on h.item_type = 'card' and c.id = h.item_id
+			left join user_credentials u on h.item_type = 'credential' and u.id = h.item_id
+			left join text_data t on h.item_type = 'text' and t.id = h.item_id
+			left join binary_file f on h.item_type = 'file' and f.id = h.item_id
+			left join otp_keys o on h.item_type = 'otp' and o.id = h.item_id
+			left join ssh_keys k on h.item_type = 'ssh_key' and k.id = h.item_id
+		order by h.item_type, h.item_id
+		limit $4`
+	var results []entities.SearchResult
+	if err := s.tm.getConn(ctx).SelectContext(ctx, &results, query,
+		userID, pq.ByteaArray(tokens), len(tokens), limit); err != nil {
+		return results, fmt.Errorf("repo: search err %w", err)
+	}
+	return results, nil
+}
+
+// Unindexed returns the next items of the type without search tokens
+// after the item id, in the order of the ids.
+func (s search) Unindexed(ctx context.Context, itemType string, afterID, limit int) ([]entities.UnindexedItem, error) {
+	table, err := itemTable(itemType)
+	if err != nil {
+		return nil, err
+	}
+	query := fmt.Sprintf(`select i.id, i.user_id from %s i
+		where i.id > $2 and not exists (
+			select 1 from search_tokens s where s.item_type = $1 and s.item_id = i.id)
+		order by i.id
+		limit $3`, table)
+	var items []entities.UnindexedItem
+	if err = s.tm.getConn(ctx).SelectContext(ctx, &items, query, itemType, afterID, limit); err != nil {
+		return items, fmt.Errorf("repo: unindexed %s err %w", itemType, err)
+	}
+	return items, nil
+}
+
+// SaveTokens replaces the search tokens of the item.
+func (s search) SaveTokens(ctx context.Context, userID int, itemType string, itemID int, tokens [][]byte) error {
+	return s.tm.do(ctx, func(ctx context.Context) error {
+		return saveSearchTokens(ctx, s.tm, userID, itemType, itemID, tokens)
+	})
+}
+
+// saveSearchTokens replaces the search tokens of the item.
+func saveSearchTokens(ctx context.Context, tm transactionManager, userID int, itemType string,
+	itemID int, tokens pq.ByteaArray) error {
+	query := `delete from search_tokens where item_type=$1 and item_id=$2`
+	if _, err := tm.getConn(ctx).ExecContext(ctx, query, itemType, itemID); err != nil {
+		return fmt.Errorf("repo search tokens delete err: %w", err)
+	}
+	if len(tokens) == 0 {
+		return nil
+	}
+	query = `insert into search_tokens (user_id, item_type, item_id, token)
+		select $1, $2, $3, unnest($4::bytea[])`
+	if _, err := tm.getConn(ctx).ExecContext(ctx, query, userID, itemType, itemID, tokens); err != nil {
+		return fmt.Errorf("repo search tokens save err: %w", err)
+	}
+	return nil
+}
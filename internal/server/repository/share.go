@@ -0,0 +1,131 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/zelas91/goph-keeper/internal/server/repository/entities"
+	"golang.org/x/net/context"
+)
+
+type share struct {
+	tm transactionManager
+}
+
+const shareSelect = `select s.*, o.login as owner, r.login as recipient from shares s
+	join users o on o.id = s.owner_id join users r on r.id = s.recipient_id`
+
+// Create shares the item of the owner with the user of the login, a share
+// of the item with the user made before takes the new permission and key.
+// ErrNotFound is returned when the owner has no such item or there is no
+// other user of the login.
+func (s share) Create(ctx context.Context, sh entities.Share) (int, error) {
+	table, ok := itemTables[sh.ItemType]
+	if !ok {
+		return 0, ErrNotFound
+	}
+	query := fmt.Sprintf(`insert into shares (owner_id, recipient_id, item_type, item_id, permission, file_key)
+		select $1, u.id, $2, $3, $4, $5 from users u
+		where u.login = $6 and u.id <> $1 and exists (select 1 from %s where id=$3 and user_id=$1)
+		on conflict (item_type, item_id, recipient_id)
+			do update set permission = excluded.permission, file_key = excluded.file_key
+		returning id`, table)
+	var id int
+	err := s.tm.getConn(ctx).GetContext(ctx, &id, query,
+		sh.OwnerID, sh.ItemType, sh.ItemID, sh.Permission, sh.FileKey, sh.Recipient)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, ErrNotFound
+	}
+	if err != nil {
+		return 0, fmt.Errorf("repo share create err: %w", err)
+	}
+	return id, nil
+}
+
+// FindByOwner returns the shares the user made.
+func (s share) FindByOwner(ctx context.Context, ownerID int) ([]entities.Share, error) {
+	var shares []entities.Share
+	query := shareSelect + ` where s.owner_id=$1 order by s.id`
+	if err := s.tm.getConn(ctx).SelectContext(ctx, &shares, query, ownerID); err != nil {
+		return nil, fmt.Errorf("repo shares get err: %w", err)
+	}
+	return shares, nil
+}
+
+// FindByRecipient returns the shares other users made with the user.
+func (s share) FindByRecipient(ctx context.Context, recipientID int) ([]entities.Share, error) {
+	var shares []entities.Share
+	query := shareSelect + ` where s.recipient_id=$1 order by s.id`
+	if err := s.tm.getConn(ctx).SelectContext(ctx, &shares, query, recipientID); err != nil {
+		return nil, fmt.Errorf("repo shares get err: %w", err)
+	}
+	return shares, nil
+}
+
+// FindByItemAndRecipient returns the share of the item with the user.
+func (s share) FindByItemAndRecipient(ctx context.Context, itemType string,
+	itemID, recipientID int) (entities.Share, error) {
+	var sh entities.Share
+	query := shareSelect + ` where s.item_type=$1 and s.item_id=$2 and s.recipient_id=$3`
+	err := s.tm.getConn(ctx).GetContext(ctx, &sh, query, itemType, itemID, recipientID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return sh, ErrNotFound
+	}
+	if err != nil {
+		return sh, fmt.Errorf("repo share get err: %w", err)
+	}
+	return sh, nil
+}
+
+// Delete removes the share, the owner revokes it and the recipient leaves it.
+func (s share) Delete(ctx context.Context, shareID, userID int) error {
+	query := `delete from shares where id=$1 and (owner_id=$2 or recipient_id=$2)`
+	result, err := s.tm.getConn(ctx).ExecContext(ctx, query, shareID, userID)
+	if err != nil {
+		return fmt.Errorf("repo share delete err: %w", err)
+	}
+	return affected(result)
+}
+
+func (s share) Keys(ctx context.Context, userID int) (entities.UserKeys, error) {
+	var keys entities.UserKeys
+	query := `select public_key, private_key from user_keys where user_id=$1`
+	err := s.tm.getConn(ctx).GetContext(ctx, &keys, query, userID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return keys, ErrNotFound
+	}
+	if err != nil {
+		return keys, fmt.Errorf("repo user keys get err: %w", err)
+	}
+	return keys, nil
+}
+
+// SetKeys keeps the key pair of the user, a pair kept before is not
+// replaced: the keys of the files shared with the user are sealed for it.
+func (s share) SetKeys(ctx context.Context, userID int, keys entities.UserKeys) error {
+	query := `insert into user_keys (user_id, public_key, private_key) values ($1, $2, $3)
+		on conflict (user_id) do nothing`
+	result, err := s.tm.getConn(ctx).ExecContext(ctx, query, userID, keys.PublicKey, keys.PrivateKey)
+	if err != nil {
+		return fmt.Errorf("repo user keys set err: %w", err)
+	}
+	if err = affected(result); errors.Is(err, ErrNotFound) {
+		return ErrKeysExist
+	}
+	return err
+}
+
+// PublicKey returns the public key of the user of the login.
+func (s share) PublicKey(ctx context.Context, login string) (string, error) {
+	var key string
+	query := `select k.public_key from user_keys k join users u on u.id = k.user_id where u.login=$1`
+	err := s.tm.getConn(ctx).GetContext(ctx, &key, query, login)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", ErrNotFound
+	}
+	if err != nil {
+		return "", fmt.Errorf("repo public key get err: %w", err)
+	}
+	return key, nil
+}
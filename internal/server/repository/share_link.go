@@ -0,0 +1,84 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/zelas91/goph-keeper/internal/server/repository/entities"
+	"golang.org/x/net/context"
+)
+
+type shareLink struct {
+	tm transactionManager
+}
+
+func (s shareLink) Create(ctx context.Context, link entities.ShareLink) error {
+	query := `insert into share_links (id, user_id, kind, data, max_views, expires_at)
+		values (:id, :user_id, :kind, :data, :max_views, :expires_at)`
+	if _, err := s.tm.getConn(ctx).NamedExecContext(ctx, query, link); err != nil {
+		return fmt.Errorf("repo share link create err: %w", err)
+	}
+	return nil
+}
+
+// FindByUser returns the links of the user that are still open, without
+// their content.
+func (s shareLink) FindByUser(ctx context.Context, userID int, now time.Time) ([]entities.ShareLink, error) {
+	var links []entities.ShareLink
+	query := `select id, user_id, kind, max_views, views, expires_at, created_at from share_links
+		where user_id=$1 and expires_at > $2 order by created_at`
+	if err := s.tm.getConn(ctx).SelectContext(ctx, &links, query, userID, now); err != nil {
+		return nil, fmt.Errorf("repo share links get err: %w", err)
+	}
+	return links, nil
+}
+
+func (s shareLink) Delete(ctx context.Context, linkID string, userID int) error {
+	result, err := s.tm.getConn(ctx).ExecContext(ctx,
+		`delete from share_links where id=$1 and user_id=$2`, linkID, userID)
+	if err != nil {
+		return fmt.Errorf("repo share link delete err: %w", err)
+	}
+	return affected(result)
+}
+
+// Open counts the view of the link and returns it, the link of the last
+// view is deleted. ErrNotFound is returned for the links expired, used up
+// or never made.
+func (s shareLink) Open(ctx context.Context, linkID string, now time.Time) (entities.ShareLink, error) {
+	var link entities.ShareLink
+	err := s.tm.do(ctx, func(ctx context.Context) error {
+		query := `update share_links set views = views + 1
+			where id=$1 and expires_at > $2 and (max_views = 0 or views < max_views) returning *`
+		if err := s.tm.getConn(ctx).GetContext(ctx, &link, query, linkID, now); err != nil {
+			return err
+		}
+		if link.MaxViews == 0 || link.Views < link.MaxViews {
+			return nil
+		}
+		_, err := s.tm.getConn(ctx).ExecContext(ctx, `delete from share_links where id=$1`, linkID)
+		return err
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return link, ErrNotFound
+	}
+	if err != nil {
+		return link, fmt.Errorf("repo share link open err: %w", err)
+	}
+	return link, nil
+}
+
+// DeleteExpired removes the links expired and returns how many it removed.
+func (s shareLink) DeleteExpired(ctx context.Context, now time.Time) (int64, error) {
+	result, err := s.tm.getConn(ctx).ExecContext(ctx, `delete from share_links where expires_at <= $1`, now)
+	if err != nil {
+		return 0, fmt.Errorf("repo share links delete err: %w", err)
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("rows affected err: %w", err)
+	}
+	return rows, nil
+}
@@ -0,0 +1,115 @@
+package repository
+
+import (
+	"fmt"
+
+	"github.com/zelas91/goph-keeper/internal/server/repository/entities"
+	"github.com/zelas91/goph-keeper/internal/server/types"
+	"golang.org/x/net/context"
+)
+
+type sshKey struct {
+	tm transactionManager
+}
+
+func (s sshKey) Create(ctx context.Context, key entities.SSHKey) (int, error) {
+	var id int
+	err := s.tm.do(ctx, func(ctx context.Context) error {
+		seq, err := nextChangeSeq(ctx, s.tm, key.UserId)
+		if err != nil {
+			return err
+		}
+		key.ChangeSeq = seq
+		query := `insert into ssh_keys (key_type,private_key,public_key,fingerprint,certificate,comment,passphrase,
+				user_id,title,note,metadata,change_seq,folder_id)
+			values (:key_type,:private_key,:public_key,:fingerprint,:certificate,:comment,:passphrase,
+				:user_id,:title,:note,:metadata,:change_seq,
+				(select id from folders where id=:folder_id and user_id=:user_id)) returning id;`
+		if err = namedGet(ctx, s.tm, &id, query, key); err != nil {
+			return fmt.Errorf("repo ssh key create err: %w", err)
+		}
+		return saveSearchTokens(ctx, s.tm, key.UserId, types.ItemSSHKey, id, key.SearchTokens)
+	})
+	if err != nil {
+		return 0, err
+	}
+	return id, nil
+}
+
+func (s sshKey) FindAllByUserID(ctx context.Context, userID int,
+	filter entities.ItemFilter) ([]entities.SSHKey, error) {
+	query, args := itemListSelect(types.ItemSSHKey, userID, filter)
+	var keys []entities.SSHKey
+	if err := s.tm.getConn(ctx).SelectContext(ctx, &keys, query, args...); err != nil {
+		return keys, fmt.Errorf("repo: get ssh keys err %w", err)
+	}
+	return keys, nil
+}
+
+func (s sshKey) FindByIDAndUserID(ctx context.Context, sshKeyID, userID int) (entities.SSHKey, error) {
+	query := itemSelect(types.ItemSSHKey)
+	var key entities.SSHKey
+	if err := s.tm.getConn(ctx).GetContext(ctx, &key, query, sshKeyID, userID); err != nil {
+		return key, fmt.Errorf("repo: ssh key get id=%d  err: %w", sshKeyID, err)
+	}
+	return key, nil
+}
+
+func (s sshKey) Delete(ctx context.Context, sshKeyID, userID int) error {
+	return s.tm.do(ctx, func(ctx context.Context) error {
+		if err := deleteItemLinks(ctx, s.tm, types.ItemSSHKey, sshKeyID, userID); err != nil {
+			return err
+		}
+		query := `delete from ssh_keys where id=$1 and user_id=$2`
+		result, err := s.tm.getConn(ctx).ExecContext(ctx, query, sshKeyID, userID)
+		if err != nil {
+			return fmt.Errorf("repo ssh key delete err: %w", err)
+		}
+		if rows, err := result.RowsAffected(); err != nil || rows == 0 {
+			return err
+		}
+		return addTombstone(ctx, s.tm, userID, types.ItemSSHKey, sshKeyID)
+	})
+}
+
+func (s sshKey) Update(ctx context.Context, key entities.SSHKey) error {
+	err := s.tm.do(ctx, func(ctx context.Context) error {
+		query := `select id from ssh_keys where id=$1 for update;`
+		if _, err := s.tm.getConn(ctx).ExecContext(ctx, query, key.ID); err != nil {
+			return fmt.Errorf("repo ssh key update block err :%w", err)
+		}
+		seq, err := nextChangeSeq(ctx, s.tm, key.UserId)
+		if err != nil {
+			return err
+		}
+		key.ChangeSeq = seq
+		query = `update ssh_keys set
+				key_type=:key_type,
+				private_key=:private_key,
+				public_key=:public_key,
+				fingerprint=:fingerprint,
+				certificate=:certificate,
+				comment=:comment,
+				passphrase=:passphrase,
+				title=:title,
+				note=:note,
+				metadata=:metadata,
+				change_seq=:change_seq
+			where
+				id=:id and user_id=:user_id and version=:version;`
+		result, err := s.tm.getConn(ctx).NamedExecContext(ctx, query, key)
+		if err != nil {
+			return fmt.Errorf("repo ssh key update err: %w", err)
+		}
+		rowsAffected, err := result.RowsAffected()
+		if err != nil {
+			return fmt.Errorf("repo ssh key update result err: %w", err)
+		}
+		if rowsAffected == 0 {
+			return updateMissed(ctx, s.tm, types.ItemSSHKey, key.ID, key.UserId)
+		}
+		return saveSearchTokens(ctx, s.tm, key.UserId, types.ItemSSHKey, key.ID, key.SearchTokens)
+	})
+
+	return err
+}
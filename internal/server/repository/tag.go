@@ -0,0 +1,89 @@
+package repository
+
+import (
+	"fmt"
+
+	"github.com/zelas91/goph-keeper/internal/server/repository/entities"
+	"golang.org/x/net/context"
+)
+
+type tag struct {
+	tm transactionManager
+}
+
+func (t tag) Create(ctx context.Context, tg entities.Tag) (int, error) {
+	query := `insert into tags (user_id, name) values ($1, $2)
+		on conflict (user_id, name) do update set name=excluded.name returning id;`
+	var id int
+	if err := t.tm.getConn(ctx).GetContext(ctx, &id, query, tg.UserId, tg.Name); err != nil {
+		return 0, fmt.Errorf("repo tag create err: %w", err)
+	}
+	return id, nil
+}
+
+func (t tag) FindAllByUserID(ctx context.Context, userID int) ([]entities.Tag, error) {
+	query := `select * from tags where user_id=$1 order by name`
+	var tags []entities.Tag
+	if err := t.tm.getConn(ctx).SelectContext(ctx, &tags, query, userID); err != nil {
+		return tags, fmt.Errorf("repo: get tags err %w", err)
+	}
+	return tags, nil
+}
+
+// Delete removes the tag, the items it was attached to are marked changed.
+func (t tag) Delete(ctx context.Context, tagID, userID int) error {
+	return t.tm.do(ctx, func(ctx context.Context) error {
+		for itemType := range itemTables {
+			if err := touchItems(ctx, t.tm, userID, itemType, `id in (select item_id from item_tags
+				where tag_id=$3 and item_type=$4)`, tagID, itemType); err != nil {
+				return err
+			}
+		}
+		query := `delete from tags where id=$1 and user_id=$2`
+		result, err := t.tm.getConn(ctx).ExecContext(ctx, query, tagID, userID)
+		if err != nil {
+			return fmt.Errorf("repo tag delete err: %w", err)
+		}
+		return affected(result)
+	})
+}
+
+// Attach links the tag to the item when both belong to the user.
+func (t tag) Attach(ctx context.Context, userID, tagID int, itemType string, itemID int) error {
+	table, err := itemTable(itemType)
+	if err != nil {
+		return err
+	}
+	return t.tm.do(ctx, func(ctx context.Context) error {
+		query := fmt.Sprintf(`insert into item_tags (tag_id, item_type, item_id)
+			select t.id, $2, $3 from tags t
+			where t.id=$1 and t.user_id=$4 and exists (select 1 from %s where id=$3 and user_id=$4)
+			on conflict (tag_id, item_type, item_id) do update set tag_id=excluded.tag_id`, table)
+		result, err := t.tm.getConn(ctx).ExecContext(ctx, query, tagID, itemType, itemID, userID)
+		if err != nil {
+			return fmt.Errorf("repo tag attach err: %w", err)
+		}
+		if err = affected(result); err != nil {
+			return err
+		}
+		return touchItems(ctx, t.tm, userID, itemType, "id=$3", itemID)
+	})
+}
+
+func (t tag) Detach(ctx context.Context, userID, tagID int, itemType string, itemID int) error {
+	if _, err := itemTable(itemType); err != nil {
+		return err
+	}
+	return t.tm.do(ctx, func(ctx context.Context) error {
+		query := `delete from item_tags it using tags t
+			where it.tag_id=t.id and t.id=$1 and t.user_id=$2 and it.item_type=$3 and it.item_id=$4`
+		result, err := t.tm.getConn(ctx).ExecContext(ctx, query, tagID, userID, itemType, itemID)
+		if err != nil {
+			return fmt.Errorf("repo tag detach err: %w", err)
+		}
+		if err = affected(result); err != nil {
+			return err
+		}
+		return touchItems(ctx, t.tm, userID, itemType, "id=$3", itemID)
+	})
+}
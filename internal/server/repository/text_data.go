@@ -1,10 +1,10 @@
 package repository
 
 import (
-	"errors"
 	"fmt"
 
 	"github.com/zelas91/goph-keeper/internal/server/repository/entities"
+	"github.com/zelas91/goph-keeper/internal/server/types"
 	"golang.org/x/net/context"
 )
 
@@ -12,26 +12,40 @@ type textData struct {
 	tm transactionManager
 }
 
-func (t textData) Create(ctx context.Context, uc entities.TextData) error {
-	query := `insert into text_data (large_text,user_id)
-		values (:large_text,:user_id);`
-	if _, err := t.tm.getConn(ctx).NamedExecContext(ctx, query, uc); err != nil {
-		return fmt.Errorf("repo text create err: %w", err)
+func (t textData) Create(ctx context.Context, uc entities.TextData) (int, error) {
+	var id int
+	err := t.tm.do(ctx, func(ctx context.Context) error {
+		seq, err := nextChangeSeq(ctx, t.tm, uc.UserId)
+		if err != nil {
+			return err
+		}
+		uc.ChangeSeq = seq
+		query := `insert into text_data (large_text,user_id,title,note,metadata,change_seq,folder_id)
+			values (:large_text,:user_id,:title,:note,:metadata,:change_seq,
+				(select id from folders where id=:folder_id and user_id=:user_id)) returning id;`
+		if err = namedGet(ctx, t.tm, &id, query, uc); err != nil {
+			return fmt.Errorf("repo text create err: %w", err)
+		}
+		return saveSearchTokens(ctx, t.tm, uc.UserId, types.ItemText, id, uc.SearchTokens)
+	})
+	if err != nil {
+		return 0, err
 	}
-	return nil
+	return id, nil
 }
 
-func (t textData) FindAllByUserID(ctx context.Context, userID int) ([]entities.TextData, error) {
-	query := `select * from text_data where user_id=$1`
+func (t textData) FindAllByUserID(ctx context.Context, userID int,
+	filter entities.ItemFilter) ([]entities.TextData, error) {
+	query, args := itemListSelect(types.ItemText, userID, filter)
 	var texts []entities.TextData
-	if err := t.tm.getConn(ctx).SelectContext(ctx, &texts, query, userID); err != nil {
+	if err := t.tm.getConn(ctx).SelectContext(ctx, &texts, query, args...); err != nil {
 		return texts, fmt.Errorf("repo: get texts err %w", err)
 	}
 	return texts, nil
 }
 
 func (t textData) FindByIDAndUserID(ctx context.Context, textID, userID int) (entities.TextData, error) {
-	query := `select * from text_data where id=$1 and user_id=$2`
+	query := itemSelect(types.ItemText)
 	var text entities.TextData
 	if err := t.tm.getConn(ctx).GetContext(ctx, &text, query, textID, userID); err != nil {
 		return text, fmt.Errorf("repo: text get id=%d  err: %w", textID, err)
@@ -40,11 +54,20 @@ func (t textData) FindByIDAndUserID(ctx context.Context, textID, userID int) (en
 }
 
 func (t textData) Delete(ctx context.Context, textID, userID int) error {
-	query := `delete from text_data where id=$1 and user_id=$2`
-	if _, err := t.tm.getConn(ctx).ExecContext(ctx, query, textID, userID); err != nil {
-		return fmt.Errorf("repo text delete err: %w", err)
-	}
-	return nil
+	return t.tm.do(ctx, func(ctx context.Context) error {
+		if err := deleteItemLinks(ctx, t.tm, types.ItemText, textID, userID); err != nil {
+			return err
+		}
+		query := `delete from text_data where id=$1 and user_id=$2`
+		result, err := t.tm.getConn(ctx).ExecContext(ctx, query, textID, userID)
+		if err != nil {
+			return fmt.Errorf("repo text delete err: %w", err)
+		}
+		if rows, err := result.RowsAffected(); err != nil || rows == 0 {
+			return err
+		}
+		return addTombstone(ctx, t.tm, userID, types.ItemText, textID)
+	})
 }
 
 func (t textData) Update(ctx context.Context, text entities.TextData) error {
@@ -53,8 +76,17 @@ func (t textData) Update(ctx context.Context, text entities.TextData) error {
 		if _, err := t.tm.getConn(ctx).ExecContext(ctx, query, text.ID); err != nil {
 			return fmt.Errorf("repo text update block err :%w", err)
 		}
+		seq, err := nextChangeSeq(ctx, t.tm, text.UserId)
+		if err != nil {
+			return err
+		}
+		text.ChangeSeq = seq
 		query = `update text_data set
-				large_text=:large_text
+				large_text=:large_text,
+				title=:title,
+				note=:note,
+				metadata=:metadata,
+				change_seq=:change_seq
 			where
 				id=:id and user_id=:user_id and version=:version;`
 		result, err := t.tm.getConn(ctx).NamedExecContext(ctx, query, text)
@@ -66,9 +98,9 @@ func (t textData) Update(ctx context.Context, text entities.TextData) error {
 			return fmt.Errorf("repo text update result err: %w", err)
 		}
 		if rowsAffected == 0 {
-			return errors.New("the versions on the server and client do not match")
+			return updateMissed(ctx, t.tm, types.ItemText, text.ID, text.UserId)
 		}
-		return nil
+		return saveSearchTokens(ctx, t.tm, text.UserId, types.ItemText, text.ID, text.SearchTokens)
 	})
 
 	return err
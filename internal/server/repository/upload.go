@@ -0,0 +1,84 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/zelas91/goph-keeper/internal/server/repository/entities"
+	"golang.org/x/net/context"
+)
+
+type upload struct {
+	tm transactionManager
+}
+
+func (u upload) Create(ctx context.Context, up entities.Upload) error {
+	query := `insert into uploads (id, user_id, file_name, size, info)
+		values (:id, :user_id, :file_name, :size, :info)`
+	if _, err := u.tm.getConn(ctx).NamedExecContext(ctx, query, up); err != nil {
+		return fmt.Errorf("repo upload create err: %w", err)
+	}
+	return nil
+}
+
+func (u upload) FindByIDAndUserID(ctx context.Context, uploadID string, userID int) (entities.Upload, error) {
+	query := `select * from uploads where id=$1 and user_id=$2`
+	var up entities.Upload
+	if err := u.tm.getConn(ctx).GetContext(ctx, &up, query, uploadID, userID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return up, ErrNotFound
+		}
+		return up, fmt.Errorf("repo: upload get id=%s err: %w", uploadID, err)
+	}
+	return up, nil
+}
+
+func (u upload) FindAllByUserID(ctx context.Context, userID int) ([]entities.Upload, error) {
+	query := `select * from uploads where user_id=$1 order by created_at`
+	var uploads []entities.Upload
+	if err := u.tm.getConn(ctx).SelectContext(ctx, &uploads, query, userID); err != nil {
+		return uploads, fmt.Errorf("repo: get uploads err %w", err)
+	}
+	return uploads, nil
+}
+
+// Chunks returns the received ranges ordered by start, they may overlap.
+func (u upload) Chunks(ctx context.Context, uploadID string) ([]entities.UploadChunk, error) {
+	query := `select start_at, end_at from upload_chunks where upload_id=$1 order by start_at, end_at`
+	var chunks []entities.UploadChunk
+	if err := u.tm.getConn(ctx).SelectContext(ctx, &chunks, query, uploadID); err != nil {
+		return chunks, fmt.Errorf("repo: get upload chunks err %w", err)
+	}
+	return chunks, nil
+}
+
+// AddChunk records the range written to the upload file, a chunk sent
+// again is recorded once.
+func (u upload) AddChunk(ctx context.Context, uploadID string, userID int, chunk entities.UploadChunk) error {
+	return u.tm.do(ctx, func(ctx context.Context) error {
+		query := `update uploads set update_at=now() where id=$1 and user_id=$2`
+		result, err := u.tm.getConn(ctx).ExecContext(ctx, query, uploadID, userID)
+		if err != nil {
+			return fmt.Errorf("repo upload touch err: %w", err)
+		}
+		if err = affected(result); err != nil {
+			return err
+		}
+		query = `insert into upload_chunks (upload_id, start_at, end_at) values ($1, $2, $3)
+			on conflict do nothing`
+		if _, err = u.tm.getConn(ctx).ExecContext(ctx, query, uploadID, chunk.Start, chunk.End); err != nil {
+			return fmt.Errorf("repo upload chunk err: %w", err)
+		}
+		return nil
+	})
+}
+
+func (u upload) Delete(ctx context.Context, uploadID string, userID int) error {
+	query := `delete from uploads where id=$1 and user_id=$2`
+	result, err := u.tm.getConn(ctx).ExecContext(ctx, query, uploadID, userID)
+	if err != nil {
+		return fmt.Errorf("repo upload delete err: %w", err)
+	}
+	return affected(result)
+}
@@ -0,0 +1,34 @@
+package repository
+
+import (
+	"fmt"
+
+	"github.com/zelas91/goph-keeper/internal/server/repository/entities"
+	"golang.org/x/net/context"
+)
+
+type usage struct {
+	tm transactionManager
+}
+
+// Usage counts the items of the user and the bytes of the files. The
+// files with the same content are counted once, the unfinished uploads
+// are counted with their full size as the space they take when finished.
+func (u usage) Usage(ctx context.Context, userID int) (entities.Usage, error) {
+	query := `select
+			(select coalesce(sum(size), 0) from (
+				select distinct on (case when sha256 = '' then path else sha256 end) size
+				from binary_file where user_id = $1) f)
+			+ (select coalesce(sum(size), 0) from uploads where user_id = $1) as bytes,
+			(select count(*) from cards where user_id = $1)
+			+ (select count(*) from user_credentials where user_id = $1)
+			+ (select count(*) from text_data where user_id = $1)
+			+ (select count(*) from binary_file where user_id = $1)
+			+ (select count(*) from otp_keys where user_id = $1)
+			+ (select count(*) from ssh_keys where user_id = $1) as items`
+	var result entities.Usage
+	if err := u.tm.getConn(ctx).GetContext(ctx, &result, query, userID); err != nil {
+		return result, fmt.Errorf("repo: usage err %w", err)
+	}
+	return result, nil
+}
@@ -1,99 +1,195 @@
 package services
 
 import (
-	"compress/gzip"
+	"bytes"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"io"
-	"os"
-	"path/filepath"
+	"strings"
+	"time"
 
 	"github.com/zelas91/goph-keeper/internal/logger"
 	"github.com/zelas91/goph-keeper/internal/server/helper"
 	"github.com/zelas91/goph-keeper/internal/server/models"
 	"github.com/zelas91/goph-keeper/internal/server/repository/entities"
+	"github.com/zelas91/goph-keeper/internal/server/storage"
 	"github.com/zelas91/goph-keeper/internal/server/types"
+	compress2 "github.com/zelas91/goph-keeper/internal/utils/compress"
 	"golang.org/x/net/context"
 )
 
 type binaryFile struct {
 	log        logger.Logger
 	repo       binaryFileRepo
-	basePath   string
+	crypto     crypto
+	blobs      storage.BlobStore
+	codec      compress2.Codec
 	compress   compress
 	decompress decompress
+	events     *events
+	quota      *quota
+	share      *share
 }
 
 type compress interface {
-	Writer() *gzip.Writer
-	Release(writer *gzip.Writer)
+	Writer(codec compress2.Codec, w io.Writer) (io.WriteCloser, error)
 }
 
 type decompress interface {
-	Release(reader *gzip.Reader)
-	Reader() *gzip.Reader
+	Reader(codec compress2.Codec, r io.Reader) (io.ReadCloser, error)
 }
 
 //go:generate mockgen -package mocks -destination=./mocks/mock_binary_file_repo.go -source=binary_file.go -package=mock
 type binaryFileRepo interface {
-	Create(ctx context.Context, bf entities.BinaryFile) error
+	Create(ctx context.Context, bf entities.BinaryFile, blobSize int64) (int, string, error)
 	FindByIDAndUserID(ctx context.Context, fileID, userID int) (entities.BinaryFile, error)
-	FindAllByUserID(ctx context.Context, userID int) ([]entities.BinaryFile, error)
-	Delete(ctx context.Context, userID, fileID int) error
+	FindAllByUserID(ctx context.Context, userID int, filter entities.ItemFilter) ([]entities.BinaryFile, error)
+	Delete(ctx context.Context, userID, fileID int) (string, error)
 }
 
 func (b *binaryFile) Upload(ctx context.Context, bf models.BinaryFile, reader <-chan []byte) error {
+	if err := b.quota.checkFile(ctx, int64(bf.Size)); err != nil {
+		return err
+	}
+	return b.store(ctx, bf, &chanReader{ch: reader}, "")
+}
+
+// store compresses the content into a new blob of the user and creates
+// the item, a content that fails or does not match the size or the SHA-256
+// sent by the client leaves nothing. The blobs are counted by the SHA-256
+// of the content in the namespace of the user, a content the user has
+// already is kept once. The key of a new blob is random, so a blob removed
+// with its last file is never the blob of a file stored at the same time.
+// The file of an upload removes the upload with its creation.
+func (b *binaryFile) store(ctx context.Context, bf models.BinaryFile, src io.Reader, uploadID string) error {
 	userID := ctx.Value(types.UserIDKey).(int)
-	path := fmt.Sprintf("%s/%d/%s", b.basePath, userID, bf.FileName)
-	bf.Path = path
+	name := make([]byte, 16)
+	if _, err := rand.Read(name); err != nil {
+		return fmt.Errorf("blob key err: %w", err)
+	}
+	key := fmt.Sprintf("%d/blobs/%s", userID, hex.EncodeToString(name))
+	bf.Path = key
 	bf.UserId = userID
 
-	if err := os.MkdirAll(filepath.Dir(path), os.ModePerm); err != nil {
+	h := sha256.New()
+	src = &limitReader{r: src, n: int64(bf.Size)}
+	codec, count, blobSize, err := b.compressTo(ctx, key, io.TeeReader(src, h))
+	sum := hex.EncodeToString(h.Sum(nil))
+	stored := err == nil
+	if err == nil && bf.Size != count {
+		err = errors.New("file does not match length")
+	}
+	if err == nil && bf.Sha256 != "" && !strings.EqualFold(bf.Sha256, sum) {
+		err = ErrFileChecksum
+	}
+	bf.Sha256 = sum
+	if err != nil {
+		if stored {
+			b.removeBlob(ctx, key)
+		}
 		return err
 	}
 
-	file, err := os.Create(path)
+	ef, err := b.encryptToEntities(bf)
 	if err != nil {
-		return fmt.Errorf("failed to create file:%w", err)
+		return err
 	}
-	defer func() {
-		if err := file.Close(); err != nil {
-			b.log.Errorf("close file err:%v", err)
-		}
-	}()
-	gz := b.compress.Writer()
-	defer b.compress.Release(gz)
-	gz.Reset(file)
-	count := 0
-	for val := range reader {
-		count = count + len(val)
-		_, err = gz.Write(val)
+	ef.Codec = string(codec)
+	ef.UploadID = uploadID
+	id, blobKey, err := b.repo.Create(ctx, ef, blobSize)
+	if err != nil {
+		b.removeBlob(ctx, key)
+		return err
+	}
+	if blobKey != key {
+		b.removeBlob(ctx, key)
+	}
+	b.events.publish(ctx, models.EventCreated, types.ItemFile, id)
+	return nil
+}
+
+// compressTo streams the compressed content into the blob, the count is
+// the size of the content and the size is the size of the blob. The content
+// that looks compressed already by its start is stored as it is.
+func (b *binaryFile) compressTo(ctx context.Context, key string,
+	src io.Reader) (codec compress2.Codec, count int, size int64, err error) {
+	sample := make([]byte, compress2.SampleSize)
+	n, err := io.ReadFull(src, sample)
+	if err != nil && err != io.EOF && !errors.Is(err, io.ErrUnexpectedEOF) {
+		return "", 0, 0, fmt.Errorf("save err %w", err)
+	}
+	codec = b.codec
+	if compress2.Incompressible(sample[:n]) {
+		codec = compress2.None
+	}
+	src = io.MultiReader(bytes.NewReader(sample[:n]), src)
+
+	pr, pw := io.Pipe()
+	var read int64
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		w, err := b.compress.Writer(codec, pw)
 		if err != nil {
-			return fmt.Errorf("save err %w", err)
+			_ = pw.CloseWithError(err)
+			return
 		}
+		read, err = io.Copy(w, src)
+		if closeErr := w.Close(); err == nil {
+			err = closeErr
+		}
+		_ = pw.CloseWithError(err)
+	}()
+
+	size, err = b.blobs.Put(ctx, key, pr)
+	_ = pr.CloseWithError(io.ErrClosedPipe)
+	<-done
+	if err != nil {
+		return "", 0, 0, fmt.Errorf("save err %w", err)
 	}
-	if err = gz.Flush(); err != nil {
-		return fmt.Errorf("gzip flush err %w", err)
-	}
+	return codec, int(read), size, nil
+}
 
-	if bf.Size != count {
-		return errors.New("file does not match length")
+func (b *binaryFile) removeBlob(ctx context.Context, key string) {
+	if err := b.blobs.Delete(ctx, key); err != nil {
+		b.log.Errorf("remove blob %s err: %v", key, err)
 	}
+}
 
-	if err := b.repo.Create(ctx, helper.ToEntitiesBinaryFile(bf)); err != nil {
-		return err
+// chanReader reads the messages of the websocket upload as a stream.
+type chanReader struct {
+	ch  <-chan []byte
+	buf []byte
+}
+
+func (r *chanReader) Read(p []byte) (int, error) {
+	for len(r.buf) == 0 {
+		v, ok := <-r.ch
+		if !ok {
+			return 0, io.EOF
+		}
+		r.buf = v
 	}
-	return nil
+	n := copy(p, r.buf)
+	r.buf = r.buf[n:]
+	return n, nil
 }
 
 func (b *binaryFile) Download(ctx context.Context, bf models.BinaryFile, write chan<- []byte) error {
 	defer close(write)
+	ctx, _, err := b.share.asOwner(ctx, types.ItemFile, bf.ID, false)
+	if err != nil {
+		return err
+	}
 	userID := ctx.Value(types.UserIDKey).(int)
 	ef, err := b.repo.FindByIDAndUserID(ctx, bf.ID, userID)
 	if err != nil {
 		return err
 	}
-	file, err := os.OpenFile(ef.Path, os.O_RDONLY, 066)
+	file, err := b.blobs.Get(ctx, ef.Path)
 	if err != nil {
 		return err
 	}
@@ -103,56 +199,110 @@ func (b *binaryFile) Download(ctx context.Context, bf models.BinaryFile, write c
 		}
 	}()
 
-	gz := b.decompress.Reader()
-	defer b.decompress.Release(gz)
-	if err = gz.Reset(file); err != nil {
-		return fmt.Errorf("gzip reader reset err: %w", err)
+	reader, err := b.decompress.Reader(compress2.Codec(ef.Codec), file)
+	if err != nil {
+		return err
 	}
+	defer func() {
+		_ = reader.Close()
+	}()
 
+	content := newVerifyReader(reader, ef.Sha256, int64(ef.Size))
 	for {
 		buffer := make([]byte, 1024)
-		n, err := gz.Read(buffer)
+		n, err := content.Read(buffer)
+		if n > 0 {
+			write <- buffer[:n]
+		}
 		if err != nil {
 			if err != io.EOF {
 				return fmt.Errorf("failed read file err: %w", err)
 			}
 			break
 		}
-		write <- buffer[:n]
 	}
 	return nil
 }
 
-func (b *binaryFile) Files(ctx context.Context) ([]models.BinaryFile, error) {
+func (b *binaryFile) Files(ctx context.Context, filter models.ItemFilter) ([]models.BinaryFile, string, error) {
 	userID := ctx.Value(types.UserIDKey).(int)
-	ef, err := b.repo.FindAllByUserID(ctx, userID)
+	ef, err := b.repo.FindAllByUserID(ctx, userID, pageFilter(filter))
 	if err != nil {
-		return nil, err
+		return nil, "", err
 	}
+	ef, next := cutPage(ef, filter, func(v entities.BinaryFile) (int, time.Time, time.Time) {
+		return v.ID, v.CreatedAt, v.UpdateAt
+	})
 	files := make([]models.BinaryFile, len(ef))
 	for i, v := range ef {
-		files[i] = helper.ToModelBinaryFile(v)
+		file, err := b.decryptToModels(v)
+		if err != nil {
+			return nil, "", err
+		}
+		files[i] = file
 	}
-	return files, nil
+	return files, next, nil
 }
 
+// File returns the file item, a file shared with the user comes with
+// its key wrapped for the user.
 func (b *binaryFile) File(ctx context.Context, fileID int) (models.BinaryFile, error) {
+	ctx, sh, err := b.share.asOwner(ctx, types.ItemFile, fileID, false)
+	if err != nil {
+		return models.BinaryFile{}, err
+	}
 	userID := ctx.Value(types.UserIDKey).(int)
 	file, err := b.repo.FindByIDAndUserID(ctx, fileID, userID)
 	if err != nil {
 		return models.BinaryFile{}, fmt.Errorf("get file err: %w", err)
 	}
-	return helper.ToModelBinaryFile(file), nil
+	bf, err := b.decryptToModels(file)
+	if err != nil {
+		return models.BinaryFile{}, err
+	}
+	if sh != nil {
+		bf.FileKey = sh.FileKey
+	}
+	return bf, nil
 }
 func (b *binaryFile) Delete(ctx context.Context, fileID int) error {
 	userID := ctx.Value(types.UserIDKey).(int)
-	file, err := b.repo.FindByIDAndUserID(ctx, fileID, userID)
+	if _, err := b.repo.FindByIDAndUserID(ctx, fileID, userID); err != nil {
+		return err
+	}
+	orphan, err := b.repo.Delete(ctx, userID, fileID)
 	if err != nil {
 		return err
 	}
+	if orphan != "" {
+		b.removeBlob(ctx, orphan)
+	}
+	b.events.publish(ctx, models.EventDeleted, types.ItemFile, fileID)
+	return nil
+}
 
-	if err = os.Remove(file.Path); err != nil {
-		return err
+func (b *binaryFile) encryptToEntities(bf models.BinaryFile) (entities.BinaryFile, error) {
+	meta, err := encryptMeta(b.crypto, bf.Title, bf.Note, bf.Metadata)
+	if err != nil {
+		return entities.BinaryFile{}, err
+	}
+	ef := helper.ToEntitiesBinaryFile(bf)
+	ef.Title = meta.title
+	ef.Note = meta.note
+	ef.Metadata = meta.metadata
+	ef.SearchTokens = searchTokens(b.crypto, bf.UserId, bf.Title, bf.Note, bf.Metadata, bf.FileName)
+	return ef, nil
+}
+
+func (b *binaryFile) decryptToModels(ef entities.BinaryFile) (models.BinaryFile, error) {
+	title, note, metadata, err := decryptMeta(b.crypto,
+		itemMeta{title: ef.Title, note: ef.Note, metadata: ef.Metadata})
+	if err != nil {
+		return models.BinaryFile{}, err
 	}
-	return b.repo.Delete(ctx, userID, fileID)
+	bf := helper.ToModelBinaryFile(ef)
+	bf.Title = title
+	bf.Note = note
+	bf.Metadata = metadata
+	return bf, nil
 }
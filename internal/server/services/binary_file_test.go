@@ -0,0 +1,86 @@
+package services
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/zelas91/goph-keeper/internal/logger"
+	"github.com/zelas91/goph-keeper/internal/server/models"
+	"github.com/zelas91/goph-keeper/internal/server/repository/entities"
+	mock "github.com/zelas91/goph-keeper/internal/server/services/mocks"
+	"github.com/zelas91/goph-keeper/internal/server/storage"
+	"github.com/zelas91/goph-keeper/internal/server/types"
+	compress2 "github.com/zelas91/goph-keeper/internal/utils/compress"
+	crypto2 "github.com/zelas91/goph-keeper/internal/utils/crypto"
+	"golang.org/x/net/context"
+)
+
+// createBlob answers the create of the file as the repository does
+// for a content the user does not have yet.
+func createBlob(_ context.Context, ef entities.BinaryFile, _ int64) (int, string, error) {
+	return 1, ef.Path, nil
+}
+
+// blobs lists the blobs of the user kept under the dir.
+func blobs(t *testing.T, dir string, userID int) []string {
+	entries, err := os.ReadDir(filepath.Join(dir, strconv.Itoa(userID), "blobs"))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	assert.NoError(t, err)
+	names := make([]string, len(entries))
+	for i, v := range entries {
+		names[i] = v.Name()
+	}
+	return names
+}
+
+func TestStoreDedup(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	c, err := crypto2.NewCrypto("0123456789abcdef0123456789abcdef")
+	assert.NoError(t, err)
+	repo := mock.NewMockbinaryFileRepo(ctrl)
+	basePath := t.TempDir()
+	s := New(WithBinaryFileUseRepository(repo, c, logger.New(""), storage.NewLocal(basePath), compress2.Gzip))
+	ctx := context.WithValue(context.Background(), types.UserIDKey, 1)
+	content := "the same content under two names"
+
+	var first string
+	gomock.InOrder(
+		repo.EXPECT().Create(gomock.Any(), gomock.Any(), gomock.Any()).
+			DoAndReturn(func(ctx context.Context, ef entities.BinaryFile, size int64) (int, string, error) {
+				first = ef.Path
+				assert.True(t, strings.HasPrefix(ef.Path, "1/blobs/"))
+				assert.Positive(t, size)
+				return createBlob(ctx, ef, size)
+			}),
+		repo.EXPECT().Create(gomock.Any(), gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, ef entities.BinaryFile, _ int64) (int, string, error) {
+				assert.NotEqual(t, first, ef.Path, "a new blob does not overwrite the blob of another file")
+				return 2, first, nil
+			}),
+	)
+	for _, name := range []string{"a.txt", "b.txt"} {
+		bf := models.BinaryFile{FileName: name, Size: len(content)}
+		assert.NoError(t, s.BinaryFile.store(ctx, bf, strings.NewReader(content), ""))
+	}
+	assert.Equal(t, []string{filepath.Base(first)}, blobs(t, basePath, 1), "the content is kept once")
+
+	repo.EXPECT().FindByIDAndUserID(gomock.Any(), gomock.Any(), 1).Return(entities.BinaryFile{}, nil).Times(2)
+	gomock.InOrder(
+		repo.EXPECT().Delete(gomock.Any(), 1, 1).Return("", nil),
+		repo.EXPECT().Delete(gomock.Any(), 1, 2).Return(first, nil),
+	)
+	assert.NoError(t, s.BinaryFile.Delete(ctx, 1))
+	assert.Len(t, blobs(t, basePath, 1), 1, "the blob is kept while a file points to it")
+	assert.NoError(t, s.BinaryFile.Delete(ctx, 2))
+	assert.Empty(t, blobs(t, basePath, 1), "the blob is removed with its last file")
+}
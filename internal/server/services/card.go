@@ -2,6 +2,7 @@ package services
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/zelas91/goph-keeper/internal/server/models"
 	"github.com/zelas91/goph-keeper/internal/server/repository/entities"
@@ -12,12 +13,15 @@ import (
 type creditCard struct {
 	repo   cardRepo
 	crypto crypto
+	events *events
+	quota  *quota
+	share  *share
 }
 
 //go:generate mockgen -package mocks -destination=./mocks/mock_card_repo.go -source=card.go -package=mock
 type cardRepo interface {
-	Create(ctx context.Context, card entities.Card) error
-	FindAllByUserID(ctx context.Context, userID int) ([]entities.Card, error)
+	Create(ctx context.Context, card entities.Card) (int, error)
+	FindAllByUserID(ctx context.Context, userID int, filter entities.ItemFilter) ([]entities.Card, error)
 	FindByIDAndUserID(ctx context.Context, cardID, userID int) (entities.Card, error)
 	Delete(ctx context.Context, cardID, userID int) error
 	Update(ctx context.Context, card entities.Card) error
@@ -25,35 +29,47 @@ type cardRepo interface {
 
 func (c creditCard) Create(ctx context.Context, card models.Card) error {
 	userID := ctx.Value(types.UserIDKey).(int)
+	if err := c.quota.checkItem(ctx); err != nil {
+		return err
+	}
 	card.UserId = userID
 	entitiesCard, err := c.encryptToEntities(card)
 	if err != nil {
 		return err
 	}
-	if err := c.repo.Create(ctx, entitiesCard); err != nil {
+	id, err := c.repo.Create(ctx, entitiesCard)
+	if err != nil {
 		return fmt.Errorf("create card err: %w", err)
 	}
+	c.events.publish(ctx, models.EventCreated, types.ItemCard, id)
 	return nil
 }
 
-func (c creditCard) Cards(ctx context.Context) ([]models.Card, error) {
+func (c creditCard) Cards(ctx context.Context, filter models.ItemFilter) ([]models.Card, string, error) {
 	userID := ctx.Value(types.UserIDKey).(int)
-	cards, err := c.repo.FindAllByUserID(ctx, userID)
+	cards, err := c.repo.FindAllByUserID(ctx, userID, pageFilter(filter))
 	if err != nil {
-		return nil, fmt.Errorf("get cards err: %w", err)
+		return nil, "", fmt.Errorf("get cards err: %w", err)
 	}
+	cards, next := cutPage(cards, filter, func(v entities.Card) (int, time.Time, time.Time) {
+		return v.ID, v.CreatedAt, v.UpdateAt
+	})
 	cardsModel := make([]models.Card, len(cards))
 	for i, v := range cards {
 		modelCard, err := c.decryptToModels(v)
 		if err != nil {
-			return nil, err
+			return nil, "", err
 		}
 		cardsModel[i] = modelCard
 	}
-	return cardsModel, err
+	return cardsModel, next, nil
 }
 
 func (c creditCard) Card(ctx context.Context, cardID int) (models.Card, error) {
+	ctx, _, err := c.share.asOwner(ctx, types.ItemCard, cardID, false)
+	if err != nil {
+		return models.Card{}, err
+	}
 	userID := ctx.Value(types.UserIDKey).(int)
 	card, err := c.repo.FindByIDAndUserID(ctx, cardID, userID)
 	if err != nil {
@@ -72,10 +88,15 @@ func (c creditCard) Delete(ctx context.Context, cardID int) error {
 	if err := c.repo.Delete(ctx, cardID, userID); err != nil {
 		return fmt.Errorf("delete card err: %w", err)
 	}
+	c.events.publish(ctx, models.EventDeleted, types.ItemCard, cardID)
 	return nil
 }
 
 func (c creditCard) Update(ctx context.Context, card models.Card) error {
+	ctx, _, err := c.share.asOwner(ctx, types.ItemCard, card.ID, true)
+	if err != nil {
+		return err
+	}
 	userID := ctx.Value(types.UserIDKey).(int)
 	card.UserId = userID
 	entitiesCard, err := c.encryptToEntities(card)
@@ -85,6 +106,7 @@ func (c creditCard) Update(ctx context.Context, card models.Card) error {
 	if err := c.repo.Update(ctx, entitiesCard); err != nil {
 		return fmt.Errorf("update card err:%w", err)
 	}
+	c.events.publish(ctx, models.EventUpdated, types.ItemCard, card.ID)
 	return nil
 }
 func (c creditCard) encryptToEntities(card models.Card) (entities.Card, error) {
@@ -100,13 +122,22 @@ func (c creditCard) encryptToEntities(card models.Card) (entities.Card, error) {
 	if err != nil {
 		return entities.Card{}, err
 	}
+	meta, err := encryptMeta(c.crypto, card.Title, card.Note, card.Metadata)
+	if err != nil {
+		return entities.Card{}, err
+	}
 	return entities.Card{
-		Number:    number,
-		Cvv:       cvv,
-		ExpiredAt: ex,
-		ID:        card.ID,
-		UserId:    card.UserId,
-		Version:   card.Version,
+		Number:       number,
+		Cvv:          cvv,
+		ExpiredAt:    ex,
+		ID:           card.ID,
+		UserId:       card.UserId,
+		Version:      card.Version,
+		Title:        meta.title,
+		Note:         meta.note,
+		Metadata:     meta.metadata,
+		FolderID:     card.FolderID,
+		SearchTokens: searchTokens(c.crypto, card.UserId, card.Title, card.Note, card.Metadata),
 	}, nil
 }
 
@@ -123,6 +154,11 @@ func (c creditCard) decryptToModels(card entities.Card) (models.Card, error) {
 	if err != nil {
 		return models.Card{}, err
 	}
+	title, note, metadata, err := decryptMeta(c.crypto,
+		itemMeta{title: card.Title, note: card.Note, metadata: card.Metadata})
+	if err != nil {
+		return models.Card{}, err
+	}
 	return models.Card{
 		Number:    string(number),
 		Cvv:       string(cvv),
@@ -130,5 +166,10 @@ func (c creditCard) decryptToModels(card entities.Card) (models.Card, error) {
 		ID:        card.ID,
 		UserId:    card.UserId,
 		Version:   card.Version,
+		Title:     title,
+		Note:      note,
+		Metadata:  metadata,
+		FolderID:  card.FolderID,
+		Tags:      card.Tags,
 	}, nil
 }
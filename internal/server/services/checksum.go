@@ -0,0 +1,41 @@
+package services
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"hash"
+	"io"
+)
+
+var ErrFileChecksum = errors.New("file checksum does not match")
+
+// verifyReader checks the SHA-256 of the content when its last byte is read,
+// a mismatch is returned in place of the end of the content. An empty want,
+// as of the files stored before the checksums, checks nothing.
+type verifyReader struct {
+	r    io.Reader
+	h    hash.Hash
+	want []byte
+	n    int64
+	size int64
+}
+
+func newVerifyReader(r io.Reader, sum string, size int64) *verifyReader {
+	want, err := hex.DecodeString(sum)
+	if err != nil {
+		want = nil
+	}
+	return &verifyReader{r: r, h: sha256.New(), want: want, size: size}
+}
+
+func (v *verifyReader) Read(p []byte) (int, error) {
+	n, err := v.r.Read(p)
+	v.h.Write(p[:n])
+	v.n += int64(n)
+	if len(v.want) > 0 && v.n == v.size && !bytes.Equal(v.h.Sum(nil), v.want) {
+		return n, ErrFileChecksum
+	}
+	return n, err
+}
@@ -0,0 +1,86 @@
+package services
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/zelas91/goph-keeper/internal/logger"
+	"github.com/zelas91/goph-keeper/internal/server/models"
+	mock "github.com/zelas91/goph-keeper/internal/server/services/mocks"
+	"github.com/zelas91/goph-keeper/internal/server/storage"
+	"github.com/zelas91/goph-keeper/internal/server/types"
+	compress2 "github.com/zelas91/goph-keeper/internal/utils/compress"
+	crypto2 "github.com/zelas91/goph-keeper/internal/utils/crypto"
+	"golang.org/x/net/context"
+)
+
+func TestVerifyReader(t *testing.T) {
+	content := "content of the stored file"
+	sum := sha256.Sum256([]byte(content))
+	tests := []struct {
+		name    string
+		content string
+		sum     string
+		wantErr error
+	}{
+		{name: "#1 ok checksum matches", content: content, sum: hex.EncodeToString(sum[:])},
+		{name: "#2 ok file stored without checksum", content: content},
+		{name: "#3 nok file damaged", content: "CONTENT of the stored file", sum: hex.EncodeToString(sum[:]),
+			wantErr: ErrFileChecksum},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			data, err := io.ReadAll(newVerifyReader(strings.NewReader(test.content), test.sum, int64(len(content))))
+			assert.ErrorIs(t, err, test.wantErr)
+			assert.Equal(t, test.content, string(data))
+		})
+	}
+}
+
+func TestStoreChecksum(t *testing.T) {
+	content := "content sent by the client"
+	sum := sha256.Sum256([]byte(content))
+	tests := []struct {
+		name         string
+		sha256       string
+		mockBehavior func(r *mock.MockbinaryFileRepo)
+		wantErr      error
+	}{
+		{
+			name:   "#1 ok checksum of the client matches",
+			sha256: strings.ToUpper(hex.EncodeToString(sum[:])),
+			mockBehavior: func(r *mock.MockbinaryFileRepo) {
+				r.EXPECT().Create(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(createBlob)
+			},
+		},
+		{name: "#2 nok content changed in transit", sha256: strings.Repeat("0", 64), wantErr: ErrFileChecksum},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			c, err := crypto2.NewCrypto("0123456789abcdef0123456789abcdef")
+			assert.NoError(t, err)
+			repo := mock.NewMockbinaryFileRepo(ctrl)
+			if test.mockBehavior != nil {
+				test.mockBehavior(repo)
+			}
+			basePath := t.TempDir()
+			s := New(WithBinaryFileUseRepository(repo, c, logger.New(""), storage.NewLocal(basePath), compress2.Gzip))
+			ctx := context.WithValue(context.Background(), types.UserIDKey, 1)
+
+			bf := models.BinaryFile{FileName: "sent.txt", Size: len(content), Sha256: test.sha256}
+			err = s.BinaryFile.store(ctx, bf, strings.NewReader(content), "")
+			assert.ErrorIs(t, err, test.wantErr)
+			if test.wantErr != nil {
+				assert.Empty(t, blobs(t, basePath, 1), "a refused file is removed")
+			}
+		})
+	}
+}
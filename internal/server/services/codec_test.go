@@ -0,0 +1,116 @@
+package services
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/rand"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/zelas91/goph-keeper/internal/logger"
+	"github.com/zelas91/goph-keeper/internal/server/models"
+	"github.com/zelas91/goph-keeper/internal/server/repository/entities"
+	mock "github.com/zelas91/goph-keeper/internal/server/services/mocks"
+	"github.com/zelas91/goph-keeper/internal/server/storage"
+	"github.com/zelas91/goph-keeper/internal/server/types"
+	compress2 "github.com/zelas91/goph-keeper/internal/utils/compress"
+	crypto2 "github.com/zelas91/goph-keeper/internal/utils/crypto"
+	"golang.org/x/net/context"
+)
+
+func TestStoreCodec(t *testing.T) {
+	random := make([]byte, 256<<10)
+	_, err := rand.Read(random)
+	assert.NoError(t, err)
+	text := []byte(strings.Repeat("the content compresses well ", 10000))
+
+	tests := []struct {
+		name      string
+		codec     compress2.Codec
+		content   []byte
+		wantCodec compress2.Codec
+	}{
+		{name: "#1 ok zstd", codec: compress2.Zstd, content: text, wantCodec: compress2.Zstd},
+		{name: "#2 ok gzip", codec: compress2.Gzip, content: text, wantCodec: compress2.Gzip},
+		{name: "#3 ok none", codec: compress2.None, content: text, wantCodec: compress2.None},
+		{name: "#4 ok incompressible", codec: compress2.Zstd, content: random, wantCodec: compress2.None},
+		{name: "#5 ok short", codec: compress2.Zstd, content: []byte("short"), wantCodec: compress2.Zstd},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			c, err := crypto2.NewCrypto("0123456789abcdef0123456789abcdef")
+			assert.NoError(t, err)
+			repo := mock.NewMockbinaryFileRepo(ctrl)
+			basePath := t.TempDir()
+			s := New(WithBinaryFileUseRepository(repo, c, logger.New(""), storage.NewLocal(basePath), test.codec))
+			ctx := context.WithValue(context.Background(), types.UserIDKey, 1)
+
+			var stored entities.BinaryFile
+			var blobSize int64
+			repo.EXPECT().Create(gomock.Any(), gomock.Any(), gomock.Any()).
+				DoAndReturn(func(ctx context.Context, ef entities.BinaryFile, size int64) (int, string, error) {
+					stored, blobSize = ef, size
+					return createBlob(ctx, ef, size)
+				})
+			bf := models.BinaryFile{FileName: "a.bin", Size: len(test.content)}
+			assert.NoError(t, s.BinaryFile.store(ctx, bf, bytes.NewReader(test.content), ""))
+			assert.Equal(t, string(test.wantCodec), stored.Codec)
+			if test.wantCodec == compress2.None {
+				assert.Equal(t, int64(len(test.content)), blobSize)
+			}
+
+			repo.EXPECT().FindByIDAndUserID(gomock.Any(), 0, 1).Return(stored, nil)
+			write := make(chan []byte)
+			errc := make(chan error, 1)
+			go func() {
+				errc <- s.BinaryFile.Download(ctx, models.BinaryFile{}, write)
+			}()
+			var got []byte
+			for v := range write {
+				got = append(got, v...)
+			}
+			assert.NoError(t, <-errc)
+			assert.Equal(t, test.content, got)
+		})
+	}
+}
+
+func TestDownloadLegacyGzip(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	content := "a file stored before the codecs"
+	basePath := t.TempDir()
+	assert.NoError(t, os.MkdirAll(filepath.Join(basePath, "1"), 0o700))
+	file, err := os.Create(filepath.Join(basePath, "1", "old.txt"))
+	assert.NoError(t, err)
+	gz, err := gzip.NewWriterLevel(file, gzip.BestCompression)
+	assert.NoError(t, err)
+	_, err = io.WriteString(gz, content)
+	assert.NoError(t, err)
+	assert.NoError(t, gz.Close())
+	assert.NoError(t, file.Close())
+
+	c, err := crypto2.NewCrypto("0123456789abcdef0123456789abcdef")
+	assert.NoError(t, err)
+	repo := mock.NewMockbinaryFileRepo(ctrl)
+	repo.EXPECT().FindByIDAndUserID(gomock.Any(), 7, 1).
+		Return(entities.BinaryFile{ID: 7, Path: "1/old.txt", Size: len(content)}, nil)
+	s := New(WithBinaryFileUseRepository(repo, c, logger.New(""), storage.NewLocal(basePath), compress2.Zstd))
+	ctx := context.WithValue(context.Background(), types.UserIDKey, 1)
+
+	fc, err := s.BinaryFile.Content(ctx, 7)
+	assert.NoError(t, err)
+	got, err := io.ReadAll(fc.Content)
+	assert.NoError(t, err)
+	assert.NoError(t, fc.Content.Close())
+	assert.Equal(t, content, string(got))
+}
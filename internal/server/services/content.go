@@ -0,0 +1,132 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/zelas91/goph-keeper/internal/server/models"
+	"github.com/zelas91/goph-keeper/internal/server/types"
+	compress2 "github.com/zelas91/goph-keeper/internal/utils/compress"
+	"golang.org/x/net/context"
+)
+
+// Content opens the file for the range requests, the content is decompressed
+// on the fly so the size of the item is the size of the content. A read
+// to the end of the file checks its SHA-256.
+func (b *binaryFile) Content(ctx context.Context, fileID int) (models.FileContent, error) {
+	ctx, _, err := b.share.asOwner(ctx, types.ItemFile, fileID, false)
+	if err != nil {
+		return models.FileContent{}, err
+	}
+	userID := ctx.Value(types.UserIDKey).(int)
+	ef, err := b.repo.FindByIDAndUserID(ctx, fileID, userID)
+	if err != nil {
+		return models.FileContent{}, fmt.Errorf("get file err: %w", err)
+	}
+	bf, err := b.decryptToModels(ef)
+	if err != nil {
+		return models.FileContent{}, err
+	}
+	// the blob is opened only when the content is read, after the status
+	// is sent, a lost blob is found here to be told from the other errors.
+	if _, err = b.blobs.Stat(ctx, ef.Path); err != nil {
+		return models.FileContent{}, fmt.Errorf("stat file err: %w", err)
+	}
+	open := func() (io.ReadCloser, error) {
+		file, err := b.blobs.Get(ctx, ef.Path)
+		if err != nil {
+			return nil, fmt.Errorf("open file err: %w", err)
+		}
+		reader, err := b.decompress.Reader(compress2.Codec(ef.Codec), file)
+		if err != nil {
+			_ = file.Close()
+			return nil, err
+		}
+		return &readCloser{Reader: newVerifyReader(reader, ef.Sha256, int64(ef.Size)), close: func() error {
+			_ = reader.Close()
+			return file.Close()
+		}}, nil
+	}
+	return models.FileContent{
+		File:    bf,
+		ETag:    fmt.Sprintf(`"%d-%d"`, ef.ID, ef.ChangeSeq),
+		ModTime: ef.ModTime,
+		Content: &seekReader{open: open, size: int64(ef.Size)},
+	}, nil
+}
+
+type readCloser struct {
+	io.Reader
+	close func() error
+}
+
+func (r *readCloser) Close() error {
+	return r.close()
+}
+
+// seekReader seeks in a stream that is read from the start only. A seek
+// back opens the stream again, a seek forward skips the bytes between.
+type seekReader struct {
+	open func() (io.ReadCloser, error)
+	size int64
+	// pos is where the stream is, off is where the next read starts.
+	pos, off int64
+	r        io.ReadCloser
+}
+
+func (s *seekReader) Read(p []byte) (int, error) {
+	if s.off >= s.size {
+		return 0, io.EOF
+	}
+	if s.r != nil && s.off < s.pos {
+		if err := s.r.Close(); err != nil {
+			return 0, err
+		}
+		s.r = nil
+	}
+	if s.r == nil {
+		r, err := s.open()
+		if err != nil {
+			return 0, err
+		}
+		s.r, s.pos = r, 0
+	}
+	if s.off > s.pos {
+		n, err := io.CopyN(io.Discard, s.r, s.off-s.pos)
+		s.pos += n
+		if err != nil {
+			return 0, err
+		}
+	}
+	n, err := s.r.Read(p)
+	s.pos += int64(n)
+	s.off = s.pos
+	return n, err
+}
+
+func (s *seekReader) Seek(offset int64, whence int) (int64, error) {
+	switch whence {
+	case io.SeekStart:
+	case io.SeekCurrent:
+		offset += s.off
+	case io.SeekEnd:
+		offset += s.size
+	default:
+		return 0, errors.New("seek: invalid whence")
+	}
+	if offset < 0 {
+		return 0, errors.New("seek: negative position")
+	}
+	s.off = offset
+	return offset, nil
+}
+
+func (s *seekReader) Close() error {
+	if s.r == nil {
+		return nil
+	}
+	err := s.r.Close()
+	s.r = nil
+	return err
+}
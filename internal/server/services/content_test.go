@@ -0,0 +1,52 @@
+package services
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSeekReader(t *testing.T) {
+	data := []byte("0123456789abcdefghijklmnopqrstuvwxyz")
+	var compressed bytes.Buffer
+	gz := gzip.NewWriter(&compressed)
+	_, err := gz.Write(data)
+	assert.NoError(t, err)
+	assert.NoError(t, gz.Close())
+
+	opened := 0
+	s := &seekReader{size: int64(len(data)), open: func() (io.ReadCloser, error) {
+		opened++
+		return gzip.NewReader(bytes.NewReader(compressed.Bytes()))
+	}}
+
+	tests := []struct {
+		name       string
+		offset     int64
+		whence     int
+		read       int
+		want       string
+		wantOpened int
+	}{
+		{name: "#1 ok size from the end", offset: 0, whence: io.SeekEnd, read: 0, want: "", wantOpened: 0},
+		{name: "#2 ok seek forward skips", offset: 10, whence: io.SeekStart, read: 6, want: "abcdef", wantOpened: 1},
+		{name: "#3 ok read goes on", offset: 0, whence: io.SeekCurrent, read: 3, want: "ghi", wantOpened: 1},
+		{name: "#4 ok seek back opens again", offset: 2, whence: io.SeekStart, read: 3, want: "234", wantOpened: 2},
+		{name: "#5 ok tail of the content", offset: -2, whence: io.SeekEnd, read: 2, want: "yz", wantOpened: 2},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := s.Seek(test.offset, test.whence)
+			assert.NoError(t, err)
+			buf := make([]byte, test.read)
+			_, err = io.ReadFull(s, buf)
+			assert.NoError(t, err)
+			assert.Equal(t, test.want, string(buf))
+			assert.Equal(t, test.wantOpened, opened)
+		})
+	}
+	assert.NoError(t, s.Close())
+}
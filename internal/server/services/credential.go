@@ -2,6 +2,7 @@ package services
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/zelas91/goph-keeper/internal/server/models"
 	"github.com/zelas91/goph-keeper/internal/server/repository/entities"
@@ -12,12 +13,15 @@ import (
 type credential struct {
 	repo   credentialRepo
 	crypto crypto
+	events *events
+	quota  *quota
+	share  *share
 }
 
 //go:generate mockgen -package mocks -destination=./mocks/mock_credential_repo.go -source=credential.go -package=mock
 type credentialRepo interface {
-	Create(ctx context.Context, uc entities.UserCredentials) error
-	FindAllByUserID(ctx context.Context, userID int) ([]entities.UserCredentials, error)
+	Create(ctx context.Context, uc entities.UserCredentials) (int, error)
+	FindAllByUserID(ctx context.Context, userID int, filter entities.ItemFilter) ([]entities.UserCredentials, error)
 	FindByIDAndUserID(ctx context.Context, ucID, userID int) (entities.UserCredentials, error)
 	Delete(ctx context.Context, ucID, userID int) error
 	Update(ctx context.Context, uc entities.UserCredentials) error
@@ -25,35 +29,47 @@ type credentialRepo interface {
 
 func (c credential) Create(ctx context.Context, uc models.UserCredentials) error {
 	userID := ctx.Value(types.UserIDKey).(int)
+	if err := c.quota.checkItem(ctx); err != nil {
+		return err
+	}
 	uc.UserId = userID
 	ucEntities, err := c.encryptToEntities(uc)
 	if err != nil {
 		return err
 	}
-	if err := c.repo.Create(ctx, ucEntities); err != nil {
+	id, err := c.repo.Create(ctx, ucEntities)
+	if err != nil {
 		return fmt.Errorf("create credential err: %w", err)
 	}
+	c.events.publish(ctx, models.EventCreated, types.ItemCredential, id)
 	return nil
 }
 
-func (c credential) Credentials(ctx context.Context) ([]models.UserCredentials, error) {
+func (c credential) Credentials(ctx context.Context, filter models.ItemFilter) ([]models.UserCredentials, string, error) {
 	userID := ctx.Value(types.UserIDKey).(int)
-	ucs, err := c.repo.FindAllByUserID(ctx, userID)
+	ucs, err := c.repo.FindAllByUserID(ctx, userID, pageFilter(filter))
 	if err != nil {
-		return nil, fmt.Errorf("get credentials err: %w", err)
+		return nil, "", fmt.Errorf("get credentials err: %w", err)
 	}
+	ucs, next := cutPage(ucs, filter, func(v entities.UserCredentials) (int, time.Time, time.Time) {
+		return v.ID, v.CreatedAt, v.UpdateAt
+	})
 	ucsModel := make([]models.UserCredentials, len(ucs))
 	for i, v := range ucs {
 		ucModel, err := c.decryptToModels(v)
 		if err != nil {
-			return nil, err
+			return nil, "", err
 		}
 		ucsModel[i] = ucModel
 	}
-	return ucsModel, err
+	return ucsModel, next, nil
 }
 
 func (c credential) Credential(ctx context.Context, ucID int) (models.UserCredentials, error) {
+	ctx, _, err := c.share.asOwner(ctx, types.ItemCredential, ucID, false)
+	if err != nil {
+		return models.UserCredentials{}, err
+	}
 	userID := ctx.Value(types.UserIDKey).(int)
 	uc, err := c.repo.FindByIDAndUserID(ctx, ucID, userID)
 
@@ -73,10 +89,15 @@ func (c credential) Delete(ctx context.Context, ucID int) error {
 	if err := c.repo.Delete(ctx, ucID, userID); err != nil {
 		return fmt.Errorf("delete credential err: %w", err)
 	}
+	c.events.publish(ctx, models.EventDeleted, types.ItemCredential, ucID)
 	return nil
 }
 
 func (c credential) Update(ctx context.Context, uc models.UserCredentials) error {
+	ctx, _, err := c.share.asOwner(ctx, types.ItemCredential, uc.ID, true)
+	if err != nil {
+		return err
+	}
 	userID := ctx.Value(types.UserIDKey).(int)
 	uc.UserId = userID
 	ucEntities, err := c.encryptToEntities(uc)
@@ -86,6 +107,7 @@ func (c credential) Update(ctx context.Context, uc models.UserCredentials) error
 	if err := c.repo.Update(ctx, ucEntities); err != nil {
 		return fmt.Errorf("update credentials err:%w", err)
 	}
+	c.events.publish(ctx, models.EventUpdated, types.ItemCredential, uc.ID)
 	return nil
 }
 
@@ -98,12 +120,21 @@ func (c credential) encryptToEntities(us models.UserCredentials) (entities.UserC
 	if err != nil {
 		return entities.UserCredentials{}, err
 	}
+	meta, err := encryptMeta(c.crypto, us.Title, us.Note, us.Metadata)
+	if err != nil {
+		return entities.UserCredentials{}, err
+	}
 	return entities.UserCredentials{
-		Login:    login,
-		Password: password,
-		ID:       us.ID,
-		UserId:   us.UserId,
-		Version:  us.Version,
+		Login:        login,
+		Password:     password,
+		ID:           us.ID,
+		UserId:       us.UserId,
+		Version:      us.Version,
+		Title:        meta.title,
+		Note:         meta.note,
+		Metadata:     meta.metadata,
+		FolderID:     us.FolderID,
+		SearchTokens: searchTokens(c.crypto, us.UserId, us.Title, us.Note, us.Metadata, us.Login),
 	}, nil
 }
 
@@ -116,11 +147,21 @@ func (c credential) decryptToModels(us entities.UserCredentials) (models.UserCre
 	if err != nil {
 		return models.UserCredentials{}, err
 	}
+	title, note, metadata, err := decryptMeta(c.crypto,
+		itemMeta{title: us.Title, note: us.Note, metadata: us.Metadata})
+	if err != nil {
+		return models.UserCredentials{}, err
+	}
 	return models.UserCredentials{
 		ID:       us.ID,
 		UserId:   us.UserId,
 		Version:  us.Version,
 		Password: string(password),
 		Login:    string(login),
+		Title:    title,
+		Note:     note,
+		Metadata: metadata,
+		FolderID: us.FolderID,
+		Tags:     us.Tags,
 	}, nil
 }
@@ -0,0 +1,156 @@
+package services
+
+import (
+	"errors"
+	"time"
+
+	"github.com/zelas91/goph-keeper/internal/logger"
+	"github.com/zelas91/goph-keeper/internal/server/models"
+	"github.com/zelas91/goph-keeper/internal/server/repository/entities"
+	"github.com/zelas91/goph-keeper/internal/server/types"
+	"golang.org/x/net/context"
+)
+
+var ErrEmergencyReadOnly = errors.New("the emergency access is for reading only")
+
+// emergency lets a trusted contact read the vault of the owner. The contact
+// requests the access, the owner approves or rejects it, and a request
+// nobody rejects is granted once its waiting period passes.
+type emergency struct {
+	repo emergencyRepo
+	log  logger.Logger
+	now  func() time.Time
+}
+
+//go:generate mockgen -package mocks -destination=./mocks/mock_emergency_repo.go -source=emergency.go -package=mock
+type emergencyRepo interface {
+	Create(ctx context.Context, ea entities.EmergencyAccess) (int, error)
+	FindByOwner(ctx context.Context, ownerID int) ([]entities.EmergencyAccess, error)
+	FindByContact(ctx context.Context, contactID int) ([]entities.EmergencyAccess, error)
+	FindGranted(ctx context.Context, accessID, contactID int) (entities.EmergencyAccess, error)
+	Request(ctx context.Context, accessID, contactID int, now time.Time) error
+	Approve(ctx context.Context, accessID, ownerID int, now time.Time) error
+	Reject(ctx context.Context, accessID, ownerID int) error
+	Delete(ctx context.Context, accessID, userID int) error
+	GrantExpired(ctx context.Context, now time.Time) (int64, error)
+}
+
+// Create names the contact of the user.
+func (e emergency) Create(ctx context.Context, ea models.EmergencyAccess) (models.EmergencyAccess, error) {
+	userID := ctx.Value(types.UserIDKey).(int)
+	id, err := e.repo.Create(ctx, entities.EmergencyAccess{
+		OwnerID:   userID,
+		Contact:   ea.Contact,
+		WaitHours: ea.WaitHours,
+		VaultKey:  ea.VaultKey,
+	})
+	if err != nil {
+		return models.EmergencyAccess{}, err
+	}
+	ea.ID = id
+	ea.Status = models.EmergencyIdle
+	return ea, nil
+}
+
+// Accesses returns the contacts of the user and the owners that name the
+// user their contact. The contact gets the key of the vault once granted.
+func (e emergency) Accesses(ctx context.Context) (models.EmergencyAccesses, error) {
+	userID := ctx.Value(types.UserIDKey).(int)
+	contacts, err := e.repo.FindByOwner(ctx, userID)
+	if err != nil {
+		return models.EmergencyAccesses{}, err
+	}
+	owners, err := e.repo.FindByContact(ctx, userID)
+	if err != nil {
+		return models.EmergencyAccesses{}, err
+	}
+	accesses := models.EmergencyAccesses{
+		Contacts: make([]models.EmergencyAccess, len(contacts)),
+		Owners:   make([]models.EmergencyAccess, len(owners)),
+	}
+	for i, v := range contacts {
+		accesses.Contacts[i] = toModelEmergency(v)
+	}
+	for i, v := range owners {
+		if v.Status != models.EmergencyGranted {
+			v.VaultKey = ""
+		}
+		accesses.Owners[i] = toModelEmergency(v)
+	}
+	return accesses, nil
+}
+
+// Request starts the waiting period, the contact does it.
+func (e emergency) Request(ctx context.Context, accessID int) error {
+	userID := ctx.Value(types.UserIDKey).(int)
+	return e.repo.Request(ctx, accessID, userID, e.now())
+}
+
+// Approve grants the access without waiting, the owner does it.
+func (e emergency) Approve(ctx context.Context, accessID int) error {
+	userID := ctx.Value(types.UserIDKey).(int)
+	return e.repo.Approve(ctx, accessID, userID, e.now())
+}
+
+// Reject turns down the request or takes back the access granted, the
+// owner does it.
+func (e emergency) Reject(ctx context.Context, accessID int) error {
+	userID := ctx.Value(types.UserIDKey).(int)
+	return e.repo.Reject(ctx, accessID, userID)
+}
+
+// Delete removes the access, the owner and the contact do it.
+func (e emergency) Delete(ctx context.Context, accessID int) error {
+	userID := ctx.Value(types.UserIDKey).(int)
+	return e.repo.Delete(ctx, accessID, userID)
+}
+
+// VaultUserID returns the owner of the access granted to the user for the
+// request of the access, the contact only reads the vault.
+func (e emergency) VaultUserID(ctx context.Context, accessID int, access string) (int, error) {
+	if access != models.AccessRead {
+		return 0, ErrEmergencyReadOnly
+	}
+	userID := ctx.Value(types.UserIDKey).(int)
+	ea, err := e.repo.FindGranted(ctx, accessID, userID)
+	if err != nil {
+		return 0, err
+	}
+	return ea.OwnerID, nil
+}
+
+// GrantEvery grants the requests whose waiting period has passed until
+// the context is done.
+func (e emergency) GrantEvery(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			granted, err := e.repo.GrantExpired(ctx, e.now())
+			if err != nil {
+				e.log.Errorf("grant emergency access err: %v", err)
+				continue
+			}
+			if granted > 0 {
+				e.log.Infof("granted %d emergency accesses", granted)
+			}
+		}
+	}
+}
+
+func toModelEmergency(ea entities.EmergencyAccess) models.EmergencyAccess {
+	return models.EmergencyAccess{
+		ID:          ea.ID,
+		Owner:       ea.Owner,
+		Contact:     ea.Contact,
+		WaitHours:   ea.WaitHours,
+		Status:      ea.Status,
+		VaultKey:    ea.VaultKey,
+		RequestedAt: ea.RequestedAt,
+		GrantedAt:   ea.GrantedAt,
+		CreatedAt:   ea.CreatedAt,
+	}
+}
@@ -0,0 +1,101 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/zelas91/goph-keeper/internal/server/models"
+	"github.com/zelas91/goph-keeper/internal/server/repository"
+	"github.com/zelas91/goph-keeper/internal/server/repository/entities"
+	mock "github.com/zelas91/goph-keeper/internal/server/services/mocks"
+	"github.com/zelas91/goph-keeper/internal/server/types"
+	"golang.org/x/net/context"
+)
+
+func TestEmergencyVaultUserID(t *testing.T) {
+	tests := []struct {
+		name    string
+		access  string
+		mock    func(r *mock.MockemergencyRepo)
+		wantID  int
+		wantErr error
+	}{
+		{
+			name:   "#1 ok contact reads the vault of the owner",
+			access: models.AccessRead,
+			mock: func(r *mock.MockemergencyRepo) {
+				r.EXPECT().FindGranted(gomock.Any(), 3, 2).
+					Return(entities.EmergencyAccess{ID: 3, OwnerID: 1, Status: models.EmergencyGranted}, nil)
+			},
+			wantID: 1,
+		},
+		{
+			name:    "#2 nok contact writes",
+			access:  models.AccessWrite,
+			wantErr: ErrEmergencyReadOnly,
+		},
+		{
+			name:   "#3 nok access not granted",
+			access: models.AccessRead,
+			mock: func(r *mock.MockemergencyRepo) {
+				r.EXPECT().FindGranted(gomock.Any(), 3, 2).Return(entities.EmergencyAccess{}, repository.ErrNotFound)
+			},
+			wantErr: repository.ErrNotFound,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			repo := mock.NewMockemergencyRepo(ctrl)
+			if test.mock != nil {
+				test.mock(repo)
+			}
+			s := New(WithEmergencyUseRepository(repo, nil))
+			ctx := context.WithValue(context.Background(), types.UserIDKey, 2)
+
+			id, err := s.Emergency.VaultUserID(ctx, 3, test.access)
+			assert.ErrorIs(t, err, test.wantErr)
+			assert.Equal(t, test.wantID, id)
+		})
+	}
+}
+
+func TestEmergencyAccesses(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := mock.NewMockemergencyRepo(ctrl)
+	repo.EXPECT().FindByOwner(gomock.Any(), 2).Return([]entities.EmergencyAccess{
+		{ID: 1, OwnerID: 2, Contact: "carol", Status: models.EmergencyIdle, VaultKey: "Ym94MQ=="},
+	}, nil)
+	repo.EXPECT().FindByContact(gomock.Any(), 2).Return([]entities.EmergencyAccess{
+		{ID: 2, ContactID: 2, Owner: "alice", Status: models.EmergencyRequested, VaultKey: "Ym94Mg=="},
+		{ID: 3, ContactID: 2, Owner: "bob", Status: models.EmergencyGranted, VaultKey: "Ym94Mw=="},
+	}, nil)
+	s := New(WithEmergencyUseRepository(repo, nil))
+	ctx := context.WithValue(context.Background(), types.UserIDKey, 2)
+
+	accesses, err := s.Emergency.Accesses(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, "Ym94MQ==", accesses.Contacts[0].VaultKey)
+	assert.Empty(t, accesses.Owners[0].VaultKey, "the key is given to the contact once granted")
+	assert.Equal(t, "Ym94Mw==", accesses.Owners[1].VaultKey)
+}
+
+func TestEmergencyRequest(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	now := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	repo := mock.NewMockemergencyRepo(ctrl)
+	repo.EXPECT().Request(gomock.Any(), 3, 2, now).Return(nil)
+	s := New(WithEmergencyUseRepository(repo, nil))
+	s.Emergency.now = func() time.Time { return now }
+	ctx := context.WithValue(context.Background(), types.UserIDKey, 2)
+
+	assert.NoError(t, s.Emergency.Request(ctx, 3))
+}
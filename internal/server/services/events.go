@@ -0,0 +1,74 @@
+package services
+
+import (
+	"sync"
+
+	"github.com/zelas91/goph-keeper/internal/server/models"
+	"github.com/zelas91/goph-keeper/internal/server/types"
+	"golang.org/x/net/context"
+)
+
+// eventBuffer is the number of events a slow connection may lag behind,
+// the events over it are dropped. The client syncs on reconnect anyway.
+const eventBuffer = 64
+
+// events fans the changes of the vault out to the connections of the user
+// on this server instance.
+type events struct {
+	mu   sync.Mutex
+	subs map[int]map[chan models.Event]struct{}
+}
+
+func newEvents() *events {
+	return &events{subs: make(map[int]map[chan models.Event]struct{})}
+}
+
+// Subscribe returns the events of the user from the context, cancel stops them
+// and closes the channel.
+func (e *events) Subscribe(ctx context.Context) (<-chan models.Event, func()) {
+	userID := ctx.Value(types.UserIDKey).(int)
+	ch := make(chan models.Event, eventBuffer)
+
+	e.mu.Lock()
+	if e.subs[userID] == nil {
+		e.subs[userID] = make(map[chan models.Event]struct{})
+	}
+	e.subs[userID][ch] = struct{}{}
+	e.mu.Unlock()
+
+	var once sync.Once
+	return ch, func() {
+		once.Do(func() {
+			e.mu.Lock()
+			defer e.mu.Unlock()
+			delete(e.subs[userID], ch)
+			if len(e.subs[userID]) == 0 {
+				delete(e.subs, userID)
+			}
+			close(ch)
+		})
+	}
+}
+
+// publish sends the change made by the user from the context, the services
+// made without events publish nothing.
+func (e *events) publish(ctx context.Context, action, itemType string, itemID int) {
+	if e == nil {
+		return
+	}
+	userID, ok := ctx.Value(types.UserIDKey).(int)
+	if !ok {
+		return
+	}
+	origin, _ := ctx.Value(types.ClientIDKey).(string)
+	event := models.Event{Action: action, ItemType: itemType, ItemID: itemID, Origin: origin}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	for ch := range e.subs[userID] {
+		select {
+		case ch <- event:
+		default:
+		}
+	}
+}
@@ -0,0 +1,39 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/zelas91/goph-keeper/internal/server/models"
+	"github.com/zelas91/goph-keeper/internal/server/types"
+	"golang.org/x/net/context"
+)
+
+func TestEvents(t *testing.T) {
+	e := newEvents()
+	alice := context.WithValue(context.Background(), types.UserIDKey, 1)
+	bob := context.WithValue(context.Background(), types.UserIDKey, 2)
+
+	aliceEvents, cancelAlice := e.Subscribe(alice)
+	bobEvents, cancelBob := e.Subscribe(bob)
+	defer cancelBob()
+
+	e.publish(context.WithValue(alice, types.ClientIDKey, "phone"), models.EventUpdated, types.ItemCard, 7)
+	assert.Equal(t, models.Event{Action: models.EventUpdated, ItemType: types.ItemCard, ItemID: 7, Origin: "phone"},
+		<-aliceEvents)
+	assert.Len(t, bobEvents, 0, "events of one user must not reach another")
+
+	cancelAlice()
+	cancelAlice()
+	_, ok := <-aliceEvents
+	assert.False(t, ok, "cancel closes the channel")
+	e.publish(alice, models.EventDeleted, types.ItemCard, 7)
+
+	for i := 0; i < eventBuffer+1; i++ {
+		e.publish(bob, models.EventCreated, types.ItemText, 0)
+	}
+	assert.Len(t, bobEvents, eventBuffer, "a slow subscriber drops the events over the buffer")
+
+	var none *events
+	none.publish(alice, models.EventCreated, types.ItemCard, 0)
+}
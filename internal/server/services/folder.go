@@ -0,0 +1,133 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/zelas91/goph-keeper/internal/server/helper"
+	"github.com/zelas91/goph-keeper/internal/server/models"
+	"github.com/zelas91/goph-keeper/internal/server/repository/entities"
+	"github.com/zelas91/goph-keeper/internal/server/types"
+	"golang.org/x/net/context"
+)
+
+var ErrFolderParent = errors.New("parent folder not found or makes a cycle")
+
+type folder struct {
+	repo   folderRepo
+	events *events
+}
+
+//go:generate mockgen -package mocks -destination=./mocks/mock_folder_repo.go -source=folder.go -package=mock
+type folderRepo interface {
+	Create(ctx context.Context, fd entities.Folder) (int, error)
+	FindAllByUserID(ctx context.Context, userID int) ([]entities.Folder, error)
+	Update(ctx context.Context, fd entities.Folder) error
+	Delete(ctx context.Context, folderID, userID int) error
+	MoveItem(ctx context.Context, userID int, folderID *int, itemType string, itemID int) error
+}
+
+func (f folder) Create(ctx context.Context, fd models.Folder) (models.Folder, error) {
+	userID := ctx.Value(types.UserIDKey).(int)
+	fd.UserId = userID
+	if fd.ParentID != nil {
+		folders, err := f.repo.FindAllByUserID(ctx, userID)
+		if err != nil {
+			return models.Folder{}, fmt.Errorf("get folders err: %w", err)
+		}
+		if !validParent(folders, 0, *fd.ParentID) {
+			return models.Folder{}, ErrFolderParent
+		}
+	}
+	id, err := f.repo.Create(ctx, helper.ToEntitiesFolder(fd))
+	if err != nil {
+		return models.Folder{}, fmt.Errorf("create folder err: %w", err)
+	}
+	fd.ID = id
+	f.events.publish(ctx, models.EventCreated, types.Folder, id)
+	return fd, nil
+}
+
+func (f folder) Folders(ctx context.Context) ([]models.Folder, error) {
+	userID := ctx.Value(types.UserIDKey).(int)
+	folders, err := f.repo.FindAllByUserID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("get folders err: %w", err)
+	}
+	foldersModel := make([]models.Folder, len(folders))
+	for i, v := range folders {
+		foldersModel[i] = helper.ToModelFolder(v)
+	}
+	return foldersModel, nil
+}
+
+func (f folder) Update(ctx context.Context, fd models.Folder) error {
+	userID := ctx.Value(types.UserIDKey).(int)
+	fd.UserId = userID
+	if fd.ParentID != nil {
+		folders, err := f.repo.FindAllByUserID(ctx, userID)
+		if err != nil {
+			return fmt.Errorf("get folders err: %w", err)
+		}
+		if !validParent(folders, fd.ID, *fd.ParentID) {
+			return ErrFolderParent
+		}
+	}
+	if err := f.repo.Update(ctx, helper.ToEntitiesFolder(fd)); err != nil {
+		return fmt.Errorf("update folder err: %w", err)
+	}
+	f.events.publish(ctx, models.EventUpdated, types.Folder, fd.ID)
+	return nil
+}
+
+func (f folder) Delete(ctx context.Context, folderID int) error {
+	userID := ctx.Value(types.UserIDKey).(int)
+	if err := f.repo.Delete(ctx, folderID, userID); err != nil {
+		return fmt.Errorf("delete folder err: %w", err)
+	}
+	f.events.publish(ctx, models.EventDeleted, types.Folder, folderID)
+	return nil
+}
+
+// MoveItem puts the item into the folder, nil folderID moves it to the root.
+func (f folder) MoveItem(ctx context.Context, folderID *int, item models.ItemRef) error {
+	userID := ctx.Value(types.UserIDKey).(int)
+	if folderID != nil {
+		folders, err := f.repo.FindAllByUserID(ctx, userID)
+		if err != nil {
+			return fmt.Errorf("get folders err: %w", err)
+		}
+		if !validParent(folders, 0, *folderID) {
+			return ErrFolderParent
+		}
+	}
+	if err := f.repo.MoveItem(ctx, userID, folderID, item.ItemType, item.ItemID); err != nil {
+		return fmt.Errorf("move item err: %w", err)
+	}
+	f.events.publish(ctx, models.EventUpdated, item.ItemType, item.ItemID)
+	return nil
+}
+
+// validParent reports whether parentID is one of the user folders
+// and folderID is not among its ancestors.
+func validParent(folders []entities.Folder, folderID, parentID int) bool {
+	parents := make(map[int]*int, len(folders))
+	for _, v := range folders {
+		parents[v.ID] = v.ParentID
+	}
+	seen := make(map[int]bool)
+	for id := parentID; ; {
+		if id == folderID || seen[id] {
+			return false
+		}
+		seen[id] = true
+		parent, ok := parents[id]
+		if !ok {
+			return false
+		}
+		if parent == nil {
+			return true
+		}
+		id = *parent
+	}
+}
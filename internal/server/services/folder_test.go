@@ -0,0 +1,35 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/zelas91/goph-keeper/internal/server/repository/entities"
+)
+
+func TestValidParent(t *testing.T) {
+	one, two := 1, 2
+	folders := []entities.Folder{
+		{ID: 1},
+		{ID: 2, ParentID: &one},
+		{ID: 3, ParentID: &two},
+		{ID: 4},
+	}
+	tests := []struct {
+		name     string
+		folderID int
+		parentID int
+		want     bool
+	}{
+		{name: "#1 ok new folder in root child", folderID: 0, parentID: 1, want: true},
+		{name: "#2 ok move to other branch", folderID: 2, parentID: 4, want: true},
+		{name: "#3 nok parent not found", folderID: 0, parentID: 9, want: false},
+		{name: "#4 nok parent is the folder", folderID: 2, parentID: 2, want: false},
+		{name: "#5 nok parent is a descendant", folderID: 1, parentID: 3, want: false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.want, validParent(folders, test.folderID, test.parentID))
+		})
+	}
+}
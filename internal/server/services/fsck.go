@@ -0,0 +1,168 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/zelas91/goph-keeper/internal/server/models"
+	"github.com/zelas91/goph-keeper/internal/server/repository/entities"
+	"github.com/zelas91/goph-keeper/internal/server/storage"
+	"golang.org/x/net/context"
+)
+
+// orphanGrace keeps the blobs written for a file not committed yet,
+// a blob younger than it is never an orphan.
+const orphanGrace = time.Hour
+
+// fsck checks that the blob store and the files agree. A file is stored
+// into a new blob that the commit of the file makes referenced, a blob is
+// removed after the commit of the delete, so a crash in between leaves
+// an orphan blob only. The orphans are collected in the background, the
+// files without a blob are reported and removed on demand.
+type fsck struct {
+	repo    fsckRepo
+	service *Service
+	now     func() time.Time
+}
+
+//go:generate mockgen -package mocks -destination=./mocks/mock_fsck_repo.go -source=fsck.go -package=mock
+type fsckRepo interface {
+	Keys(ctx context.Context) ([]string, error)
+	UploadIDs(ctx context.Context) ([]string, error)
+	Files(ctx context.Context) ([]entities.BinaryFile, error)
+	StaleRefs(ctx context.Context) ([]entities.BlobRefs, error)
+	FixRefs(ctx context.Context) error
+}
+
+// Check reports the orphans on both sides, with fix the stale refs are
+// counted again, the orphan blobs are removed and so are the files
+// whose blob is missing.
+func (f fsck) Check(ctx context.Context, fix bool) (models.StorageReport, error) {
+	report := models.StorageReport{Fixed: fix}
+	refs, err := f.repo.StaleRefs(ctx)
+	if err != nil {
+		return report, err
+	}
+	for _, v := range refs {
+		report.StaleRefs = append(report.StaleRefs, v.Key)
+	}
+	if fix && len(refs) > 0 {
+		if err = f.repo.FixRefs(ctx); err != nil {
+			return report, err
+		}
+	}
+	if report.OrphanBlobs, err = f.orphans(ctx, fix); err != nil {
+		return report, err
+	}
+	if report.MissingFiles, err = f.missing(ctx, fix); err != nil {
+		return report, err
+	}
+	return report, nil
+}
+
+// Collect removes the orphan blobs.
+func (f fsck) Collect(ctx context.Context) ([]string, error) {
+	return f.orphans(ctx, true)
+}
+
+// CollectEvery collects the orphan blobs until the context is done.
+func (f fsck) CollectEvery(ctx context.Context, interval time.Duration) {
+	log := f.service.BinaryFile.log
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			orphans, err := f.Collect(ctx)
+			if err != nil {
+				log.Errorf("collect orphan blobs err: %v", err)
+				continue
+			}
+			if len(orphans) > 0 {
+				log.Infof("removed %d orphan blobs", len(orphans))
+			}
+		}
+	}
+}
+
+// orphans finds the blobs of the files and the chunks of the uploads that
+// nothing points to. The files written before the blob store are not
+// under the blob keys and are left alone.
+func (f fsck) orphans(ctx context.Context, remove bool) ([]string, error) {
+	keys, err := f.repo.Keys(ctx)
+	if err != nil {
+		return nil, err
+	}
+	referenced := make(map[string]bool, len(keys))
+	for _, v := range keys {
+		referenced[v] = true
+	}
+	ids, err := f.repo.UploadIDs(ctx)
+	if err != nil {
+		return nil, err
+	}
+	uploads := make(map[string]bool, len(ids))
+	for _, v := range ids {
+		uploads[v] = true
+	}
+
+	blobs := f.service.BinaryFile.blobs
+	before := f.now().Add(-orphanGrace)
+	var orphans []string
+	err = blobs.List(ctx, "", func(info storage.BlobInfo) error {
+		parts := strings.SplitN(info.Key, "/", 4)
+		if len(parts) < 3 || info.ModTime.After(before) {
+			return nil
+		}
+		switch {
+		case parts[1] == "blobs" && len(parts) == 3 && !referenced[info.Key]:
+		case parts[1] == "uploads" && !uploads[parts[2]]:
+		default:
+			return nil
+		}
+		orphans = append(orphans, info.Key)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if remove {
+		for _, v := range orphans {
+			if err = blobs.Delete(ctx, v); err != nil {
+				return orphans, err
+			}
+		}
+	}
+	return orphans, nil
+}
+
+// missing finds the files whose blob is not in the store.
+func (f fsck) missing(ctx context.Context, remove bool) ([]models.MissingFile, error) {
+	files, err := f.repo.Files(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var missing []models.MissingFile
+	for _, v := range files {
+		_, err = f.service.BinaryFile.blobs.Stat(ctx, v.Path)
+		if errors.Is(err, storage.ErrNotFound) {
+			missing = append(missing, models.MissingFile{UserID: v.UserId, FileID: v.ID, Key: v.Path})
+			continue
+		}
+		if err != nil {
+			return missing, err
+		}
+	}
+	if remove {
+		for _, v := range missing {
+			if _, err = f.service.BinaryFile.repo.Delete(ctx, v.UserID, v.FileID); err != nil {
+				return missing, fmt.Errorf("remove file %d err: %w", v.FileID, err)
+			}
+		}
+	}
+	return missing, nil
+}
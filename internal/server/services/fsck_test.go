@@ -0,0 +1,94 @@
+package services
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/zelas91/goph-keeper/internal/logger"
+	"github.com/zelas91/goph-keeper/internal/server/models"
+	"github.com/zelas91/goph-keeper/internal/server/repository/entities"
+	mock "github.com/zelas91/goph-keeper/internal/server/services/mocks"
+	"github.com/zelas91/goph-keeper/internal/server/storage"
+	compress2 "github.com/zelas91/goph-keeper/internal/utils/compress"
+	"golang.org/x/net/context"
+)
+
+func TestFsckCheck(t *testing.T) {
+	tests := []struct {
+		name       string
+		fix        bool
+		wantLeft   []string
+		mockDelete func(r *mock.MockbinaryFileRepo)
+	}{
+		{
+			name:     "#1 ok report only",
+			wantLeft: []string{"1/blobs/kept", "1/blobs/orphan", "1/blobs/young", "1/old.txt", "1/uploads/gone/0-4", "1/uploads/up/0-4"},
+		},
+		{
+			name:     "#2 ok fix",
+			fix:      true,
+			wantLeft: []string{"1/blobs/kept", "1/blobs/young", "1/old.txt", "1/uploads/up/0-4"},
+			mockDelete: func(r *mock.MockbinaryFileRepo) {
+				r.EXPECT().Delete(gomock.Any(), 2, 8).Return("2/blobs/lost", nil)
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			ctx := context.Background()
+			dir := t.TempDir()
+			blobs := storage.NewLocal(dir)
+			for _, key := range []string{"1/blobs/kept", "1/blobs/orphan", "1/blobs/young", "1/old.txt",
+				"1/uploads/up/0-4", "1/uploads/gone/0-4"} {
+				_, err := blobs.Put(ctx, key, strings.NewReader("data"))
+				assert.NoError(t, err)
+			}
+			now := time.Now().Add(2 * orphanGrace)
+			assert.NoError(t, os.Chtimes(filepath.Join(dir, "1", "blobs", "young"), now, now))
+
+			repo := mock.NewMockfsckRepo(ctrl)
+			repo.EXPECT().StaleRefs(gomock.Any()).Return([]entities.BlobRefs{{Key: "1/blobs/kept", UserId: 1, Refs: 1, Files: 2}}, nil)
+			if test.fix {
+				repo.EXPECT().FixRefs(gomock.Any()).Return(nil)
+			}
+			repo.EXPECT().Keys(gomock.Any()).Return([]string{"1/blobs/kept", "2/blobs/lost"}, nil)
+			repo.EXPECT().UploadIDs(gomock.Any()).Return([]string{"up"}, nil)
+			repo.EXPECT().Files(gomock.Any()).Return([]entities.BinaryFile{
+				{ID: 7, UserId: 1, Path: "1/blobs/kept"},
+				{ID: 8, UserId: 2, Path: "2/blobs/lost"},
+				{ID: 9, UserId: 1, Path: filepath.Join(dir, "1", "old.txt")},
+			}, nil)
+			fileRepo := mock.NewMockbinaryFileRepo(ctrl)
+			if test.mockDelete != nil {
+				test.mockDelete(fileRepo)
+			}
+
+			s := New(WithBinaryFileUseRepository(fileRepo, nil, logger.New(""), blobs, compress2.Gzip), WithFsckUseRepository(repo))
+			s.Fsck.now = func() time.Time { return now }
+
+			report, err := s.Fsck.Check(ctx, test.fix)
+			assert.NoError(t, err)
+			assert.Equal(t, models.StorageReport{
+				OrphanBlobs:  []string{"1/blobs/orphan", "1/uploads/gone/0-4"},
+				MissingFiles: []models.MissingFile{{UserID: 2, FileID: 8, Key: "2/blobs/lost"}},
+				StaleRefs:    []string{"1/blobs/kept"},
+				Fixed:        test.fix,
+			}, report)
+
+			var left []string
+			assert.NoError(t, blobs.List(ctx, "", func(info storage.BlobInfo) error {
+				left = append(left, info.Key)
+				return nil
+			}))
+			assert.Equal(t, test.wantLeft, left)
+		})
+	}
+}
@@ -0,0 +1,65 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// itemMeta is the encrypted form of the title, note and metadata
+// that every vault item carries next to its secret.
+type itemMeta struct {
+	title    []byte
+	note     []byte
+	metadata []byte
+}
+
+func encryptMeta(c crypto, title, note string, metadata map[string]string) (itemMeta, error) {
+	var meta itemMeta
+	var err error
+	if meta.title, err = c.Encrypt([]byte(title)); err != nil {
+		return meta, fmt.Errorf("encrypt title err: %w", err)
+	}
+	if meta.note, err = c.Encrypt([]byte(note)); err != nil {
+		return meta, fmt.Errorf("encrypt note err: %w", err)
+	}
+	if len(metadata) == 0 {
+		return meta, nil
+	}
+	body, err := json.Marshal(metadata)
+	if err != nil {
+		return meta, fmt.Errorf("metadata encode err: %w", err)
+	}
+	if meta.metadata, err = c.Encrypt(body); err != nil {
+		return meta, fmt.Errorf("encrypt metadata err: %w", err)
+	}
+	return meta, nil
+}
+
+// decryptMeta skips empty columns, rows created before the metadata
+// columns were added keep them NULL.
+func decryptMeta(c crypto, meta itemMeta) (title, note string, metadata map[string]string, err error) {
+	if len(meta.title) > 0 {
+		body, err := c.Decrypt(meta.title)
+		if err != nil {
+			return "", "", nil, fmt.Errorf("decrypt title err: %w", err)
+		}
+		title = string(body)
+	}
+	if len(meta.note) > 0 {
+		body, err := c.Decrypt(meta.note)
+		if err != nil {
+			return "", "", nil, fmt.Errorf("decrypt note err: %w", err)
+		}
+		note = string(body)
+	}
+	if len(meta.metadata) > 0 {
+		body, err := c.Decrypt(meta.metadata)
+		if err != nil {
+			return "", "", nil, fmt.Errorf("decrypt metadata err: %w", err)
+		}
+		if err = json.Unmarshal(body, &metadata); err != nil {
+			return "", "", nil, fmt.Errorf("metadata decode err: %w", err)
+		}
+	}
+	return title, note, metadata, nil
+}
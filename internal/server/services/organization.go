@@ -0,0 +1,243 @@
+package services
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+
+	"github.com/zelas91/goph-keeper/internal/server/models"
+	"github.com/zelas91/goph-keeper/internal/server/repository/entities"
+	"github.com/zelas91/goph-keeper/internal/server/types"
+	"golang.org/x/net/context"
+)
+
+var (
+	ErrOrgForbidden = errors.New("the role in the organization does not allow it")
+	ErrLastOwner    = errors.New("the organization keeps at least one owner")
+)
+
+// VaultLoginPrefix starts the logins of the users of the organization
+// vaults, the users do not sign up with it.
+const VaultLoginPrefix = "org:"
+
+// roleRank orders the roles, a role may do what the lower ones do.
+var roleRank = map[string]int{
+	models.RoleReadOnly: 1,
+	models.RoleMember:   2,
+	models.RoleAdmin:    3,
+	models.RoleOwner:    4,
+}
+
+// organization lets the users keep items in the vaults of their
+// organizations. The vault is the one of a user made for the organization,
+// the members work in it as that user with the rights of their role.
+type organization struct {
+	repo organizationRepo
+}
+
+//go:generate mockgen -package mocks -destination=./mocks/mock_organization_repo.go -source=organization.go -package=mock
+type organizationRepo interface {
+	Create(ctx context.Context, org entities.Organization, owner entities.OrgMember) (int, error)
+	FindByUser(ctx context.Context, userID int) ([]entities.Organization, error)
+	FindByIDAndUserID(ctx context.Context, orgID, userID int) (entities.Organization, error)
+	Delete(ctx context.Context, orgID int) error
+	Members(ctx context.Context, orgID int) ([]entities.OrgMember, error)
+	AddMember(ctx context.Context, member entities.OrgMember) error
+	SetRole(ctx context.Context, orgID int, login, role string) error
+	RemoveMember(ctx context.Context, orgID int, login string) error
+}
+
+// Create makes the organization with the user as its owner.
+func (o *organization) Create(ctx context.Context, org models.Organization) (models.Organization, error) {
+	userID := ctx.Value(types.UserIDKey).(int)
+	name := make([]byte, 16)
+	if _, err := rand.Read(name); err != nil {
+		return models.Organization{}, fmt.Errorf("vault login err: %w", err)
+	}
+	id, err := o.repo.Create(ctx,
+		entities.Organization{Name: org.Name, VaultLogin: VaultLoginPrefix + hex.EncodeToString(name)},
+		entities.OrgMember{UserID: userID, Role: models.RoleOwner, OrgKey: org.OrgKey})
+	if err != nil {
+		return models.Organization{}, err
+	}
+	org.ID = id
+	org.Role = models.RoleOwner
+	return org, nil
+}
+
+// Organizations returns the organizations of the user.
+func (o *organization) Organizations(ctx context.Context) ([]models.Organization, error) {
+	userID := ctx.Value(types.UserIDKey).(int)
+	orgs, err := o.repo.FindByUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]models.Organization, len(orgs))
+	for i, v := range orgs {
+		result[i] = toModelOrganization(v)
+	}
+	return result, nil
+}
+
+// Delete removes the organization, only an owner does it.
+func (o *organization) Delete(ctx context.Context, orgID int) error {
+	if _, err := o.member(ctx, orgID, models.RoleOwner); err != nil {
+		return err
+	}
+	return o.repo.Delete(ctx, orgID)
+}
+
+// Members returns the members of the organization, every member reads them.
+func (o *organization) Members(ctx context.Context, orgID int) ([]models.OrgMember, error) {
+	if _, err := o.member(ctx, orgID, models.RoleReadOnly); err != nil {
+		return nil, err
+	}
+	members, err := o.repo.Members(ctx, orgID)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]models.OrgMember, len(members))
+	for i, v := range members {
+		result[i] = models.OrgMember{Login: v.Login, Role: v.Role, CreatedAt: v.CreatedAt}
+	}
+	return result, nil
+}
+
+// AddMember invites the user to the organization, the admins invite the
+// users with any role but the owner and the owners invite the owners.
+func (o *organization) AddMember(ctx context.Context, orgID int, member models.OrgMember) error {
+	if err := o.manage(ctx, orgID, nil, member.Login, member.Role); err != nil {
+		return err
+	}
+	return o.repo.AddMember(ctx, entities.OrgMember{
+		OrgID:  orgID,
+		Login:  member.Login,
+		Role:   member.Role,
+		OrgKey: member.OrgKey,
+	})
+}
+
+// SetRole changes the role of the member.
+func (o *organization) SetRole(ctx context.Context, orgID int, login, role string) error {
+	members, err := o.members(ctx, orgID)
+	if err != nil {
+		return err
+	}
+	if err = o.manage(ctx, orgID, members, login, role); err != nil {
+		return err
+	}
+	if role != models.RoleOwner && lastOwner(members, login) {
+		return ErrLastOwner
+	}
+	return o.repo.SetRole(ctx, orgID, login, role)
+}
+
+// RemoveMember removes the member from the organization, every member
+// leaves it on their own.
+func (o *organization) RemoveMember(ctx context.Context, orgID int, login string) error {
+	userID := ctx.Value(types.UserIDKey).(int)
+	members, err := o.members(ctx, orgID)
+	if err != nil {
+		return err
+	}
+	leaving := false
+	for _, v := range members {
+		leaving = leaving || v.UserID == userID && v.Login == login
+	}
+	if !leaving {
+		if err = o.manage(ctx, orgID, members, login, ""); err != nil {
+			return err
+		}
+	}
+	if lastOwner(members, login) {
+		return ErrLastOwner
+	}
+	return o.repo.RemoveMember(ctx, orgID, login)
+}
+
+// VaultUserID returns the user of the vault of the organization for the
+// request of the access, the role of the user has to allow the access:
+// the read-only members read, the members create and update the items and
+// the admins delete them.
+func (o *organization) VaultUserID(ctx context.Context, orgID int, access string) (int, error) {
+	role := models.RoleMember
+	switch access {
+	case models.AccessRead:
+		role = models.RoleReadOnly
+	case models.AccessDelete:
+		role = models.RoleAdmin
+	}
+	org, err := o.member(ctx, orgID, role)
+	if err != nil {
+		return 0, err
+	}
+	return org.VaultUserID, nil
+}
+
+// member returns the organization of the user whose role is at least the role.
+func (o *organization) member(ctx context.Context, orgID int, role string) (entities.Organization, error) {
+	userID := ctx.Value(types.UserIDKey).(int)
+	org, err := o.repo.FindByIDAndUserID(ctx, orgID, userID)
+	if err != nil {
+		return entities.Organization{}, err
+	}
+	if roleRank[org.Role] < roleRank[role] {
+		return entities.Organization{}, ErrOrgForbidden
+	}
+	return org, nil
+}
+
+// members returns the members of the organization to a member of it.
+func (o *organization) members(ctx context.Context, orgID int) ([]entities.OrgMember, error) {
+	if _, err := o.member(ctx, orgID, models.RoleReadOnly); err != nil {
+		return nil, err
+	}
+	return o.repo.Members(ctx, orgID)
+}
+
+// manage checks the user may give the role to the member of the login, an
+// empty role removes the member. The admins manage the members, only the
+// owners make an owner or manage one.
+func (o *organization) manage(ctx context.Context, orgID int, members []entities.OrgMember,
+	login, role string) error {
+	required := models.RoleAdmin
+	if role == models.RoleOwner || roleOf(members, login) == models.RoleOwner {
+		required = models.RoleOwner
+	}
+	_, err := o.member(ctx, orgID, required)
+	return err
+}
+
+func roleOf(members []entities.OrgMember, login string) string {
+	for _, v := range members {
+		if v.Login == login {
+			return v.Role
+		}
+	}
+	return ""
+}
+
+// lastOwner reports whether the member of the login is the only owner.
+func lastOwner(members []entities.OrgMember, login string) bool {
+	if roleOf(members, login) != models.RoleOwner {
+		return false
+	}
+	owners := 0
+	for _, v := range members {
+		if v.Role == models.RoleOwner {
+			owners++
+		}
+	}
+	return owners == 1
+}
+
+func toModelOrganization(org entities.Organization) models.Organization {
+	return models.Organization{
+		ID:        org.ID,
+		Name:      org.Name,
+		Role:      org.Role,
+		OrgKey:    org.OrgKey,
+		CreatedAt: org.CreatedAt,
+	}
+}
@@ -0,0 +1,132 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/zelas91/goph-keeper/internal/server/models"
+	"github.com/zelas91/goph-keeper/internal/server/repository/entities"
+	mock "github.com/zelas91/goph-keeper/internal/server/services/mocks"
+	"github.com/zelas91/goph-keeper/internal/server/types"
+	"golang.org/x/net/context"
+)
+
+func TestVaultUserID(t *testing.T) {
+	tests := []struct {
+		name    string
+		role    string
+		access  string
+		wantErr error
+	}{
+		{name: "#1 ok read-only member reads", role: models.RoleReadOnly, access: models.AccessRead},
+		{name: "#2 nok read-only member writes", role: models.RoleReadOnly, access: models.AccessWrite,
+			wantErr: ErrOrgForbidden},
+		{name: "#3 ok member updates", role: models.RoleMember, access: models.AccessWrite},
+		{name: "#4 nok member deletes", role: models.RoleMember, access: models.AccessDelete,
+			wantErr: ErrOrgForbidden},
+		{name: "#5 ok admin deletes", role: models.RoleAdmin, access: models.AccessDelete},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			repo := mock.NewMockorganizationRepo(ctrl)
+			repo.EXPECT().FindByIDAndUserID(gomock.Any(), 3, 1).
+				Return(entities.Organization{ID: 3, VaultUserID: 42, Role: test.role}, nil)
+			s := New(WithOrganizationUseRepository(repo))
+			ctx := context.WithValue(context.Background(), types.UserIDKey, 1)
+
+			id, err := s.Org.VaultUserID(ctx, 3, test.access)
+			assert.ErrorIs(t, err, test.wantErr)
+			if test.wantErr == nil {
+				assert.Equal(t, 42, id)
+			}
+		})
+	}
+}
+
+func TestOrgMembers(t *testing.T) {
+	members := []entities.OrgMember{
+		{UserID: 1, Login: "alice", Role: models.RoleOwner},
+		{UserID: 2, Login: "bob", Role: models.RoleAdmin},
+		{UserID: 3, Login: "carol", Role: models.RoleMember},
+	}
+	tests := []struct {
+		name    string
+		userID  int
+		role    string
+		call    func(s *Service, ctx context.Context) error
+		mock    func(r *mock.MockorganizationRepo)
+		wantErr error
+	}{
+		{
+			name:   "#1 ok admin changes the role of a member",
+			userID: 2,
+			role:   models.RoleAdmin,
+			call: func(s *Service, ctx context.Context) error {
+				return s.Org.SetRole(ctx, 3, "carol", models.RoleReadOnly)
+			},
+			mock: func(r *mock.MockorganizationRepo) {
+				r.EXPECT().SetRole(gomock.Any(), 3, "carol", models.RoleReadOnly).Return(nil)
+			},
+		},
+		{
+			name:   "#2 nok admin makes an owner",
+			userID: 2,
+			role:   models.RoleAdmin,
+			call: func(s *Service, ctx context.Context) error {
+				return s.Org.SetRole(ctx, 3, "carol", models.RoleOwner)
+			},
+			wantErr: ErrOrgForbidden,
+		},
+		{
+			name:   "#3 nok the last owner steps down",
+			userID: 1,
+			role:   models.RoleOwner,
+			call: func(s *Service, ctx context.Context) error {
+				return s.Org.SetRole(ctx, 3, "alice", models.RoleAdmin)
+			},
+			wantErr: ErrLastOwner,
+		},
+		{
+			name:   "#4 ok member leaves",
+			userID: 3,
+			role:   models.RoleMember,
+			call: func(s *Service, ctx context.Context) error {
+				return s.Org.RemoveMember(ctx, 3, "carol")
+			},
+			mock: func(r *mock.MockorganizationRepo) {
+				r.EXPECT().RemoveMember(gomock.Any(), 3, "carol").Return(nil)
+			},
+		},
+		{
+			name:   "#5 nok member removes another member",
+			userID: 3,
+			role:   models.RoleMember,
+			call: func(s *Service, ctx context.Context) error {
+				return s.Org.RemoveMember(ctx, 3, "bob")
+			},
+			wantErr: ErrOrgForbidden,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			repo := mock.NewMockorganizationRepo(ctrl)
+			repo.EXPECT().FindByIDAndUserID(gomock.Any(), 3, test.userID).
+				Return(entities.Organization{ID: 3, VaultUserID: 42, Role: test.role}, nil).AnyTimes()
+			repo.EXPECT().Members(gomock.Any(), 3).Return(members, nil)
+			if test.mock != nil {
+				test.mock(repo)
+			}
+			s := New(WithOrganizationUseRepository(repo))
+			ctx := context.WithValue(context.Background(), types.UserIDKey, test.userID)
+
+			assert.ErrorIs(t, test.call(s, ctx), test.wantErr)
+		})
+	}
+}
@@ -0,0 +1,186 @@
+package services
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/zelas91/goph-keeper/internal/server/models"
+	"github.com/zelas91/goph-keeper/internal/server/repository/entities"
+	"github.com/zelas91/goph-keeper/internal/server/types"
+	"golang.org/x/net/context"
+)
+
+// otp keeps the seeds of the authenticators, the seed, the issuer and the
+// account are encrypted like the logins and the passwords of the credentials.
+type otp struct {
+	repo   otpRepo
+	crypto crypto
+	events *events
+	quota  *quota
+	share  *share
+}
+
+//go:generate mockgen -package mocks -destination=./mocks/mock_otp_repo.go -source=otp.go -package=mock
+type otpRepo interface {
+	Create(ctx context.Context, key entities.OTP) (int, error)
+	FindAllByUserID(ctx context.Context, userID int, filter entities.ItemFilter) ([]entities.OTP, error)
+	FindByIDAndUserID(ctx context.Context, otpID, userID int) (entities.OTP, error)
+	Delete(ctx context.Context, otpID, userID int) error
+	Update(ctx context.Context, key entities.OTP) error
+}
+
+func (o otp) Create(ctx context.Context, key models.OTP) error {
+	userID := ctx.Value(types.UserIDKey).(int)
+	if err := o.quota.checkItem(ctx); err != nil {
+		return err
+	}
+	key.UserId = userID
+	keyEntities, err := o.encryptToEntities(key)
+	if err != nil {
+		return err
+	}
+	id, err := o.repo.Create(ctx, keyEntities)
+	if err != nil {
+		return fmt.Errorf("create otp err: %w", err)
+	}
+	o.events.publish(ctx, models.EventCreated, types.ItemOTP, id)
+	return nil
+}
+
+func (o otp) OTPs(ctx context.Context, filter models.ItemFilter) ([]models.OTP, string, error) {
+	userID := ctx.Value(types.UserIDKey).(int)
+	keys, err := o.repo.FindAllByUserID(ctx, userID, pageFilter(filter))
+	if err != nil {
+		return nil, "", fmt.Errorf("get otp keys err: %w", err)
+	}
+	keys, next := cutPage(keys, filter, func(v entities.OTP) (int, time.Time, time.Time) {
+		return v.ID, v.CreatedAt, v.UpdateAt
+	})
+	keysModel := make([]models.OTP, len(keys))
+	for i, v := range keys {
+		keyModel, err := o.decryptToModels(v)
+		if err != nil {
+			return nil, "", err
+		}
+		keysModel[i] = keyModel
+	}
+	return keysModel, next, nil
+}
+
+func (o otp) OTP(ctx context.Context, otpID int) (models.OTP, error) {
+	ctx, _, err := o.share.asOwner(ctx, types.ItemOTP, otpID, false)
+	if err != nil {
+		return models.OTP{}, err
+	}
+	userID := ctx.Value(types.UserIDKey).(int)
+	key, err := o.repo.FindByIDAndUserID(ctx, otpID, userID)
+	if err != nil {
+		return models.OTP{}, fmt.Errorf("get otp err: %w", err)
+	}
+	return o.decryptToModels(key)
+}
+
+func (o otp) Delete(ctx context.Context, otpID int) error {
+	userID := ctx.Value(types.UserIDKey).(int)
+
+	if err := o.repo.Delete(ctx, otpID, userID); err != nil {
+		return fmt.Errorf("delete otp err: %w", err)
+	}
+	o.events.publish(ctx, models.EventDeleted, types.ItemOTP, otpID)
+	return nil
+}
+
+// Update changes the key, the clients move the counter of a HOTP key on
+// with it after every code.
+func (o otp) Update(ctx context.Context, key models.OTP) error {
+	ctx, _, err := o.share.asOwner(ctx, types.ItemOTP, key.ID, true)
+	if err != nil {
+		return err
+	}
+	userID := ctx.Value(types.UserIDKey).(int)
+	key.UserId = userID
+	keyEntities, err := o.encryptToEntities(key)
+	if err != nil {
+		return err
+	}
+	if err := o.repo.Update(ctx, keyEntities); err != nil {
+		return fmt.Errorf("update otp err:%w", err)
+	}
+	o.events.publish(ctx, models.EventUpdated, types.ItemOTP, key.ID)
+	return nil
+}
+
+func (o otp) encryptToEntities(key models.OTP) (entities.OTP, error) {
+	secret, err := o.crypto.Encrypt([]byte(key.Secret))
+	if err != nil {
+		return entities.OTP{}, err
+	}
+	issuer, err := o.crypto.Encrypt([]byte(key.Issuer))
+	if err != nil {
+		return entities.OTP{}, err
+	}
+	account, err := o.crypto.Encrypt([]byte(key.Account))
+	if err != nil {
+		return entities.OTP{}, err
+	}
+	meta, err := encryptMeta(o.crypto, key.Title, key.Note, key.Metadata)
+	if err != nil {
+		return entities.OTP{}, err
+	}
+	return entities.OTP{
+		ID:           key.ID,
+		UserId:       key.UserId,
+		Version:      key.Version,
+		Type:         key.Type,
+		Issuer:       issuer,
+		Account:      account,
+		Secret:       secret,
+		Algorithm:    key.Algorithm,
+		Digits:       key.Digits,
+		Period:       key.Period,
+		Counter:      key.Counter,
+		Title:        meta.title,
+		Note:         meta.note,
+		Metadata:     meta.metadata,
+		FolderID:     key.FolderID,
+		SearchTokens: searchTokens(o.crypto, key.UserId, key.Title, key.Note, key.Metadata, key.Issuer, key.Account),
+	}, nil
+}
+
+func (o otp) decryptToModels(key entities.OTP) (models.OTP, error) {
+	secret, err := o.crypto.Decrypt(key.Secret)
+	if err != nil {
+		return models.OTP{}, err
+	}
+	issuer, err := o.crypto.Decrypt(key.Issuer)
+	if err != nil {
+		return models.OTP{}, err
+	}
+	account, err := o.crypto.Decrypt(key.Account)
+	if err != nil {
+		return models.OTP{}, err
+	}
+	title, note, metadata, err := decryptMeta(o.crypto,
+		itemMeta{title: key.Title, note: key.Note, metadata: key.Metadata})
+	if err != nil {
+		return models.OTP{}, err
+	}
+	return models.OTP{
+		ID:        key.ID,
+		UserId:    key.UserId,
+		Version:   key.Version,
+		Type:      key.Type,
+		Issuer:    string(issuer),
+		Account:   string(account),
+		Secret:    string(secret),
+		Algorithm: key.Algorithm,
+		Digits:    key.Digits,
+		Period:    key.Period,
+		Counter:   key.Counter,
+		Title:     title,
+		Note:      note,
+		Metadata:  metadata,
+		FolderID:  key.FolderID,
+		Tags:      key.Tags,
+	}, nil
+}
@@ -0,0 +1,36 @@
+package services
+
+import (
+	"strings"
+	"time"
+
+	"github.com/zelas91/goph-keeper/internal/server/helper"
+	"github.com/zelas91/goph-keeper/internal/server/models"
+	"github.com/zelas91/goph-keeper/internal/server/repository/entities"
+)
+
+// pageFilter asks the repository for one item more than the page holds,
+// the extra item tells that the next page exists.
+func pageFilter(filter models.ItemFilter) entities.ItemFilter {
+	f := helper.ToEntitiesItemFilter(filter)
+	if f.Limit > 0 {
+		f.Limit++
+	}
+	return f
+}
+
+// cutPage drops the extra item and returns the cursor of the next page,
+// the cursor is empty on the last page.
+func cutPage[T any](items []T, filter models.ItemFilter,
+	key func(T) (id int, createdAt, updateAt time.Time)) ([]T, string) {
+	if filter.Limit == 0 || len(items) <= filter.Limit {
+		return items, ""
+	}
+	items = items[:filter.Limit]
+	id, createdAt, updateAt := key(items[len(items)-1])
+	c := models.Cursor{Sort: filter.Sort, Time: createdAt, ID: id}
+	if strings.TrimPrefix(filter.Sort, "-") == models.SortUpdated {
+		c.Time = updateAt
+	}
+	return items, helper.EncodeCursor(c)
+}
@@ -0,0 +1,57 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/zelas91/goph-keeper/internal/server/helper"
+	"github.com/zelas91/goph-keeper/internal/server/models"
+)
+
+func TestCutPage(t *testing.T) {
+	created := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	updated := time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)
+	key := func(id int) (int, time.Time, time.Time) { return id, created, updated }
+	tests := []struct {
+		name      string
+		items     []int
+		filter    models.ItemFilter
+		wantItems []int
+		wantNext  string
+	}{
+		{
+			name:      "#1 ok last page",
+			items:     []int{1, 2},
+			filter:    models.ItemFilter{Sort: models.SortCreated, Limit: 2},
+			wantItems: []int{1, 2},
+		},
+		{
+			name:      "#2 ok next page by created",
+			items:     []int{1, 2, 3},
+			filter:    models.ItemFilter{Sort: models.SortCreated, Limit: 2},
+			wantItems: []int{1, 2},
+			wantNext:  helper.EncodeCursor(models.Cursor{Sort: models.SortCreated, Time: created, ID: 2}),
+		},
+		{
+			name:      "#3 ok next page by updated desc",
+			items:     []int{5, 4},
+			filter:    models.ItemFilter{Sort: "-updated", Limit: 1},
+			wantItems: []int{5},
+			wantNext:  helper.EncodeCursor(models.Cursor{Sort: "-updated", Time: updated, ID: 5}),
+		},
+		{
+			name:      "#4 ok no limit",
+			items:     []int{1, 2, 3},
+			filter:    models.ItemFilter{},
+			wantItems: []int{1, 2, 3},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			items, next := cutPage(test.items, test.filter, key)
+			assert.Equal(t, test.wantItems, items)
+			assert.Equal(t, test.wantNext, next)
+		})
+	}
+}
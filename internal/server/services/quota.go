@@ -0,0 +1,92 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/zelas91/goph-keeper/internal/server/models"
+	"github.com/zelas91/goph-keeper/internal/server/repository/entities"
+	"github.com/zelas91/goph-keeper/internal/server/types"
+	"golang.org/x/net/context"
+)
+
+var (
+	ErrQuotaExceeded = errors.New("storage quota exceeded")
+	ErrFileTooLarge  = errors.New("file is larger than allowed")
+)
+
+// quota keeps the users within the limits, nothing is limited without
+// the repository or without the quota.
+type quota struct {
+	repo   usageRepo
+	limits models.Limits
+}
+
+//go:generate mockgen -package mocks -destination=./mocks/mock_usage_repo.go -source=quota.go -package=mock
+type usageRepo interface {
+	Usage(ctx context.Context, userID int) (entities.Usage, error)
+}
+
+func (q *quota) Usage(ctx context.Context) (models.Usage, error) {
+	userID := ctx.Value(types.UserIDKey).(int)
+	if q.repo == nil {
+		return models.Usage{}, nil
+	}
+	usage, err := q.repo.Usage(ctx, userID)
+	if err != nil {
+		return models.Usage{}, fmt.Errorf("get usage err: %w", err)
+	}
+	return models.Usage{Bytes: usage.Bytes, Items: usage.Items, Limits: q.limits}, nil
+}
+
+// checkItem is called before an item is created.
+func (q *quota) checkItem(ctx context.Context) error {
+	return q.check(ctx, 0)
+}
+
+// checkFile is called before a file item of the size is received, the
+// size the client declares is enforced while the file is read.
+func (q *quota) checkFile(ctx context.Context, size int64) error {
+	if q == nil {
+		return nil
+	}
+	if q.limits.FileSize > 0 && size > q.limits.FileSize {
+		return fmt.Errorf("%w: %d bytes, the limit is %d", ErrFileTooLarge, size, q.limits.FileSize)
+	}
+	return q.check(ctx, size)
+}
+
+func (q *quota) check(ctx context.Context, size int64) error {
+	if q == nil || q.repo == nil || q.limits.Bytes == 0 && q.limits.Items == 0 {
+		return nil
+	}
+	usage, err := q.Usage(ctx)
+	if err != nil {
+		return err
+	}
+	if q.limits.Items > 0 && usage.Items >= q.limits.Items {
+		return fmt.Errorf("%w: %d items, the limit is %d", ErrQuotaExceeded, usage.Items, q.limits.Items)
+	}
+	if q.limits.Bytes > 0 && usage.Bytes+size > q.limits.Bytes {
+		return fmt.Errorf("%w: %d of %d bytes used, %d more requested",
+			ErrQuotaExceeded, usage.Bytes, q.limits.Bytes, size)
+	}
+	return nil
+}
+
+// limitReader fails the read of more than n bytes, it keeps the client
+// from sending more than it declared.
+type limitReader struct {
+	r io.Reader
+	n int64
+}
+
+func (l *limitReader) Read(p []byte) (int, error) {
+	n, err := l.r.Read(p)
+	l.n -= int64(n)
+	if l.n < 0 {
+		return n, ErrFileTooLarge
+	}
+	return n, err
+}
@@ -0,0 +1,64 @@
+package services
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/zelas91/goph-keeper/internal/logger"
+	"github.com/zelas91/goph-keeper/internal/server/models"
+	"github.com/zelas91/goph-keeper/internal/server/repository/entities"
+	mock "github.com/zelas91/goph-keeper/internal/server/services/mocks"
+	"github.com/zelas91/goph-keeper/internal/server/storage"
+	"github.com/zelas91/goph-keeper/internal/server/types"
+	compress2 "github.com/zelas91/goph-keeper/internal/utils/compress"
+	"golang.org/x/net/context"
+)
+
+func TestQuotaCheckFile(t *testing.T) {
+	limits := models.Limits{Bytes: 1000, Items: 5, FileSize: 600}
+	tests := []struct {
+		name    string
+		limits  models.Limits
+		usage   entities.Usage
+		size    int64
+		wantErr error
+	}{
+		{name: "#1 ok within limits", limits: limits, usage: entities.Usage{Bytes: 300, Items: 4}, size: 600},
+		{name: "#2 ok no limits", usage: entities.Usage{Bytes: 1 << 40, Items: 1 << 20}, size: 1 << 30},
+		{name: "#3 nok file too large", limits: limits, size: 601, wantErr: ErrFileTooLarge},
+		{name: "#4 nok bytes exceeded", limits: limits, usage: entities.Usage{Bytes: 401}, size: 600,
+			wantErr: ErrQuotaExceeded},
+		{name: "#5 nok items exceeded", limits: limits, usage: entities.Usage{Items: 5}, size: 1,
+			wantErr: ErrQuotaExceeded},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			repo := mock.NewMockusageRepo(ctrl)
+			repo.EXPECT().Usage(gomock.Any(), 1).Return(test.usage, nil).AnyTimes()
+			s := New(WithQuotaUseRepository(repo, test.limits))
+			ctx := context.WithValue(context.Background(), types.UserIDKey, 1)
+
+			assert.ErrorIs(t, s.Quota.checkFile(ctx, test.size), test.wantErr)
+		})
+	}
+}
+
+func TestStoreMoreThanDeclared(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := mock.NewMockbinaryFileRepo(ctrl)
+	basePath := t.TempDir()
+	s := New(WithBinaryFileUseRepository(repo, nil, logger.New(""), storage.NewLocal(basePath), compress2.Gzip))
+	ctx := context.WithValue(context.Background(), types.UserIDKey, 1)
+
+	bf := models.BinaryFile{FileName: "small.txt", Size: 5}
+	err := s.BinaryFile.store(ctx, bf, strings.NewReader("much more than five bytes"), "")
+	assert.ErrorIs(t, err, ErrFileTooLarge)
+	assert.Empty(t, blobs(t, basePath, 1), "nothing is kept")
+}
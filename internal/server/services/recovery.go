@@ -0,0 +1,103 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/zelas91/goph-keeper/internal/server/models"
+	"github.com/zelas91/goph-keeper/internal/server/repository"
+	"github.com/zelas91/goph-keeper/internal/server/repository/entities"
+	"github.com/zelas91/goph-keeper/internal/server/types"
+	"golang.org/x/crypto/bcrypt"
+	"golang.org/x/net/context"
+)
+
+var ErrRecoveryKey = errors.New("wrong login or recovery key")
+
+// recovery lets the user that lost the password set a new one with the
+// recovery key. The client makes the key and keeps the vault key wrapped
+// with it on the server, the server checks the proof of the key only.
+type recovery struct {
+	repo recoveryRepo
+}
+
+//go:generate mockgen -package mocks -destination=./mocks/mock_recovery_repo.go -source=recovery.go -package=mock
+type recoveryRepo interface {
+	Set(ctx context.Context, key entities.RecoveryKey) error
+	FindByLogin(ctx context.Context, login string) (entities.RecoveryKey, error)
+	State(ctx context.Context, userID int) (entities.RecoveryState, error)
+	Recover(ctx context.Context, user entities.User, next entities.RecoveryKey) error
+}
+
+// Set keeps the new recovery key of the user, the key kept before does not
+// recover the account any more.
+func (r *recovery) Set(ctx context.Context, key models.RecoveryKey) error {
+	userID := ctx.Value(types.UserIDKey).(int)
+	entity, err := toEntityRecoveryKey(key)
+	if err != nil {
+		return err
+	}
+	entity.UserID = userID
+	return r.repo.Set(ctx, entity)
+}
+
+func (r *recovery) State(ctx context.Context) (models.RecoveryState, error) {
+	userID := ctx.Value(types.UserIDKey).(int)
+	state, err := r.repo.State(ctx, userID)
+	if err != nil {
+		return models.RecoveryState{}, err
+	}
+	return models.RecoveryState{Set: state.Set, VaultKey: state.VaultKey}, nil
+}
+
+// VaultKey returns the vault key wrapped with the recovery key to the
+// user that proves to have the key.
+func (r *recovery) VaultKey(ctx context.Context, proof models.RecoveryProof) (models.RecoveryKey, error) {
+	key, err := r.check(ctx, proof)
+	if err != nil {
+		return models.RecoveryKey{}, err
+	}
+	return models.RecoveryKey{VaultKey: key.VaultKey}, nil
+}
+
+// Recover sets the new password of the user and replaces the recovery key
+// used, a key recovers the account once.
+func (r *recovery) Recover(ctx context.Context, rec models.Recover) error {
+	key, err := r.check(ctx, rec.RecoveryProof)
+	if err != nil {
+		return err
+	}
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(rec.Password), bcrypt.DefaultCost)
+	if err != nil {
+		return errors.New("generate password hash err")
+	}
+	next, err := toEntityRecoveryKey(rec.Next)
+	if err != nil {
+		return err
+	}
+	return r.repo.Recover(ctx, entities.User{ID: key.UserID, Password: string(hashedPassword), VaultKey: rec.VaultKey}, next)
+}
+
+// check returns the recovery key of the proof, the unknown logins and the
+// wrong keys are the same error.
+func (r *recovery) check(ctx context.Context, proof models.RecoveryProof) (entities.RecoveryKey, error) {
+	key, err := r.repo.FindByLogin(ctx, proof.Login)
+	if errors.Is(err, repository.ErrNotFound) {
+		return key, ErrRecoveryKey
+	}
+	if err != nil {
+		return key, err
+	}
+	if err = bcrypt.CompareHashAndPassword([]byte(key.KeyHash), []byte(proof.Key)); err != nil {
+		return key, ErrRecoveryKey
+	}
+	return key, nil
+}
+
+func toEntityRecoveryKey(key models.RecoveryKey) (entities.RecoveryKey, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(key.Key), bcrypt.DefaultCost)
+	if err != nil {
+		return entities.RecoveryKey{}, fmt.Errorf("recovery key hash err: %w", err)
+	}
+	return entities.RecoveryKey{KeyHash: string(hash), VaultKey: key.VaultKey}, nil
+}
@@ -0,0 +1,79 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/zelas91/goph-keeper/internal/server/models"
+	"github.com/zelas91/goph-keeper/internal/server/repository"
+	"github.com/zelas91/goph-keeper/internal/server/repository/entities"
+	mock "github.com/zelas91/goph-keeper/internal/server/services/mocks"
+	"golang.org/x/crypto/bcrypt"
+	"golang.org/x/net/context"
+)
+
+func TestRecover(t *testing.T) {
+	hash, err := bcrypt.GenerateFromPassword([]byte("proof"), bcrypt.MinCost)
+	assert.NoError(t, err)
+	stored := entities.RecoveryKey{UserID: 2, KeyHash: string(hash), VaultKey: "wrapped"}
+	rec := models.Recover{
+		RecoveryProof: models.RecoveryProof{Login: "test", Key: "proof"},
+		Password:      "new password",
+		VaultKey:      "wrapped with password",
+		Next:          models.RecoveryKey{Key: "next proof", VaultKey: "wrapped with next"},
+	}
+	tests := []struct {
+		name    string
+		key     string
+		mock    func(r *mock.MockrecoveryRepo)
+		wantErr error
+	}{
+		{
+			name: "#1 ok",
+			key:  "proof",
+			mock: func(r *mock.MockrecoveryRepo) {
+				r.EXPECT().FindByLogin(gomock.Any(), "test").Return(stored, nil)
+				r.EXPECT().Recover(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(
+					func(_ context.Context, user entities.User, next entities.RecoveryKey) error {
+						assert.Equal(t, 2, user.ID)
+						assert.Equal(t, "wrapped with password", user.VaultKey)
+						assert.NoError(t, bcrypt.CompareHashAndPassword([]byte(user.Password), []byte("new password")))
+						assert.Equal(t, "wrapped with next", next.VaultKey)
+						assert.NoError(t, bcrypt.CompareHashAndPassword([]byte(next.KeyHash), []byte("next proof")))
+						return nil
+					})
+			},
+		},
+		{
+			name: "#2 nok wrong key",
+			key:  "other proof",
+			mock: func(r *mock.MockrecoveryRepo) {
+				r.EXPECT().FindByLogin(gomock.Any(), "test").Return(stored, nil)
+			},
+			wantErr: ErrRecoveryKey,
+		},
+		{
+			name: "#3 nok no recovery key",
+			key:  "proof",
+			mock: func(r *mock.MockrecoveryRepo) {
+				r.EXPECT().FindByLogin(gomock.Any(), "test").Return(entities.RecoveryKey{}, repository.ErrNotFound)
+			},
+			wantErr: ErrRecoveryKey,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			repo := mock.NewMockrecoveryRepo(ctrl)
+			test.mock(repo)
+			s := New(WithRecoveryUseRepository(repo))
+
+			rec := rec
+			rec.Key = test.key
+			assert.ErrorIs(t, s.Recovery.Recover(context.Background(), rec), test.wantErr)
+		})
+	}
+}
@@ -0,0 +1,227 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/zelas91/goph-keeper/internal/server/models"
+	"github.com/zelas91/goph-keeper/internal/server/repository/entities"
+	"github.com/zelas91/goph-keeper/internal/server/types"
+	"golang.org/x/net/context"
+)
+
+const (
+	minTokenLen   = 3
+	maxTokenLen   = 32
+	searchLimit   = 100
+	maxQueryWords = 8
+	reindexBatch  = 100
+)
+
+var ErrSearchQuery = errors.New("search query has no words of 3 letters or more")
+
+type search struct {
+	repo    searchRepo
+	crypto  crypto
+	service *Service
+}
+
+//go:generate mockgen -package mocks -destination=./mocks/mock_search_repo.go -source=search.go -package=mock
+type searchRepo interface {
+	Search(ctx context.Context, userID int, tokens [][]byte, limit int) ([]entities.SearchResult, error)
+	Unindexed(ctx context.Context, itemType string, afterID, limit int) ([]entities.UnindexedItem, error)
+	SaveTokens(ctx context.Context, userID int, itemType string, itemID int, tokens [][]byte) error
+}
+
+// Search returns the items whose title, note, metadata or plain fields
+// contain every word of the query, a word matches by its prefix.
+func (s search) Search(ctx context.Context, query string) ([]models.SearchResult, error) {
+	userID := ctx.Value(types.UserIDKey).(int)
+	words := searchWords(query)
+	if len(words) == 0 {
+		return nil, ErrSearchQuery
+	}
+	if len(words) > maxQueryWords {
+		words = words[:maxQueryWords]
+	}
+	tokens := make([][]byte, len(words))
+	for i, word := range words {
+		tokens[i] = searchToken(s.crypto, userID, word)
+	}
+	results, err := s.repo.Search(ctx, userID, tokens, searchLimit)
+	if err != nil {
+		return nil, fmt.Errorf("search err: %w", err)
+	}
+	resultsModel := make([]models.SearchResult, len(results))
+	for i, v := range results {
+		title, _, _, err := decryptMeta(s.crypto, itemMeta{title: v.Title})
+		if err != nil {
+			return nil, err
+		}
+		resultsModel[i] = models.SearchResult{ItemType: v.ItemType, ItemID: v.ItemID, Title: title}
+	}
+	return resultsModel, nil
+}
+
+// Reindex saves the search tokens of the items that have none, the items
+// saved before the search was added are found only after it. The tokens
+// are hashed with the key of the server, so they are built here and not
+// by a migration. It returns the number of the items indexed.
+func (s search) Reindex(ctx context.Context) (int, error) {
+	indexed := 0
+	for _, itemType := range []string{types.ItemCard, types.ItemCredential, types.ItemText,
+		types.ItemFile, types.ItemOTP, types.ItemSSHKey} {
+		afterID := 0
+		for {
+			items, err := s.repo.Unindexed(ctx, itemType, afterID, reindexBatch)
+			if err != nil {
+				return indexed, fmt.Errorf("reindex err: %w", err)
+			}
+			for _, v := range items {
+				tokens, err := s.itemTokens(ctx, itemType, v.ID, v.UserID)
+				if err != nil {
+					return indexed, fmt.Errorf("reindex %s id=%d err: %w", itemType, v.ID, err)
+				}
+				if len(tokens) > 0 {
+					if err = s.repo.SaveTokens(ctx, v.UserID, itemType, v.ID, tokens); err != nil {
+						return indexed, fmt.Errorf("reindex err: %w", err)
+					}
+					indexed++
+				}
+				afterID = v.ID
+			}
+			if len(items) < reindexBatch {
+				break
+			}
+		}
+	}
+	return indexed, nil
+}
+
+// itemTokens builds the tokens of the saved item the way its service does
+// when the item is saved.
+func (s search) itemTokens(ctx context.Context, itemType string, itemID, userID int) ([][]byte, error) {
+	switch itemType {
+	case types.ItemCard:
+		card, err := s.service.CreditCard.repo.FindByIDAndUserID(ctx, itemID, userID)
+		if err != nil {
+			return nil, err
+		}
+		model, err := s.service.CreditCard.decryptToModels(card)
+		if err != nil {
+			return nil, err
+		}
+		card, err = s.service.CreditCard.encryptToEntities(model)
+		return card.SearchTokens, err
+	case types.ItemCredential:
+		uc, err := s.service.Credential.repo.FindByIDAndUserID(ctx, itemID, userID)
+		if err != nil {
+			return nil, err
+		}
+		model, err := s.service.Credential.decryptToModels(uc)
+		if err != nil {
+			return nil, err
+		}
+		uc, err = s.service.Credential.encryptToEntities(model)
+		return uc.SearchTokens, err
+	case types.ItemText:
+		text, err := s.service.TextData.repo.FindByIDAndUserID(ctx, itemID, userID)
+		if err != nil {
+			return nil, err
+		}
+		model, err := s.service.TextData.decryptToModels(text)
+		if err != nil {
+			return nil, err
+		}
+		text, err = s.service.TextData.encryptToEntities(model)
+		return text.SearchTokens, err
+	case types.ItemFile:
+		file, err := s.service.BinaryFile.repo.FindByIDAndUserID(ctx, itemID, userID)
+		if err != nil {
+			return nil, err
+		}
+		model, err := s.service.BinaryFile.decryptToModels(file)
+		if err != nil {
+			return nil, err
+		}
+		file, err = s.service.BinaryFile.encryptToEntities(model)
+		return file.SearchTokens, err
+	case types.ItemOTP:
+		key, err := s.service.OTP.repo.FindByIDAndUserID(ctx, itemID, userID)
+		if err != nil {
+			return nil, err
+		}
+		model, err := s.service.OTP.decryptToModels(key)
+		if err != nil {
+			return nil, err
+		}
+		key, err = s.service.OTP.encryptToEntities(model)
+		return key.SearchTokens, err
+	case types.ItemSSHKey:
+		key, err := s.service.SSHKey.repo.FindByIDAndUserID(ctx, itemID, userID)
+		if err != nil {
+			return nil, err
+		}
+		model, err := s.service.SSHKey.decryptToModels(key)
+		if err != nil {
+			return nil, err
+		}
+		key, err = s.service.SSHKey.encryptToEntities(model)
+		return key.SearchTokens, err
+	}
+	return nil, fmt.Errorf("unknown item type %q", itemType)
+}
+
+// searchTokens builds the blind index of an item: every word of the fields
+// and every prefix of it is hashed, so the server can match the tokens
+// of a query without seeing the words.
+func searchTokens(c crypto, userID int, title, note string, metadata map[string]string,
+	fields ...string) [][]byte {
+	texts := append([]string{title, note}, fields...)
+	for key, val := range metadata {
+		texts = append(texts, key, val)
+	}
+	seen := make(map[string]bool)
+	var tokens [][]byte
+	for _, word := range searchWords(strings.Join(texts, " ")) {
+		runes := []rune(word)
+		for n := minTokenLen; n <= len(runes); n++ {
+			prefix := string(runes[:n])
+			if seen[prefix] {
+				continue
+			}
+			seen[prefix] = true
+			tokens = append(tokens, searchToken(c, userID, prefix))
+		}
+	}
+	return tokens
+}
+
+// searchToken salts the word with the user id, equal words
+// of different users give different tokens.
+func searchToken(c crypto, userID int, word string) []byte {
+	return c.Hash([]byte(strconv.Itoa(userID) + ":" + word))
+}
+
+// searchWords splits text into lower-case words of minTokenLen letters or more,
+// longer words are cut to maxTokenLen.
+func searchWords(text string) []string {
+	fields := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	words := fields[:0]
+	for _, v := range fields {
+		runes := []rune(v)
+		if len(runes) < minTokenLen {
+			continue
+		}
+		if len(runes) > maxTokenLen {
+			v = string(runes[:maxTokenLen])
+		}
+		words = append(words, v)
+	}
+	return words
+}
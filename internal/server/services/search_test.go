@@ -0,0 +1,79 @@
+package services
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zelas91/goph-keeper/internal/server/models"
+	"github.com/zelas91/goph-keeper/internal/server/repository/entities"
+	mock "github.com/zelas91/goph-keeper/internal/server/services/mocks"
+	"github.com/zelas91/goph-keeper/internal/server/types"
+	crypto2 "github.com/zelas91/goph-keeper/internal/utils/crypto"
+	"golang.org/x/net/context"
+)
+
+func TestSearchTokens(t *testing.T) {
+	c, err := crypto2.NewCrypto("0123456789abcdef0123456789abcdef")
+	assert.NoError(t, err)
+	tokens := searchTokens(c, 1, "AWS root", "", map[string]string{"env": "production"}, "admin@example.com")
+
+	tests := []struct {
+		name   string
+		userID int
+		word   string
+		want   bool
+	}{
+		{name: "#1 ok title word", userID: 1, word: "aws", want: true},
+		{name: "#2 ok metadata value prefix", userID: 1, word: "prod", want: true},
+		{name: "#3 ok login part", userID: 1, word: "example", want: true},
+		{name: "#4 nok other user", userID: 2, word: "aws", want: false},
+		{name: "#5 nok not a prefix", userID: 1, word: "duction", want: false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			token := searchToken(c, test.userID, test.word)
+			found := false
+			for _, v := range tokens {
+				if bytes.Equal(v, token) {
+					found = true
+				}
+			}
+			assert.Equal(t, test.want, found)
+		})
+	}
+}
+
+func TestReindex(t *testing.T) {
+	c, err := crypto2.NewCrypto("0123456789abcdef0123456789abcdef")
+	require.NoError(t, err)
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	searchRepo := mock.NewMocksearchRepo(ctrl)
+	cardRepo := mock.NewMockcardRepo(ctrl)
+	s := New(
+		WithCardUseRepository(cardRepo, c),
+		WithSearchUseRepository(searchRepo, c),
+	)
+	titled, err := s.CreditCard.encryptToEntities(models.Card{ID: 3, UserId: 1, Title: "AWS root"})
+	require.NoError(t, err)
+	untitled, err := s.CreditCard.encryptToEntities(models.Card{ID: 4, UserId: 2})
+	require.NoError(t, err)
+	require.NotEmpty(t, titled.SearchTokens)
+	require.Empty(t, untitled.SearchTokens)
+
+	searchRepo.EXPECT().Unindexed(gomock.Any(), types.ItemCard, 0, reindexBatch).
+		Return([]entities.UnindexedItem{{ID: 3, UserID: 1}, {ID: 4, UserID: 2}}, nil)
+	searchRepo.EXPECT().Unindexed(gomock.Any(), gomock.Not(types.ItemCard), 0, reindexBatch).
+		Return(nil, nil).Times(5)
+	cardRepo.EXPECT().FindByIDAndUserID(gomock.Any(), 3, 1).Return(titled, nil)
+	cardRepo.EXPECT().FindByIDAndUserID(gomock.Any(), 4, 2).Return(untitled, nil)
+	searchRepo.EXPECT().SaveTokens(gomock.Any(), 1, types.ItemCard, 3, titled.SearchTokens).Return(nil)
+
+	indexed, err := s.Search.Reindex(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 1, indexed)
+}
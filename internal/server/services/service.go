@@ -5,6 +5,8 @@ import (
 
 	"github.com/patrickmn/go-cache"
 	"github.com/zelas91/goph-keeper/internal/logger"
+	"github.com/zelas91/goph-keeper/internal/server/models"
+	"github.com/zelas91/goph-keeper/internal/server/storage"
 	compress2 "github.com/zelas91/goph-keeper/internal/utils/compress"
 )
 
@@ -12,17 +14,33 @@ type Service struct {
 	Auth       *auth
 	CreditCard *creditCard
 	Credential *credential
+	OTP        *otp
+	SSHKey     *sshKey
 	TextData   *textData
 	BinaryFile *binaryFile
+	Folder     *folder
+	Tag        *tag
+	Search     *search
+	Sync       *syncer
+	Events     *events
+	Upload     *upload
+	Quota      *quota
+	Fsck       *fsck
+	Share      *share
+	Org        *organization
+	Emergency  *emergency
+	ShareLink  *shareLink
+	Recovery   *recovery
 }
 
 type crypto interface {
 	Encrypt(data []byte) ([]byte, error)
 	Decrypt(data []byte) ([]byte, error)
+	Hash(data []byte) []byte
 }
 
 func New(options ...func(s *Service)) *Service {
-	sv := &Service{}
+	sv := &Service{Events: newEvents(), Quota: &quota{}, Share: &share{}}
 	for _, opt := range options {
 		opt(sv)
 	}
@@ -37,29 +55,132 @@ func WithAuthUseRepository(up userRepo) func(s *Service) {
 
 func WithCardUseRepository(cr cardRepo, crypto crypto) func(s *Service) {
 	return func(s *Service) {
-		s.CreditCard = &creditCard{repo: cr, crypto: crypto}
+		s.CreditCard = &creditCard{repo: cr, crypto: crypto, events: s.Events, quota: s.Quota, share: s.Share}
 	}
 }
 
 func WithCredentialUseRepository(cr credentialRepo, crypto crypto) func(s *Service) {
 	return func(s *Service) {
-		s.Credential = &credential{repo: cr, crypto: crypto}
+		s.Credential = &credential{repo: cr, crypto: crypto, events: s.Events, quota: s.Quota, share: s.Share}
 	}
 }
+func WithOTPUseRepository(or otpRepo, crypto crypto) func(s *Service) {
+	return func(s *Service) {
+		s.OTP = &otp{repo: or, crypto: crypto, events: s.Events, quota: s.Quota, share: s.Share}
+	}
+}
+
+func WithSSHKeyUseRepository(sr sshKeyRepo, crypto crypto) func(s *Service) {
+	return func(s *Service) {
+		s.SSHKey = &sshKey{repo: sr, crypto: crypto, events: s.Events, quota: s.Quota, share: s.Share}
+	}
+}
+
 func WithTextUseRepository(tr textDataRepo, crypto crypto) func(s *Service) {
 	return func(s *Service) {
-		s.TextData = &textData{repo: tr, crypto: crypto}
+		s.TextData = &textData{repo: tr, crypto: crypto, events: s.Events, quota: s.Quota, share: s.Share}
 	}
 }
 
-func WithBinaryFileUseRepository(bf binaryFileRepo, log logger.Logger, basePathSaveFile string) func(s *Service) {
+func WithBinaryFileUseRepository(bf binaryFileRepo, crypto crypto, log logger.Logger,
+	blobs storage.BlobStore, codec compress2.Codec) func(s *Service) {
 	return func(s *Service) {
 		s.BinaryFile = &binaryFile{
 			repo:       bf,
+			crypto:     crypto,
 			log:        log,
-			basePath:   basePathSaveFile,
+			blobs:      blobs,
+			codec:      codec,
 			compress:   compress2.NewCompress(log),
 			decompress: compress2.NewDecompress(),
+			events:     s.Events,
+			quota:      s.Quota,
+			share:      s.Share,
 		}
 	}
 }
+
+func WithFolderUseRepository(fr folderRepo) func(s *Service) {
+	return func(s *Service) {
+		s.Folder = &folder{repo: fr, events: s.Events}
+	}
+}
+
+func WithTagUseRepository(tr tagRepo) func(s *Service) {
+	return func(s *Service) {
+		s.Tag = &tag{repo: tr, events: s.Events}
+	}
+}
+
+// WithSearchUseRepository reindexes the items through the repositories of
+// the item services, the options of those services may come later.
+func WithSearchUseRepository(sr searchRepo, crypto crypto) func(s *Service) {
+	return func(s *Service) {
+		s.Search = &search{repo: sr, crypto: crypto, service: s}
+	}
+}
+
+// WithSyncUseRepository reads the items through the repositories of the item
+// services, the options of those services may come later.
+func WithSyncUseRepository(sr syncRepo) func(s *Service) {
+	return func(s *Service) {
+		s.Sync = &syncer{repo: sr, service: s}
+	}
+}
+
+// WithUploadUseRepository stores the finished uploads through the file
+// service, its option may come later.
+func WithUploadUseRepository(ur uploadRepo) func(s *Service) {
+	return func(s *Service) {
+		s.Upload = &upload{repo: ur, service: s}
+	}
+}
+
+// WithQuotaUseRepository limits every user, the services made before
+// and after it share the limits.
+func WithQuotaUseRepository(ur usageRepo, limits models.Limits) func(s *Service) {
+	return func(s *Service) {
+		s.Quota.repo = ur
+		s.Quota.limits = limits
+	}
+}
+
+// WithFsckUseRepository checks the blobs of the binary file service.
+func WithFsckUseRepository(fr fsckRepo) func(s *Service) {
+	return func(s *Service) {
+		s.Fsck = &fsck{repo: fr, service: s, now: time.Now}
+	}
+}
+
+// WithShareUseRepository lets the users share their items, the services
+// made before and after it enforce the shares.
+func WithShareUseRepository(sr shareRepo) func(s *Service) {
+	return func(s *Service) {
+		s.Share.repo = sr
+		s.Share.service = s
+	}
+}
+
+func WithOrganizationUseRepository(or organizationRepo) func(s *Service) {
+	return func(s *Service) {
+		s.Org = &organization{repo: or}
+	}
+}
+
+func WithEmergencyUseRepository(er emergencyRepo, log logger.Logger) func(s *Service) {
+	return func(s *Service) {
+		s.Emergency = &emergency{repo: er, log: log, now: time.Now}
+	}
+}
+
+func WithShareLinkUseRepository(sr shareLinkRepo, log logger.Logger) func(s *Service) {
+	return func(s *Service) {
+		s.ShareLink = &shareLink{repo: sr, service: s, log: log, now: time.Now}
+	}
+}
+
+func WithRecoveryUseRepository(rr recoveryRepo) func(s *Service) {
+	return func(s *Service) {
+		s.Recovery = &recovery{repo: rr}
+	}
+}
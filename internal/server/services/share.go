@@ -0,0 +1,232 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/zelas91/goph-keeper/internal/server/models"
+	"github.com/zelas91/goph-keeper/internal/server/repository"
+	"github.com/zelas91/goph-keeper/internal/server/repository/entities"
+	"github.com/zelas91/goph-keeper/internal/server/types"
+	"golang.org/x/net/context"
+)
+
+var (
+	ErrShareReadOnly = errors.New("the item is shared for reading only")
+	ErrShareFileKey  = errors.New("the key of the file is not wrapped for the recipient")
+)
+
+// share lets the users read and update the items of each other. The item
+// services read a shared item as its owner, nothing is shared without the
+// repository.
+type share struct {
+	repo    shareRepo
+	service *Service
+}
+
+//go:generate mockgen -package mocks -destination=./mocks/mock_share_repo.go -source=share.go -package=mock
+type shareRepo interface {
+	Create(ctx context.Context, sh entities.Share) (int, error)
+	FindByOwner(ctx context.Context, ownerID int) ([]entities.Share, error)
+	FindByRecipient(ctx context.Context, recipientID int) ([]entities.Share, error)
+	FindByItemAndRecipient(ctx context.Context, itemType string, itemID, recipientID int) (entities.Share, error)
+	Delete(ctx context.Context, shareID, userID int) error
+	Keys(ctx context.Context, userID int) (entities.UserKeys, error)
+	SetKeys(ctx context.Context, userID int, keys entities.UserKeys) error
+	PublicKey(ctx context.Context, login string) (string, error)
+}
+
+// Create shares the item of the user. A file encrypted by the client is
+// shared with its key wrapped for the recipient, the server can not do it.
+func (s *share) Create(ctx context.Context, sh models.Share) (models.Share, error) {
+	userID := ctx.Value(types.UserIDKey).(int)
+	if sh.ItemType == types.ItemFile {
+		file, err := s.service.BinaryFile.repo.FindByIDAndUserID(ctx, sh.ItemID, userID)
+		if err != nil {
+			return models.Share{}, fmt.Errorf("get file err: %w", err)
+		}
+		if file.FileKey != "" && sh.FileKey == "" {
+			return models.Share{}, ErrShareFileKey
+		}
+	} else {
+		sh.FileKey = ""
+	}
+	id, err := s.repo.Create(ctx, entities.Share{
+		OwnerID:    userID,
+		Recipient:  sh.Recipient,
+		ItemType:   sh.ItemType,
+		ItemID:     sh.ItemID,
+		Permission: sh.Permission,
+		FileKey:    sh.FileKey,
+	})
+	if err != nil {
+		return models.Share{}, err
+	}
+	sh.ID = id
+	return sh, nil
+}
+
+// Shares returns the shares the user made.
+func (s *share) Shares(ctx context.Context) ([]models.Share, error) {
+	userID := ctx.Value(types.UserIDKey).(int)
+	shares, err := s.repo.FindByOwner(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]models.Share, len(shares))
+	for i, v := range shares {
+		result[i] = toModelShare(v)
+	}
+	return result, nil
+}
+
+// Incoming returns the items other users share with the user.
+func (s *share) Incoming(ctx context.Context) (models.SharedItems, error) {
+	userID := ctx.Value(types.UserIDKey).(int)
+	shares, err := s.repo.FindByRecipient(ctx, userID)
+	if err != nil {
+		return models.SharedItems{}, err
+	}
+	items := models.SharedItems{Shares: make([]models.Share, 0, len(shares))}
+	for _, v := range shares {
+		if err = s.addItem(ctx, &items, v); err != nil {
+			return models.SharedItems{}, err
+		}
+		items.Shares = append(items.Shares, toModelShare(v))
+	}
+	return items, nil
+}
+
+func (s *share) addItem(ctx context.Context, items *models.SharedItems, sh entities.Share) error {
+	switch sh.ItemType {
+	case types.ItemCard:
+		card, err := s.service.CreditCard.repo.FindByIDAndUserID(ctx, sh.ItemID, sh.OwnerID)
+		if err != nil {
+			return fmt.Errorf("get shared card err: %w", err)
+		}
+		model, err := s.service.CreditCard.decryptToModels(card)
+		if err != nil {
+			return err
+		}
+		items.Cards = append(items.Cards, model)
+	case types.ItemCredential:
+		uc, err := s.service.Credential.repo.FindByIDAndUserID(ctx, sh.ItemID, sh.OwnerID)
+		if err != nil {
+			return fmt.Errorf("get shared credential err: %w", err)
+		}
+		model, err := s.service.Credential.decryptToModels(uc)
+		if err != nil {
+			return err
+		}
+		items.Credentials = append(items.Credentials, model)
+	case types.ItemText:
+		text, err := s.service.TextData.repo.FindByIDAndUserID(ctx, sh.ItemID, sh.OwnerID)
+		if err != nil {
+			return fmt.Errorf("get shared text err: %w", err)
+		}
+		model, err := s.service.TextData.decryptToModels(text)
+		if err != nil {
+			return err
+		}
+		items.Texts = append(items.Texts, model)
+	case types.ItemFile:
+		file, err := s.service.BinaryFile.repo.FindByIDAndUserID(ctx, sh.ItemID, sh.OwnerID)
+		if err != nil {
+			return fmt.Errorf("get shared file err: %w", err)
+		}
+		model, err := s.service.BinaryFile.decryptToModels(file)
+		if err != nil {
+			return err
+		}
+		model.FileKey = sh.FileKey
+		items.Files = append(items.Files, model)
+	case types.ItemOTP:
+		key, err := s.service.OTP.repo.FindByIDAndUserID(ctx, sh.ItemID, sh.OwnerID)
+		if err != nil {
+			return fmt.Errorf("get shared otp err: %w", err)
+		}
+		model, err := s.service.OTP.decryptToModels(key)
+		if err != nil {
+			return err
+		}
+		items.OTPs = append(items.OTPs, model)
+	case types.ItemSSHKey:
+		key, err := s.service.SSHKey.repo.FindByIDAndUserID(ctx, sh.ItemID, sh.OwnerID)
+		if err != nil {
+			return fmt.Errorf("get shared ssh key err: %w", err)
+		}
+		model, err := s.service.SSHKey.decryptToModels(key)
+		if err != nil {
+			return err
+		}
+		items.SSHKeys = append(items.SSHKeys, model)
+	}
+	return nil
+}
+
+// Delete removes the share made by the user or with the user.
+func (s *share) Delete(ctx context.Context, shareID int) error {
+	userID := ctx.Value(types.UserIDKey).(int)
+	return s.repo.Delete(ctx, shareID, userID)
+}
+
+func (s *share) Keys(ctx context.Context) (models.UserKeys, error) {
+	userID := ctx.Value(types.UserIDKey).(int)
+	keys, err := s.repo.Keys(ctx, userID)
+	if err != nil {
+		return models.UserKeys{}, err
+	}
+	return models.UserKeys{PublicKey: keys.PublicKey, PrivateKey: keys.PrivateKey}, nil
+}
+
+func (s *share) SetKeys(ctx context.Context, keys models.UserKeys) error {
+	userID := ctx.Value(types.UserIDKey).(int)
+	return s.repo.SetKeys(ctx, userID, entities.UserKeys{PublicKey: keys.PublicKey, PrivateKey: keys.PrivateKey})
+}
+
+// PublicKey returns the key the keys of the files shared with the user
+// of the login are wrapped for.
+func (s *share) PublicKey(ctx context.Context, login string) (models.UserKeys, error) {
+	key, err := s.repo.PublicKey(ctx, login)
+	if err != nil {
+		return models.UserKeys{}, err
+	}
+	return models.UserKeys{PublicKey: key}, nil
+}
+
+// asOwner returns the context of the owner of the item for the services
+// to read or with write update the item as the owner. The items of the user
+// and the items nobody shares with the user keep the context of the user,
+// the repositories find only the own items then. The share is nil unless
+// the item is shared with the user.
+func (s *share) asOwner(ctx context.Context, itemType string, itemID int,
+	write bool) (context.Context, *entities.Share, error) {
+	if s == nil || s.repo == nil {
+		return ctx, nil, nil
+	}
+	userID := ctx.Value(types.UserIDKey).(int)
+	sh, err := s.repo.FindByItemAndRecipient(ctx, itemType, itemID, userID)
+	if errors.Is(err, repository.ErrNotFound) {
+		return ctx, nil, nil
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	if write && sh.Permission != models.PermissionWrite {
+		return nil, nil, ErrShareReadOnly
+	}
+	return context.WithValue(ctx, types.UserIDKey, sh.OwnerID), &sh, nil
+}
+
+func toModelShare(sh entities.Share) models.Share {
+	return models.Share{
+		ID:         sh.ID,
+		Owner:      sh.Owner,
+		Recipient:  sh.Recipient,
+		ItemType:   sh.ItemType,
+		ItemID:     sh.ItemID,
+		Permission: sh.Permission,
+		FileKey:    sh.FileKey,
+		CreatedAt:  sh.CreatedAt,
+	}
+}
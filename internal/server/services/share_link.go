@@ -0,0 +1,182 @@
+package services
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/zelas91/goph-keeper/internal/logger"
+	"github.com/zelas91/goph-keeper/internal/server/models"
+	"github.com/zelas91/goph-keeper/internal/server/repository/entities"
+	"github.com/zelas91/goph-keeper/internal/server/types"
+	"golang.org/x/net/context"
+)
+
+// LinkSecretKind is the kind of the links to a text given, not to an item.
+const LinkSecretKind = "secret"
+
+// shareLink hands the secrets to the people without an account. The secret
+// is sealed with a new key that goes into the fragment of the link, the
+// browsers do not send the fragment and the server forgets the key, so
+// the secret kept is opened by the holder of the link only.
+type shareLink struct {
+	repo    shareLinkRepo
+	service *Service
+	log     logger.Logger
+	now     func() time.Time
+}
+
+//go:generate mockgen -package mocks -destination=./mocks/mock_share_link_repo.go -source=share_link.go -package=mock
+type shareLinkRepo interface {
+	Create(ctx context.Context, link entities.ShareLink) error
+	FindByUser(ctx context.Context, userID int, now time.Time) ([]entities.ShareLink, error)
+	Delete(ctx context.Context, linkID string, userID int) error
+	Open(ctx context.Context, linkID string, now time.Time) (entities.ShareLink, error)
+	DeleteExpired(ctx context.Context, now time.Time) (int64, error)
+}
+
+// Create seals the secret and returns the link to it under the base URL,
+// the page of the link is base/s/<id> and the key is its fragment.
+func (s shareLink) Create(ctx context.Context, nl models.NewShareLink, base string) (models.ShareLink, error) {
+	userID := ctx.Value(types.UserIDKey).(int)
+	kind, secret, err := s.secret(ctx, nl)
+	if err != nil {
+		return models.ShareLink{}, err
+	}
+	plain, err := json.Marshal(secret)
+	if err != nil {
+		return models.ShareLink{}, fmt.Errorf("link secret encode err: %w", err)
+	}
+	key := make([]byte, 32)
+	id := make([]byte, 16)
+	if _, err = rand.Read(key); err != nil {
+		return models.ShareLink{}, fmt.Errorf("link key err: %w", err)
+	}
+	if _, err = rand.Read(id); err != nil {
+		return models.ShareLink{}, fmt.Errorf("link id err: %w", err)
+	}
+	data, err := sealLink(key, plain)
+	if err != nil {
+		return models.ShareLink{}, err
+	}
+	now := s.now()
+	link := entities.ShareLink{
+		ID:        hex.EncodeToString(id),
+		UserID:    userID,
+		Kind:      kind,
+		Data:      data,
+		MaxViews:  nl.MaxViews,
+		ExpiresAt: now.Add(time.Duration(nl.ExpiresIn) * time.Second),
+		CreatedAt: now,
+	}
+	if err = s.repo.Create(ctx, link); err != nil {
+		return models.ShareLink{}, err
+	}
+	result := toModelShareLink(link)
+	result.URL = fmt.Sprintf("%s/s/%s#%s", base, link.ID, base64.RawURLEncoding.EncodeToString(key))
+	return result, nil
+}
+
+// secret reads the item of the link, the items shared with the user as well.
+func (s shareLink) secret(ctx context.Context, nl models.NewShareLink) (string, models.LinkSecret, error) {
+	switch nl.ItemType {
+	case types.ItemText:
+		text, err := s.service.TextData.Text(ctx, nl.ItemID)
+		if err != nil {
+			return "", models.LinkSecret{}, err
+		}
+		return types.ItemText, models.LinkSecret{Title: text.Title, Text: text.Text}, nil
+	case types.ItemCredential:
+		uc, err := s.service.Credential.Credential(ctx, nl.ItemID)
+		if err != nil {
+			return "", models.LinkSecret{}, err
+		}
+		return types.ItemCredential, models.LinkSecret{Title: uc.Title, Login: uc.Login, Password: uc.Password}, nil
+	default:
+		return LinkSecretKind, models.LinkSecret{Text: nl.Text}, nil
+	}
+}
+
+// Links returns the links of the user that still open.
+func (s shareLink) Links(ctx context.Context) ([]models.ShareLink, error) {
+	userID := ctx.Value(types.UserIDKey).(int)
+	links, err := s.repo.FindByUser(ctx, userID, s.now())
+	if err != nil {
+		return nil, err
+	}
+	result := make([]models.ShareLink, len(links))
+	for i, v := range links {
+		result[i] = toModelShareLink(v)
+	}
+	return result, nil
+}
+
+// Delete removes the link before it expires.
+func (s shareLink) Delete(ctx context.Context, linkID string) error {
+	userID := ctx.Value(types.UserIDKey).(int)
+	return s.repo.Delete(ctx, linkID, userID)
+}
+
+// Open returns the sealed secret of the link to anybody, the link of
+// the last view is gone after it.
+func (s shareLink) Open(ctx context.Context, linkID string) (models.SealedSecret, error) {
+	link, err := s.repo.Open(ctx, linkID, s.now())
+	if err != nil {
+		return models.SealedSecret{}, err
+	}
+	return models.SealedSecret{Kind: link.Kind, Data: link.Data}, nil
+}
+
+// RemoveExpiredEvery removes the expired links until the context is done.
+func (s shareLink) RemoveExpiredEvery(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			removed, err := s.repo.DeleteExpired(ctx, s.now())
+			if err != nil {
+				s.log.Errorf("remove expired share links err: %v", err)
+				continue
+			}
+			if removed > 0 {
+				s.log.Infof("removed %d expired share links", removed)
+			}
+		}
+	}
+}
+
+// sealLink seals the secret with AES-GCM, the nonce goes first.
+func sealLink(key, plain []byte) ([]byte, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("link cipher err: %w", err)
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("link gcm err: %w", err)
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err = rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("link nonce err: %w", err)
+	}
+	return gcm.Seal(nonce, nonce, plain, nil), nil
+}
+
+func toModelShareLink(link entities.ShareLink) models.ShareLink {
+	return models.ShareLink{
+		ID:        link.ID,
+		Kind:      link.Kind,
+		MaxViews:  link.MaxViews,
+		Views:     link.Views,
+		ExpiresAt: link.ExpiresAt,
+		CreatedAt: link.CreatedAt,
+	}
+}
@@ -0,0 +1,59 @@
+package services
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zelas91/goph-keeper/internal/server/models"
+	"github.com/zelas91/goph-keeper/internal/server/repository/entities"
+	mock "github.com/zelas91/goph-keeper/internal/server/services/mocks"
+	"github.com/zelas91/goph-keeper/internal/server/types"
+	"golang.org/x/net/context"
+)
+
+func TestShareLinkCreate(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := mock.NewMockshareLinkRepo(ctrl)
+	var saved entities.ShareLink
+	repo.EXPECT().Create(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, link entities.ShareLink) error {
+			saved = link
+			return nil
+		})
+	s := New(WithShareLinkUseRepository(repo, nil))
+	ctx := context.WithValue(context.Background(), types.UserIDKey, 2)
+
+	link, err := s.ShareLink.Create(ctx, models.NewShareLink{Text: "secret", MaxViews: 1, ExpiresIn: 3600},
+		"https://keeper.example")
+	require.NoError(t, err)
+	assert.Equal(t, LinkSecretKind, saved.Kind)
+	assert.Equal(t, 2, saved.UserID)
+	assert.Equal(t, 1, saved.MaxViews)
+	assert.Equal(t, saved.CreatedAt.Add(time.Hour), saved.ExpiresAt)
+
+	prefix := "https://keeper.example/s/" + saved.ID + "#"
+	require.True(t, strings.HasPrefix(link.URL, prefix))
+	assert.NotContains(t, string(saved.Data), "secret")
+
+	key, err := base64.RawURLEncoding.DecodeString(strings.TrimPrefix(link.URL, prefix))
+	require.NoError(t, err)
+	block, err := aes.NewCipher(key)
+	require.NoError(t, err)
+	gcm, err := cipher.NewGCM(block)
+	require.NoError(t, err)
+	plain, err := gcm.Open(nil, saved.Data[:gcm.NonceSize()], saved.Data[gcm.NonceSize():], nil)
+	require.NoError(t, err)
+	var secret models.LinkSecret
+	require.NoError(t, json.Unmarshal(plain, &secret))
+	assert.Equal(t, "secret", secret.Text)
+}
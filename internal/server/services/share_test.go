@@ -0,0 +1,146 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/zelas91/goph-keeper/internal/server/models"
+	"github.com/zelas91/goph-keeper/internal/server/repository"
+	"github.com/zelas91/goph-keeper/internal/server/repository/entities"
+	mock "github.com/zelas91/goph-keeper/internal/server/services/mocks"
+	"github.com/zelas91/goph-keeper/internal/server/types"
+	crypto2 "github.com/zelas91/goph-keeper/internal/utils/crypto"
+	"golang.org/x/net/context"
+)
+
+func TestShareAccess(t *testing.T) {
+	const owner, recipient = 1, 2
+	tests := []struct {
+		name        string
+		share       *entities.Share
+		write       bool
+		wantUserID  int
+		wantErr     error
+		mockContent func(r *mock.MocktextDataRepo, userID int, text entities.TextData)
+	}{
+		{
+			name:       "#1 ok own item",
+			wantUserID: recipient,
+			mockContent: func(r *mock.MocktextDataRepo, userID int, text entities.TextData) {
+				r.EXPECT().FindByIDAndUserID(gomock.Any(), 5, userID).Return(text, nil)
+			},
+		},
+		{
+			name:       "#2 ok read shared item as owner",
+			share:      &entities.Share{OwnerID: owner, Permission: models.PermissionRead},
+			wantUserID: owner,
+			mockContent: func(r *mock.MocktextDataRepo, userID int, text entities.TextData) {
+				r.EXPECT().FindByIDAndUserID(gomock.Any(), 5, userID).Return(text, nil)
+			},
+		},
+		{
+			name:       "#3 ok update item shared for writing",
+			share:      &entities.Share{OwnerID: owner, Permission: models.PermissionWrite},
+			write:      true,
+			wantUserID: owner,
+			mockContent: func(r *mock.MocktextDataRepo, userID int, _ entities.TextData) {
+				r.EXPECT().Update(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(_ context.Context, td entities.TextData) error {
+						assert.Equal(t, userID, td.UserId)
+						return nil
+					})
+			},
+		},
+		{
+			name:    "#4 nok update item shared for reading",
+			share:   &entities.Share{OwnerID: owner, Permission: models.PermissionRead},
+			write:   true,
+			wantErr: ErrShareReadOnly,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			c, err := crypto2.NewCrypto("0123456789abcdef0123456789abcdef")
+			assert.NoError(t, err)
+			shareRepo := mock.NewMockshareRepo(ctrl)
+			if test.share != nil {
+				shareRepo.EXPECT().FindByItemAndRecipient(gomock.Any(), types.ItemText, 5, recipient).
+					Return(*test.share, nil)
+			} else {
+				shareRepo.EXPECT().FindByItemAndRecipient(gomock.Any(), types.ItemText, 5, recipient).
+					Return(entities.Share{}, repository.ErrNotFound)
+			}
+			textRepo := mock.NewMocktextDataRepo(ctrl)
+			s := New(WithTextUseRepository(textRepo, c), WithShareUseRepository(shareRepo))
+			if test.mockContent != nil {
+				text, err := s.TextData.encryptToEntities(models.TextData{ID: 5, Text: "secret"})
+				assert.NoError(t, err)
+				test.mockContent(textRepo, test.wantUserID, text)
+			}
+			ctx := context.WithValue(context.Background(), types.UserIDKey, recipient)
+
+			if test.write {
+				err = s.TextData.Update(ctx, models.TextData{ID: 5, Text: "new", Version: 1})
+			} else {
+				_, err = s.TextData.Text(ctx, 5)
+			}
+			assert.ErrorIs(t, err, test.wantErr)
+		})
+	}
+}
+
+func TestShareCreateFile(t *testing.T) {
+	tests := []struct {
+		name    string
+		file    entities.BinaryFile
+		share   models.Share
+		wantErr error
+	}{
+		{
+			name:  "#1 ok file encrypted by the client with the key for the recipient",
+			file:  entities.BinaryFile{ID: 3, FileKey: "a2V5"},
+			share: models.Share{Recipient: "bob", ItemType: types.ItemFile, ItemID: 3, FileKey: "Ym94"},
+		},
+		{
+			name:  "#2 ok file encrypted by the server",
+			file:  entities.BinaryFile{ID: 3},
+			share: models.Share{Recipient: "bob", ItemType: types.ItemFile, ItemID: 3},
+		},
+		{
+			name:    "#3 nok no key for the recipient",
+			file:    entities.BinaryFile{ID: 3, FileKey: "a2V5"},
+			share:   models.Share{Recipient: "bob", ItemType: types.ItemFile, ItemID: 3},
+			wantErr: ErrShareFileKey,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			fileRepo := mock.NewMockbinaryFileRepo(ctrl)
+			fileRepo.EXPECT().FindByIDAndUserID(gomock.Any(), 3, 1).Return(test.file, nil)
+			shareRepo := mock.NewMockshareRepo(ctrl)
+			if test.wantErr == nil {
+				shareRepo.EXPECT().Create(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(_ context.Context, sh entities.Share) (int, error) {
+						assert.Equal(t, 1, sh.OwnerID)
+						assert.Equal(t, test.share.FileKey, sh.FileKey)
+						return 7, nil
+					})
+			}
+			s := New(WithBinaryFileUseRepository(fileRepo, nil, nil, nil, ""), WithShareUseRepository(shareRepo))
+			ctx := context.WithValue(context.Background(), types.UserIDKey, 1)
+
+			sh, err := s.Share.Create(ctx, test.share)
+			assert.ErrorIs(t, err, test.wantErr)
+			if test.wantErr == nil {
+				assert.Equal(t, 7, sh.ID)
+			}
+		})
+	}
+}
@@ -0,0 +1,184 @@
+package services
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/zelas91/goph-keeper/internal/server/models"
+	"github.com/zelas91/goph-keeper/internal/server/repository/entities"
+	"github.com/zelas91/goph-keeper/internal/server/types"
+	"golang.org/x/net/context"
+)
+
+// sshKey keeps the SSH private keys, the private key, its passphrase and
+// the comment are encrypted, the public key and the fingerprint are public
+// anyway and are kept as they are.
+type sshKey struct {
+	repo   sshKeyRepo
+	crypto crypto
+	events *events
+	quota  *quota
+	share  *share
+}
+
+//go:generate mockgen -package mocks -destination=./mocks/mock_ssh_key_repo.go -source=ssh_key.go -package=mock
+type sshKeyRepo interface {
+	Create(ctx context.Context, key entities.SSHKey) (int, error)
+	FindAllByUserID(ctx context.Context, userID int, filter entities.ItemFilter) ([]entities.SSHKey, error)
+	FindByIDAndUserID(ctx context.Context, sshKeyID, userID int) (entities.SSHKey, error)
+	Delete(ctx context.Context, sshKeyID, userID int) error
+	Update(ctx context.Context, key entities.SSHKey) error
+}
+
+func (s sshKey) Create(ctx context.Context, key models.SSHKey) error {
+	userID := ctx.Value(types.UserIDKey).(int)
+	if err := s.quota.checkItem(ctx); err != nil {
+		return err
+	}
+	key.UserId = userID
+	keyEntities, err := s.encryptToEntities(key)
+	if err != nil {
+		return err
+	}
+	id, err := s.repo.Create(ctx, keyEntities)
+	if err != nil {
+		return fmt.Errorf("create ssh key err: %w", err)
+	}
+	s.events.publish(ctx, models.EventCreated, types.ItemSSHKey, id)
+	return nil
+}
+
+func (s sshKey) SSHKeys(ctx context.Context, filter models.ItemFilter) ([]models.SSHKey, string, error) {
+	userID := ctx.Value(types.UserIDKey).(int)
+	keys, err := s.repo.FindAllByUserID(ctx, userID, pageFilter(filter))
+	if err != nil {
+		return nil, "", fmt.Errorf("get ssh keys err: %w", err)
+	}
+	keys, next := cutPage(keys, filter, func(v entities.SSHKey) (int, time.Time, time.Time) {
+		return v.ID, v.CreatedAt, v.UpdateAt
+	})
+	keysModel := make([]models.SSHKey, len(keys))
+	for i, v := range keys {
+		keyModel, err := s.decryptToModels(v)
+		if err != nil {
+			return nil, "", err
+		}
+		keysModel[i] = keyModel
+	}
+	return keysModel, next, nil
+}
+
+func (s sshKey) SSHKey(ctx context.Context, sshKeyID int) (models.SSHKey, error) {
+	ctx, _, err := s.share.asOwner(ctx, types.ItemSSHKey, sshKeyID, false)
+	if err != nil {
+		return models.SSHKey{}, err
+	}
+	userID := ctx.Value(types.UserIDKey).(int)
+	key, err := s.repo.FindByIDAndUserID(ctx, sshKeyID, userID)
+	if err != nil {
+		return models.SSHKey{}, fmt.Errorf("get ssh key err: %w", err)
+	}
+	return s.decryptToModels(key)
+}
+
+func (s sshKey) Delete(ctx context.Context, sshKeyID int) error {
+	userID := ctx.Value(types.UserIDKey).(int)
+
+	if err := s.repo.Delete(ctx, sshKeyID, userID); err != nil {
+		return fmt.Errorf("delete ssh key err: %w", err)
+	}
+	s.events.publish(ctx, models.EventDeleted, types.ItemSSHKey, sshKeyID)
+	return nil
+}
+
+func (s sshKey) Update(ctx context.Context, key models.SSHKey) error {
+	ctx, _, err := s.share.asOwner(ctx, types.ItemSSHKey, key.ID, true)
+	if err != nil {
+		return err
+	}
+	userID := ctx.Value(types.UserIDKey).(int)
+	key.UserId = userID
+	keyEntities, err := s.encryptToEntities(key)
+	if err != nil {
+		return err
+	}
+	if err := s.repo.Update(ctx, keyEntities); err != nil {
+		return fmt.Errorf("update ssh key err:%w", err)
+	}
+	s.events.publish(ctx, models.EventUpdated, types.ItemSSHKey, key.ID)
+	return nil
+}
+
+func (s sshKey) encryptToEntities(key models.SSHKey) (entities.SSHKey, error) {
+	privateKey, err := s.crypto.Encrypt([]byte(key.PrivateKey))
+	if err != nil {
+		return entities.SSHKey{}, err
+	}
+	passphrase, err := s.crypto.Encrypt([]byte(key.Passphrase))
+	if err != nil {
+		return entities.SSHKey{}, err
+	}
+	comment, err := s.crypto.Encrypt([]byte(key.Comment))
+	if err != nil {
+		return entities.SSHKey{}, err
+	}
+	meta, err := encryptMeta(s.crypto, key.Title, key.Note, key.Metadata)
+	if err != nil {
+		return entities.SSHKey{}, err
+	}
+	return entities.SSHKey{
+		ID:          key.ID,
+		UserId:      key.UserId,
+		Version:     key.Version,
+		KeyType:     key.KeyType,
+		PrivateKey:  privateKey,
+		PublicKey:   key.PublicKey,
+		Fingerprint: key.Fingerprint,
+		Certificate: key.Certificate,
+		Comment:     comment,
+		Passphrase:  passphrase,
+		Title:       meta.title,
+		Note:        meta.note,
+		Metadata:    meta.metadata,
+		FolderID:    key.FolderID,
+		SearchTokens: searchTokens(s.crypto, key.UserId, key.Title, key.Note, key.Metadata,
+			key.Comment, key.Fingerprint),
+	}, nil
+}
+
+func (s sshKey) decryptToModels(key entities.SSHKey) (models.SSHKey, error) {
+	privateKey, err := s.crypto.Decrypt(key.PrivateKey)
+	if err != nil {
+		return models.SSHKey{}, err
+	}
+	passphrase, err := s.crypto.Decrypt(key.Passphrase)
+	if err != nil {
+		return models.SSHKey{}, err
+	}
+	comment, err := s.crypto.Decrypt(key.Comment)
+	if err != nil {
+		return models.SSHKey{}, err
+	}
+	title, note, metadata, err := decryptMeta(s.crypto,
+		itemMeta{title: key.Title, note: key.Note, metadata: key.Metadata})
+	if err != nil {
+		return models.SSHKey{}, err
+	}
+	return models.SSHKey{
+		ID:          key.ID,
+		UserId:      key.UserId,
+		Version:     key.Version,
+		KeyType:     key.KeyType,
+		PrivateKey:  string(privateKey),
+		PublicKey:   key.PublicKey,
+		Fingerprint: key.Fingerprint,
+		Certificate: key.Certificate,
+		Comment:     string(comment),
+		Passphrase:  string(passphrase),
+		Title:       title,
+		Note:        note,
+		Metadata:    metadata,
+		FolderID:    key.FolderID,
+		Tags:        key.Tags,
+	}, nil
+}
@@ -0,0 +1,128 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/zelas91/goph-keeper/internal/server/models"
+	"github.com/zelas91/goph-keeper/internal/server/repository/entities"
+	"github.com/zelas91/goph-keeper/internal/server/types"
+	"golang.org/x/net/context"
+)
+
+var ErrSyncCursor = errors.New("sync cursor is not valid")
+
+type syncer struct {
+	repo    syncRepo
+	service *Service
+}
+
+//go:generate mockgen -package mocks -destination=./mocks/mock_sync_repo.go -source=sync.go -package=mock
+type syncRepo interface {
+	ChangeSeq(ctx context.Context, userID int) (int64, error)
+	Tombstones(ctx context.Context, userID int, after, upTo int64) ([]entities.Tombstone, error)
+}
+
+// Changes returns the items changed after the since cursor, an empty
+// cursor returns every item of the user.
+func (s syncer) Changes(ctx context.Context, since string) (models.SyncChanges, error) {
+	userID := ctx.Value(types.UserIDKey).(int)
+	var after int64
+	if since != "" {
+		var err error
+		if after, err = strconv.ParseInt(since, 10, 64); err != nil || after < 0 {
+			return models.SyncChanges{}, ErrSyncCursor
+		}
+	}
+	// the changes committed after this read get greater numbers
+	// and come with the next sync.
+	upTo, err := s.repo.ChangeSeq(ctx, userID)
+	if err != nil {
+		return models.SyncChanges{}, fmt.Errorf("sync err: %w", err)
+	}
+	changes := models.SyncChanges{Cursor: strconv.FormatInt(upTo, 10)}
+	if after > upTo {
+		return changes, ErrSyncCursor
+	}
+	if after == upTo {
+		return changes, nil
+	}
+
+	filter := entities.ItemFilter{SortColumn: "created_at", ChangedAfter: after, ChangedUpTo: upTo}
+	cards, err := s.service.CreditCard.repo.FindAllByUserID(ctx, userID, filter)
+	if err != nil {
+		return changes, fmt.Errorf("sync cards err: %w", err)
+	}
+	for _, v := range cards {
+		card, err := s.service.CreditCard.decryptToModels(v)
+		if err != nil {
+			return changes, err
+		}
+		changes.Cards = append(changes.Cards, card)
+	}
+	ucs, err := s.service.Credential.repo.FindAllByUserID(ctx, userID, filter)
+	if err != nil {
+		return changes, fmt.Errorf("sync credentials err: %w", err)
+	}
+	for _, v := range ucs {
+		uc, err := s.service.Credential.decryptToModels(v)
+		if err != nil {
+			return changes, err
+		}
+		changes.Credentials = append(changes.Credentials, uc)
+	}
+	texts, err := s.service.TextData.repo.FindAllByUserID(ctx, userID, filter)
+	if err != nil {
+		return changes, fmt.Errorf("sync texts err: %w", err)
+	}
+	for _, v := range texts {
+		text, err := s.service.TextData.decryptToModels(v)
+		if err != nil {
+			return changes, err
+		}
+		changes.Texts = append(changes.Texts, text)
+	}
+	files, err := s.service.BinaryFile.repo.FindAllByUserID(ctx, userID, filter)
+	if err != nil {
+		return changes, fmt.Errorf("sync files err: %w", err)
+	}
+	for _, v := range files {
+		file, err := s.service.BinaryFile.decryptToModels(v)
+		if err != nil {
+			return changes, err
+		}
+		changes.Files = append(changes.Files, file)
+	}
+	keys, err := s.service.OTP.repo.FindAllByUserID(ctx, userID, filter)
+	if err != nil {
+		return changes, fmt.Errorf("sync otp keys err: %w", err)
+	}
+	for _, v := range keys {
+		key, err := s.service.OTP.decryptToModels(v)
+		if err != nil {
+			return changes, err
+		}
+		changes.OTPs = append(changes.OTPs, key)
+	}
+	sshKeys, err := s.service.SSHKey.repo.FindAllByUserID(ctx, userID, filter)
+	if err != nil {
+		return changes, fmt.Errorf("sync ssh keys err: %w", err)
+	}
+	for _, v := range sshKeys {
+		key, err := s.service.SSHKey.decryptToModels(v)
+		if err != nil {
+			return changes, err
+		}
+		changes.SSHKeys = append(changes.SSHKeys, key)
+	}
+
+	tombstones, err := s.repo.Tombstones(ctx, userID, after, upTo)
+	if err != nil {
+		return changes, fmt.Errorf("sync tombstones err: %w", err)
+	}
+	for _, v := range tombstones {
+		changes.Deleted = append(changes.Deleted, models.ItemRef{ItemType: v.ItemType, ItemID: v.ItemID})
+	}
+	return changes, nil
+}
@@ -0,0 +1,110 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/zelas91/goph-keeper/internal/server/models"
+	"github.com/zelas91/goph-keeper/internal/server/repository/entities"
+	mock "github.com/zelas91/goph-keeper/internal/server/services/mocks"
+	"github.com/zelas91/goph-keeper/internal/server/types"
+	"golang.org/x/net/context"
+)
+
+func TestSyncChanges(t *testing.T) {
+	type repos struct {
+		sync *mock.MocksyncRepo
+		card *mock.MockcardRepo
+		uc   *mock.MockcredentialRepo
+		text *mock.MocktextDataRepo
+		file *mock.MockbinaryFileRepo
+		otp  *mock.MockotpRepo
+		ssh  *mock.MocksshKeyRepo
+	}
+	tests := []struct {
+		name         string
+		since        string
+		want         models.SyncChanges
+		wantErr      error
+		mockBehavior func(r repos)
+	}{
+		{
+			name:  "#1 ok nothing changed",
+			since: "5",
+			want:  models.SyncChanges{Cursor: "5"},
+			mockBehavior: func(r repos) {
+				r.sync.EXPECT().ChangeSeq(gomock.Any(), 1).Return(int64(5), nil)
+			},
+		},
+		{
+			name:  "#2 ok deleted since cursor",
+			since: "3",
+			want: models.SyncChanges{
+				Deleted: []models.ItemRef{{ItemType: "card", ItemID: 8}},
+				Cursor:  "5",
+			},
+			mockBehavior: func(r repos) {
+				filter := entities.ItemFilter{SortColumn: "created_at", ChangedAfter: 3, ChangedUpTo: 5}
+				r.sync.EXPECT().ChangeSeq(gomock.Any(), 1).Return(int64(5), nil)
+				r.card.EXPECT().FindAllByUserID(gomock.Any(), 1, filter).Return(nil, nil)
+				r.uc.EXPECT().FindAllByUserID(gomock.Any(), 1, filter).Return(nil, nil)
+				r.text.EXPECT().FindAllByUserID(gomock.Any(), 1, filter).Return(nil, nil)
+				r.file.EXPECT().FindAllByUserID(gomock.Any(), 1, filter).Return(nil, nil)
+				r.otp.EXPECT().FindAllByUserID(gomock.Any(), 1, filter).Return(nil, nil)
+				r.ssh.EXPECT().FindAllByUserID(gomock.Any(), 1, filter).Return(nil, nil)
+				r.sync.EXPECT().Tombstones(gomock.Any(), 1, int64(3), int64(5)).
+					Return([]entities.Tombstone{{ItemType: "card", ItemID: 8, ChangeSeq: 4}}, nil)
+			},
+		},
+		{
+			name:    "#3 nok cursor ahead of the server",
+			since:   "9",
+			wantErr: ErrSyncCursor,
+			mockBehavior: func(r repos) {
+				r.sync.EXPECT().ChangeSeq(gomock.Any(), 1).Return(int64(5), nil)
+			},
+		},
+		{
+			name:         "#4 nok cursor not a number",
+			since:        "abc",
+			wantErr:      ErrSyncCursor,
+			mockBehavior: func(r repos) {},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			r := repos{
+				sync: mock.NewMocksyncRepo(ctrl),
+				card: mock.NewMockcardRepo(ctrl),
+				uc:   mock.NewMockcredentialRepo(ctrl),
+				text: mock.NewMocktextDataRepo(ctrl),
+				file: mock.NewMockbinaryFileRepo(ctrl),
+				otp:  mock.NewMockotpRepo(ctrl),
+				ssh:  mock.NewMocksshKeyRepo(ctrl),
+			}
+			test.mockBehavior(r)
+			s := New(
+				WithSyncUseRepository(r.sync),
+				WithCardUseRepository(r.card, nil),
+				WithCredentialUseRepository(r.uc, nil),
+				WithTextUseRepository(r.text, nil),
+				WithBinaryFileUseRepository(r.file, nil, nil, nil, ""),
+				WithOTPUseRepository(r.otp, nil),
+				WithSSHKeyUseRepository(r.ssh, nil),
+			)
+
+			ctx := context.WithValue(context.Background(), types.UserIDKey, 1)
+			changes, err := s.Sync.Changes(ctx, test.since)
+			if test.wantErr != nil {
+				assert.ErrorIs(t, err, test.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, test.want, changes)
+		})
+	}
+}
@@ -0,0 +1,78 @@
+package services
+
+import (
+	"fmt"
+
+	"github.com/zelas91/goph-keeper/internal/server/helper"
+	"github.com/zelas91/goph-keeper/internal/server/models"
+	"github.com/zelas91/goph-keeper/internal/server/repository/entities"
+	"github.com/zelas91/goph-keeper/internal/server/types"
+	"golang.org/x/net/context"
+)
+
+type tag struct {
+	repo   tagRepo
+	events *events
+}
+
+//go:generate mockgen -package mocks -destination=./mocks/mock_tag_repo.go -source=tag.go -package=mock
+type tagRepo interface {
+	Create(ctx context.Context, tg entities.Tag) (int, error)
+	FindAllByUserID(ctx context.Context, userID int) ([]entities.Tag, error)
+	Delete(ctx context.Context, tagID, userID int) error
+	Attach(ctx context.Context, userID, tagID int, itemType string, itemID int) error
+	Detach(ctx context.Context, userID, tagID int, itemType string, itemID int) error
+}
+
+// Create returns the existing tag when the user already has one with this name.
+func (t tag) Create(ctx context.Context, tg models.Tag) (models.Tag, error) {
+	userID := ctx.Value(types.UserIDKey).(int)
+	tg.UserId = userID
+	id, err := t.repo.Create(ctx, entities.Tag{UserId: userID, Name: tg.Name})
+	if err != nil {
+		return models.Tag{}, fmt.Errorf("create tag err: %w", err)
+	}
+	tg.ID = id
+	t.events.publish(ctx, models.EventCreated, types.Tag, id)
+	return tg, nil
+}
+
+func (t tag) Tags(ctx context.Context) ([]models.Tag, error) {
+	userID := ctx.Value(types.UserIDKey).(int)
+	tags, err := t.repo.FindAllByUserID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("get tags err: %w", err)
+	}
+	tagsModel := make([]models.Tag, len(tags))
+	for i, v := range tags {
+		tagsModel[i] = helper.ToModelTag(v)
+	}
+	return tagsModel, nil
+}
+
+func (t tag) Delete(ctx context.Context, tagID int) error {
+	userID := ctx.Value(types.UserIDKey).(int)
+	if err := t.repo.Delete(ctx, tagID, userID); err != nil {
+		return fmt.Errorf("delete tag err: %w", err)
+	}
+	t.events.publish(ctx, models.EventDeleted, types.Tag, tagID)
+	return nil
+}
+
+func (t tag) Attach(ctx context.Context, tagID int, item models.ItemRef) error {
+	userID := ctx.Value(types.UserIDKey).(int)
+	if err := t.repo.Attach(ctx, userID, tagID, item.ItemType, item.ItemID); err != nil {
+		return fmt.Errorf("attach tag err: %w", err)
+	}
+	t.events.publish(ctx, models.EventUpdated, item.ItemType, item.ItemID)
+	return nil
+}
+
+func (t tag) Detach(ctx context.Context, tagID int, item models.ItemRef) error {
+	userID := ctx.Value(types.UserIDKey).(int)
+	if err := t.repo.Detach(ctx, userID, tagID, item.ItemType, item.ItemID); err != nil {
+		return fmt.Errorf("detach tag err: %w", err)
+	}
+	t.events.publish(ctx, models.EventUpdated, item.ItemType, item.ItemID)
+	return nil
+}
@@ -2,6 +2,7 @@ package services
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/zelas91/goph-keeper/internal/server/models"
 	"github.com/zelas91/goph-keeper/internal/server/repository/entities"
@@ -12,12 +13,15 @@ import (
 type textData struct {
 	repo   textDataRepo
 	crypto crypto
+	events *events
+	quota  *quota
+	share  *share
 }
 
 //go:generate mockgen -package mocks -destination=./mocks/mock_text_data_repo.go -source=text_data.go -package=mock
 type textDataRepo interface {
-	Create(ctx context.Context, text entities.TextData) error
-	FindAllByUserID(ctx context.Context, textID int) ([]entities.TextData, error)
+	Create(ctx context.Context, text entities.TextData) (int, error)
+	FindAllByUserID(ctx context.Context, userID int, filter entities.ItemFilter) ([]entities.TextData, error)
 	FindByIDAndUserID(ctx context.Context, textID, userID int) (entities.TextData, error)
 	Delete(ctx context.Context, textID, userID int) error
 	Update(ctx context.Context, uc entities.TextData) error
@@ -25,35 +29,47 @@ type textDataRepo interface {
 
 func (t textData) Create(ctx context.Context, text models.TextData) error {
 	userID := ctx.Value(types.UserIDKey).(int)
+	if err := t.quota.checkItem(ctx); err != nil {
+		return err
+	}
 	text.UserId = userID
 	textEntities, err := t.encryptToEntities(text)
 	if err != nil {
 		return err
 	}
-	if err := t.repo.Create(ctx, textEntities); err != nil {
+	id, err := t.repo.Create(ctx, textEntities)
+	if err != nil {
 		return fmt.Errorf("create text err: %w", err)
 	}
+	t.events.publish(ctx, models.EventCreated, types.ItemText, id)
 	return nil
 }
 
-func (t textData) Texts(ctx context.Context) ([]models.TextData, error) {
+func (t textData) Texts(ctx context.Context, filter models.ItemFilter) ([]models.TextData, string, error) {
 	userID := ctx.Value(types.UserIDKey).(int)
-	texts, err := t.repo.FindAllByUserID(ctx, userID)
+	texts, err := t.repo.FindAllByUserID(ctx, userID, pageFilter(filter))
 	if err != nil {
-		return nil, fmt.Errorf("get texts err: %w", err)
+		return nil, "", fmt.Errorf("get texts err: %w", err)
 	}
+	texts, next := cutPage(texts, filter, func(v entities.TextData) (int, time.Time, time.Time) {
+		return v.ID, v.CreatedAt, v.UpdateAt
+	})
 	textsModel := make([]models.TextData, len(texts))
 	for i, v := range texts {
 		textModel, err := t.decryptToModels(v)
 		if err != nil {
-			return nil, err
+			return nil, "", err
 		}
 		textsModel[i] = textModel
 	}
-	return textsModel, err
+	return textsModel, next, nil
 }
 
 func (t textData) Text(ctx context.Context, ucID int) (models.TextData, error) {
+	ctx, _, err := t.share.asOwner(ctx, types.ItemText, ucID, false)
+	if err != nil {
+		return models.TextData{}, err
+	}
 	userID := ctx.Value(types.UserIDKey).(int)
 
 	text, err := t.repo.FindByIDAndUserID(ctx, ucID, userID)
@@ -73,10 +89,15 @@ func (t textData) Delete(ctx context.Context, ucID int) error {
 	if err := t.repo.Delete(ctx, ucID, userID); err != nil {
 		return fmt.Errorf("delete text err: %w", err)
 	}
+	t.events.publish(ctx, models.EventDeleted, types.ItemText, ucID)
 	return nil
 }
 
 func (t textData) Update(ctx context.Context, text models.TextData) error {
+	ctx, _, err := t.share.asOwner(ctx, types.ItemText, text.ID, true)
+	if err != nil {
+		return err
+	}
 	userID := ctx.Value(types.UserIDKey).(int)
 	text.UserId = userID
 	textEntities, err := t.encryptToEntities(text)
@@ -86,6 +107,7 @@ func (t textData) Update(ctx context.Context, text models.TextData) error {
 	if err := t.repo.Update(ctx, textEntities); err != nil {
 		return fmt.Errorf("update credentials err:%w", err)
 	}
+	t.events.publish(ctx, models.EventUpdated, types.ItemText, text.ID)
 	return nil
 }
 
@@ -94,11 +116,20 @@ func (t textData) encryptToEntities(td models.TextData) (entities.TextData, erro
 	if err != nil {
 		return entities.TextData{}, err
 	}
+	meta, err := encryptMeta(t.crypto, td.Title, td.Note, td.Metadata)
+	if err != nil {
+		return entities.TextData{}, err
+	}
 	return entities.TextData{
-		Text:    text,
-		ID:      td.ID,
-		UserId:  td.UserId,
-		Version: td.Version,
+		Text:         text,
+		ID:           td.ID,
+		UserId:       td.UserId,
+		Version:      td.Version,
+		Title:        meta.title,
+		Note:         meta.note,
+		Metadata:     meta.metadata,
+		FolderID:     td.FolderID,
+		SearchTokens: searchTokens(t.crypto, td.UserId, td.Title, td.Note, td.Metadata),
 	}, nil
 }
 
@@ -107,10 +138,20 @@ func (t textData) decryptToModels(td entities.TextData) (models.TextData, error)
 	if err != nil {
 		return models.TextData{}, err
 	}
+	title, note, metadata, err := decryptMeta(t.crypto,
+		itemMeta{title: td.Title, note: td.Note, metadata: td.Metadata})
+	if err != nil {
+		return models.TextData{}, err
+	}
 	return models.TextData{
-		ID:      td.ID,
-		UserId:  td.UserId,
-		Version: td.Version,
-		Text:    string(text),
+		ID:       td.ID,
+		UserId:   td.UserId,
+		Version:  td.Version,
+		Text:     string(text),
+		Title:    title,
+		Note:     note,
+		Metadata: metadata,
+		FolderID: td.FolderID,
+		Tags:     td.Tags,
 	}, nil
 }
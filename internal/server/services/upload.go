@@ -0,0 +1,301 @@
+package services
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/zelas91/goph-keeper/internal/server/models"
+	"github.com/zelas91/goph-keeper/internal/server/repository/entities"
+	"github.com/zelas91/goph-keeper/internal/server/storage"
+	"github.com/zelas91/goph-keeper/internal/server/types"
+	"golang.org/x/net/context"
+)
+
+// uploadTTL is how long an upload nobody sends chunks to is kept.
+const uploadTTL = 7 * 24 * time.Hour
+
+var (
+	ErrUploadChunk      = errors.New("chunk is empty or out of the file")
+	ErrUploadChecksum   = errors.New("chunk checksum does not match")
+	ErrUploadIncomplete = errors.New("upload is not complete")
+)
+
+// upload keeps each chunk of a file as a blob next to the files of the user,
+// so any replica of the server takes the next chunk. The finished upload
+// is stored as the file item of the websocket upload.
+type upload struct {
+	repo    uploadRepo
+	service *Service
+}
+
+//go:generate mockgen -package mocks -destination=./mocks/mock_upload_repo.go -source=upload.go -package=mock
+type uploadRepo interface {
+	Create(ctx context.Context, up entities.Upload) error
+	FindByIDAndUserID(ctx context.Context, uploadID string, userID int) (entities.Upload, error)
+	FindAllByUserID(ctx context.Context, userID int) ([]entities.Upload, error)
+	Chunks(ctx context.Context, uploadID string) ([]entities.UploadChunk, error)
+	AddChunk(ctx context.Context, uploadID string, userID int, chunk entities.UploadChunk) error
+	Delete(ctx context.Context, uploadID string, userID int) error
+}
+
+// Create starts the upload of the file, the uploads of the user left
+// for longer than uploadTTL are removed. The size of the upload is taken
+// from the quota until the upload is finished or removed.
+func (u upload) Create(ctx context.Context, bf models.BinaryFile) (models.Upload, error) {
+	userID := ctx.Value(types.UserIDKey).(int)
+	uploads, err := u.repo.FindAllByUserID(ctx, userID)
+	if err != nil {
+		return models.Upload{}, fmt.Errorf("get uploads err: %w", err)
+	}
+	stale := time.Now().Add(-uploadTTL)
+	for _, v := range uploads {
+		if v.UpdateAt.Before(stale) {
+			if err = u.remove(ctx, userID, v.ID); err != nil {
+				return models.Upload{}, fmt.Errorf("remove stale upload err: %w", err)
+			}
+		}
+	}
+
+	if err = u.service.Quota.checkFile(ctx, int64(bf.Size)); err != nil {
+		return models.Upload{}, err
+	}
+
+	id := make([]byte, 16)
+	if _, err = rand.Read(id); err != nil {
+		return models.Upload{}, fmt.Errorf("upload id err: %w", err)
+	}
+	info, err := json.Marshal(bf)
+	if err != nil {
+		return models.Upload{}, fmt.Errorf("upload info encode err: %w", err)
+	}
+	if info, err = u.service.BinaryFile.crypto.Encrypt(info); err != nil {
+		return models.Upload{}, fmt.Errorf("upload info encrypt err: %w", err)
+	}
+	up := entities.Upload{
+		ID:       hex.EncodeToString(id),
+		UserId:   userID,
+		FileName: bf.FileName,
+		Size:     bf.Size,
+		Info:     info,
+	}
+	if err = u.repo.Create(ctx, up); err != nil {
+		return models.Upload{}, fmt.Errorf("create upload err: %w", err)
+	}
+	return models.Upload{
+		ID:        up.ID,
+		File:      bf,
+		ChunkSize: models.UploadChunkSize,
+		Received:  []models.Range{},
+		CreatedAt: time.Now(),
+	}, nil
+}
+
+func (u upload) Uploads(ctx context.Context) ([]models.Upload, error) {
+	userID := ctx.Value(types.UserIDKey).(int)
+	uploads, err := u.repo.FindAllByUserID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("get uploads err: %w", err)
+	}
+	result := make([]models.Upload, len(uploads))
+	for i, v := range uploads {
+		if result[i], err = u.toModel(ctx, v); err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+func (u upload) Upload(ctx context.Context, uploadID string) (models.Upload, error) {
+	userID := ctx.Value(types.UserIDKey).(int)
+	up, err := u.repo.FindByIDAndUserID(ctx, uploadID, userID)
+	if err != nil {
+		return models.Upload{}, fmt.Errorf("get upload err: %w", err)
+	}
+	return u.toModel(ctx, up)
+}
+
+// WriteChunk keeps the chunk at the offset, the checksum is the SHA-256
+// of the chunk.
+func (u upload) WriteChunk(ctx context.Context, uploadID string, offset int, checksum, data []byte) error {
+	userID := ctx.Value(types.UserIDKey).(int)
+	up, err := u.repo.FindByIDAndUserID(ctx, uploadID, userID)
+	if err != nil {
+		return fmt.Errorf("get upload err: %w", err)
+	}
+	if len(data) == 0 || offset < 0 || offset+len(data) > up.Size {
+		return ErrUploadChunk
+	}
+	sum := sha256.Sum256(data)
+	if subtle.ConstantTimeCompare(sum[:], checksum) != 1 {
+		return ErrUploadChecksum
+	}
+
+	chunk := entities.UploadChunk{Start: offset, End: offset + len(data)}
+	if _, err = u.service.BinaryFile.blobs.Put(ctx, chunkKey(userID, uploadID, chunk), bytes.NewReader(data)); err != nil {
+		return fmt.Errorf("write chunk err: %w", err)
+	}
+	if err = u.repo.AddChunk(ctx, uploadID, userID, chunk); err != nil {
+		return fmt.Errorf("add chunk err: %w", err)
+	}
+	return nil
+}
+
+// Finish stores the uploaded file once every byte of it is received. The
+// upload is removed in the transaction that creates the file, so a finish
+// sent again or at the same time finds no upload and creates no second file.
+func (u upload) Finish(ctx context.Context, uploadID string) error {
+	userID := ctx.Value(types.UserIDKey).(int)
+	up, err := u.Upload(ctx, uploadID)
+	if err != nil {
+		return err
+	}
+	if len(up.Received) != 1 || up.Received[0] != (models.Range{Start: 0, End: up.File.Size}) {
+		return ErrUploadIncomplete
+	}
+
+	chunks, err := u.repo.Chunks(ctx, uploadID)
+	if err != nil {
+		return fmt.Errorf("get upload chunks err: %w", err)
+	}
+	part := &chunksReader{ctx: ctx, blobs: u.service.BinaryFile.blobs, userID: userID, uploadID: uploadID, chunks: chunks}
+	defer func() {
+		if err := part.Close(); err != nil {
+			u.service.BinaryFile.log.Errorf("close chunk err: %v", err)
+		}
+	}()
+	if err = u.service.BinaryFile.store(ctx, up.File, part, uploadID); err != nil {
+		return fmt.Errorf("store uploaded file err: %w", err)
+	}
+	u.removeChunks(ctx, userID, uploadID, chunks)
+	return nil
+}
+
+// Delete cancels the upload.
+func (u upload) Delete(ctx context.Context, uploadID string) error {
+	userID := ctx.Value(types.UserIDKey).(int)
+	return u.remove(ctx, userID, uploadID)
+}
+
+// remove deletes the upload and then its chunks, a chunk left by a failed
+// delete belongs to no upload and is not read again.
+func (u upload) remove(ctx context.Context, userID int, uploadID string) error {
+	chunks, err := u.repo.Chunks(ctx, uploadID)
+	if err != nil {
+		return fmt.Errorf("get upload chunks err: %w", err)
+	}
+	if err = u.repo.Delete(ctx, uploadID, userID); err != nil {
+		return fmt.Errorf("delete upload err: %w", err)
+	}
+	u.removeChunks(ctx, userID, uploadID, chunks)
+	return nil
+}
+
+// removeChunks deletes the chunks of the upload removed already.
+func (u upload) removeChunks(ctx context.Context, userID int, uploadID string, chunks []entities.UploadChunk) {
+	for _, v := range chunks {
+		if err := u.service.BinaryFile.blobs.Delete(ctx, chunkKey(userID, uploadID, v)); err != nil {
+			u.service.BinaryFile.log.Errorf("remove chunk err: %v", err)
+		}
+	}
+}
+
+func (u upload) toModel(ctx context.Context, up entities.Upload) (models.Upload, error) {
+	info, err := u.service.BinaryFile.crypto.Decrypt(up.Info)
+	if err != nil {
+		return models.Upload{}, fmt.Errorf("upload info decrypt err: %w", err)
+	}
+	var bf models.BinaryFile
+	if err = json.Unmarshal(info, &bf); err != nil {
+		return models.Upload{}, fmt.Errorf("upload info decode err: %w", err)
+	}
+	chunks, err := u.repo.Chunks(ctx, up.ID)
+	if err != nil {
+		return models.Upload{}, fmt.Errorf("get upload chunks err: %w", err)
+	}
+	return models.Upload{
+		ID:        up.ID,
+		File:      bf,
+		ChunkSize: models.UploadChunkSize,
+		Received:  mergeRanges(chunks),
+		CreatedAt: up.CreatedAt,
+	}, nil
+}
+
+func chunkKey(userID int, uploadID string, chunk entities.UploadChunk) string {
+	return fmt.Sprintf("%d/uploads/%s/%d-%d", userID, uploadID, chunk.Start, chunk.End)
+}
+
+// chunksReader reads the chunks ordered by start as the uploaded file,
+// the bytes a chunk shares with the chunks before it are skipped.
+type chunksReader struct {
+	ctx      context.Context
+	blobs    storage.BlobStore
+	userID   int
+	uploadID string
+	chunks   []entities.UploadChunk
+	pos      int
+	r        io.ReadCloser
+}
+
+func (c *chunksReader) Read(p []byte) (int, error) {
+	for c.r == nil {
+		for len(c.chunks) > 0 && c.chunks[0].End <= c.pos {
+			c.chunks = c.chunks[1:]
+		}
+		if len(c.chunks) == 0 {
+			return 0, io.EOF
+		}
+		chunk := c.chunks[0]
+		if chunk.Start > c.pos {
+			return 0, ErrUploadIncomplete
+		}
+		r, err := c.blobs.Get(c.ctx, chunkKey(c.userID, c.uploadID, chunk))
+		if err != nil {
+			return 0, fmt.Errorf("open chunk err: %w", err)
+		}
+		c.r = r
+		c.chunks = c.chunks[1:]
+		if _, err = io.CopyN(io.Discard, r, int64(c.pos-chunk.Start)); err != nil {
+			return 0, fmt.Errorf("read chunk err: %w", err)
+		}
+	}
+	n, err := c.r.Read(p)
+	c.pos += n
+	if errors.Is(err, io.EOF) {
+		err = c.r.Close()
+		c.r = nil
+	}
+	return n, err
+}
+
+func (c *chunksReader) Close() error {
+	if c.r == nil {
+		return nil
+	}
+	err := c.r.Close()
+	c.r = nil
+	return err
+}
+
+// mergeRanges joins the chunks ordered by start into the received ranges.
+func mergeRanges(chunks []entities.UploadChunk) []models.Range {
+	ranges := make([]models.Range, 0, len(chunks))
+	for _, v := range chunks {
+		if n := len(ranges); n > 0 && v.Start <= ranges[n-1].End {
+			if v.End > ranges[n-1].End {
+				ranges[n-1].End = v.End
+			}
+			continue
+		}
+		ranges = append(ranges, models.Range{Start: v.Start, End: v.End})
+	}
+	return ranges
+}
@@ -0,0 +1,156 @@
+package services
+
+import (
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/zelas91/goph-keeper/internal/logger"
+	"github.com/zelas91/goph-keeper/internal/server/models"
+	"github.com/zelas91/goph-keeper/internal/server/repository"
+	"github.com/zelas91/goph-keeper/internal/server/repository/entities"
+	mock "github.com/zelas91/goph-keeper/internal/server/services/mocks"
+	"github.com/zelas91/goph-keeper/internal/server/storage"
+	"github.com/zelas91/goph-keeper/internal/server/types"
+	compress2 "github.com/zelas91/goph-keeper/internal/utils/compress"
+	crypto2 "github.com/zelas91/goph-keeper/internal/utils/crypto"
+	"golang.org/x/net/context"
+)
+
+func TestMergeRanges(t *testing.T) {
+	tests := []struct {
+		name   string
+		chunks []entities.UploadChunk
+		want   []models.Range
+	}{
+		{name: "#1 ok nothing received", want: []models.Range{}},
+		{
+			name:   "#2 ok adjacent chunks join",
+			chunks: []entities.UploadChunk{{Start: 0, End: 10}, {Start: 10, End: 20}},
+			want:   []models.Range{{Start: 0, End: 20}},
+		},
+		{
+			name:   "#3 ok chunk sent again and a gap",
+			chunks: []entities.UploadChunk{{Start: 0, End: 10}, {Start: 0, End: 10}, {Start: 5, End: 12}, {Start: 20, End: 30}},
+			want:   []models.Range{{Start: 0, End: 12}, {Start: 20, End: 30}},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.want, mergeRanges(test.chunks))
+		})
+	}
+}
+
+func TestUploadResume(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	c, err := crypto2.NewCrypto("0123456789abcdef0123456789abcdef")
+	assert.NoError(t, err)
+	data := []byte("a file sent in chunks that come in any order")
+	bf := models.BinaryFile{FileName: "notes.txt", Size: len(data)}
+	info, err := json.Marshal(bf)
+	assert.NoError(t, err)
+	info, err = c.Encrypt(info)
+	assert.NoError(t, err)
+
+	uploadRepo := mock.NewMockuploadRepo(ctrl)
+	fileRepo := mock.NewMockbinaryFileRepo(ctrl)
+	basePath := t.TempDir()
+	s := New(WithBinaryFileUseRepository(fileRepo, c, logger.New(""), storage.NewLocal(basePath), compress2.Gzip), WithUploadUseRepository(uploadRepo))
+	ctx := context.WithValue(context.Background(), types.UserIDKey, 1)
+
+	uploadRepo.EXPECT().FindByIDAndUserID(gomock.Any(), "up", 1).
+		Return(entities.Upload{ID: "up", UserId: 1, FileName: bf.FileName, Size: bf.Size, Info: info}, nil).AnyTimes()
+	uploadRepo.EXPECT().AddChunk(gomock.Any(), "up", 1, gomock.Any()).Return(nil).Times(3)
+
+	half := len(data) / 2
+	sum := sha256.Sum256(data[half:])
+	assert.NoError(t, s.Upload.WriteChunk(ctx, "up", half, sum[:], data[half:]))
+	assert.ErrorIs(t, s.Upload.WriteChunk(ctx, "up", 0, sum[:], data[:half]), ErrUploadChecksum)
+	assert.ErrorIs(t, s.Upload.WriteChunk(ctx, "up", half+1, sum[:], data[half:]), ErrUploadChunk)
+
+	uploadRepo.EXPECT().Chunks(gomock.Any(), "up").
+		Return([]entities.UploadChunk{{Start: half, End: len(data)}}, nil)
+	assert.ErrorIs(t, s.Upload.Finish(ctx, "up"), ErrUploadIncomplete)
+
+	sum = sha256.Sum256(data[:half])
+	assert.NoError(t, s.Upload.WriteChunk(ctx, "up", 0, sum[:], data[:half]))
+	sum = sha256.Sum256(data[1 : half+2])
+	assert.NoError(t, s.Upload.WriteChunk(ctx, "up", 1, sum[:], data[1:half+2]))
+
+	uploadRepo.EXPECT().Chunks(gomock.Any(), "up").
+		Return([]entities.UploadChunk{{Start: 0, End: half}, {Start: 1, End: half + 2}, {Start: half, End: len(data)}}, nil).
+		AnyTimes()
+	events, cancel := s.Events.Subscribe(ctx)
+	defer cancel()
+	var key string
+	fileRepo.EXPECT().Create(gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, ef entities.BinaryFile, size int64) (int, string, error) {
+			assert.Equal(t, "up", ef.UploadID, "the upload is claimed with the file")
+			key = ef.Path
+			return createBlob(ctx, ef, size)
+		})
+	assert.NoError(t, s.Upload.Finish(ctx, "up"))
+	assert.Equal(t, models.Event{Action: models.EventCreated, ItemType: types.ItemFile, ItemID: 1}, <-events,
+		"the created event carries the id of the file")
+
+	file, err := os.Open(filepath.Join(basePath, filepath.FromSlash(key)))
+	assert.NoError(t, err)
+	defer file.Close()
+	gz, err := gzip.NewReader(file)
+	assert.NoError(t, err)
+	stored, err := io.ReadAll(gz)
+	assert.NoError(t, err)
+	assert.Equal(t, data, stored)
+	chunks, err := os.ReadDir(filepath.Join(basePath, "1", "uploads", "up"))
+	assert.NoError(t, err)
+	assert.Empty(t, chunks, "the chunks are removed")
+}
+
+func TestUploadFinishAgain(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	c, err := crypto2.NewCrypto("0123456789abcdef0123456789abcdef")
+	assert.NoError(t, err)
+	data := []byte("a file finished twice")
+	bf := models.BinaryFile{FileName: "notes.txt", Size: len(data)}
+	info, err := json.Marshal(bf)
+	assert.NoError(t, err)
+	info, err = c.Encrypt(info)
+	assert.NoError(t, err)
+
+	uploadRepo := mock.NewMockuploadRepo(ctrl)
+	fileRepo := mock.NewMockbinaryFileRepo(ctrl)
+	basePath := t.TempDir()
+	s := New(WithBinaryFileUseRepository(fileRepo, c, logger.New(""), storage.NewLocal(basePath), compress2.Gzip), WithUploadUseRepository(uploadRepo))
+	ctx := context.WithValue(context.Background(), types.UserIDKey, 1)
+
+	uploadRepo.EXPECT().FindByIDAndUserID(gomock.Any(), "up", 1).
+		Return(entities.Upload{ID: "up", UserId: 1, FileName: bf.FileName, Size: bf.Size, Info: info}, nil)
+	uploadRepo.EXPECT().AddChunk(gomock.Any(), "up", 1, gomock.Any()).Return(nil)
+	sum := sha256.Sum256(data)
+	assert.NoError(t, s.Upload.WriteChunk(ctx, "up", 0, sum[:], data))
+
+	// the other finish has created the file and removed the upload
+	// between the read of the upload and the creation of the file.
+	uploadRepo.EXPECT().FindByIDAndUserID(gomock.Any(), "up", 1).
+		Return(entities.Upload{ID: "up", UserId: 1, FileName: bf.FileName, Size: bf.Size, Info: info}, nil)
+	uploadRepo.EXPECT().Chunks(gomock.Any(), "up").
+		Return([]entities.UploadChunk{{Start: 0, End: len(data)}}, nil).Times(2)
+	fileRepo.EXPECT().Create(gomock.Any(), gomock.Any(), gomock.Any()).Return(0, "", repository.ErrNotFound)
+	assert.ErrorIs(t, s.Upload.Finish(ctx, "up"), repository.ErrNotFound)
+
+	assert.Empty(t, blobs(t, basePath, 1), "the blob of the second file is removed")
+	chunks, err := os.ReadDir(filepath.Join(basePath, "1", "uploads", "up"))
+	assert.NoError(t, err)
+	assert.Len(t, chunks, 1, "the chunks are left to the finish that created the file")
+}
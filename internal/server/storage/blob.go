@@ -0,0 +1,32 @@
+// Package storage keeps the content of the files as blobs, on the local
+// disk or in an S3-compatible object storage.
+package storage
+
+import (
+	"errors"
+	"io"
+	"time"
+
+	"golang.org/x/net/context"
+)
+
+var ErrNotFound = errors.New("blob not found")
+
+// BlobInfo describes the stored blob.
+type BlobInfo struct {
+	Key     string
+	Size    int64
+	ModTime time.Time
+}
+
+// BlobStore keeps the blobs by key, the keys are slash separated paths.
+// Put reads the blob to the end and stores it whole or not at all, Delete
+// of a missing blob is not an error. List calls fn for every blob with
+// the key starting with prefix and stops at the first error of fn.
+type BlobStore interface {
+	Put(ctx context.Context, key string, r io.Reader) (int64, error)
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	Delete(ctx context.Context, key string) error
+	Stat(ctx context.Context, key string) (BlobInfo, error)
+	List(ctx context.Context, prefix string, fn func(BlobInfo) error) error
+}
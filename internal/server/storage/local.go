@@ -0,0 +1,146 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/net/context"
+)
+
+// Local keeps the blobs as files under the directory.
+type Local struct {
+	dir string
+}
+
+func NewLocal(dir string) *Local {
+	return &Local{dir: filepath.Clean(dir)}
+}
+
+// path maps the key to the file. The files stored before the blob store
+// kept the directory in their path, such keys are taken as they are.
+func (l *Local) path(key string) (string, error) {
+	path := filepath.Clean(filepath.FromSlash(key))
+	if strings.HasPrefix(path, l.dir+string(filepath.Separator)) {
+		return path, nil
+	}
+	if filepath.IsAbs(path) || path == "." || path == ".." ||
+		strings.HasPrefix(path, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("storage: invalid key %q", key)
+	}
+	return filepath.Join(l.dir, path), nil
+}
+
+// Put writes the blob to a temporary file and renames it, a failed
+// write leaves the old blob if there was one.
+func (l *Local) Put(_ context.Context, key string, r io.Reader) (int64, error) {
+	path, err := l.path(key)
+	if err != nil {
+		return 0, err
+	}
+	if err = os.MkdirAll(filepath.Dir(path), os.ModePerm); err != nil {
+		return 0, fmt.Errorf("storage: create dir err: %w", err)
+	}
+	file, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*")
+	if err != nil {
+		return 0, fmt.Errorf("storage: create file err: %w", err)
+	}
+	n, err := io.Copy(file, r)
+	if closeErr := file.Close(); err == nil && closeErr != nil {
+		err = fmt.Errorf("storage: close file err: %w", closeErr)
+	}
+	if err == nil {
+		if err = os.Rename(file.Name(), path); err != nil {
+			err = fmt.Errorf("storage: rename file err: %w", err)
+		}
+	}
+	if err != nil {
+		_ = os.Remove(file.Name())
+		return 0, err
+	}
+	return n, nil
+}
+
+func (l *Local) Get(_ context.Context, key string) (io.ReadCloser, error) {
+	path, err := l.path(key)
+	if err != nil {
+		return nil, err
+	}
+	file, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("storage: open file err: %w", err)
+	}
+	return file, nil
+}
+
+func (l *Local) Delete(_ context.Context, key string) error {
+	path, err := l.path(key)
+	if err != nil {
+		return err
+	}
+	if err = os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("storage: remove file err: %w", err)
+	}
+	return nil
+}
+
+func (l *Local) Stat(_ context.Context, key string) (BlobInfo, error) {
+	path, err := l.path(key)
+	if err != nil {
+		return BlobInfo{}, err
+	}
+	info, err := os.Stat(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return BlobInfo{}, ErrNotFound
+	}
+	if err != nil {
+		return BlobInfo{}, fmt.Errorf("storage: stat file err: %w", err)
+	}
+	return BlobInfo{Key: key, Size: info.Size(), ModTime: info.ModTime()}, nil
+}
+
+// List walks the directory, the files written before the blob store are
+// listed with keys relative to the directory as well.
+func (l *Local) List(ctx context.Context, prefix string, fn func(BlobInfo) error) error {
+	err := filepath.WalkDir(l.dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				return nil
+			}
+			return err
+		}
+		if err = ctx.Err(); err != nil {
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+		rel, err := filepath.Rel(l.dir, path)
+		if err != nil {
+			return err
+		}
+		key := filepath.ToSlash(rel)
+		if !strings.HasPrefix(key, prefix) {
+			return nil
+		}
+		info, err := d.Info()
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		return fn(BlobInfo{Key: key, Size: info.Size(), ModTime: info.ModTime()})
+	})
+	if err != nil {
+		return fmt.Errorf("storage: list err: %w", err)
+	}
+	return nil
+}
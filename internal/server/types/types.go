@@ -4,4 +4,23 @@ type ContextKey string
 
 const (
 	UserIDKey = ContextKey("userID")
+	// ClientIDKey is the client the request came from, the change events
+	// are not sent back to the client that made the change.
+	ClientIDKey = ContextKey("clientID")
+)
+
+// Item types of the vault, used wherever an item of any kind is referenced.
+const (
+	ItemCard       = "card"
+	ItemCredential = "credential"
+	ItemText       = "text"
+	ItemFile       = "file"
+	ItemOTP        = "otp"
+	ItemSSHKey     = "ssh_key"
+)
+
+// Types of the vault objects that are not items, used in the change events.
+const (
+	Folder = "folder"
+	Tag    = "tag"
 )
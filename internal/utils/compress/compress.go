@@ -2,67 +2,188 @@ package compress
 
 import (
 	"compress/gzip"
+	"fmt"
+	"io"
+	"math"
 	"sync"
 
+	"github.com/klauspost/compress/zstd"
 	"github.com/zelas91/goph-keeper/internal/logger"
 )
 
+// Codec is the compression of a stored file.
+type Codec string
+
+const (
+	None Codec = "none"
+	Gzip Codec = "gzip"
+	Zstd Codec = "zstd"
+)
+
+// SampleSize is the size of the start of the content Incompressible looks at.
+const SampleSize = 64 << 10
+
+// maxEntropy is the entropy in bits per byte above which the content is taken
+// as compressed or encrypted already.
+const maxEntropy = 7.5
+
+func ParseCodec(s string) (Codec, error) {
+	switch c := Codec(s); c {
+	case None, Gzip, Zstd:
+		return c, nil
+	}
+	return "", fmt.Errorf("unknown codec %q", s)
+}
+
+// Incompressible tells from the Shannon entropy of the sample that the
+// content will not get smaller, a short sample is never skipped.
+func Incompressible(sample []byte) bool {
+	if len(sample) < 512 {
+		return false
+	}
+	var counts [256]int
+	for _, v := range sample {
+		counts[v]++
+	}
+	var entropy float64
+	for _, c := range counts {
+		if c == 0 {
+			continue
+		}
+		p := float64(c) / float64(len(sample))
+		entropy -= p * math.Log2(p)
+	}
+	return entropy > maxEntropy
+}
+
 type Compress struct {
-	compress sync.Pool
-	log      logger.Logger
+	gzip sync.Pool
+	zstd sync.Pool
+	log  logger.Logger
 }
 
 func NewCompress(log logger.Logger) *Compress {
-	return &Compress{compress: sync.Pool{New: func() any {
-		writer, err := gzip.NewWriterLevel(nil, gzip.BestCompression)
-		if err != nil {
+	return &Compress{
+		gzip: sync.Pool{New: func() any {
 			return gzip.NewWriter(nil)
-		}
-		return writer
-	}},
-		log: log}
+		}},
+		zstd: sync.Pool{New: func() any {
+			writer, err := zstd.NewWriter(nil, zstd.WithEncoderConcurrency(1))
+			if err != nil {
+				log.Errorf("Failed to create zstd writer err: %v", err)
+				return nil
+			}
+			return writer
+		}},
+		log: log,
+	}
 }
 
-func (c *Compress) Writer() *gzip.Writer {
-	if v := c.compress.Get(); v != nil {
-		return v.(*gzip.Writer)
+// Writer compresses into w with the codec, the close of the writer flushes
+// the content and puts the writer back to the pool.
+func (c *Compress) Writer(codec Codec, w io.Writer) (io.WriteCloser, error) {
+	switch codec {
+	case None:
+		return nopWriteCloser{w}, nil
+	case Gzip:
+		writer := c.gzip.Get().(*gzip.Writer)
+		writer.Reset(w)
+		return &pooledWriter{WriteCloser: writer, release: func() {
+			c.gzip.Put(writer)
+		}}, nil
+	case Zstd:
+		writer, ok := c.zstd.Get().(*zstd.Encoder)
+		if !ok {
+			return nil, fmt.Errorf("zstd writer is not available")
+		}
+		writer.Reset(w)
+		return &pooledWriter{WriteCloser: writer, release: func() {
+			c.zstd.Put(writer)
+		}}, nil
 	}
-	writer, err := gzip.NewWriterLevel(nil, gzip.BestCompression)
-	if err != nil {
-		c.log.Errorf("Failed to create gzip writer err: %v", err)
+	return nil, fmt.Errorf("unknown codec %q", codec)
+}
+
+type pooledWriter struct {
+	io.WriteCloser
+	release func()
+}
+
+func (w *pooledWriter) Close() error {
+	if w.release == nil {
 		return nil
 	}
+	err := w.WriteCloser.Close()
+	w.release()
+	w.release = nil
+	return err
+}
 
-	return writer
+type nopWriteCloser struct {
+	io.Writer
 }
 
-func (c *Compress) Release(writer *gzip.Writer) {
-	defer func(w *gzip.Writer) {
-		if err := writer.Close(); err != nil {
-			c.log.Errorf("Failed to close gzip writer:", err)
-			return
-		}
-	}(writer)
-	c.compress.Put(writer)
+func (nopWriteCloser) Close() error {
+	return nil
 }
 
 type Decompress struct {
-	decompress sync.Pool
+	gzip sync.Pool
+	zstd sync.Pool
 }
 
 func NewDecompress() *Decompress {
-	return &Decompress{decompress: sync.Pool{}}
+	return &Decompress{}
 }
 
-func (d *Decompress) Reader() *gzip.Reader {
-	reader := d.decompress.Get()
-	if reader == nil {
-		return &gzip.Reader{}
+// Reader decompresses r with the codec, the files stored before the codec
+// was recorded are gzip. The close of the reader puts it back to the pool
+// and does not close r.
+func (d *Decompress) Reader(codec Codec, r io.Reader) (io.ReadCloser, error) {
+	switch codec {
+	case None:
+		return io.NopCloser(r), nil
+	case Gzip, "":
+		reader, ok := d.gzip.Get().(*gzip.Reader)
+		if !ok {
+			reader = &gzip.Reader{}
+		}
+		if err := reader.Reset(r); err != nil {
+			d.gzip.Put(reader)
+			return nil, fmt.Errorf("gzip reader reset err: %w", err)
+		}
+		return &pooledReader{Reader: reader, release: func() {
+			d.gzip.Put(reader)
+		}}, nil
+	case Zstd:
+		reader, ok := d.zstd.Get().(*zstd.Decoder)
+		if !ok {
+			var err error
+			if reader, err = zstd.NewReader(nil, zstd.WithDecoderConcurrency(1)); err != nil {
+				return nil, fmt.Errorf("zstd reader err: %w", err)
+			}
+		}
+		if err := reader.Reset(r); err != nil {
+			d.zstd.Put(reader)
+			return nil, fmt.Errorf("zstd reader reset err: %w", err)
+		}
+		return &pooledReader{Reader: reader, release: func() {
+			_ = reader.Reset(nil)
+			d.zstd.Put(reader)
+		}}, nil
 	}
+	return nil, fmt.Errorf("unknown codec %q", codec)
+}
 
-	return reader.(*gzip.Reader)
+type pooledReader struct {
+	io.Reader
+	release func()
 }
 
-func (d *Decompress) Release(reader *gzip.Reader) {
-	d.decompress.Put(reader)
+func (r *pooledReader) Close() error {
+	if r.release != nil {
+		r.release()
+		r.release = nil
+	}
+	return nil
 }
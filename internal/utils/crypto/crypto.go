@@ -3,12 +3,15 @@ package crypto
 import (
 	"crypto/aes"
 	"crypto/cipher"
+	"crypto/hmac"
 	"crypto/rand"
+	"crypto/sha256"
 	"fmt"
 )
 
 type Crypto struct {
-	gcm cipher.AEAD
+	gcm     cipher.AEAD
+	hashKey []byte
 }
 
 func NewCrypto(secretKey string) (*Crypto, error) {
@@ -21,8 +24,11 @@ func NewCrypto(secretKey string) (*Crypto, error) {
 	if err != nil {
 		return nil, err
 	}
+	mac := hmac.New(sha256.New, []byte(secretKey))
+	mac.Write([]byte("blind index"))
 	return &Crypto{
-		gcm: gcm,
+		gcm:     gcm,
+		hashKey: mac.Sum(nil),
 	}, nil
 }
 
@@ -44,3 +50,11 @@ func (c *Crypto) Decrypt(data []byte) ([]byte, error) {
 	}
 	return result, err
 }
+
+// Hash is a keyed hash of data used as a blind index: equal data gives
+// equal hashes that can be searched without decrypting the storage.
+func (c *Crypto) Hash(data []byte) []byte {
+	mac := hmac.New(sha256.New, c.hashKey)
+	mac.Write(data)
+	return mac.Sum(nil)
+}
@@ -0,0 +1,223 @@
+// Package otp reads the otpauth:// keys of the authenticator apps and makes
+// their one-time codes: HOTP of RFC 4226 and TOTP of RFC 6238.
+package otp
+
+import (
+	"crypto/hmac"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base32"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	TypeTOTP = "totp"
+	TypeHOTP = "hotp"
+
+	AlgorithmSHA1   = "SHA1"
+	AlgorithmSHA256 = "SHA256"
+	AlgorithmSHA512 = "SHA512"
+
+	DefaultDigits = 6
+	DefaultPeriod = 30
+)
+
+var (
+	ErrURI       = errors.New("otp: not an otpauth:// key")
+	ErrSecret    = errors.New("otp: the secret is not base32")
+	ErrAlgorithm = errors.New("otp: unknown algorithm")
+)
+
+// Key is the seed of an authenticator. Counter is the counter of the next
+// HOTP code, Period the time step of TOTP in seconds.
+type Key struct {
+	Type      string
+	Issuer    string
+	Account   string
+	Secret    string
+	Algorithm string
+	Digits    int
+	Period    int
+	Counter   int64
+}
+
+// Parse reads the key of the otpauth://<type>/<issuer>:<account>?secret=...
+// URI, the parameters not given take the defaults of the authenticators.
+func Parse(uri string) (Key, error) {
+	u, err := url.Parse(strings.TrimSpace(uri))
+	if err != nil || u.Scheme != "otpauth" {
+		return Key{}, ErrURI
+	}
+	key := Key{Type: strings.ToLower(u.Host)}
+	if key.Type != TypeTOTP && key.Type != TypeHOTP {
+		return Key{}, ErrURI
+	}
+	label := strings.TrimPrefix(u.Path, "/")
+	if issuer, account, ok := strings.Cut(label, ":"); ok {
+		key.Issuer, key.Account = strings.TrimSpace(issuer), strings.TrimSpace(account)
+	} else {
+		key.Account = label
+	}
+	query := u.Query()
+	key.Secret = query.Get("secret")
+	if issuer := query.Get("issuer"); issuer != "" {
+		key.Issuer = issuer
+	}
+	key.Algorithm = strings.ToUpper(query.Get("algorithm"))
+	if key.Digits, err = intParam(query, "digits"); err != nil {
+		return Key{}, err
+	}
+	if key.Period, err = intParam(query, "period"); err != nil {
+		return Key{}, err
+	}
+	counter, err := intParam(query, "counter")
+	if err != nil {
+		return Key{}, err
+	}
+	key.Counter = int64(counter)
+	key = key.WithDefaults()
+	if _, err = key.secret(); err != nil {
+		return Key{}, err
+	}
+	return key, nil
+}
+
+func intParam(query url.Values, name string) (int, error) {
+	v := query.Get(name)
+	if v == "" {
+		return 0, nil
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("%w: %s=%s", ErrURI, name, v)
+	}
+	return n, nil
+}
+
+// WithDefaults returns the key with the parameters not set taking the
+// defaults of the authenticators.
+func (k Key) WithDefaults() Key {
+	if k.Type == "" {
+		k.Type = TypeTOTP
+	}
+	if k.Algorithm == "" {
+		k.Algorithm = AlgorithmSHA1
+	}
+	if k.Digits == 0 {
+		k.Digits = DefaultDigits
+	}
+	if k.Period == 0 && k.Type == TypeTOTP {
+		k.Period = DefaultPeriod
+	}
+	return k
+}
+
+// URI returns the otpauth:// URI of the key for the authenticator apps.
+func (k Key) URI() string {
+	label := k.Account
+	if k.Issuer != "" {
+		label = k.Issuer + ":" + k.Account
+	}
+	query := url.Values{}
+	query.Set("secret", k.Secret)
+	if k.Issuer != "" {
+		query.Set("issuer", k.Issuer)
+	}
+	query.Set("algorithm", k.Algorithm)
+	query.Set("digits", strconv.Itoa(k.Digits))
+	if k.Type == TypeHOTP {
+		query.Set("counter", strconv.FormatInt(k.Counter, 10))
+	} else {
+		query.Set("period", strconv.Itoa(k.Period))
+	}
+	u := url.URL{Scheme: "otpauth", Host: k.Type, Path: "/" + label, RawQuery: query.Encode()}
+	return u.String()
+}
+
+// Validate tells whether the codes of the key can be made.
+func (k Key) Validate() error {
+	if _, err := k.secret(); err != nil {
+		return err
+	}
+	if _, err := k.hash(); err != nil {
+		return err
+	}
+	return nil
+}
+
+// Code returns the TOTP code of the time t or the HOTP code of the counter.
+func (k Key) Code(t time.Time) (string, error) {
+	counter := uint64(k.Counter)
+	if k.Type != TypeHOTP {
+		counter = uint64(t.Unix() / int64(k.period()))
+	}
+	secret, err := k.secret()
+	if err != nil {
+		return "", err
+	}
+	h, err := k.hash()
+	if err != nil {
+		return "", err
+	}
+	mac := hmac.New(h, secret)
+	if err = binary.Write(mac, binary.BigEndian, counter); err != nil {
+		return "", fmt.Errorf("otp: hmac err: %w", err)
+	}
+	sum := mac.Sum(nil)
+	offset := sum[len(sum)-1] & 0x0f
+	value := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+	digits := k.Digits
+	if digits == 0 {
+		digits = DefaultDigits
+	}
+	mod := uint32(1)
+	for i := 0; i < digits; i++ {
+		mod *= 10
+	}
+	return fmt.Sprintf("%0*d", digits, value%mod), nil
+}
+
+// Remaining returns how long the TOTP code of the time t stays valid.
+func (k Key) Remaining(t time.Time) time.Duration {
+	period := int64(k.period())
+	return time.Duration(period-t.Unix()%period) * time.Second
+}
+
+func (k Key) period() int {
+	if k.Period <= 0 {
+		return DefaultPeriod
+	}
+	return k.Period
+}
+
+// secret decodes the secret, the authenticators write it in upper or lower
+// case with spaces and without the padding.
+func (k Key) secret() ([]byte, error) {
+	cleaned := strings.ToUpper(strings.TrimRight(strings.ReplaceAll(k.Secret, " ", ""), "="))
+	secret, err := base32.StdEncoding.WithPadding(base32.NoPadding).DecodeString(cleaned)
+	if err != nil || len(secret) == 0 {
+		return nil, ErrSecret
+	}
+	return secret, nil
+}
+
+func (k Key) hash() (func() hash.Hash, error) {
+	switch strings.ToUpper(k.Algorithm) {
+	case "", AlgorithmSHA1:
+		return sha1.New, nil
+	case AlgorithmSHA256:
+		return sha256.New, nil
+	case AlgorithmSHA512:
+		return sha512.New, nil
+	default:
+		return nil, ErrAlgorithm
+	}
+}
@@ -0,0 +1,64 @@
+package otp
+
+import (
+	"encoding/base32"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCode(t *testing.T) {
+	// the test vectors of RFC 6238, the seeds are 20, 32 and 64 bytes long.
+	seed := "1234567890"
+	secret := func(n int) string {
+		s := ""
+		for len(s) < n {
+			s += seed
+		}
+		return base32.StdEncoding.EncodeToString([]byte(s[:n]))
+	}
+	tests := []struct {
+		algorithm string
+		secret    string
+		unix      int64
+		want      string
+	}{
+		{algorithm: AlgorithmSHA1, secret: secret(20), unix: 59, want: "94287082"},
+		{algorithm: AlgorithmSHA256, secret: secret(32), unix: 59, want: "46119246"},
+		{algorithm: AlgorithmSHA512, secret: secret(64), unix: 59, want: "90693936"},
+		{algorithm: AlgorithmSHA1, secret: secret(20), unix: 1111111109, want: "07081804"},
+		{algorithm: AlgorithmSHA1, secret: secret(20), unix: 20000000000, want: "65353130"},
+	}
+	for _, test := range tests {
+		t.Run(test.want, func(t *testing.T) {
+			key := Key{Type: TypeTOTP, Secret: test.secret, Algorithm: test.algorithm, Digits: 8, Period: 30}
+			code, err := key.Code(time.Unix(test.unix, 0))
+			assert.NoError(t, err)
+			assert.Equal(t, test.want, code)
+		})
+	}
+
+	// the first codes of RFC 4226.
+	key := Key{Type: TypeHOTP, Secret: secret(20), Counter: 1}.WithDefaults()
+	code, err := key.Code(time.Now())
+	assert.NoError(t, err)
+	assert.Equal(t, "287082", code)
+}
+
+func TestParse(t *testing.T) {
+	key, err := Parse("otpauth://totp/ACME%20Co:john@example.com?secret=jbsw%20y3dpehpk3pxp&issuer=ACME%20Co&period=60")
+	assert.NoError(t, err)
+	assert.Equal(t, Key{Type: TypeTOTP, Issuer: "ACME Co", Account: "john@example.com", Secret: "jbsw y3dpehpk3pxp",
+		Algorithm: AlgorithmSHA1, Digits: 6, Period: 60}, key)
+
+	again, err := Parse(key.URI())
+	assert.NoError(t, err)
+	assert.Equal(t, key, again)
+
+	_, err = Parse("https://example.com/?secret=JBSWY3DPEHPK3PXP")
+	assert.ErrorIs(t, err, ErrURI)
+	_, err = Parse("otpauth://totp/x?secret=1")
+	assert.ErrorIs(t, err, ErrSecret)
+	assert.Equal(t, 15*time.Second, key.Remaining(time.Unix(45, 0)))
+}
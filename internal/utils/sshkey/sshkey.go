@@ -0,0 +1,88 @@
+// Package sshkey reads the SSH private keys and certificates kept in the
+// vault: the key has to parse and the public key and its fingerprint are
+// taken from it, never from the user.
+package sshkey
+
+import (
+	"bytes"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/ssh"
+)
+
+var (
+	ErrKey         = errors.New("sshkey: not an SSH private key")
+	ErrPassphrase  = errors.New("sshkey: wrong passphrase of the key")
+	ErrCertificate = errors.New("sshkey: not a certificate of the key")
+)
+
+// Key is what the private key tells about itself. PublicKey is in the
+// authorized_keys format with the comment, Fingerprint is the SHA256 one
+// ssh-keygen -l prints.
+type Key struct {
+	Type        string
+	PublicKey   string
+	Fingerprint string
+}
+
+// Parse reads the PEM or OpenSSH private key, an encrypted key opens with
+// the passphrase only.
+func Parse(privateKey, passphrase, comment string) (Key, error) {
+	var (
+		raw any
+		err error
+	)
+	if passphrase == "" {
+		raw, err = ssh.ParseRawPrivateKey([]byte(privateKey))
+	} else {
+		raw, err = ssh.ParseRawPrivateKeyWithPassphrase([]byte(privateKey), []byte(passphrase))
+	}
+	var missing *ssh.PassphraseMissingError
+	switch {
+	case errors.As(err, &missing), errors.Is(err, x509.IncorrectPasswordError):
+		return Key{}, ErrPassphrase
+	case err != nil:
+		return Key{}, fmt.Errorf("%w: %v", ErrKey, err)
+	}
+	signer, err := ssh.NewSignerFromKey(raw)
+	if err != nil {
+		return Key{}, fmt.Errorf("%w: %v", ErrKey, err)
+	}
+	public := signer.PublicKey()
+	authorized := strings.TrimSpace(string(ssh.MarshalAuthorizedKey(public)))
+	if comment = strings.TrimSpace(comment); comment != "" {
+		authorized += " " + comment
+	}
+	return Key{Type: public.Type(), PublicKey: authorized, Fingerprint: ssh.FingerprintSHA256(public)}, nil
+}
+
+// CheckCertificate tells whether the certificate in the authorized_keys
+// format is made for the public key.
+func CheckCertificate(certificate, publicKey string) error {
+	parsed, _, _, _, err := ssh.ParseAuthorizedKey([]byte(certificate))
+	if err != nil {
+		return ErrCertificate
+	}
+	cert, ok := parsed.(*ssh.Certificate)
+	if !ok {
+		return ErrCertificate
+	}
+	public, _, _, _, err := ssh.ParseAuthorizedKey([]byte(publicKey))
+	if err != nil || !bytes.Equal(cert.Key.Marshal(), public.Marshal()) {
+		return ErrCertificate
+	}
+	return nil
+}
+
+// Comment returns the comment of the public key in the authorized_keys
+// format, like the one of a .pub file next to the private key.
+func Comment(publicKey string) string {
+	_, comment, _, _, err := ssh.ParseAuthorizedKey([]byte(publicKey))
+	if err != nil {
+		return ""
+	}
+	return comment
+}
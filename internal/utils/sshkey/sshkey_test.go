@@ -0,0 +1,74 @@
+package sshkey
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/pem"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/ssh"
+)
+
+func TestParse(t *testing.T) {
+	public, private, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	sshPublic, err := ssh.NewPublicKey(public)
+	require.NoError(t, err)
+	plain, err := ssh.MarshalPrivateKey(private, "john@host")
+	require.NoError(t, err)
+	encrypted, err := ssh.MarshalPrivateKeyWithPassphrase(private, "john@host", []byte("secret"))
+	require.NoError(t, err)
+
+	tests := []struct {
+		name       string
+		key        string
+		passphrase string
+		wantErr    error
+	}{
+		{name: "#1 ok plain", key: string(pem.EncodeToMemory(plain))},
+		{name: "#2 ok encrypted", key: string(pem.EncodeToMemory(encrypted)), passphrase: "secret"},
+		{name: "#3 nok no passphrase", key: string(pem.EncodeToMemory(encrypted)), wantErr: ErrPassphrase},
+		{name: "#4 nok wrong passphrase", key: string(pem.EncodeToMemory(encrypted)), passphrase: "guess",
+			wantErr: ErrPassphrase},
+		{name: "#5 nok not a key", key: "ssh-ed25519 AAAA", wantErr: ErrKey},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			key, err := Parse(test.key, test.passphrase, "john@host")
+			if test.wantErr != nil {
+				assert.ErrorIs(t, err, test.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, ssh.KeyAlgoED25519, key.Type)
+			assert.Equal(t, ssh.FingerprintSHA256(sshPublic), key.Fingerprint)
+			assert.True(t, strings.HasSuffix(key.PublicKey, " john@host"))
+			assert.Equal(t, "john@host", Comment(key.PublicKey))
+		})
+	}
+}
+
+func TestCheckCertificate(t *testing.T) {
+	newSigner := func() ssh.Signer {
+		_, private, err := ed25519.GenerateKey(rand.Reader)
+		require.NoError(t, err)
+		signer, err := ssh.NewSignerFromKey(private)
+		require.NoError(t, err)
+		return signer
+	}
+	ca, user, other := newSigner(), newSigner(), newSigner()
+	cert := &ssh.Certificate{Key: user.PublicKey(), CertType: ssh.UserCert, KeyId: "john",
+		ValidBefore: uint64(time.Now().Add(time.Hour).Unix())}
+	require.NoError(t, cert.SignCert(rand.Reader, ca))
+	certificate := string(ssh.MarshalAuthorizedKey(cert))
+
+	assert.NoError(t, CheckCertificate(certificate, string(ssh.MarshalAuthorizedKey(user.PublicKey()))))
+	assert.ErrorIs(t, CheckCertificate(certificate, string(ssh.MarshalAuthorizedKey(other.PublicKey()))),
+		ErrCertificate)
+	assert.ErrorIs(t, CheckCertificate(string(ssh.MarshalAuthorizedKey(user.PublicKey())),
+		string(ssh.MarshalAuthorizedKey(user.PublicKey()))), ErrCertificate)
+}
@@ -0,0 +1,19 @@
+alter table cards
+    drop column title,
+    drop column note,
+    drop column metadata;
+
+alter table user_credentials
+    drop column title,
+    drop column note,
+    drop column metadata;
+
+alter table text_data
+    drop column title,
+    drop column note,
+    drop column metadata;
+
+alter table binary_file
+    drop column title,
+    drop column note,
+    drop column metadata;
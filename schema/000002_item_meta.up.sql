@@ -0,0 +1,19 @@
+alter table cards
+    add column title    bytea,
+    add column note     bytea,
+    add column metadata bytea;
+
+alter table user_credentials
+    add column title    bytea,
+    add column note     bytea,
+    add column metadata bytea;
+
+alter table text_data
+    add column title    bytea,
+    add column note     bytea,
+    add column metadata bytea;
+
+alter table binary_file
+    add column title    bytea,
+    add column note     bytea,
+    add column metadata bytea;
@@ -0,0 +1,15 @@
+alter table cards
+    drop column folder_id;
+
+alter table user_credentials
+    drop column folder_id;
+
+alter table text_data
+    drop column folder_id;
+
+alter table binary_file
+    drop column folder_id;
+
+drop table item_tags;
+drop table tags;
+drop table folders;
@@ -0,0 +1,39 @@
+create table folders
+(
+    id         bigserial not null unique primary key,
+    user_id    int references users (id) not null,
+    parent_id  int references folders (id) on delete cascade,
+    name       varchar not null,
+    created_at timestamp not null default now()
+);
+
+create table tags
+(
+    id         bigserial not null unique primary key,
+    user_id    int references users (id) not null,
+    name       varchar not null,
+    created_at timestamp not null default now(),
+    unique (user_id, name)
+);
+
+create table item_tags
+(
+    tag_id    int references tags (id) on delete cascade not null,
+    item_type varchar not null,
+    item_id   int not null,
+    primary key (tag_id, item_type, item_id)
+);
+
+create index item_tags_item_idx on item_tags (item_type, item_id);
+
+alter table cards
+    add column folder_id int references folders (id) on delete set null;
+
+alter table user_credentials
+    add column folder_id int references folders (id) on delete set null;
+
+alter table text_data
+    add column folder_id int references folders (id) on delete set null;
+
+alter table binary_file
+    add column folder_id int references folders (id) on delete set null;
@@ -0,0 +1,10 @@
+create table search_tokens
+(
+    user_id   int references users (id) not null,
+    item_type varchar not null,
+    item_id   int not null,
+    token     bytea not null
+);
+
+create index search_tokens_token_idx on search_tokens (user_id, token);
+create index search_tokens_item_idx on search_tokens (item_type, item_id);
@@ -0,0 +1,15 @@
+drop index cards_created_idx;
+drop index cards_updated_idx;
+drop index user_credentials_created_idx;
+drop index user_credentials_updated_idx;
+drop index text_data_created_idx;
+drop index text_data_updated_idx;
+drop index binary_file_created_idx;
+drop index binary_file_updated_idx;
+
+drop trigger update_binary_file_trigger on binary_file;
+
+alter table binary_file
+    drop column update_at;
+
+drop function update_at();
@@ -0,0 +1,24 @@
+create or replace function update_at()
+    returns trigger as $$
+begin
+    new.update_at = now();
+    return new;
+end;
+$$ language plpgsql;
+
+alter table binary_file
+    add column update_at timestamp not null default now();
+
+create trigger update_binary_file_trigger
+    before update on binary_file
+    for each row
+execute function update_at();
+
+create index cards_created_idx on cards (user_id, created_at, id);
+create index cards_updated_idx on cards (user_id, update_at, id);
+create index user_credentials_created_idx on user_credentials (user_id, created_at, id);
+create index user_credentials_updated_idx on user_credentials (user_id, update_at, id);
+create index text_data_created_idx on text_data (user_id, created_at, id);
+create index text_data_updated_idx on text_data (user_id, update_at, id);
+create index binary_file_created_idx on binary_file (user_id, created_at, id);
+create index binary_file_updated_idx on binary_file (user_id, update_at, id);
@@ -0,0 +1,16 @@
+drop table tombstones;
+
+alter table cards
+    drop column change_seq;
+
+alter table user_credentials
+    drop column change_seq;
+
+alter table text_data
+    drop column change_seq;
+
+alter table binary_file
+    drop column change_seq;
+
+alter table users
+    drop column change_seq;
@@ -0,0 +1,30 @@
+alter table users
+    add column change_seq bigint not null default 0;
+
+alter table cards
+    add column change_seq bigint not null default 0;
+
+alter table user_credentials
+    add column change_seq bigint not null default 0;
+
+alter table text_data
+    add column change_seq bigint not null default 0;
+
+alter table binary_file
+    add column change_seq bigint not null default 0;
+
+create index cards_change_seq_idx on cards (user_id, change_seq);
+create index user_credentials_change_seq_idx on user_credentials (user_id, change_seq);
+create index text_data_change_seq_idx on text_data (user_id, change_seq);
+create index binary_file_change_seq_idx on binary_file (user_id, change_seq);
+
+create table tombstones
+(
+    user_id    int references users (id) not null,
+    item_type  varchar not null,
+    item_id    int not null,
+    change_seq bigint not null,
+    deleted_at timestamp not null default now()
+);
+
+create index tombstones_change_seq_idx on tombstones (user_id, change_seq);
@@ -0,0 +1,25 @@
+create table uploads
+(
+    id         varchar primary key,
+    user_id    int references users (id) not null,
+    file_name  varchar not null,
+    size       bigint not null,
+    info       bytea,
+    created_at timestamp not null default now(),
+    update_at  timestamp not null default now()
+);
+
+create index uploads_user_id_idx on uploads (user_id);
+
+create trigger uploads_update_at
+    before update on uploads
+    for each row
+execute function update_at();
+
+create table upload_chunks
+(
+    upload_id varchar references uploads (id) on delete cascade not null,
+    start_at  bigint not null,
+    end_at    bigint not null,
+    primary key (upload_id, start_at, end_at)
+);
@@ -0,0 +1,2 @@
+alter table binary_file
+    drop column sha256;
@@ -0,0 +1,2 @@
+alter table binary_file
+    add column sha256 varchar not null default '';
@@ -0,0 +1,10 @@
+create table blobs
+(
+    user_id    int references users (id) not null,
+    sha256     varchar not null,
+    key        varchar not null unique,
+    size       bigint not null,
+    refs       int not null,
+    created_at timestamp not null default now(),
+    primary key (user_id, sha256)
+);
@@ -0,0 +1,4 @@
+alter table blobs
+    drop column codec;
+alter table binary_file
+    drop column codec;
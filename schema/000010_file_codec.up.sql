@@ -0,0 +1,4 @@
+alter table binary_file
+    add column codec varchar(8) not null default 'gzip';
+alter table blobs
+    add column codec varchar(8) not null default 'gzip';
@@ -0,0 +1,2 @@
+alter table binary_file
+    drop column file_key;
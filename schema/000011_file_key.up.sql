@@ -0,0 +1,2 @@
+alter table binary_file
+    add column file_key varchar not null default '';
@@ -0,0 +1,2 @@
+drop table shares;
+drop table user_keys;
@@ -0,0 +1,21 @@
+create table user_keys
+(
+    user_id     int references users (id) not null primary key,
+    public_key  varchar not null,
+    private_key varchar not null
+);
+
+create table shares
+(
+    id           serial primary key,
+    owner_id     int references users (id) not null,
+    recipient_id int references users (id) not null,
+    item_type    varchar not null,
+    item_id      int not null,
+    permission   varchar(8) not null,
+    file_key     varchar not null default '',
+    created_at   timestamp not null default now(),
+    unique (item_type, item_id, recipient_id)
+);
+create index shares_recipient_idx on shares (recipient_id);
+create index shares_owner_idx on shares (owner_id);
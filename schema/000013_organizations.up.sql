@@ -0,0 +1,18 @@
+create table organizations
+(
+    id            serial primary key,
+    name          varchar not null,
+    vault_user_id int references users (id) not null unique,
+    created_at    timestamp not null default now()
+);
+
+create table org_members
+(
+    org_id     int references organizations (id) on delete cascade not null,
+    user_id    int references users (id) not null,
+    role       varchar(16) not null,
+    org_key    varchar not null default '',
+    created_at timestamp not null default now(),
+    primary key (org_id, user_id)
+);
+create index org_members_user_idx on org_members (user_id);
@@ -0,0 +1 @@
+drop table emergency_access;
@@ -0,0 +1,15 @@
+create table emergency_access
+(
+    id           serial primary key,
+    owner_id     int references users (id) not null,
+    contact_id   int references users (id) not null,
+    wait_hours   int not null,
+    status       varchar(16) not null default 'idle',
+    vault_key    varchar not null default '',
+    requested_at timestamp,
+    granted_at   timestamp,
+    created_at   timestamp not null default now(),
+    unique (owner_id, contact_id)
+);
+create index emergency_access_contact_idx on emergency_access (contact_id);
+create index emergency_access_requested_idx on emergency_access (requested_at) where status = 'requested';
@@ -0,0 +1 @@
+drop table share_links;
@@ -0,0 +1,13 @@
+create table share_links
+(
+    id         varchar(32) primary key,
+    user_id    int references users (id) not null,
+    kind       varchar(16) not null,
+    data       bytea not null,
+    max_views  int not null,
+    views      int not null default 0,
+    expires_at timestamp not null,
+    created_at timestamp not null default now()
+);
+create index share_links_user_idx on share_links (user_id);
+create index share_links_expires_idx on share_links (expires_at);
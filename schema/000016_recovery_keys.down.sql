@@ -0,0 +1,4 @@
+alter table users
+    drop column vault_key;
+
+drop table recovery_keys;
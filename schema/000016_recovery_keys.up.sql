@@ -0,0 +1,10 @@
+create table recovery_keys
+(
+    user_id    int primary key references users (id) on delete cascade,
+    key_hash   varchar not null,
+    vault_key  varchar not null,
+    created_at timestamp not null default now()
+);
+
+alter table users
+    add column vault_key varchar not null default '';
@@ -0,0 +1,6 @@
+delete from item_tags where item_type = 'otp';
+delete from search_tokens where item_type = 'otp';
+delete from shares where item_type = 'otp';
+delete from tombstones where item_type = 'otp';
+
+drop table otp_keys;
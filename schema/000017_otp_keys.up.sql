@@ -0,0 +1,30 @@
+create table otp_keys
+(
+    id         bigserial not null unique primary key,
+    user_id    int references users (id) not null,
+    type       varchar(8) not null,
+    issuer     bytea not null,
+    account    bytea not null,
+    secret     bytea not null,
+    algorithm  varchar(8) not null,
+    digits     int not null,
+    period     int not null,
+    counter    bigint not null default 0,
+    title      bytea,
+    note       bytea,
+    metadata   bytea,
+    folder_id  int references folders (id) on delete set null,
+    change_seq bigint not null default 0,
+    version    int default 1,
+    created_at timestamp not null default now(),
+    update_at  timestamp not null default now()
+);
+
+create trigger update_otp_keys_trigger
+    before update on otp_keys
+    for each row
+execute function update_version();
+
+create index otp_keys_created_idx on otp_keys (user_id, created_at, id);
+create index otp_keys_updated_idx on otp_keys (user_id, update_at, id);
+create index otp_keys_change_seq_idx on otp_keys (user_id, change_seq);
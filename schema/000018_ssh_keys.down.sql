@@ -0,0 +1,6 @@
+delete from item_tags where item_type = 'ssh_key';
+delete from search_tokens where item_type = 'ssh_key';
+delete from shares where item_type = 'ssh_key';
+delete from tombstones where item_type = 'ssh_key';
+
+drop table ssh_keys;
@@ -0,0 +1,29 @@
+create table ssh_keys
+(
+    id          bigserial not null unique primary key,
+    user_id     int references users (id) not null,
+    key_type    varchar(64) not null,
+    private_key bytea not null,
+    public_key  text not null,
+    fingerprint varchar(64) not null,
+    certificate text not null default '',
+    comment     bytea not null,
+    passphrase  bytea not null,
+    title       bytea,
+    note        bytea,
+    metadata    bytea,
+    folder_id   int references folders (id) on delete set null,
+    change_seq  bigint not null default 0,
+    version     int default 1,
+    created_at  timestamp not null default now(),
+    update_at   timestamp not null default now()
+);
+
+create trigger update_ssh_keys_trigger
+    before update on ssh_keys
+    for each row
+execute function update_version();
+
+create index ssh_keys_created_idx on ssh_keys (user_id, created_at, id);
+create index ssh_keys_updated_idx on ssh_keys (user_id, update_at, id);
+create index ssh_keys_change_seq_idx on ssh_keys (user_id, change_seq);